@@ -0,0 +1,300 @@
+// Package bidcrypto implements sealed-bid encryption for tendercc.
+//
+// A buyer generates a P-256 tender key, publishes the public half on the
+// tender and splits the private scalar into Shamir shares for the evaluators.
+// Bidders encrypt their bid with Encrypt (ECIES: ephemeral ECDH + AES-256-GCM).
+// After the tender closes, evaluators release their shares on-chain; once the
+// threshold is reached CombineShares rebuilds the key and bids can be opened.
+package bidcrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// curveOrder is the order of the P-256 base point; shares live in the field of this size
+var curveOrder = elliptic.P256().Params().N
+
+const (
+	pointLen = 65 // uncompressed P-256 point
+	nonceLen = 12
+)
+
+// Share is one evaluator's piece of the tender private key
+type Share struct {
+	Index int    `json:"index"` // x coordinate, 1..n
+	Value string `json:"value"` // hex encoded scalar
+}
+
+// GenerateTenderKey creates a new tender key pair
+func GenerateTenderKey(rand io.Reader) (*ecdh.PrivateKey, error) {
+	return ecdh.P256().GenerateKey(rand)
+}
+
+// ParsePrivateKey decodes a raw 32 byte tender private key
+func ParsePrivateKey(key []byte) (*ecdh.PrivateKey, error) {
+	k, err := ecdh.P256().NewPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return k, nil
+}
+
+// MarshalPublicKey encodes a tender public key as a PKIX PEM block
+func MarshalPublicKey(pub *ecdh.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParsePublicKey decodes a PKIX PEM encoded P-256 public key
+func ParsePublicKey(pemKey string) (*ecdh.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("public key must be a PEM encoded PUBLIC KEY block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("public key must be on curve P-256")
+		}
+		return k.ECDH()
+	case *ecdh.PublicKey:
+		if k.Curve() != ecdh.P256() {
+			return nil, errors.New("public key must be on curve P-256")
+		}
+		return k, nil
+	default:
+		return nil, errors.New("public key must be an EC key")
+	}
+}
+
+// BidContext is the associated data used for bid ciphertexts. Binding the
+// tender and bid IDs stops a ciphertext being resubmitted under another bid.
+func BidContext(tenderID, bidID string) []byte {
+	return []byte(tenderID + "|" + bidID)
+}
+
+// deriveKey turns an ECDH secret into an AES-256 key bound to the ephemeral key and context
+func deriveKey(secret, ephemeral, context []byte) []byte {
+	h := sha256.New()
+	h.Write(secret)
+	h.Write(ephemeral)
+	h.Write(context)
+	return h.Sum(nil)
+}
+
+// Encrypt seals plaintext to a tender public key. The context (normally the
+// tender ID) is authenticated so a ciphertext cannot be replayed on another tender.
+// Output layout: ephemeral public key (65) || nonce (12) || AES-GCM ciphertext.
+func Encrypt(rand io.Reader, pub *ecdh.PublicKey, context, plaintext []byte) ([]byte, error) {
+	eph, err := ecdh.P256().GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := eph.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	ephBytes := eph.PublicKey().Bytes()
+	gcm, err := newGCM(deriveKey(secret, ephBytes, context))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceLen)
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, pointLen+nonceLen+len(plaintext)+gcm.Overhead())
+	out = append(out, ephBytes...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, context), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt
+func Decrypt(priv *ecdh.PrivateKey, context, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < pointLen+nonceLen {
+		return nil, errors.New("ciphertext too short")
+	}
+	eph, err := ecdh.P256().NewPublicKey(ciphertext[:pointLen])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral key: %v", err)
+	}
+	secret, err := priv.ECDH(eph)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(deriveKey(secret, ciphertext[:pointLen], context))
+	if err != nil {
+		return nil, err
+	}
+	nonce := ciphertext[pointLen : pointLen+nonceLen]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext[pointLen+nonceLen:], context)
+	if err != nil {
+		return nil, errors.New("ciphertext authentication failed")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SplitKey splits a tender private key into n shares, any threshold of which rebuild it.
+// It also returns the public commitment (share * G) for each share so shares
+// can be checked on-chain without revealing them early.
+func SplitKey(rand io.Reader, priv *ecdh.PrivateKey, threshold, n int) ([]Share, []string, error) {
+	if threshold < 1 || threshold > n {
+		return nil, nil, fmt.Errorf("threshold must be between 1 and %d", n)
+	}
+	coeffs := make([]*big.Int, threshold)
+	coeffs[0] = new(big.Int).SetBytes(priv.Bytes())
+	for i := 1; i < threshold; i++ {
+		c, err := randomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		coeffs[i] = c
+	}
+
+	shares := make([]Share, n)
+	commitments := make([]string, n)
+	for i := 1; i <= n; i++ {
+		// Horner evaluation of the polynomial at x = i
+		x := big.NewInt(int64(i))
+		y := new(big.Int)
+		for j := threshold - 1; j >= 0; j-- {
+			y.Mul(y, x)
+			y.Add(y, coeffs[j])
+			y.Mod(y, curveOrder)
+		}
+		shares[i-1] = Share{Index: i, Value: hex.EncodeToString(scalarBytes(y))}
+		c, err := Commitment(shares[i-1])
+		if err != nil {
+			return nil, nil, err
+		}
+		commitments[i-1] = c
+	}
+	return shares, commitments, nil
+}
+
+// Commitment returns the hex encoded public point share * G
+func Commitment(share Share) (string, error) {
+	k, err := shareKey(share)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(k.PublicKey().Bytes()), nil
+}
+
+// VerifyShare checks a released share against its published commitment
+func VerifyShare(share Share, commitment string) error {
+	c, err := Commitment(share)
+	if err != nil {
+		return err
+	}
+	if c != commitment {
+		return fmt.Errorf("share %d does not match its commitment", share.Index)
+	}
+	return nil
+}
+
+// CombineShares rebuilds the tender private key from at least threshold shares
+func CombineShares(shares []Share) (*ecdh.PrivateKey, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares")
+	}
+	xs := make([]*big.Int, len(shares))
+	ys := make([]*big.Int, len(shares))
+	seen := make(map[int]bool)
+	for i, s := range shares {
+		if s.Index < 1 {
+			return nil, fmt.Errorf("invalid share index %d", s.Index)
+		}
+		if seen[s.Index] {
+			return nil, fmt.Errorf("duplicate share index %d", s.Index)
+		}
+		seen[s.Index] = true
+		v, err := hex.DecodeString(s.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid share %d: %v", s.Index, err)
+		}
+		xs[i] = big.NewInt(int64(s.Index))
+		ys[i] = new(big.Int).SetBytes(v)
+	}
+
+	// Lagrange interpolation at x = 0
+	secret := new(big.Int)
+	for i := range shares {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			num.Mul(num, xs[j])
+			num.Mod(num, curveOrder)
+			diff := new(big.Int).Sub(xs[j], xs[i])
+			den.Mul(den, diff)
+			den.Mod(den, curveOrder)
+		}
+		inv := new(big.Int).ModInverse(den, curveOrder)
+		if inv == nil {
+			return nil, errors.New("shares cannot be combined")
+		}
+		term := new(big.Int).Mul(ys[i], num)
+		term.Mul(term, inv)
+		secret.Add(secret, term)
+		secret.Mod(secret, curveOrder)
+	}
+	return ecdh.P256().NewPrivateKey(scalarBytes(secret))
+}
+
+func shareKey(share Share) (*ecdh.PrivateKey, error) {
+	v, err := hex.DecodeString(share.Value)
+	if err != nil || len(v) != 32 {
+		return nil, fmt.Errorf("share %d must be a 32 byte hex scalar", share.Index)
+	}
+	return ecdh.P256().NewPrivateKey(v)
+}
+
+func randomScalar(rand io.Reader) (*big.Int, error) {
+	for {
+		b := make([]byte, 32)
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).SetBytes(b)
+		if k.Sign() > 0 && k.Cmp(curveOrder) < 0 {
+			return k, nil
+		}
+	}
+}
+
+// scalarBytes left-pads a scalar to 32 bytes
+func scalarBytes(k *big.Int) []byte {
+	out := make([]byte, 32)
+	k.FillBytes(out)
+	return out
+}
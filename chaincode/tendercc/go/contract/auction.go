@@ -0,0 +1,513 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// Auction types supported on EnhancedTender.AuctionType
+const (
+	auctionTypeSealed  = "SEALED"  // default single sealed bid per contractor
+	auctionTypeReverse = "REVERSE" // live descending-price e-auction
+)
+
+func auctionStateKey(tenderID string) string {
+	return fmt.Sprintf("AUCTION_%s", tenderID)
+}
+
+func auctionResultKey(tenderID string) string {
+	return fmt.Sprintf("AUCTIONRESULT_%s", tenderID)
+}
+
+// auctionOfferKey is keyed by bidder alias, so each bidder holds one live offer; the
+// public BidRef of an offer carries the alias as its contractor
+func auctionOfferKey(tenderID, alias string) string {
+	return fmt.Sprintf("AUCTIONOFFER_%s_%s", tenderID, alias)
+}
+
+func isReverseAuction(tender *EnhancedTender) bool {
+	return tender.AuctionType == auctionTypeReverse
+}
+
+// validateAuctionConfig checks the auction settings of a REVERSE tender
+func validateAuctionConfig(tender *EnhancedTender) error {
+	switch tender.AuctionType {
+	case "", auctionTypeSealed:
+		if tender.Auction != nil {
+			return fmt.Errorf("auction settings are only allowed for %s tenders", auctionTypeReverse)
+		}
+		return nil
+	case auctionTypeReverse:
+	default:
+		return fmt.Errorf("unknown auction type %s", tender.AuctionType)
+	}
+
+	cfg := tender.Auction
+	if cfg == nil {
+		return fmt.Errorf("auction settings are required for %s tenders", auctionTypeReverse)
+	}
+	start, err := time.Parse(time.RFC3339, cfg.StartTime)
+	if err != nil {
+		return fmt.Errorf("invalid auction start time: %v", err)
+	}
+	end, err := time.Parse(time.RFC3339, cfg.EndTime)
+	if err != nil {
+		return fmt.Errorf("invalid auction end time: %v", err)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("auction end time must be after start time")
+	}
+	if cfg.MinDecrement < 0 || cfg.MinDecrementPercent < 0 || cfg.MinDecrementPercent >= 100 {
+		return fmt.Errorf("minimum decrement must be non-negative and below 100%%")
+	}
+	if cfg.StartingPrice < 0 {
+		return fmt.Errorf("starting price must not be negative")
+	}
+	if cfg.ExtensionWindowSecs < 0 || cfg.ExtensionSecs < 0 || cfg.MaxExtensions < 0 {
+		return fmt.Errorf("anti-sniping settings must not be negative")
+	}
+	if cfg.ExtensionWindowSecs > 0 && cfg.ExtensionSecs == 0 {
+		return fmt.Errorf("extension length is required when an extension window is set")
+	}
+	return nil
+}
+
+// requiredDecrement returns how much a new offer must undercut the previous amount
+func requiredDecrement(cfg *AuctionConfig, previous float64) float64 {
+	dec := cfg.MinDecrement
+	if pct := previous * cfg.MinDecrementPercent / 100.0; pct > dec {
+		dec = pct
+	}
+	return dec
+}
+
+// bidderAlias derives a stable pseudonym for a bidder within one tender
+func bidderAlias(tenderID, bidderID string) string {
+	sum := sha256.Sum256([]byte(tenderID + "|" + bidderID))
+	return "BIDDER-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// auctionWindow reads the shared auction record, which only holds the status, close
+// time and extensions. It is written when an offer extends the auction or the auction
+// closes, so offers that do neither do not conflict with each other.
+func (s *EnhancedSmartContract) auctionWindow(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) (*AuctionState, error) {
+	data, err := ctx.GetStub().GetState(auctionStateKey(tender.ID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return &AuctionState{
+			TenderID: tender.ID,
+			Status:   "SCHEDULED",
+			EndTime:  tender.Auction.EndTime,
+		}, nil
+	}
+	var state AuctionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (s *EnhancedSmartContract) putAuctionWindow(ctx contractapi.TransactionContextInterface, state *AuctionState) error {
+	window := AuctionState{TenderID: state.TenderID, Status: state.Status, EndTime: state.EndTime, Extensions: state.Extensions}
+	data, _ := json.Marshal(window)
+	return ctx.GetStub().PutState(auctionStateKey(state.TenderID), data)
+}
+
+// getAuctionState adds the offer totals, computed from the per-bidder offer keys, to the
+// auction window
+func (s *EnhancedSmartContract) getAuctionState(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) (*AuctionState, error) {
+	state, err := s.auctionWindow(ctx, tender)
+	if err != nil {
+		return nil, err
+	}
+	offers, err := s.listAuctionOffers(ctx, tender.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range offers {
+		state.OfferCount += o.OfferCount
+		if state.LeadingAmount == 0 || o.Amount < state.LeadingAmount {
+			state.LeadingAmount = o.Amount
+		}
+		if o.PlacedAt > state.LastOfferAt {
+			state.LastOfferAt = o.PlacedAt
+		}
+	}
+	state.BidderCount = len(offers)
+	if state.Status == "SCHEDULED" && len(offers) > 0 {
+		state.Status = "RUNNING"
+	}
+	return state, nil
+}
+
+func (s *EnhancedSmartContract) listAuctionOffers(ctx contractapi.TransactionContextInterface, tenderID string) ([]*AuctionOffer, error) {
+	iter, err := ctx.GetStub().GetPrivateDataByRange(privateCollectionName, "AUCTIONOFFER_"+tenderID+"_", "AUCTIONOFFER_"+tenderID+"_~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var out []*AuctionOffer
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var o AuctionOffer
+		if err := json.Unmarshal(kv.Value, &o); err == nil {
+			out = append(out, &o)
+		}
+	}
+	return out, nil
+}
+
+// rankAuctionOffers orders offers lowest amount first; ties go to the earlier offer
+func rankAuctionOffers(offers []*AuctionOffer) {
+	sort.SliceStable(offers, func(i, j int) bool {
+		if offers[i].Amount != offers[j].Amount {
+			return offers[i].Amount < offers[j].Amount
+		}
+		if offers[i].PlacedAt != offers[j].PlacedAt {
+			return offers[i].PlacedAt < offers[j].PlacedAt
+		}
+		return offers[i].BidID < offers[j].BidID
+	})
+}
+
+// PlaceAuctionBid records an improving offer in a reverse auction.
+// The offer is read from the transient map key "auctionBid" so amounts and
+// contractor identities stay in the private bids collection. Tenders that require
+// digital signatures need the contractor's signature over each offer as submitted.
+func (s *EnhancedSmartContract) PlaceAuctionBid(ctx contractapi.TransactionContextInterface, tenderID, bidID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if !isReverseAuction(tender) {
+		return fmt.Errorf("tender %s is not a reverse auction", tenderID)
+	}
+	if tender.Status != "OPEN" {
+		return fmt.Errorf("tender is not open for bids")
+	}
+
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	state, err := s.auctionWindow(ctx, tender)
+	if err != nil {
+		return err
+	}
+	start, _ := time.Parse(time.RFC3339, tender.Auction.StartTime)
+	end, err := time.Parse(time.RFC3339, state.EndTime)
+	if err != nil {
+		return fmt.Errorf("invalid auction end time: %v", err)
+	}
+	if txTime.Before(start) {
+		return fmt.Errorf("auction for tender %s has not started", tenderID)
+	}
+	if !txTime.Before(end) {
+		return fmt.Errorf("auction for tender %s has ended", tenderID)
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient: %v", err)
+	}
+	offerBytes, ok := transient["auctionBid"]
+	if !ok {
+		return fmt.Errorf("transient map must contain 'auctionBid'")
+	}
+	var offer AuctionOffer
+	if err := json.Unmarshal(offerBytes, &offer); err != nil {
+		return fmt.Errorf("invalid auction bid JSON: %v", err)
+	}
+	if offer.TenderID != tenderID || offer.BidID != bidID {
+		return fmt.Errorf("tenderId/bidId mismatch")
+	}
+	if offer.ContractorID == "" {
+		return fmt.Errorf("contractor ID is required")
+	}
+	if offer.Amount <= 0 {
+		return fmt.Errorf("offer amount must be positive")
+	}
+	if cur := tender.ProjectScope.Budget.Currency; cur != "" && offer.Currency != "" && offer.Currency != cur {
+		return fmt.Errorf("offer currency %s does not match tender currency %s", offer.Currency, cur)
+	}
+
+	if err := s.checkBidder(ctx, tender, offer.ContractorID, txTime); err != nil {
+		return err
+	}
+
+	bidderID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	// Only improving offers are accepted: a bidder must beat their own last offer,
+	// under the bid they opened with
+	alias := bidderAlias(tenderID, bidderID)
+	prevBytes, err := ctx.GetStub().GetPrivateData(privateCollectionName, auctionOfferKey(tenderID, alias))
+	if err != nil {
+		return err
+	}
+	if prevBytes != nil {
+		var prev AuctionOffer
+		if err := json.Unmarshal(prevBytes, &prev); err != nil {
+			return err
+		}
+		if prev.BidID != bidID {
+			return fmt.Errorf("caller already bids on tender %s as %s", tenderID, prev.BidID)
+		}
+		if prev.ContractorID != offer.ContractorID {
+			return fmt.Errorf("bid %s was placed for contractor %s", bidID, prev.ContractorID)
+		}
+		if dec := requiredDecrement(tender.Auction, prev.Amount); offer.Amount > prev.Amount-dec || offer.Amount >= prev.Amount {
+			return fmt.Errorf("offer must improve on the previous offer of %.2f by at least %.2f", prev.Amount, dec)
+		}
+		offer.OfferCount = prev.OfferCount + 1
+	} else {
+		if exists, err := s.assetExists(ctx, bidRefKey(tenderID, bidID)); err != nil {
+			return err
+		} else if exists {
+			return fmt.Errorf("bid %s already exists for tender %s", bidID, tenderID)
+		}
+		if ceiling := tender.Auction.StartingPrice; ceiling > 0 && offer.Amount > ceiling {
+			return fmt.Errorf("offer must not exceed the starting price of %.2f", ceiling)
+		}
+		offer.OfferCount = 1
+	}
+	// The signature names the bidder, so its record stays private with the offer
+	sigRecord, err := verifySignature(ctx, tenderID, sigSubjectBid, bidID, offerBytes, offer.ContractorID, signaturesRequired(tender))
+	if err != nil {
+		return err
+	}
+	if sigRecord != nil {
+		recordBytes, _ := json.Marshal(sigRecord)
+		if err := ctx.GetStub().PutPrivateData(privateCollectionName, signatureKey(tenderID, sigSubjectBid, bidID), recordBytes); err != nil {
+			return fmt.Errorf("failed to store offer signature: %v", err)
+		}
+	}
+
+	offer.BidderAlias = alias
+	offer.BidderID = bidderID
+	offer.PlacedAt = txTime.Format(time.RFC3339)
+	if offer.Currency == "" {
+		offer.Currency = tender.ProjectScope.Budget.Currency
+	}
+
+	stored, err := canonicalMarshal(offer)
+	if err != nil {
+		return err
+	}
+	// Anti-sniping: offers close to the end that take the lead push the close time
+	// out. Only these offers read the other offers and write the shared auction record.
+	cfg := tender.Auction
+	extend := false
+	if cfg.ExtensionWindowSecs > 0 && end.Sub(txTime) <= time.Duration(cfg.ExtensionWindowSecs)*time.Second &&
+		(cfg.MaxExtensions == 0 || state.Extensions < cfg.MaxExtensions) {
+		offers, err := s.listAuctionOffers(ctx, tenderID)
+		if err != nil {
+			return err
+		}
+		extend = true
+		for _, o := range offers {
+			if o.Amount <= offer.Amount {
+				extend = false
+				break
+			}
+		}
+	}
+
+	if err := ctx.GetStub().PutPrivateData(privateCollectionName, auctionOfferKey(tenderID, alias), stored); err != nil {
+		return fmt.Errorf("failed to store auction offer: %v", err)
+	}
+
+	// The public ref names the bidder by alias only and tracks the latest offer hash
+	hash := sha256.Sum256(stored)
+	ref := BidRef{
+		TenderID:     tenderID,
+		BidID:        bidID,
+		ContractorID: offer.BidderAlias,
+		BidHash:      hex.EncodeToString(hash[:]),
+		DocType:      docTypeBidRef,
+	}
+	refBytes, _ := json.Marshal(ref)
+	if err := ctx.GetStub().PutState(bidRefKey(tenderID, bidID), refBytes); err != nil {
+		return err
+	}
+	if err := indexBidRef(ctx, &ref); err != nil {
+		return err
+	}
+
+	if extend {
+		state.Status = "RUNNING"
+		state.EndTime = end.Add(time.Duration(cfg.ExtensionSecs) * time.Second).Format(time.RFC3339)
+		state.Extensions++
+		if err := s.putAuctionWindow(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	// Emit event without amounts or identities
+	return emitEvent(ctx, events.AuctionBidPlaced, tenderID, events.AuctionBidPayload{
+		TenderID:    tenderID,
+		BidID:       bidID,
+		BidderAlias: offer.BidderAlias,
+		OfferCount:  offer.OfferCount,
+		EndTime:     state.EndTime,
+		Extended:    extend,
+	})
+}
+
+// GetAuctionState returns the public progress of a reverse auction
+func (s *EnhancedSmartContract) GetAuctionState(ctx contractapi.TransactionContextInterface, tenderID string) (*AuctionState, error) {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isReverseAuction(tender) {
+		return nil, fmt.Errorf("tender %s is not a reverse auction", tenderID)
+	}
+	return s.getAuctionState(ctx, tender)
+}
+
+// GetMyAuctionRank returns the caller's rank without exposing competitors
+func (s *EnhancedSmartContract) GetMyAuctionRank(ctx contractapi.TransactionContextInterface, tenderID string) (*AuctionRank, error) {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isReverseAuction(tender) {
+		return nil, fmt.Errorf("tender %s is not a reverse auction", tenderID)
+	}
+	state, err := s.getAuctionState(ctx, tender)
+	if err != nil {
+		return nil, err
+	}
+	bidderID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	offers, err := s.listAuctionOffers(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	rankAuctionOffers(offers)
+	for i, o := range offers {
+		if o.BidderID != bidderID {
+			continue
+		}
+		return &AuctionRank{
+			TenderID:      tenderID,
+			BidID:         o.BidID,
+			Rank:          i + 1,
+			BidderCount:   len(offers),
+			MyAmount:      o.Amount,
+			LeadingAmount: offers[0].Amount,
+			EndTime:       state.EndTime,
+			Status:        state.Status,
+		}, nil
+	}
+	return nil, fmt.Errorf("no auction offer found for caller on tender %s", tenderID)
+}
+
+// GetAuctionResult returns the final ranking of a closed reverse auction
+func (s *EnhancedSmartContract) GetAuctionResult(ctx contractapi.TransactionContextInterface, tenderID string) (*AuctionResult, error) {
+	data, err := ctx.GetStub().GetState(auctionResultKey(tenderID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("auction result for tender %s not found", tenderID)
+	}
+	var result AuctionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// closeAuction freezes the ranking and records one evaluation per bid so the
+// lowest offer wins through AwardBestBid/AwardTender
+func (s *EnhancedSmartContract) closeAuction(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, now time.Time) error {
+	state, err := s.auctionWindow(ctx, tender)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.RFC3339, state.EndTime)
+	if err != nil {
+		return fmt.Errorf("invalid auction end time: %v", err)
+	}
+	if now.Before(end) {
+		return fmt.Errorf("auction for tender %s is still running until %s", tender.ID, state.EndTime)
+	}
+
+	offers, err := s.listAuctionOffers(ctx, tender.ID)
+	if err != nil {
+		return err
+	}
+	rankAuctionOffers(offers)
+
+	result := AuctionResult{TenderID: tender.ID, ClosedAt: now.Format(time.RFC3339)}
+	for i, o := range offers {
+		result.Ranking = append(result.Ranking, AuctionResultEntry{
+			Rank:        i + 1,
+			BidID:       o.BidID,
+			BidderAlias: o.BidderAlias,
+			Amount:      o.Amount,
+			PlacedAt:    o.PlacedAt,
+		})
+
+		// Score relative to the leading offer: the winner gets 100
+		eval := Evaluation{
+			TenderID: tender.ID,
+			BidID:    o.BidID,
+			Score:    100.0 * offers[0].Amount / o.Amount,
+			Notes:    fmt.Sprintf("Reverse auction rank %d", i+1),
+		}
+		evalBytes, _ := json.Marshal(eval)
+		if err := ctx.GetStub().PutState(evalKey(tender.ID, o.BidID), evalBytes); err != nil {
+			return err
+		}
+	}
+	resultBytes, _ := json.Marshal(result)
+	if err := ctx.GetStub().PutState(auctionResultKey(tender.ID), resultBytes); err != nil {
+		return err
+	}
+
+	state.Status = "CLOSED"
+	return s.putAuctionWindow(ctx, state)
+}
+
+// validateAuctionAward only lets the top-ranked offer of a reverse auction win; with
+// several winners, as in a framework, the top-ranked offer must be one of them
+func (s *EnhancedSmartContract) validateAuctionAward(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, bidIDs ...string) error {
+	result, err := s.GetAuctionResult(ctx, tender.ID)
+	if err != nil {
+		return err
+	}
+	if len(result.Ranking) == 0 {
+		return fmt.Errorf("auction for tender %s received no offers", tender.ID)
+	}
+	top := result.Ranking[0].BidID
+	for _, bidID := range bidIDs {
+		if bidID == top {
+			return nil
+		}
+	}
+	if len(bidIDs) == 1 {
+		return fmt.Errorf("bid %s is not the top-ranked auction offer", bidIDs[0])
+	}
+	return fmt.Errorf("top-ranked auction offer %s must be one of the winning bids", top)
+}
@@ -0,0 +1,345 @@
+package contract
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+var (
+	auctionStart = t0.Add(time.Hour)
+	auctionEnd   = t0.Add(2 * time.Hour)
+)
+
+// auctionFixture is a reverse auction running from auctionStart to auctionEnd
+func auctionFixture(id string) *EnhancedTender {
+	tender := tenderFixture(id, auctionEnd)
+	tender.AuctionType = auctionTypeReverse
+	tender.Auction = &AuctionConfig{
+		StartTime:           rfc(auctionStart),
+		EndTime:             rfc(auctionEnd),
+		StartingPrice:       120000,
+		MinDecrement:        1000,
+		ExtensionWindowSecs: 120,
+		ExtensionSecs:       300,
+		MaxExtensions:       1,
+	}
+	return tender
+}
+
+func (n *testNet) placeOffer(who, tenderID, bidID string, amount float64) error {
+	n.t.Helper()
+	offer := AuctionOffer{TenderID: tenderID, BidID: bidID, ContractorID: who, Amount: amount}
+	return n.tx(who, transientOf(n.t, "auctionBid", offer), func(ctx *TransactionContext) error {
+		return n.enh.PlaceAuctionBid(ctx, tenderID, bidID)
+	})
+}
+
+func TestAuctionConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(e *EnhancedTender)
+		wantErr string
+	}{
+		{"valid", func(*EnhancedTender) {}, ""},
+		{"sealed with settings", func(e *EnhancedTender) { e.AuctionType = auctionTypeSealed }, "auction settings are only allowed for REVERSE tenders"},
+		{"no settings", func(e *EnhancedTender) { e.Auction = nil }, "auction settings are required for REVERSE tenders"},
+		{"bad start", func(e *EnhancedTender) { e.Auction.StartTime = "" }, "invalid auction start time"},
+		{"end before start", func(e *EnhancedTender) { e.Auction.EndTime = e.Auction.StartTime }, "auction end time must be after start time"},
+		{"percent decrement of 100", func(e *EnhancedTender) { e.Auction.MinDecrementPercent = 100 }, "minimum decrement must be non-negative and below 100%"},
+		{"negative starting price", func(e *EnhancedTender) { e.Auction.StartingPrice = -1 }, "starting price must not be negative"},
+		{"window without extension", func(e *EnhancedTender) { e.Auction.ExtensionSecs = 0 }, "extension length is required"},
+		{"lots", func(e *EnhancedTender) { e.Lots = []Lot{{ID: "L1", Name: "North"}} }, "reverse auctions cannot be split into lots"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := auctionFixture("A1")
+			tc.mutate(tender)
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateEnhancedTender(ctx, mustJSON(t, tender))
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestPlaceAuctionBid(t *testing.T) {
+	tests := []struct {
+		name    string
+		at      time.Time
+		who     string
+		bidID   string
+		amount  float64
+		wantErr string
+	}{
+		{name: "at start", at: auctionStart, who: "contractorB", bidID: "B2", amount: 110000},
+		{name: "at starting price", at: auctionStart, who: "contractorB", bidID: "B2", amount: 120000},
+		{name: "before start", at: auctionStart.Add(-time.Second), who: "contractorB", bidID: "B2", amount: 100000, wantErr: "auction for tender A1 has not started"},
+		{name: "at end", at: auctionEnd, who: "contractorB", bidID: "B2", amount: 100000, wantErr: "auction for tender A1 has ended"},
+		{name: "above starting price", at: auctionStart, who: "contractorB", bidID: "B2", amount: 120001, wantErr: "offer must not exceed the starting price of 120000.00"},
+		{name: "zero", at: auctionStart, who: "contractorB", bidID: "B2", amount: 0, wantErr: "offer amount must be positive"},
+		{name: "improves by decrement", at: auctionStart, who: "contractorA", bidID: "B1", amount: 99000},
+		{name: "improves by less than decrement", at: auctionStart, who: "contractorA", bidID: "B1", amount: 99500, wantErr: "offer must improve on the previous offer of 100000.00 by at least 1000.00"},
+		{name: "another bidder's bid", at: auctionStart, who: "contractorB", bidID: "B1", amount: 90000, wantErr: "bid B1 already exists for tender A1"},
+		{name: "second bid ID", at: auctionStart, who: "contractorA", bidID: "B9", amount: 90000, wantErr: "caller already bids on tender A1 as B1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.openTender(auctionFixture("A1"))
+			n.ledger.SetTime(auctionStart)
+			if err := n.placeOffer("contractorA", "A1", "B1", 100000); err != nil {
+				t.Fatal(err)
+			}
+			n.ledger.SetTime(tc.at)
+			expectErr(t, n.placeOffer(tc.who, "A1", tc.bidID, tc.amount), tc.wantErr)
+			if tc.wantErr == "" {
+				n.expectEvents(events.AuctionBidPlaced)
+			}
+		})
+	}
+
+	t.Run("sealed tender", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(tenderFixture("T1", auctionEnd))
+		expectErr(t, n.placeOffer("contractorA", "T1", "B1", 1), "tender T1 is not a reverse auction")
+	})
+
+	t.Run("sealed bid on auction", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(auctionFixture("A1"))
+		expectErr(t, n.submitBid("contractorA", bidFixture("A1", "B1", "contractorA", 1)), "tender A1 is a reverse auction; use PlaceAuctionBid")
+	})
+
+	t.Run("prequalification", func(t *testing.T) {
+		n := newTestNet(t)
+		n.registerVendor("buyer", vendorFixture("contractorA"))
+		n.registerVendor("contractorB", vendorFixture("contractorB"))
+		tender := auctionFixture("A1")
+		tender.BidRequirements.PrequalificationRequired = true
+		n.openTender(tender)
+		n.ledger.SetTime(auctionStart)
+		expectErr(t, n.placeOffer("contractorA", "A1", "B1", 100000), "vendor contractorA is registered by BuyerMSP, not ContractorAMSP")
+		expectErr(t, n.placeOffer("contractorC", "A1", "B3", 100000), "contractor contractorC is not prequalified: vendor contractorC not found")
+		if err := n.placeOffer("contractorB", "A1", "B2", 100000); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("signed offers", func(t *testing.T) {
+		n := newTestNet(t)
+		tender := auctionFixture("A1")
+		tender.BidRequirements.SubmissionFormat.DigitalSignature = true
+		n.openTender(tender)
+		n.ledger.SetTime(auctionStart)
+		expectErr(t, n.placeOffer("contractorA", "A1", "B1", 100000), "tender A1 requires a signed BID payload")
+
+		offer := []byte(mustJSON(t, AuctionOffer{TenderID: "A1", BidID: "B1", ContractorID: "contractorA", Amount: 100000}))
+		place := func(signer string) error {
+			transient := with(map[string][]byte{"auctionBid": offer}, signedBy(t, n.identity(signer), "", offer))
+			return n.tx("contractorA", transient, func(ctx *TransactionContext) error { return n.enh.PlaceAuctionBid(ctx, "A1", "B1") })
+		}
+		expectErr(t, place("contractorB"), "signature verification failed: invalid ECDSA signature")
+		if err := place("contractorA"); err != nil {
+			t.Fatal(err)
+		}
+
+		// The record names the bidder, so it is kept out of the public signatures
+		var record SignatureRecord
+		if err := json.Unmarshal(n.ledger.PrivateData(privateCollectionName, signatureKey("A1", sigSubjectBid, "B1")), &record); err != nil {
+			t.Fatal(err)
+		}
+		if !record.Verified || record.SignerID != "contractorA" {
+			t.Fatalf("offer signature = %+v", record)
+		}
+		err := n.query("auditor", func(ctx *TransactionContext) error {
+			_, err := n.enh.GetSignatureRecord(ctx, "A1", sigSubjectBid, "B1")
+			return err
+		})
+		expectErr(t, err, "no signature recorded for BID B1 on tender A1")
+	})
+
+	t.Run("transient", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(auctionFixture("A1"))
+		n.ledger.SetTime(auctionStart)
+		err := n.tx("contractorA", nil, func(ctx *TransactionContext) error { return n.enh.PlaceAuctionBid(ctx, "A1", "B1") })
+		expectErr(t, err, "transient map must contain 'auctionBid'")
+		offer := AuctionOffer{TenderID: "A1", BidID: "B2", ContractorID: "contractorA", Amount: 1}
+		err = n.tx("contractorA", transientOf(t, "auctionBid", offer), func(ctx *TransactionContext) error { return n.enh.PlaceAuctionBid(ctx, "A1", "B1") })
+		expectErr(t, err, "tenderId/bidId mismatch")
+		offer = AuctionOffer{TenderID: "A1", BidID: "B1", ContractorID: "contractorA", Amount: 1, Currency: "EUR"}
+		err = n.tx("contractorA", transientOf(t, "auctionBid", offer), func(ctx *TransactionContext) error { return n.enh.PlaceAuctionBid(ctx, "A1", "B1") })
+		expectErr(t, err, "offer currency EUR does not match tender currency USD")
+	})
+}
+
+func TestAuctionAntiSniping(t *testing.T) {
+	tests := []struct {
+		name     string
+		offers   []time.Duration // before the scheduled end
+		wantEnd  time.Time
+		extended bool
+	}{
+		{"outside window", []time.Duration{121 * time.Second}, auctionEnd, false},
+		{"inside window", []time.Duration{120 * time.Second}, auctionEnd.Add(5 * time.Minute), true},
+		{"extension limit", []time.Duration{60 * time.Second, -4 * time.Minute}, auctionEnd.Add(5 * time.Minute), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.openTender(auctionFixture("A1"))
+			amount := 110000.0
+			for _, before := range tc.offers {
+				n.ledger.SetTime(auctionEnd.Add(-before))
+				if err := n.placeOffer("contractorA", "A1", "B1", amount); err != nil {
+					t.Fatal(err)
+				}
+				amount -= 5000
+			}
+			var p events.AuctionBidPayload
+			if err := n.expectEvents(events.AuctionBidPlaced)[0].Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.EndTime != rfc(tc.wantEnd) || p.Extended != tc.extended {
+				t.Fatalf("payload = %+v", p)
+			}
+		})
+	}
+
+	t.Run("only a leading offer extends", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(auctionFixture("A1"))
+		n.ledger.SetTime(auctionEnd.Add(-10 * time.Minute))
+		if err := n.placeOffer("contractorA", "A1", "B1", 100000); err != nil {
+			t.Fatal(err)
+		}
+		// Behind the lead, level with it, then ahead of it
+		n.ledger.SetTime(auctionEnd.Add(-time.Minute))
+		for _, o := range []struct {
+			amount   float64
+			extended bool
+		}{{110000, false}, {100000, false}, {99000, true}} {
+			if err := n.placeOffer("contractorB", "A1", "B2", o.amount); err != nil {
+				t.Fatal(err)
+			}
+			var p events.AuctionBidPayload
+			if err := n.expectEvents(events.AuctionBidPlaced)[0].Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Extended != o.extended {
+				t.Fatalf("offer of %.0f: payload = %+v", o.amount, p)
+			}
+		}
+	})
+}
+
+func TestAuctionLifecycle(t *testing.T) {
+	n := newTestNet(t)
+	n.openTender(auctionFixture("A1"))
+	n.openTender(tenderFixture("T1", auctionEnd))
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		state, err := n.enh.GetAuctionState(ctx, "A1")
+		if err == nil && (state.Status != "SCHEDULED" || state.EndTime != rfc(auctionEnd)) {
+			t.Fatalf("state = %+v", state)
+		}
+		return err
+	})
+
+	n.ledger.SetTime(auctionStart)
+	for _, o := range []struct {
+		who    string
+		bidID  string
+		amount float64
+	}{
+		{"contractorA", "B1", 110000},
+		{"contractorB", "B2", 105000},
+		{"contractorA", "B1", 100000},
+	} {
+		if err := n.placeOffer(o.who, "A1", o.bidID, o.amount); err != nil {
+			t.Fatal(err)
+		}
+		n.ledger.Advance(time.Minute)
+	}
+	// Offers outside the extension window leave the shared record alone, so
+	// concurrent bidders do not invalidate each other
+	if data := n.ledger.State(auctionStateKey("A1")); data != nil {
+		t.Fatalf("offers wrote the auction record: %s", data)
+	}
+
+	ranks := []struct {
+		who     string
+		rank    int
+		wantErr string
+	}{
+		{"contractorA", 1, ""},
+		{"contractorB", 2, ""},
+		{"contractorC", 0, "no auction offer found for caller on tender A1"},
+	}
+	for _, r := range ranks {
+		err := n.query(r.who, func(ctx *TransactionContext) error {
+			rank, err := n.enh.GetMyAuctionRank(ctx, "A1")
+			if err == nil && (rank.Rank != r.rank || rank.BidderCount != 2 || rank.LeadingAmount != 100000) {
+				t.Fatalf("rank of %s = %+v", r.who, rank)
+			}
+			return err
+		})
+		expectErr(t, err, r.wantErr)
+	}
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		state, err := n.enh.GetAuctionState(ctx, "A1")
+		if err != nil {
+			return err
+		}
+		if state.Status != "RUNNING" || state.OfferCount != 3 || state.BidderCount != 2 || state.LeadingAmount != 100000 {
+			t.Fatalf("state = %+v", state)
+		}
+		// Public refs carry the alias, never the contractor
+		ref, err := n.enh.GetBidRef(ctx, "A1", "B1")
+		if err == nil && ref.ContractorID == "contractorA" {
+			t.Fatalf("ref exposes contractor: %+v", ref)
+		}
+		_, err = n.enh.GetAuctionResult(ctx, "A1")
+		expectErr(t, err, "auction result for tender A1 not found")
+		_, err = n.enh.GetAuctionState(ctx, "T1")
+		expectErr(t, err, "tender T1 is not a reverse auction")
+		_, err = n.enh.GetMyAuctionRank(ctx, "T1")
+		expectErr(t, err, "tender T1 is not a reverse auction")
+		return nil
+	})
+
+	err := n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.CloseTenderEnhanced(ctx, "A1") })
+	expectErr(t, err, "auction for tender A1 is still running")
+
+	n.ledger.SetTime(auctionEnd)
+	n.closeTender("A1")
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		result, err := n.enh.GetAuctionResult(ctx, "A1")
+		if err != nil {
+			return err
+		}
+		if len(result.Ranking) != 2 || result.Ranking[0].BidID != "B1" || result.Ranking[1].Amount != 105000 {
+			t.Fatalf("result = %+v", result)
+		}
+		evals, err := n.enh.ListEvaluations(ctx, "A1")
+		if err == nil && (len(evals) != 2 || evals[0].Score != 100) {
+			t.Fatalf("evaluations = %+v", evals)
+		}
+		return err
+	})
+
+	err = n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardTender(ctx, "A1", "B2") })
+	expectErr(t, err, "bid B2 is not the top-ranked auction offer")
+	req := frameworkFixture()
+	req.TenderID, req.BidIDs = "A1", []string{"B2"}
+	expectErr(t, n.createFramework(req), "bid B2 is not the top-ranked auction offer")
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardBestBid(ctx, "A1") })
+	if got := n.tender("A1"); got.AwardedBidID != "B1" {
+		t.Fatalf("awarded %s", got.AwardedBidID)
+	}
+}
@@ -0,0 +1,28 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"tendercc/canonical"
+)
+
+// canonicalHash returns the canonical form of a JSON document and its hex SHA-256
+func canonicalHash(data []byte) ([]byte, string, error) {
+	doc, err := canonical.JSON(data)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(doc)
+	return doc, hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalMarshal marshals a value and returns its canonical JSON form
+func canonicalMarshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return canonical.JSON(data)
+}
@@ -0,0 +1,1453 @@
+// Package contract implements the tendercc basic and enhanced smart contracts
+package contract
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "math"
+    "strings"
+    "time"
+
+    "google.golang.org/protobuf/types/known/timestamppb"
+    "github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+    "tendercc/events"
+    "tendercc/schema"
+)
+
+const (
+    privateCollectionName        = "bidsCollection"
+    milestonePrivateCollection   = "milestonesCollection"
+)
+
+type SmartContract struct {
+    contractapi.Contract
+}
+
+// EnhancedSmartContract with comprehensive RFQ support
+type EnhancedSmartContract struct {
+    contractapi.Contract
+}
+
+func tenderKey(tenderID string) string {
+    return fmt.Sprintf("TENDER_%s", tenderID)
+}
+
+func bidRefKey(tenderID, bidID string) string {
+    return fmt.Sprintf("BIDREF_%s_%s", tenderID, bidID)
+}
+
+func bidPrivKey(tenderID, bidID string) string {
+    return fmt.Sprintf("BID_%s_%s", tenderID, bidID)
+}
+
+func evalKey(tenderID, bidID string) string {
+    return fmt.Sprintf("EVAL_%s_%s", tenderID, bidID)
+}
+
+func milestoneRefKey(tenderID, milestoneID string) string {
+    return fmt.Sprintf("MSREF_%s_%s", tenderID, milestoneID)
+}
+
+func milestonePrivKey(tenderID, milestoneID string) string {
+    return fmt.Sprintf("MS_%s_%s", tenderID, milestoneID)
+}
+
+func parseRFC3339(ts string) (time.Time, error) {
+    return time.Parse(time.RFC3339, ts)
+}
+
+func (s *SmartContract) CreateTender(ctx contractapi.TransactionContextInterface, tenderID, description, openAt, closeAt, criteria string) error {
+    exists, err := s.assetExists(ctx, tenderKey(tenderID))
+    if err != nil {
+        return err
+    }
+    if exists {
+        return fmt.Errorf("tender %s already exists", tenderID)
+    }
+    if openAt == "" || closeAt == "" {
+        return fmt.Errorf("openAt and closeAt must be RFC3339 timestamps")
+    }
+    openT, err := parseRFC3339(openAt)
+    if err != nil {
+        return fmt.Errorf("invalid openAt: %v", err)
+    }
+    closeT, err := parseRFC3339(closeAt)
+    if err != nil {
+        return fmt.Errorf("invalid closeAt: %v", err)
+    }
+    if !closeT.After(openT) {
+        return fmt.Errorf("closeAt must be after openAt")
+    }
+    t := Tender{
+        ID:          tenderID,
+        Description: description,
+        OpenAt:      openAt,
+        CloseAt:     closeAt,
+        Criteria:    criteria,
+        Status:      "OPEN",
+    }
+    bytes, _ := json.Marshal(t)
+    if err := ctx.GetStub().PutState(tenderKey(tenderID), bytes); err != nil {
+        return err
+    }
+    return emitEvent(ctx, events.RFQCreated, tenderID, events.RFQCreatedPayload{
+        TenderID:    t.ID,
+        Description: t.Description,
+        OpenAt:      t.OpenAt,
+        CloseAt:     t.CloseAt,
+        Criteria:    t.Criteria,
+        Status:      t.Status,
+    })
+}
+
+func (s *SmartContract) GetTender(ctx contractapi.TransactionContextInterface, tenderID string) (*Tender, error) {
+    data, err := ctx.GetStub().GetState(tenderKey(tenderID))
+    if err != nil {
+        return nil, err
+    }
+    if data == nil {
+        return nil, fmt.Errorf("tender %s not found", tenderID)
+    }
+    var t Tender
+    if err := json.Unmarshal(data, &t); err != nil {
+        return nil, err
+    }
+    return &t, nil
+}
+
+func (s *SmartContract) SubmitBid(ctx contractapi.TransactionContextInterface, tenderID, bidID string) error {
+    t, err := s.GetTender(ctx, tenderID)
+    if err != nil {
+        return err
+    }
+    if t.Status != "OPEN" {
+        return fmt.Errorf("tender %s not open for bids", tenderID)
+    }
+
+    // enforce time window: tx timestamp must be within [openAt, closeAt]
+    txTs, err := ctx.GetStub().GetTxTimestamp()
+    if err == nil && txTs != nil {
+        txTime := timestamppb.New(time.Unix(txTs.Seconds, int64(txTs.Nanos))).AsTime()
+        openT, _ := parseRFC3339(t.OpenAt)
+        closeT, _ := parseRFC3339(t.CloseAt)
+        if txTime.Before(openT) || !txTime.Before(closeT) {
+            return fmt.Errorf("submission window closed for tender %s", tenderID)
+        }
+    }
+
+    transient, err := ctx.GetStub().GetTransient()
+    if err != nil {
+        return fmt.Errorf("failed to get transient: %v", err)
+    }
+    bidBytes, ok := transient["bid"]
+    if !ok {
+        return fmt.Errorf("transient map must contain 'bid'")
+    }
+    var bid BidPrivate
+    if err := json.Unmarshal(bidBytes, &bid); err != nil {
+        return fmt.Errorf("invalid bid json: %v", err)
+    }
+    if bid.TenderID != tenderID || bid.BidID != bidID {
+        return fmt.Errorf("tenderId/bidId mismatch")
+    }
+
+    exists, err := s.assetExists(ctx, bidRefKey(tenderID, bidID))
+    if err != nil {
+        return err
+    }
+    if exists {
+        return fmt.Errorf("bid %s already exists for tender %s", bidID, tenderID)
+    }
+
+    // Store and hash the canonical form so equal bids always hash the same
+    stored, hashHex, err := canonicalHash(bidBytes)
+    if err != nil {
+        return fmt.Errorf("invalid bid json: %v", err)
+    }
+    mspID, err := clientMSPID(ctx)
+    if err != nil {
+        return err
+    }
+
+    if err := ctx.GetStub().PutPrivateData(privateCollectionName, bidPrivKey(tenderID, bidID), stored); err != nil {
+        return fmt.Errorf("failed to put private bid: %v", err)
+    }
+
+    ref := BidRef{
+        TenderID:     tenderID,
+        BidID:        bidID,
+        ContractorID: bid.ContractorID,
+        BidHash:      hashHex,
+        SubmittedBy:  mspID,
+        DocType:      docTypeBidRef,
+    }
+    refBytes, _ := json.Marshal(ref)
+    if err := ctx.GetStub().PutState(bidRefKey(tenderID, bidID), refBytes); err != nil {
+        return err
+    }
+    if err := indexBidRef(ctx, &ref); err != nil {
+        return err
+    }
+    return emitEvent(ctx, events.BidSubmitted, tenderID, events.BidSubmittedPayload{
+        TenderID:     tenderID,
+        BidID:        bidID,
+        ContractorID: ref.ContractorID,
+        BidHash:      ref.BidHash,
+    })
+}
+
+func (s *SmartContract) ListBidsPublic(ctx contractapi.TransactionContextInterface, tenderID string) ([]*BidRef, error) {
+    iter, err := ctx.GetStub().GetStateByRange("BIDREF_"+tenderID+"_", "BIDREF_"+tenderID+"_~")
+    if err != nil {
+        return nil, err
+    }
+    defer iter.Close()
+    var out []*BidRef
+    for iter.HasNext() {
+        kv, err := iter.Next()
+        if err != nil {
+            return nil, err
+        }
+        var r BidRef
+        if err := json.Unmarshal(kv.Value, &r); err == nil {
+            out = append(out, &r)
+        }
+    }
+    return out, nil
+}
+
+func (s *SmartContract) ReadBidPrivate(ctx contractapi.TransactionContextInterface, tenderID, bidID string) (*BidPrivate, error) {
+    data, err := ctx.GetStub().GetPrivateData(privateCollectionName, bidPrivKey(tenderID, bidID))
+    if err != nil {
+        return nil, err
+    }
+    if data == nil {
+        return nil, fmt.Errorf("private bid not found")
+    }
+    var b BidPrivate
+    if err := json.Unmarshal(data, &b); err != nil {
+        return nil, err
+    }
+    return &b, nil
+}
+
+func (s *SmartContract) AwardTender(ctx contractapi.TransactionContextInterface, tenderID, bidID string) error {
+    t, err := s.GetTender(ctx, tenderID)
+    if err != nil {
+        return err
+    }
+    if t.Status == "AWARDED" {
+        return fmt.Errorf("tender already awarded")
+    }
+    refData, err := ctx.GetStub().GetState(bidRefKey(tenderID, bidID))
+    if err != nil {
+        return err
+    }
+    if refData == nil {
+        return fmt.Errorf("bid %s not found for tender %s", bidID, tenderID)
+    }
+    t.AwardedBidID = bidID
+    t.Status = "AWARDED"
+    bytes, _ := json.Marshal(t)
+    if err := ctx.GetStub().PutState(tenderKey(tenderID), bytes); err != nil {
+        return err
+    }
+    return emitEvent(ctx, events.TenderAwarded, tenderID, events.TenderAwardedPayload{TenderID: tenderID, BidID: bidID, Status: t.Status})
+}
+
+// CloseTender moves a tender from OPEN to CLOSED; no more bids accepted
+func (s *SmartContract) CloseTender(ctx contractapi.TransactionContextInterface, tenderID string) error {
+    t, err := s.GetTender(ctx, tenderID)
+    if err != nil {
+        return err
+    }
+    if t.Status != "OPEN" {
+        return fmt.Errorf("tender %s not open", tenderID)
+    }
+    t.Status = "CLOSED"
+    bytes, _ := json.Marshal(t)
+    if err := ctx.GetStub().PutState(tenderKey(tenderID), bytes); err != nil {
+        return err
+    }
+    return emitEvent(ctx, events.BidWindowClosed, tenderID, events.TenderStatusPayload{TenderID: tenderID, Status: t.Status})
+}
+
+// RecordEvaluation stores an AI/off-chain computed score for a bid
+func (s *SmartContract) RecordEvaluation(ctx contractapi.TransactionContextInterface, tenderID, bidID string, score float64, notes string) error {
+    // ensure bid exists
+    exists, err := s.assetExists(ctx, bidRefKey(tenderID, bidID))
+    if err != nil {
+        return err
+    }
+    if !exists {
+        return fmt.Errorf("bid %s not found for tender %s", bidID, tenderID)
+    }
+    e := Evaluation{TenderID: tenderID, BidID: bidID, Score: score, Notes: notes}
+    b, _ := json.Marshal(e)
+    if err := ctx.GetStub().PutState(evalKey(tenderID, bidID), b); err != nil {
+        return err
+    }
+    return emitEvent(ctx, events.BidEvaluated, tenderID, events.BidEvaluatedPayload{TenderID: tenderID, BidID: bidID, Score: score, Notes: notes})
+}
+
+// ListEvaluations returns all evaluation scores for a tender
+func (s *SmartContract) ListEvaluations(ctx contractapi.TransactionContextInterface, tenderID string) ([]*Evaluation, error) {
+    iter, err := ctx.GetStub().GetStateByRange("EVAL_"+tenderID+"_", "EVAL_"+tenderID+"_~")
+    if err != nil {
+        return nil, err
+    }
+    defer iter.Close()
+    var out []*Evaluation
+    for iter.HasNext() {
+        kv, err := iter.Next()
+        if err != nil {
+            return nil, err
+        }
+        var e Evaluation
+        if err := json.Unmarshal(kv.Value, &e); err == nil {
+            out = append(out, &e)
+        }
+    }
+    return out, nil
+}
+
+// SubmitMilestone stores milestone private data and a public ref
+func (s *SmartContract) SubmitMilestone(ctx contractapi.TransactionContextInterface, tenderID, milestoneID string) error {
+    // ensure tender exists
+    if _, err := s.GetTender(ctx, tenderID); err != nil {
+        return err
+    }
+
+    transient, err := ctx.GetStub().GetTransient()
+    if err != nil {
+        return fmt.Errorf("failed to get transient: %v", err)
+    }
+    msBytes, ok := transient["milestone"]
+    if !ok {
+        return fmt.Errorf("transient map must contain 'milestone'")
+    }
+    if err := checkDocument(schema.MilestonePrivate, msBytes); err != nil {
+        return err
+    }
+    var ms MilestonePrivate
+    if err := json.Unmarshal(msBytes, &ms); err != nil {
+        return fmt.Errorf("invalid milestone json: %v", err)
+    }
+    if ms.TenderID != tenderID || ms.MilestoneID != milestoneID {
+        return fmt.Errorf("tenderId/milestoneId mismatch")
+    }
+
+    exists, err := s.assetExists(ctx, milestoneRefKey(tenderID, milestoneID))
+    if err != nil {
+        return err
+    }
+    if exists {
+        return fmt.Errorf("milestone %s already exists for tender %s", milestoneID, tenderID)
+    }
+    mspID, err := clientMSPID(ctx)
+    if err != nil {
+        return err
+    }
+
+    txTime, err := txTimestamp(ctx)
+    if err != nil {
+        return err
+    }
+    ms.SubmittedAt = txTime.Format(time.RFC3339)
+
+    // store the canonical private document including the submission time
+    stored, err := canonicalMarshal(ms)
+    if err != nil {
+        return err
+    }
+    if err := ctx.GetStub().PutPrivateData(milestonePrivateCollection, milestonePrivKey(tenderID, milestoneID), stored); err != nil {
+        return fmt.Errorf("failed to put private milestone: %v", err)
+    }
+    payloadHash := sha256.Sum256(stored)
+
+    ref := MilestoneRef{
+        TenderID:     tenderID,
+        MilestoneID:  milestoneID,
+        Title:        ms.Title,
+        EvidenceHash: ms.EvidenceHash,
+        PayloadHash:  hex.EncodeToString(payloadHash[:]),
+        Status:       "SUBMITTED",
+        PaymentReleased: false,
+        SubmittedAt:  ms.SubmittedAt,
+        SubmittedBy:  mspID,
+    }
+    refBytes, _ := json.Marshal(ref)
+    if err := ctx.GetStub().PutState(milestoneRefKey(tenderID, milestoneID), refBytes); err != nil {
+        return err
+    }
+    return emitEvent(ctx, events.MilestoneSubmitted, tenderID, milestonePayload(&ref))
+}
+
+func (s *SmartContract) ReadMilestonePrivate(ctx contractapi.TransactionContextInterface, tenderID, milestoneID string) (*MilestonePrivate, error) {
+    data, err := ctx.GetStub().GetPrivateData(milestonePrivateCollection, milestonePrivKey(tenderID, milestoneID))
+    if err != nil {
+        return nil, err
+    }
+    if data == nil {
+        return nil, fmt.Errorf("private milestone not found")
+    }
+    var m MilestonePrivate
+    if err := json.Unmarshal(data, &m); err != nil {
+        return nil, err
+    }
+    return &m, nil
+}
+
+func (s *SmartContract) ListMilestonesPublic(ctx contractapi.TransactionContextInterface, tenderID string) ([]*MilestoneRef, error) {
+    iter, err := ctx.GetStub().GetStateByRange("MSREF_"+tenderID+"_", "MSREF_"+tenderID+"_~")
+    if err != nil {
+        return nil, err
+    }
+    defer iter.Close()
+    var out []*MilestoneRef
+    for iter.HasNext() {
+        kv, err := iter.Next()
+        if err != nil {
+            return nil, err
+        }
+        var r MilestoneRef
+        if err := json.Unmarshal(kv.Value, &r); err == nil {
+            out = append(out, &r)
+        }
+    }
+    return out, nil
+}
+
+// ApproveMilestone marks a submitted milestone approved and releases payment flag
+func (s *SmartContract) ApproveMilestone(ctx contractapi.TransactionContextInterface, tenderID, milestoneID string) error {
+    data, err := ctx.GetStub().GetState(milestoneRefKey(tenderID, milestoneID))
+    if err != nil {
+        return err
+    }
+    if data == nil {
+        return fmt.Errorf("milestone not found")
+    }
+    var ref MilestoneRef
+    if err := json.Unmarshal(data, &ref); err != nil {
+        return err
+    }
+    if ref.Status != "SUBMITTED" {
+        return fmt.Errorf("milestone %s is already %s", milestoneID, ref.Status)
+    }
+    tender, err := loadTenderForSignatures(ctx, tenderID)
+    if err != nil {
+        return err
+    }
+    payload, err := milestoneApprovalPayload(tenderID, milestoneID)
+    if err != nil {
+        return err
+    }
+    if _, err := applySignature(ctx, tenderID, sigSubjectMilestone, milestoneID, payload, ownerSigner(tender), signaturesRequired(tender)); err != nil {
+        return err
+    }
+    ref.Status = "APPROVED"
+    ref.PaymentReleased = true
+    out, _ := json.Marshal(ref)
+    if err := ctx.GetStub().PutState(milestoneRefKey(tenderID, milestoneID), out); err != nil {
+        return err
+    }
+    // Approval and payment release reach clients together as one event batch
+    if err := emitEvent(ctx, events.MilestoneApproved, tenderID, milestonePayload(&ref)); err != nil {
+        return err
+    }
+    if err := emitEvent(ctx, events.PaymentReleased, tenderID, events.PaymentReleasedPayload{TenderID: tenderID, MilestoneID: milestoneID}); err != nil {
+        return err
+    }
+    return recordMilestoneOutcome(ctx, &ref, true)
+}
+
+func (s *SmartContract) assetExists(ctx contractapi.TransactionContextInterface, key string) (bool, error) {
+    data, err := ctx.GetStub().GetState(key)
+    if err != nil {
+        return false, err
+    }
+    return data != nil, nil
+}
+
+// GetBidRef returns the public bid reference
+func (s *SmartContract) GetBidRef(ctx contractapi.TransactionContextInterface, tenderID, bidID string) (*BidRef, error) {
+    data, err := ctx.GetStub().GetState(bidRefKey(tenderID, bidID))
+    if err != nil {
+        return nil, err
+    }
+    if data == nil {
+        return nil, fmt.Errorf("bid %s not found for tender %s", bidID, tenderID)
+    }
+    var r BidRef
+    if err := json.Unmarshal(data, &r); err != nil {
+        return nil, err
+    }
+    return &r, nil
+}
+
+// ListTenders returns all tenders
+func (s *SmartContract) ListTenders(ctx contractapi.TransactionContextInterface) ([]*Tender, error) {
+    iter, err := ctx.GetStub().GetStateByRange("TENDER_", "TENDER_~")
+    if err != nil {
+        return nil, err
+    }
+    defer iter.Close()
+    var out []*Tender
+    for iter.HasNext() {
+        kv, err := iter.Next()
+        if err != nil {
+            return nil, err
+        }
+        var t Tender
+        if err := json.Unmarshal(kv.Value, &t); err == nil {
+            out = append(out, &t)
+        }
+    }
+    return out, nil
+}
+
+// GetTenderHistory returns history of tender mutations
+func (s *SmartContract) GetTenderHistory(ctx contractapi.TransactionContextInterface, tenderID string) ([]string, error) {
+    iter, err := ctx.GetStub().GetHistoryForKey(tenderKey(tenderID))
+    if err != nil {
+        return nil, err
+    }
+    defer iter.Close()
+    var out []string
+    for iter.HasNext() {
+        r, err := iter.Next()
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, string(r.Value))
+    }
+    return out, nil
+}
+
+// RejectMilestone updates the status of a submitted milestone to REJECTED
+func (s *SmartContract) RejectMilestone(ctx contractapi.TransactionContextInterface, tenderID, milestoneID, reason string) error {
+    data, err := ctx.GetStub().GetState(milestoneRefKey(tenderID, milestoneID))
+    if err != nil {
+        return err
+    }
+    if data == nil {
+        return fmt.Errorf("milestone not found")
+    }
+    var ref MilestoneRef
+    if err := json.Unmarshal(data, &ref); err != nil {
+        return err
+    }
+    if ref.Status != "SUBMITTED" {
+        return fmt.Errorf("milestone %s is already %s", milestoneID, ref.Status)
+    }
+    ref.Status = "REJECTED"
+    // preserve PaymentReleased=false
+    out, _ := json.Marshal(ref)
+    if err := ctx.GetStub().PutState(milestoneRefKey(tenderID, milestoneID), out); err != nil {
+        return err
+    }
+    if err := emitEvent(ctx, events.MilestoneRejected, tenderID, milestonePayload(&ref)); err != nil {
+        return err
+    }
+    return recordMilestoneOutcome(ctx, &ref, false)
+}
+
+func (s *SmartContract) Init(ctx contractapi.TransactionContextInterface) error { return nil }
+
+// Enhanced functions for comprehensive RFQ support
+
+// assetExists checks if an asset exists on the ledger
+func (s *EnhancedSmartContract) assetExists(ctx contractapi.TransactionContextInterface, key string) (bool, error) {
+    asset, err := ctx.GetStub().GetState(key)
+    if err != nil {
+        return false, err
+    }
+    return asset != nil, nil
+}
+
+// ListBidsPublic retrieves public bid references for a tender
+func (s *EnhancedSmartContract) ListBidsPublic(ctx contractapi.TransactionContextInterface, tenderID string) ([]*BidRef, error) {
+    iter, err := ctx.GetStub().GetStateByRange("BIDREF_"+tenderID+"_", "BIDREF_"+tenderID+"_~")
+    if err != nil {
+        return nil, err
+    }
+    defer iter.Close()
+    var out []*BidRef
+    for iter.HasNext() {
+        kv, err := iter.Next()
+        if err != nil {
+            return nil, err
+        }
+        var r BidRef
+        if err := json.Unmarshal(kv.Value, &r); err == nil {
+            out = append(out, &r)
+        }
+    }
+    return out, nil
+}
+
+// GetBidRef retrieves the public reference of a bid
+func (s *EnhancedSmartContract) GetBidRef(ctx contractapi.TransactionContextInterface, tenderID, bidID string) (*BidRef, error) {
+    data, err := ctx.GetStub().GetState(bidRefKey(tenderID, bidID))
+    if err != nil {
+        return nil, err
+    }
+    if data == nil {
+        return nil, fmt.Errorf("bid %s not found for tender %s", bidID, tenderID)
+    }
+    var r BidRef
+    if err := json.Unmarshal(data, &r); err != nil {
+        return nil, err
+    }
+    return &r, nil
+}
+
+// ListEvaluations retrieves all evaluations for a tender
+func (s *EnhancedSmartContract) ListEvaluations(ctx contractapi.TransactionContextInterface, tenderID string) ([]*Evaluation, error) {
+    iter, err := ctx.GetStub().GetStateByRange("EVAL_"+tenderID+"_", "EVAL_"+tenderID+"_~")
+    if err != nil {
+        return nil, err
+    }
+    defer iter.Close()
+    var out []*Evaluation
+    for iter.HasNext() {
+        kv, err := iter.Next()
+        if err != nil {
+            return nil, err
+        }
+        var eval Evaluation
+        if err := json.Unmarshal(kv.Value, &eval); err == nil {
+            out = append(out, &eval)
+        }
+    }
+    return out, nil
+}
+
+// AwardTender awards a tender to a specific bid
+func (s *EnhancedSmartContract) AwardTender(ctx contractapi.TransactionContextInterface, tenderID, bidID string) error {
+    // Get the tender
+    bytes, err := ctx.GetStub().GetState(tenderKey(tenderID))
+    if err != nil {
+        return err
+    }
+    if bytes == nil {
+        return fmt.Errorf("tender %s not found", tenderID)
+    }
+
+    var tender EnhancedTender
+    if err := json.Unmarshal(bytes, &tender); err != nil {
+        return err
+    }
+
+    if tender.Status != "CLOSED" {
+        return fmt.Errorf("tender must be closed before awarding")
+    }
+
+    // Verify the bid exists
+    bidExists, err := s.assetExists(ctx, bidRefKey(tenderID, bidID))
+    if err != nil {
+        return err
+    }
+    if !bidExists {
+        return fmt.Errorf("bid %s not found for tender %s", bidID, tenderID)
+    }
+
+    if hasLots(&tender) {
+        return fmt.Errorf("tender %s is split into lots; use AwardLot or AwardLots", tenderID)
+    }
+
+    // Verify approvals, auction ranking and the awarding officer's signature over the award
+    payload, err := awardPayload(tenderID, bidID)
+    if err != nil {
+        return err
+    }
+    if err := s.authorizeAward(ctx, &tender, bidID, payload, bidID); err != nil {
+        return err
+    }
+
+    // Update tender status and award
+    txTime, err := s.getTxTime(ctx)
+    if err != nil {
+        return err
+    }
+    tender.Status = "AWARDED"
+    tender.AwardedBidID = bidID
+    tender.UpdatedAt = txTime.Format(time.RFC3339)
+    tender.AwardedAt = tender.UpdatedAt
+
+    // Store updated tender
+    if err := putTender(ctx, &tender); err != nil {
+        return err
+    }
+
+    return emitEvent(ctx, events.TenderAwarded, tenderID, events.TenderAwardedPayload{
+        TenderID:  tenderID,
+        BidID:     bidID,
+        Status:    "AWARDED",
+        AwardedAt: tender.UpdatedAt,
+    })
+}
+
+// getTxTime returns the transaction timestamp for deterministic operations across peers
+func (s *EnhancedSmartContract) getTxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+    ts, err := ctx.GetStub().GetTxTimestamp()
+    if err != nil || ts == nil {
+        return time.Time{}, fmt.Errorf("failed to get tx timestamp: %v", err)
+    }
+    return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
+
+// CreateEnhancedTender creates a comprehensive RFQ with all required fields
+func (s *EnhancedSmartContract) CreateEnhancedTender(ctx contractapi.TransactionContextInterface, tenderJSON string) error {
+	if err := checkDocument(schema.EnhancedTender, []byte(tenderJSON)); err != nil {
+		return err
+	}
+	var tender EnhancedTender
+	if err := json.Unmarshal([]byte(tenderJSON), &tender); err != nil {
+		return fmt.Errorf("invalid tender JSON: %v", err)
+	}
+
+	// Validate required fields
+	if err := s.validateEnhancedTender(&tender); err != nil {
+		return fmt.Errorf("tender validation failed: %v", err)
+	}
+
+	// Check if tender already exists
+    exists, err := s.assetExists(ctx, tenderKey(tender.ID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("tender %s already exists", tender.ID)
+	}
+
+    // Set system fields (deterministic tx time)
+    txTime, err := s.getTxTime(ctx)
+    if err != nil {
+        return err
+    }
+    now := txTime.Format(time.RFC3339)
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	tender.OwnerMSP = mspID
+	tender.CreatedAt = now
+	tender.UpdatedAt = now
+	tender.Version = 1
+	if tender.Status == "" {
+		tender.Status = "DRAFT"
+	}
+
+	// Store tender
+	if err := putTender(ctx, &tender); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.EnhancedRFQCreated, tender.ID, events.TenderCreatedPayload{
+		TenderID:    tender.ID,
+		Status:      tender.Status,
+		CreatedAt:   tender.CreatedAt,
+		Owner:       tender.OwnerDetails.OrganizationName,
+		Description: tender.ProjectScope.Description,
+	})
+}
+
+// validateEnhancedTender performs comprehensive validation, reporting every issue found
+func (s *EnhancedSmartContract) validateEnhancedTender(tender *EnhancedTender) error {
+	return issuesError(s.tenderIssues(tender))
+}
+
+// tenderIssues runs every check on a tender and collects the problems instead of
+// stopping at the first one
+func (s *EnhancedSmartContract) tenderIssues(tender *EnhancedTender) issueList {
+	var issues issueList
+	if tender.ID == "" {
+		issues.add("id", "tender ID is required")
+	}
+	if tender.ProjectScope.Description == "" {
+		issues.add("projectScope.description", "project description is required")
+	}
+	if tender.Deadlines.BidSubmissionDeadline == "" {
+		issues.add("deadlines.bidSubmissionDeadline", "bid submission deadline is required")
+	}
+	if tender.OwnerDetails.OrganizationName == "" {
+		issues.add("ownerDetails.organizationName", "owner organization name is required")
+	}
+	if signaturesRequired(tender) && ownerSigner(tender) == "" {
+		issues.add("ownerDetails.authorizedBy.signerId", "an authorized signer is required when digital signatures are required")
+	}
+	issues = append(issues, managedFieldIssues(tender)...)
+
+	// Deadlines, budget and criteria are checked field by field
+	issues = append(issues, s.validateDeadlines(&tender.Deadlines)...)
+	issues = append(issues, budgetIssues("projectScope.budget", &tender.ProjectScope.Budget)...)
+	issues.check("evaluationCriteria", s.validateEvaluationCriteria(tender.EvaluationCriteria))
+	issues = append(issues, subCriteriaIssues("evaluationCriteria", tender.EvaluationCriteria)...)
+
+	// The remaining checks report one problem each
+	issues.check("auction", validateAuctionConfig(tender))
+	issues.check("lots", s.validateLots(tender))
+	for i := range tender.Lots {
+		lot := &tender.Lots[i]
+		issues = append(issues, paymentScheduleIssues(fmt.Sprintf("lots.%d.budget", i), lot.Budget.PaymentSchedule)...)
+		issues = append(issues, subCriteriaIssues(fmt.Sprintf("lots.%d.evaluationCriteria", i), lot.EvaluationCriteria)...)
+	}
+	issues.check("procurementMethod", validateProcurementMethod(tender))
+	issues.check("bidEncryption", validateBidEncryption(tender))
+	issues.check("retention", validateRetentionPolicy(tender.Retention))
+	return issues
+}
+
+// validateDeadlines checks that each date parses, that the tender dates run in order
+// (issue, questions, bids, project start, project end) and that milestone deadlines
+// fall within the project
+func (s *EnhancedSmartContract) validateDeadlines(deadlines *TenderDeadlines) issueList {
+	var issues issueList
+	type date struct {
+		field, label string
+		at           time.Time
+	}
+	var previous *date
+	for _, d := range []struct{ field, label, value string }{
+		{"rfqIssueDate", "RFQ issue date", deadlines.RFQIssueDate},
+		{"questionsDeadline", "questions deadline", deadlines.QuestionsDeadline},
+		{"bidSubmissionDeadline", "bid submission deadline", deadlines.BidSubmissionDeadline},
+		{"projectStartDate", "project start date", deadlines.ProjectStartDate},
+		{"projectEndDate", "project end date", deadlines.ProjectEndDate},
+	} {
+		if d.value == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, d.value)
+		if err != nil {
+			issues.add("deadlines."+d.field, "invalid %s format: %v", d.label, err)
+			continue
+		}
+		if previous != nil && !at.After(previous.at) {
+			issues.add("deadlines."+d.field, "%s must be after the %s", d.label, previous.label)
+		}
+		previous = &date{d.field, d.label, at}
+	}
+
+	start, startErr := time.Parse(time.RFC3339, deadlines.ProjectStartDate)
+	end, endErr := time.Parse(time.RFC3339, deadlines.ProjectEndDate)
+	for i, milestone := range deadlines.MilestoneDeadlines {
+		field := fmt.Sprintf("deadlines.milestoneDeadlines.%d.deadline", i)
+		at, err := time.Parse(time.RFC3339, milestone.Deadline)
+		if err != nil {
+			issues.add(field, "invalid milestone deadline format for %s: %v", milestone.Name, err)
+			continue
+		}
+		if startErr == nil && at.Before(start) {
+			issues.add(field, "milestone %s is due before the project start date", milestone.Name)
+		}
+		if endErr == nil && at.After(end) {
+			issues.add(field, "milestone %s is due after the project end date", milestone.Name)
+		}
+	}
+
+	return issues
+}
+
+func (s *EnhancedSmartContract) validateEvaluationCriteria(criteria []EvalCriterion) error {
+	if len(criteria) == 0 {
+		return fmt.Errorf("at least one evaluation criterion is required")
+	}
+
+	totalWeight := 0.0
+	for _, criterion := range criteria {
+		if criterion.Name == "" {
+			return fmt.Errorf("criterion name is required")
+		}
+		if criterion.Weight < 0 || criterion.Weight > 100 {
+			return fmt.Errorf("criterion weight must be between 0 and 100")
+		}
+		totalWeight += criterion.Weight
+	}
+
+	// Allow some tolerance for rounding errors
+	if math.Abs(totalWeight-100.0) > 0.01 {
+		return fmt.Errorf("total evaluation criteria weight must equal 100, got %.2f", totalWeight)
+	}
+
+	return nil
+}
+
+// PublishTender publishes a draft tender to make it open for bids
+func (s *EnhancedSmartContract) PublishTender(ctx contractapi.TransactionContextInterface, tenderID string) error {
+	// Get the tender
+	bytes, err := ctx.GetStub().GetState(tenderKey(tenderID))
+	if err != nil {
+		return err
+	}
+	if bytes == nil {
+		return fmt.Errorf("tender %s not found", tenderID)
+	}
+
+	var tender EnhancedTender
+	if err := json.Unmarshal(bytes, &tender); err != nil {
+		return err
+	}
+
+	// Validate tender can be published
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.validateTenderForPublishing(&tender, txTime); err != nil {
+		return err
+	}
+
+	// Update status and timestamps
+	tender.Status = "OPEN"
+	tender.UpdatedAt = txTime.Format(time.RFC3339)
+	if tender.Deadlines.RFQIssueDate == "" {
+		tender.Deadlines.RFQIssueDate = txTime.Format(time.RFC3339)
+	}
+
+	// Store updated tender
+	if err := putTender(ctx, &tender); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.TenderPublished, tenderID, events.TenderStatusPayload{TenderID: tenderID, Status: "OPEN", At: tender.UpdatedAt})
+}
+
+func (s *EnhancedSmartContract) validateTenderForPublishing(tender *EnhancedTender, now time.Time) error {
+	if tender.Status != "DRAFT" {
+		return fmt.Errorf("only draft tenders can be published")
+	}
+
+	// Check if bid submission deadline is in the future
+	if tender.Deadlines.BidSubmissionDeadline != "" {
+		deadline, err := time.Parse(time.RFC3339, tender.Deadlines.BidSubmissionDeadline)
+		if err != nil {
+			return fmt.Errorf("invalid bid submission deadline: %v", err)
+		}
+		if deadline.Before(now) {
+			return fmt.Errorf("bid submission deadline must be in the future")
+		}
+	}
+
+	return nil
+}
+
+// SubmitEnhancedBid submits a comprehensive bid with technical and financial proposals
+func (s *EnhancedSmartContract) SubmitEnhancedBid(ctx contractapi.TransactionContextInterface, tenderID, bidID string) error {
+	// Get the tender
+	bytes, err := ctx.GetStub().GetState(tenderKey(tenderID))
+	if err != nil {
+		return err
+	}
+	if bytes == nil {
+		return fmt.Errorf("tender %s not found", tenderID)
+	}
+
+	var tender EnhancedTender
+	if err := json.Unmarshal(bytes, &tender); err != nil {
+		return err
+	}
+
+	// Validate submission window
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.validateSubmissionWindow(&tender, txTime); err != nil {
+		return err
+	}
+	if isReverseAuction(&tender) {
+		return fmt.Errorf("tender %s is a reverse auction; use PlaceAuctionBid", tenderID)
+	}
+	if bidsEncrypted(&tender) {
+		return fmt.Errorf("tender %s requires encrypted bids; use SubmitEncryptedBid", tenderID)
+	}
+
+	// Get transient data
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient: %v", err)
+	}
+
+	bidBytes, ok := transient["bid"]
+	if !ok {
+		return fmt.Errorf("transient map must contain 'bid'")
+	}
+
+	if err := checkDocument(schema.EnhancedBidPrivate, bidBytes); err != nil {
+		return err
+	}
+	var bid EnhancedBidPrivate
+	if err := json.Unmarshal(bidBytes, &bid); err != nil {
+		return fmt.Errorf("invalid bid JSON: %v", err)
+	}
+
+	// Validate bid
+	if err := s.validateEnhancedBid(&bid, &tender); err != nil {
+		return fmt.Errorf("bid validation failed: %v", err)
+	}
+
+	if err := s.checkBidder(ctx, &tender, bid.ContractorID, txTime); err != nil {
+		return err
+	}
+
+	// Check if bid already exists
+	exists, err := s.assetExists(ctx, bidRefKey(tenderID, bidID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("bid %s already exists for tender %s", bidID, tenderID)
+	}
+
+	// Verify the bidder's detached signature over the submitted bid
+	if _, err := applySignature(ctx, tenderID, sigSubjectBid, bidID, bidBytes, bid.ContractorID, signaturesRequired(&tender)); err != nil {
+		return err
+	}
+
+	// Set submission timestamp
+	bid.SubmittedAt = txTime.Format(time.RFC3339)
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Store the canonical bid including server-set fields, and hash exactly what is stored
+	stored, err := canonicalMarshal(bid)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollectionName, bidPrivKey(tenderID, bidID), stored); err != nil {
+		return fmt.Errorf("failed to store private bid: %v", err)
+	}
+
+	// Create and store public bid reference
+	hash := sha256.Sum256(stored)
+	hashHex := hex.EncodeToString(hash[:])
+
+	ref := BidRef{
+		TenderID:     tenderID,
+		BidID:        bidID,
+		ContractorID: bid.ContractorID,
+		BidHash:      hashHex,
+		LotIDs:       bidLotIDs(&bid),
+		SubmittedBy:  mspID,
+		DocType:      docTypeBidRef,
+	}
+	refBytes, _ := json.Marshal(ref)
+	if err := ctx.GetStub().PutState(bidRefKey(tenderID, bidID), refBytes); err != nil {
+		return err
+	}
+	if err := indexBidRef(ctx, &ref); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.EnhancedBidSubmitted, tenderID, events.BidSubmittedPayload{
+		TenderID:     tenderID,
+		BidID:        bidID,
+		ContractorID: bid.ContractorID,
+		BidHash:      ref.BidHash,
+		SubmittedAt:  bid.SubmittedAt,
+	})
+}
+
+// checkBidder applies the debarment, invitation and prequalification rules to a bidder
+func (s *EnhancedSmartContract) checkBidder(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, contractorID string, txTime time.Time) error {
+	// Debarred contractors cannot bid
+	if err := s.checkNotDebarred(ctx, contractorID, txTime); err != nil {
+		return err
+	}
+
+	// Restricted, invited and single-source tenders only accept invitees
+	if err := checkInvited(ctx, tender, contractorID); err != nil {
+		return err
+	}
+
+	// Check financial and experience requirements against the vendor registry
+	if tender.BidRequirements.PrequalificationRequired {
+		report := s.prequalify(ctx, tender, contractorID, txTime)
+		if !report.Qualified {
+			return fmt.Errorf("contractor %s is not prequalified: %s", contractorID, strings.Join(report.Failures, "; "))
+		}
+		// The registry entry must belong to the bidding organization, not just share its ID
+		vendor, err := s.GetVendor(ctx, contractorID)
+		if err != nil {
+			return err
+		}
+		mspID, err := clientMSPID(ctx)
+		if err != nil {
+			return err
+		}
+		if mspID != vendor.RegisteredBy {
+			return fmt.Errorf("vendor %s is registered by %s, not %s", contractorID, vendor.RegisteredBy, mspID)
+		}
+	}
+	return nil
+}
+
+func (s *EnhancedSmartContract) validateSubmissionWindow(tender *EnhancedTender, now time.Time) error {
+	if tender.Status != "OPEN" {
+		return fmt.Errorf("tender is not open for bids")
+	}
+
+	if tender.Deadlines.BidSubmissionDeadline != "" {
+		deadline, err := time.Parse(time.RFC3339, tender.Deadlines.BidSubmissionDeadline)
+		if err != nil {
+			return fmt.Errorf("invalid bid submission deadline: %v", err)
+		}
+		if now.After(deadline) {
+			return fmt.Errorf("bid submission deadline has passed")
+		}
+	}
+
+	return nil
+}
+
+func (s *EnhancedSmartContract) validateEnhancedBid(bid *EnhancedBidPrivate, tender *EnhancedTender) error {
+	if bid.TenderID == "" || bid.BidID == "" || bid.ContractorID == "" {
+		return fmt.Errorf("tender ID, bid ID, and contractor ID are required")
+	}
+
+	if bid.TotalAmount <= 0 {
+		return fmt.Errorf("total amount must be positive")
+	}
+
+	if bid.Currency == "" {
+		return fmt.Errorf("currency is required")
+	}
+
+	// Validate technical proposal
+	if bid.TechnicalProposal.Methodology == "" {
+		return fmt.Errorf("technical methodology is required")
+	}
+
+	// Validate financial proposal
+	if len(bid.FinancialProposal.BreakdownByPhase) == 0 {
+		return fmt.Errorf("financial breakdown by phase is required")
+	}
+
+	// Validate compliance checklist
+	if len(bid.ComplianceChecklist) == 0 {
+		return fmt.Errorf("compliance checklist is required")
+	}
+
+	// Validate lot coverage and pricing
+	if err := validateLotBid(bid, tender); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetEnhancedTender retrieves a comprehensive tender
+func (s *EnhancedSmartContract) GetEnhancedTender(ctx contractapi.TransactionContextInterface, tenderID string) (*EnhancedTender, error) {
+	bytes, err := ctx.GetStub().GetState(tenderKey(tenderID))
+	if err != nil {
+		return nil, err
+	}
+	if bytes == nil {
+		return nil, fmt.Errorf("tender %s not found", tenderID)
+	}
+
+	var tender EnhancedTender
+	if err := json.Unmarshal(bytes, &tender); err != nil {
+		return nil, err
+	}
+
+	return &tender, nil
+}
+
+// GetEnhancedBidPrivate retrieves private bid data
+func (s *EnhancedSmartContract) GetEnhancedBidPrivate(ctx contractapi.TransactionContextInterface, tenderID, bidID string) (*EnhancedBidPrivate, error) {
+	data, err := ctx.GetStub().GetPrivateData(privateCollectionName, bidPrivKey(tenderID, bidID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("private bid not found")
+	}
+
+	var bid EnhancedBidPrivate
+	if err := json.Unmarshal(data, &bid); err != nil {
+		return nil, err
+	}
+
+	return &bid, nil
+}
+
+// EvaluateBids performs automated bid evaluation based on criteria
+func (s *EnhancedSmartContract) EvaluateBids(ctx contractapi.TransactionContextInterface, tenderID string) error {
+	// Get the tender
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+
+	if tender.Status != "CLOSED" {
+		return fmt.Errorf("tender must be closed before evaluation")
+	}
+
+	// Get all bids
+	bids, err := s.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+
+	if len(bids) == 0 {
+		return fmt.Errorf("no bids to evaluate")
+	}
+
+	// Sealed bids must be opened with the released key first
+	if err := s.requireBidsOpened(tender, bids); err != nil {
+		return err
+	}
+
+	// Multi-lot tenders are evaluated per lot
+	if hasLots(tender) {
+		return s.evaluateLots(ctx, tender, bids)
+	}
+
+	// Evaluate each bid
+	for _, bidRef := range bids {
+		bid, err := s.GetEnhancedBidPrivate(ctx, tenderID, bidRef.BidID)
+		if err != nil {
+			continue // Skip bids that can't be retrieved
+		}
+
+		// Calculate score based on evaluation criteria
+		score := s.calculateBidScore(bid, tender.EvaluationCriteria)
+
+		// Record evaluation
+		eval := Evaluation{
+			TenderID: tenderID,
+			BidID:    bidRef.BidID,
+			Score:    score,
+			Notes:    "Automated evaluation based on criteria",
+		}
+
+		evalBytes, _ := json.Marshal(eval)
+		if err := ctx.GetStub().PutState(evalKey(tenderID, bidRef.BidID), evalBytes); err != nil {
+			return err
+		}
+
+		if err := emitEvent(ctx, events.BidEvaluated, tenderID, events.BidEvaluatedPayload{TenderID: tenderID, BidID: bidRef.BidID, Score: score}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *EnhancedSmartContract) calculateBidScore(bid *EnhancedBidPrivate, criteria []EvalCriterion) float64 {
+	totalScore := 0.0
+
+	for _, criterion := range criteria {
+		var criterionScore float64
+
+		switch criterion.Type {
+		case "QUANTITATIVE":
+			// For price-based criteria, lower is better
+			if criterion.Name == "Price" {
+				// Normalize price score (lower price = higher score)
+				criterionScore = 100.0 - (bid.TotalAmount / 1000000.0 * 10.0) // Simple normalization
+				if criterionScore < 0 {
+					criterionScore = 0
+				}
+			} else {
+				criterionScore = 75.0 // Default score for other quantitative criteria
+			}
+		case "QUALITATIVE":
+			// For qualitative criteria, check compliance
+			if compliant, exists := bid.ComplianceChecklist[criterion.Name]; exists && compliant {
+				criterionScore = 100.0
+			} else {
+				criterionScore = 50.0
+			}
+		case "PASS_FAIL":
+			if compliant, exists := bid.ComplianceChecklist[criterion.Name]; exists && compliant {
+				criterionScore = 100.0
+			} else {
+				criterionScore = 0.0
+			}
+		default:
+			criterionScore = 50.0
+		}
+
+		totalScore += criterionScore * (criterion.Weight / 100.0)
+	}
+
+	return totalScore
+}
+
+// GetTenderStatistics provides comprehensive tender statistics
+func (s *EnhancedSmartContract) GetTenderStatistics(ctx contractapi.TransactionContextInterface, tenderID string) (string, error) {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return "", err
+	}
+
+	bids, err := s.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		return "", err
+	}
+
+	// Calculate statistics
+	stats := map[string]interface{}{
+		"tenderId":           tenderID,
+		"status":             tender.Status,
+		"totalBids":          len(bids),
+		"projectDescription": tender.ProjectScope.Description,
+		"owner":              tender.OwnerDetails.OrganizationName,
+		"createdAt":          tender.CreatedAt,
+		"updatedAt":          tender.UpdatedAt,
+	}
+
+	// Add bid statistics if available
+	if len(bids) > 0 {
+		var totalAmount float64
+		var amounts []float64
+
+		for _, bidRef := range bids {
+			if bid, err := s.GetEnhancedBidPrivate(ctx, tenderID, bidRef.BidID); err == nil {
+				totalAmount += bid.TotalAmount
+				amounts = append(amounts, bid.TotalAmount)
+			}
+		}
+
+		if len(amounts) > 0 {
+			// Calculate min, max, average
+			min := amounts[0]
+			max := amounts[0]
+			for _, amount := range amounts {
+				if amount < min {
+					min = amount
+				}
+				if amount > max {
+					max = amount
+				}
+			}
+			avg := totalAmount / float64(len(amounts))
+
+			stats["bidStatistics"] = map[string]interface{}{
+				"totalAmount": totalAmount,
+				"averageBid":  avg,
+				"lowestBid":   min,
+				"highestBid":  max,
+				"currency":    tender.ProjectScope.Budget.Currency,
+			}
+		}
+	}
+
+	statsBytes, _ := json.MarshalIndent(stats, "", "  ")
+	return string(statsBytes), nil
+}
+
+// CloseTenderEnhanced closes a tender with enhanced validation
+func (s *EnhancedSmartContract) CloseTenderEnhanced(ctx contractapi.TransactionContextInterface, tenderID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+
+	if tender.Status != "OPEN" {
+		return fmt.Errorf("only open tenders can be closed")
+	}
+
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Freeze the auction ranking before closing
+	if isReverseAuction(tender) {
+		if err := s.closeAuction(ctx, tender, txTime); err != nil {
+			return err
+		}
+	}
+
+	// Lots without any bid cannot be awarded
+	if hasLots(tender) {
+		bids, err := s.ListBidsPublic(ctx, tenderID)
+		if err != nil {
+			return err
+		}
+		markUnbidLots(tender, bids)
+	}
+
+	// Update status
+	tender.Status = "CLOSED"
+	tender.UpdatedAt = txTime.Format(time.RFC3339)
+
+	// Store updated tender
+	if err := putTender(ctx, tender); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.TenderClosed, tenderID, events.TenderStatusPayload{TenderID: tenderID, Status: "CLOSED", At: tender.UpdatedAt})
+}
+
+// GetTendersByStatus retrieves the first page of tenders with a status (for API compatibility)
+func (s *EnhancedSmartContract) GetTendersByStatus(ctx contractapi.TransactionContextInterface, status string) (string, error) {
+	filter, _ := json.Marshal(TenderFilter{Status: status, PageSize: maxQueryPageSize})
+	result, err := s.QueryTenders(ctx, string(filter))
+	if err != nil {
+		return "", err
+	}
+	tendersBytes, _ := json.Marshal(result.Tenders)
+	return string(tendersBytes), nil
+}
+
+// AwardBestBid automatically awards the tender to the best bid
+func (s *EnhancedSmartContract) AwardBestBid(ctx contractapi.TransactionContextInterface, tenderID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+
+	if tender.Status != "CLOSED" {
+		return fmt.Errorf("tender must be closed before awarding")
+	}
+
+	// Get all evaluations
+	evaluations, err := s.ListEvaluations(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+
+	if len(evaluations) == 0 {
+		return fmt.Errorf("no evaluations found for tender")
+	}
+
+	// Find the best bid
+	var bestBid *Evaluation
+	bestScore := -1.0
+
+	for _, eval := range evaluations {
+		if eval.Score > bestScore {
+			bestScore = eval.Score
+			bestBid = eval
+		}
+	}
+
+	if bestBid == nil {
+		return fmt.Errorf("no valid evaluations found")
+	}
+
+	// Award the tender
+	return s.AwardTender(ctx, tenderID, bestBid.BidID)
+}
+
+// New returns the tendercc chaincode, ready to start on a peer or invoke in process
+func New() (*contractapi.ContractChaincode, error) {
+    // Register both the basic and enhanced contracts to allow gradual migration
+    // Both contracts share a transaction context that batches the events of a transaction
+    basic := new(SmartContract)
+    basic.TransactionContextHandler = new(TransactionContext)
+    enhanced := new(EnhancedSmartContract)
+    enhanced.TransactionContextHandler = new(TransactionContext)
+    return contractapi.NewChaincode(basic, enhanced)
+}
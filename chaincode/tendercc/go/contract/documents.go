@@ -0,0 +1,369 @@
+package contract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// Record types a document can be attached to
+const (
+	docLinkTender    = "TENDER"
+	docLinkBid       = "BID"
+	docLinkMilestone = "MILESTONE"
+)
+
+// formatMimeTypes maps the format names used in DocumentReq/SubmissionFormat to MIME types
+var formatMimeTypes = map[string][]string{
+	"PDF":  {"application/pdf"},
+	"DOC":  {"application/msword"},
+	"DOCX": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	"XLS":  {"application/vnd.ms-excel"},
+	"XLSX": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	"CSV":  {"text/csv"},
+	"TXT":  {"text/plain"},
+	"ZIP":  {"application/zip", "application/x-zip-compressed"},
+	"JPG":  {"image/jpeg"},
+	"JPEG": {"image/jpeg"},
+	"PNG":  {"image/png"},
+	"DWG":  {"application/acad", "image/vnd.dwg"},
+}
+
+func documentKey(hash string) string {
+	return fmt.Sprintf("DOC_%s", hash)
+}
+
+func documentLinkKey(tenderID, linkType, refID, name string, version int) string {
+	return fmt.Sprintf("DOCLINK_%s_%s_%s_%s_%04d", tenderID, linkType, refID, name, version)
+}
+
+// normalizeDocHash accepts "sha256:<hex>" or bare hex and returns lowercase hex
+func normalizeDocHash(hash string) (string, error) {
+	h := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(hash), "sha256:"))
+	if len(h) != 64 {
+		return "", fmt.Errorf("document hash must be a 64 character sha256 hex digest")
+	}
+	if _, err := hex.DecodeString(h); err != nil {
+		return "", fmt.Errorf("document hash is not valid hex: %v", err)
+	}
+	return h, nil
+}
+
+// formatAllowed reports whether a file matches one of the given format names by MIME type or extension
+func formatAllowed(formats []string, mimeType, name string) bool {
+	ext := strings.ToUpper(strings.TrimPrefix(path.Ext(name), "."))
+	for _, f := range formats {
+		f = strings.ToUpper(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if f == ext {
+			return true
+		}
+		for _, m := range formatMimeTypes[f] {
+			if strings.EqualFold(m, mimeType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkDocumentLimits applies the tender's DocumentReq and SubmissionFormat rules to an attachment
+func checkDocumentLimits(tender *EnhancedTender, req *DocumentAttachment) error {
+	const mb = 1024 * 1024
+	format := tender.BidRequirements.SubmissionFormat
+	if len(format.FileFormats) > 0 && !formatAllowed(format.FileFormats, req.MimeType, req.Name) {
+		return fmt.Errorf("document %s must be one of %s", req.Name, strings.Join(format.FileFormats, ", "))
+	}
+	if format.MaxFileSize > 0 && req.SizeBytes > int64(format.MaxFileSize)*mb {
+		return fmt.Errorf("document %s exceeds the %d MB file size limit", req.Name, format.MaxFileSize)
+	}
+
+	// A named required document may carry its own, stricter limits
+	for _, dr := range tender.BidRequirements.RequiredDocuments {
+		if !strings.EqualFold(dr.Name, req.Name) {
+			continue
+		}
+		if dr.Format != "" && !formatAllowed(strings.Split(dr.Format, ","), req.MimeType, req.Name) {
+			return fmt.Errorf("document %s must be in %s format", req.Name, dr.Format)
+		}
+		if dr.MaxSizeMB > 0 && req.SizeBytes > int64(dr.MaxSizeMB)*mb {
+			return fmt.Errorf("document %s exceeds its %d MB limit", req.Name, dr.MaxSizeMB)
+		}
+	}
+	return nil
+}
+
+// latestDocumentLink returns the newest version of a named document on a record
+func latestDocumentLink(ctx contractapi.TransactionContextInterface, tenderID, linkType, refID, name string) (*DocumentLink, error) {
+	prefix := fmt.Sprintf("DOCLINK_%s_%s_%s_%s_", tenderID, linkType, refID, name)
+	iter, err := ctx.GetStub().GetStateByRange(prefix, prefix+"~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var latest *DocumentLink
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var l DocumentLink
+		if err := json.Unmarshal(kv.Value, &l); err == nil && l.Name == name && (latest == nil || l.Version > latest.Version) {
+			latest = &l
+		}
+	}
+	return latest, nil
+}
+
+// requireSubmitter lets only the organization that submitted a bid or milestone attach
+// files to it. Reverse auction refs carry no organization, so the caller's bidder alias
+// must match instead.
+func requireSubmitter(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, submittedBy, alias, what string) error {
+	if isReverseAuction(tender) && alias != "" {
+		bidderID, err := ctx.GetClientIdentity().GetID()
+		if err != nil {
+			return fmt.Errorf("failed to get client identity: %v", err)
+		}
+		if bidderAlias(tender.ID, bidderID) == alias {
+			return nil
+		}
+	} else {
+		mspID, err := clientMSPID(ctx)
+		if err != nil {
+			return err
+		}
+		if submittedBy != "" && mspID == submittedBy {
+			return nil
+		}
+	}
+	return fmt.Errorf("only the submitter of %s may attach documents to it", what)
+}
+
+// AttachDocument registers an off-chain document and links it to a tender, bid or milestone.
+// Attaching a new file under an existing name on the same record creates a new version.
+// Tender documents come from the tender owner, bid and milestone files from their submitter.
+func (s *EnhancedSmartContract) AttachDocument(ctx contractapi.TransactionContextInterface, documentJSON string) (*DocumentLink, error) {
+	var req DocumentAttachment
+	if err := json.Unmarshal([]byte(documentJSON), &req); err != nil {
+		return nil, fmt.Errorf("invalid document JSON: %v", err)
+	}
+	if req.Name == "" || req.MimeType == "" || req.StorageURI == "" || req.TenderID == "" {
+		return nil, fmt.Errorf("name, mimeType, storageUri and tenderId are required")
+	}
+	if req.SizeBytes <= 0 {
+		return nil, fmt.Errorf("document size must be positive")
+	}
+	hash, err := normalizeDocHash(req.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	tender, err := s.GetEnhancedTender(ctx, req.TenderID)
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.LinkType {
+	case docLinkTender:
+		if err := requireOwner(ctx, tender, "attach documents to"); err != nil {
+			return nil, err
+		}
+		if tender.Status != "DRAFT" && tender.Status != "OPEN" {
+			return nil, fmt.Errorf("tender documents can only be attached to draft or open tenders")
+		}
+		req.RefID = ""
+	case docLinkBid:
+		if err := s.validateSubmissionWindow(tender, txTime); err != nil {
+			return nil, err
+		}
+		data, err := ctx.GetStub().GetState(bidRefKey(req.TenderID, req.RefID))
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return nil, fmt.Errorf("bid %s not found for tender %s", req.RefID, req.TenderID)
+		}
+		var ref BidRef
+		if err := json.Unmarshal(data, &ref); err != nil {
+			return nil, err
+		}
+		if err := requireSubmitter(ctx, tender, ref.SubmittedBy, ref.ContractorID, "bid "+req.RefID); err != nil {
+			return nil, err
+		}
+		if err := checkDocumentLimits(tender, &req); err != nil {
+			return nil, err
+		}
+	case docLinkMilestone:
+		data, err := ctx.GetStub().GetState(milestoneRefKey(req.TenderID, req.RefID))
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return nil, fmt.Errorf("milestone %s not found for tender %s", req.RefID, req.TenderID)
+		}
+		var ref MilestoneRef
+		if err := json.Unmarshal(data, &ref); err != nil {
+			return nil, err
+		}
+		if err := requireSubmitter(ctx, tender, ref.SubmittedBy, "", "milestone "+req.RefID); err != nil {
+			return nil, err
+		}
+		if err := checkDocumentLimits(tender, &req); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("linkType must be TENDER, BID or MILESTONE")
+	}
+
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := txTime.Format(time.RFC3339)
+
+	link := DocumentLink{
+		LinkType:   req.LinkType,
+		TenderID:   req.TenderID,
+		RefID:      req.RefID,
+		Name:       req.Name,
+		Version:    1,
+		Hash:       hash,
+		AttachedBy: mspID,
+		AttachedAt: now,
+		TxID:       ctx.GetStub().GetTxID(),
+	}
+	prev, err := latestDocumentLink(ctx, req.TenderID, req.LinkType, req.RefID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil {
+		if prev.Hash == hash {
+			return nil, fmt.Errorf("document %s version %d already has this content", req.Name, prev.Version)
+		}
+		link.Version = prev.Version + 1
+		link.PreviousHash = prev.Hash
+	}
+
+	// One registry entry per file content; the same file may back several records
+	var doc DocumentRef
+	data, err := ctx.GetStub().GetState(documentKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		if doc.SizeBytes != req.SizeBytes {
+			return nil, fmt.Errorf("document %s is registered with a different size", hash)
+		}
+	} else {
+		doc = DocumentRef{
+			Hash:       hash,
+			Name:       req.Name,
+			MimeType:   req.MimeType,
+			SizeBytes:  req.SizeBytes,
+			StorageURI: req.StorageURI,
+			Uploader:   mspID,
+			CreatedAt:  now,
+		}
+	}
+	doc.Links = append(doc.Links, link)
+
+	docBytes, _ := json.Marshal(doc)
+	if err := ctx.GetStub().PutState(documentKey(hash), docBytes); err != nil {
+		return nil, err
+	}
+	linkBytes, _ := json.Marshal(link)
+	if err := ctx.GetStub().PutState(documentLinkKey(req.TenderID, req.LinkType, req.RefID, req.Name, link.Version), linkBytes); err != nil {
+		return nil, err
+	}
+
+	// Keep the tender's own document map pointing at the latest version
+	if req.LinkType == docLinkTender {
+		if tender.DocumentHashes == nil {
+			tender.DocumentHashes = make(map[string]string)
+		}
+		tender.DocumentHashes[req.Name] = "sha256:" + hash
+		tender.UpdatedAt = now
+		if err := putTender(ctx, tender); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := emitEvent(ctx, events.DocumentAttached, link.TenderID, events.DocumentPayload{
+		LinkType:     link.LinkType,
+		TenderID:     link.TenderID,
+		RefID:        link.RefID,
+		Name:         link.Name,
+		Version:      link.Version,
+		PreviousHash: link.PreviousHash,
+		Hash:         link.Hash,
+		AttachedBy:   link.AttachedBy,
+		AttachedAt:   link.AttachedAt,
+	}); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// VerifyDocument reports which records reference a file hash and when they were attached
+func (s *EnhancedSmartContract) VerifyDocument(ctx contractapi.TransactionContextInterface, hash string) (*DocumentVerification, error) {
+	h, err := normalizeDocHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	result := &DocumentVerification{Hash: h}
+	data, err := ctx.GetStub().GetState(documentKey(h))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return result, nil
+	}
+	var doc DocumentRef
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	result.Found = true
+	result.Links = doc.Links
+	doc.Links = nil
+	result.Document = &doc
+	return result, nil
+}
+
+// ListDocuments returns every document version linked to a record.
+// Use linkType TENDER with an empty refID for tender documents.
+func (s *EnhancedSmartContract) ListDocuments(ctx contractapi.TransactionContextInterface, tenderID, linkType, refID string) ([]*DocumentLink, error) {
+	prefix := fmt.Sprintf("DOCLINK_%s_%s_%s_", tenderID, linkType, refID)
+	iter, err := ctx.GetStub().GetStateByRange(prefix, prefix+"~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var out []*DocumentLink
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var l DocumentLink
+		if err := json.Unmarshal(kv.Value, &l); err == nil && l.LinkType == linkType && l.RefID == refID {
+			out = append(out, &l)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,224 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func docFixture(linkType, refID, name, content string) DocumentAttachment {
+	return DocumentAttachment{
+		Name:       name,
+		MimeType:   "application/pdf",
+		SizeBytes:  int64(len(content)),
+		SHA256:     "sha256:" + sha256Hex(content),
+		StorageURI: "s3://tenders/" + content,
+		LinkType:   linkType,
+		TenderID:   "T1",
+		RefID:      refID,
+	}
+}
+
+func (n *testNet) attach(who string, doc DocumentAttachment) (*DocumentLink, error) {
+	n.t.Helper()
+	var link *DocumentLink
+	err := n.tx(who, nil, func(ctx *TransactionContext) error {
+		var err error
+		link, err = n.enh.AttachDocument(ctx, mustJSON(n.t, doc))
+		return err
+	})
+	return link, err
+}
+
+func TestAttachDocument(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		name    string
+		who     string
+		mutate  func(d *DocumentAttachment)
+		wantErr string
+	}{
+		{name: "bid document", who: "contractorA"},
+		{name: "bare hex hash", who: "contractorA", mutate: func(d *DocumentAttachment) { d.SHA256 = sha256Hex("proposal") }},
+		{name: "by extension", who: "contractorA", mutate: func(d *DocumentAttachment) { d.MimeType = "application/octet-stream"; d.Name = "proposal.pdf" }},
+		{name: "tender document", who: "buyer", mutate: func(d *DocumentAttachment) { d.LinkType = docLinkTender; d.RefID = "ignored" }},
+		{name: "tender document by bidder", who: "contractorA", mutate: func(d *DocumentAttachment) { d.LinkType = docLinkTender; d.RefID = "" }, wantErr: "only the tender owner may attach documents to tender T1"},
+		{name: "another bidder's bid", who: "contractorB", wantErr: "only the submitter of bid B1 may attach documents to it"},
+		{name: "buyer on a bid", who: "buyer", wantErr: "only the submitter of bid B1 may attach documents to it"},
+		{name: "short hash", who: "contractorA", mutate: func(d *DocumentAttachment) { d.SHA256 = "abc" }, wantErr: "document hash must be a 64 character sha256 hex digest"},
+		{name: "non-hex hash", who: "contractorA", mutate: func(d *DocumentAttachment) { d.SHA256 = sha256Hex("x")[:63] + "z" }, wantErr: "document hash is not valid hex"},
+		{name: "missing uri", who: "contractorA", mutate: func(d *DocumentAttachment) { d.StorageURI = "" }, wantErr: "name, mimeType, storageUri and tenderId are required"},
+		{name: "empty file", who: "contractorA", mutate: func(d *DocumentAttachment) { d.SizeBytes = 0 }, wantErr: "document size must be positive"},
+		{name: "link type", who: "contractorA", mutate: func(d *DocumentAttachment) { d.LinkType = "LOT" }, wantErr: "linkType must be TENDER, BID or MILESTONE"},
+		{name: "unknown tender", who: "contractorA", mutate: func(d *DocumentAttachment) { d.TenderID = "T9" }, wantErr: "tender T9 not found"},
+		{name: "unknown bid", who: "contractorA", mutate: func(d *DocumentAttachment) { d.RefID = "B9" }, wantErr: "bid B9 not found for tender T1"},
+		{name: "unknown milestone", who: "contractorA", mutate: func(d *DocumentAttachment) { d.LinkType = docLinkMilestone; d.RefID = "M1" }, wantErr: "milestone M1 not found for tender T1"},
+		{name: "wrong format", who: "contractorA", mutate: func(d *DocumentAttachment) { d.MimeType = "image/png" }, wantErr: "document Technical Proposal must be one of PDF"},
+		{name: "over tender limit", who: "contractorA", mutate: func(d *DocumentAttachment) { d.SizeBytes = 10*mb + 1 }, wantErr: "exceeds the 10 MB file size limit"},
+		{name: "required document format", who: "contractorA", mutate: func(d *DocumentAttachment) { d.Name = "Bid Bond"; d.MimeType = "application/pdf" }, wantErr: "document Bid Bond must be in PNG format"},
+		{name: "required document size", who: "contractorA", mutate: func(d *DocumentAttachment) { d.Name = "Insurance"; d.SizeBytes = 2 * mb }, wantErr: "document Insurance exceeds its 1 MB limit"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := tenderFixture("T1", t0.Add(time.Hour))
+			tender.BidRequirements.RequiredDocuments = []DocumentReq{
+				{Name: "Bid Bond", Mandatory: true, Format: "PNG"},
+				{Name: "Insurance", MaxSizeMB: 1},
+			}
+			n.openTender(tender)
+			n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+			doc := docFixture(docLinkBid, "B1", "Technical Proposal", "proposal")
+			if tc.mutate != nil {
+				tc.mutate(&doc)
+			}
+			link, err := n.attach(tc.who, doc)
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			if link.Version != 1 || link.Hash != sha256Hex("proposal") || link.AttachedBy != n.identity(tc.who).MSPID {
+				t.Fatalf("link = %+v", link)
+			}
+			n.expectEvents(events.DocumentAttached)
+			if doc.LinkType == docLinkTender {
+				if link.RefID != "" || n.tender("T1").DocumentHashes[doc.Name] != "sha256:"+link.Hash {
+					t.Fatalf("tender document link = %+v", link)
+				}
+			}
+		})
+	}
+}
+
+func TestAttachDocumentWindows(t *testing.T) {
+	n := newTestNet(t)
+	n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+
+	n.ledger.SetTime(t0.Add(time.Hour))
+	if _, err := n.attach("contractorA", docFixture(docLinkBid, "B1", "Technical Proposal", "at deadline")); err != nil {
+		t.Fatal(err)
+	}
+	n.ledger.Advance(time.Second)
+	_, err := n.attach("contractorA", docFixture(docLinkBid, "B1", "Technical Proposal", "late"))
+	expectErr(t, err, "bid submission deadline has passed")
+
+	n.closeTender("T1")
+	_, err = n.attach("buyer", docFixture(docLinkTender, "", "Addendum", "addendum"))
+	expectErr(t, err, "tender documents can only be attached to draft or open tenders")
+	_, err = n.attach("contractorA", docFixture(docLinkBid, "B1", "Technical Proposal", "closed"))
+	expectErr(t, err, "tender is not open for bids")
+}
+
+func TestAttachDocumentSubmitter(t *testing.T) {
+	n := newTestNet(t)
+
+	// Reverse auction offers are matched by the caller's bidder alias
+	n.openTender(auctionFixture("A1"))
+	n.ledger.SetTime(auctionStart)
+	if err := n.placeOffer("contractorA", "A1", "B1", 100000); err != nil {
+		t.Fatal(err)
+	}
+	offer := docFixture(docLinkBid, "B1", "Technical Proposal", "offer")
+	offer.TenderID = "A1"
+	_, err := n.attach("contractorB", offer)
+	expectErr(t, err, "only the submitter of bid B1 may attach documents to it")
+	if _, err := n.attach("contractorA", offer); err != nil {
+		t.Fatal(err)
+	}
+
+	n.awardedTender("T1")
+	ms := MilestonePrivate{TenderID: "T1", MilestoneID: "M1", Title: "Base course"}
+	n.mustTx("contractorA", transientOf(t, "milestone", ms), func(ctx *TransactionContext) error {
+		return n.basic.SubmitMilestone(ctx, "T1", "M1")
+	})
+	_, err = n.attach("contractorB", docFixture(docLinkMilestone, "M1", "Inspection Report", "report"))
+	expectErr(t, err, "only the submitter of milestone M1 may attach documents to it")
+	if _, err := n.attach("contractorA", docFixture(docLinkMilestone, "M1", "Inspection Report", "report")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDocumentVersionsAndVerification(t *testing.T) {
+	n := newTestNet(t)
+	n.createTender(tenderFixture("T1", t0.Add(time.Hour)))
+
+	versions := []struct {
+		content  string
+		version  int
+		previous string
+		wantErr  string
+	}{
+		{"drawings v1", 1, "", ""},
+		{"drawings v1", 0, "", "document Drawings version 1 already has this content"},
+		{"drawings v2", 2, sha256Hex("drawings v1"), ""},
+		{"drawings v1", 3, sha256Hex("drawings v2"), ""},
+	}
+	for _, v := range versions {
+		n.ledger.Advance(time.Minute)
+		link, err := n.attach("buyer", docFixture(docLinkTender, "", "Drawings", v.content))
+		expectErr(t, err, v.wantErr)
+		if v.wantErr == "" && (link.Version != v.version || link.PreviousHash != v.previous) {
+			t.Fatalf("link = %+v, want version %d after %s", link, v.version, v.previous)
+		}
+	}
+	if got := n.tender("T1").DocumentHashes["Drawings"]; got != "sha256:"+sha256Hex("drawings v1") {
+		t.Fatalf("tender points at %s", got)
+	}
+
+	// The same file on a second tender shares the registry entry
+	n.createTender(tenderFixture("T2", t0.Add(time.Hour)))
+	other := docFixture(docLinkTender, "", "Plans", "drawings v1")
+	other.TenderID = "T2"
+	if _, err := n.attach("buyer", other); err != nil {
+		t.Fatal(err)
+	}
+	other.Name, other.SizeBytes = "Sketches", 1
+	_, err := n.attach("buyer", other)
+	expectErr(t, err, "is registered with a different size")
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		lookups := []struct {
+			hash    string
+			found   bool
+			links   int
+			wantErr string
+		}{
+			{"sha256:" + sha256Hex("drawings v1"), true, 3, ""},
+			{sha256Hex("drawings v2"), true, 1, ""},
+			{sha256Hex("never attached"), false, 0, ""},
+			{"nope", false, 0, "document hash must be a 64 character sha256 hex digest"},
+		}
+		for _, l := range lookups {
+			v, err := n.enh.VerifyDocument(ctx, l.hash)
+			expectErr(t, err, l.wantErr)
+			if l.wantErr != "" {
+				continue
+			}
+			if v.Found != l.found || len(v.Links) != l.links || (l.found && (v.Document == nil || v.Document.Links != nil)) {
+				t.Fatalf("verification of %s = %+v", l.hash, v)
+			}
+		}
+
+		docs, err := n.enh.ListDocuments(ctx, "T1", docLinkTender, "")
+		if err != nil {
+			return err
+		}
+		if len(docs) != 3 || docs[0].Version != 1 || docs[2].Version != 3 {
+			t.Fatalf("documents = %+v", docs)
+		}
+		docs, err = n.enh.ListDocuments(ctx, "T1", docLinkBid, "B1")
+		if err == nil && len(docs) != 0 {
+			t.Fatalf("bid documents = %+v", docs)
+		}
+		return err
+	})
+}
@@ -0,0 +1,78 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// TransactionContext collects the events raised during one transaction. Fabric keeps
+// only the last chaincode event of a transaction, so every emit re-sends everything
+// raised so far and the client receives all of them together.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	events []events.Envelope
+}
+
+func (c *TransactionContext) appendEvent(env events.Envelope) []events.Envelope {
+	c.events = append(c.events, env)
+	return c.events
+}
+
+type eventCollector interface {
+	appendEvent(env events.Envelope) []events.Envelope
+}
+
+// emitEvent wraps payload in a versioned envelope and sets it as the transaction's
+// chaincode event. A second event in the same transaction turns the chaincode event
+// into a batch of both.
+func emitEvent(ctx contractapi.TransactionContextInterface, name, tenderID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", name, err)
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	env := events.Envelope{
+		Name:          name,
+		SchemaVersion: events.SchemaVersion,
+		TenderID:      tenderID,
+		TxID:          ctx.GetStub().GetTxID(),
+		TxTime:        txTime.Format(time.RFC3339),
+		Payload:       data,
+	}
+	if ci := ctx.GetClientIdentity(); ci != nil {
+		env.Actor.MSPID, _ = ci.GetMSPID()
+		env.Actor.ClientID, _ = ci.GetID()
+	}
+
+	pending := []events.Envelope{env}
+	if c, ok := ctx.(eventCollector); ok {
+		pending = c.appendEvent(env)
+	}
+	if len(pending) == 1 {
+		bytes, _ := json.Marshal(env)
+		return ctx.GetStub().SetEvent(name, bytes)
+	}
+	bytes, _ := json.Marshal(events.Batch{SchemaVersion: events.SchemaVersion, Events: pending})
+	return ctx.GetStub().SetEvent(events.BatchEventName, bytes)
+}
+
+func milestonePayload(ref *MilestoneRef) events.MilestonePayload {
+	return events.MilestonePayload{
+		TenderID:        ref.TenderID,
+		MilestoneID:     ref.MilestoneID,
+		Title:           ref.Title,
+		EvidenceHash:    ref.EvidenceHash,
+		PayloadHash:     ref.PayloadHash,
+		Status:          ref.Status,
+		PaymentReleased: ref.PaymentReleased,
+		SubmittedAt:     ref.SubmittedAt,
+	}
+}
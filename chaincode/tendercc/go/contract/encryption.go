@@ -0,0 +1,413 @@
+package contract
+
+import (
+	"crypto/ecdh"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/bidcrypto"
+	"tendercc/events"
+	"tendercc/schema"
+)
+
+func encryptedBidKey(tenderID, bidID string) string {
+	return fmt.Sprintf("ENCBID_%s_%s", tenderID, bidID)
+}
+
+func keyShareKey(tenderID string, index int) string {
+	return fmt.Sprintf("KEYSHARE_%s_%04d", tenderID, index)
+}
+
+func bidsEncrypted(tender *EnhancedTender) bool {
+	return tender.BidEncryption != nil
+}
+
+// validateBidEncryption checks the published key and share commitments
+func validateBidEncryption(tender *EnhancedTender) error {
+	cfg := tender.BidEncryption
+	if cfg == nil {
+		return nil
+	}
+	if !tender.BidRequirements.SubmissionFormat.EncryptionReq {
+		return fmt.Errorf("bid encryption key requires submissionFormat.encryptionReq")
+	}
+	if isReverseAuction(tender) {
+		return fmt.Errorf("reverse auctions do not support encrypted bids")
+	}
+	if _, err := bidcrypto.ParsePublicKey(cfg.PublicKey); err != nil {
+		return err
+	}
+	if cfg.ReleasedKey != "" || cfg.ReleasedAt != "" {
+		return fmt.Errorf("bid decryption key cannot be set before closing")
+	}
+	if len(cfg.ShareHolders) == 0 {
+		return fmt.Errorf("at least one key share holder is required")
+	}
+	if cfg.Threshold < 1 || cfg.Threshold > len(cfg.ShareHolders) {
+		return fmt.Errorf("threshold must be between 1 and %d", len(cfg.ShareHolders))
+	}
+	seen := make(map[int]bool)
+	for _, h := range cfg.ShareHolders {
+		if h.Index < 1 || h.Index > len(cfg.ShareHolders) {
+			return fmt.Errorf("share index %d out of range", h.Index)
+		}
+		if seen[h.Index] {
+			return fmt.Errorf("duplicate share index %d", h.Index)
+		}
+		seen[h.Index] = true
+		if h.HolderID == "" {
+			return fmt.Errorf("share %d has no holder", h.Index)
+		}
+		if c, err := hex.DecodeString(h.Commitment); err != nil || len(c) != 65 {
+			return fmt.Errorf("share %d commitment must be a hex encoded P-256 point", h.Index)
+		}
+	}
+	return nil
+}
+
+// SubmitEncryptedBid stores a sealed bid for a tender that publishes a bid encryption key.
+// Transient "encryptedBid" holds {contractorId, ciphertext, payloadHash}.
+func (s *EnhancedSmartContract) SubmitEncryptedBid(ctx contractapi.TransactionContextInterface, tenderID, bidID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if !bidsEncrypted(tender) {
+		return fmt.Errorf("tender %s does not accept encrypted bids", tenderID)
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.validateSubmissionWindow(tender, txTime); err != nil {
+		return err
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient: %v", err)
+	}
+	sealedBytes, ok := transient["encryptedBid"]
+	if !ok {
+		return fmt.Errorf("transient map must contain 'encryptedBid'")
+	}
+	var sealed EncryptedBid
+	if err := json.Unmarshal(sealedBytes, &sealed); err != nil {
+		return fmt.Errorf("invalid encrypted bid JSON: %v", err)
+	}
+	if sealed.ContractorID == "" {
+		return fmt.Errorf("contractor ID is required")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err != nil || len(ciphertext) == 0 {
+		return fmt.Errorf("ciphertext must be non-empty base64")
+	}
+	if h, err := hex.DecodeString(sealed.PayloadHash); err != nil || len(h) != sha256.Size {
+		return fmt.Errorf("payload hash must be a hex SHA-256 digest")
+	}
+
+	if err := s.checkBidder(ctx, tender, sealed.ContractorID, txTime); err != nil {
+		return err
+	}
+
+	exists, err := s.assetExists(ctx, bidRefKey(tenderID, bidID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("bid %s already exists for tender %s", bidID, tenderID)
+	}
+
+	// The bidder signs the sealed envelope; the plaintext cannot be checked until opening
+	if _, err := applySignature(ctx, tenderID, sigSubjectBid, bidID, sealedBytes, sealed.ContractorID, signaturesRequired(tender)); err != nil {
+		return err
+	}
+
+	cipherHash := sha256.Sum256(ciphertext)
+	sealed.TenderID = tenderID
+	sealed.BidID = bidID
+	sealed.CiphertextHash = hex.EncodeToString(cipherHash[:])
+	sealed.SubmittedAt = txTime.Format(time.RFC3339)
+	stored, _ := json.Marshal(sealed)
+	if err := ctx.GetStub().PutPrivateData(privateCollectionName, encryptedBidKey(tenderID, bidID), stored); err != nil {
+		return fmt.Errorf("failed to store encrypted bid: %v", err)
+	}
+
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	ref := BidRef{
+		TenderID:       tenderID,
+		BidID:          bidID,
+		ContractorID:   sealed.ContractorID,
+		BidHash:        sealed.PayloadHash,
+		Encrypted:      true,
+		CiphertextHash: sealed.CiphertextHash,
+		SubmittedBy:    mspID,
+		DocType:        docTypeBidRef,
+	}
+	refBytes, _ := json.Marshal(ref)
+	if err := ctx.GetStub().PutState(bidRefKey(tenderID, bidID), refBytes); err != nil {
+		return err
+	}
+	if err := indexBidRef(ctx, &ref); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.EnhancedBidSubmitted, tenderID, events.BidSubmittedPayload{
+		TenderID:     tenderID,
+		BidID:        bidID,
+		ContractorID: sealed.ContractorID,
+		SubmittedAt:  sealed.SubmittedAt,
+		Encrypted:    true,
+	})
+}
+
+// ReleaseKeyShare publishes one evaluator's key share after the tender has closed.
+// When the threshold is reached the tender key is rebuilt and recorded on the tender.
+func (s *EnhancedSmartContract) ReleaseKeyShare(ctx contractapi.TransactionContextInterface, tenderID string, index int, value string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	cfg := tender.BidEncryption
+	if cfg == nil {
+		return fmt.Errorf("tender %s does not use encrypted bids", tenderID)
+	}
+	if tender.Status == "DRAFT" || tender.Status == "OPEN" {
+		return fmt.Errorf("key shares can only be released after the tender is closed")
+	}
+	if cfg.ReleasedKey != "" {
+		return fmt.Errorf("bid decryption key for tender %s has already been released", tenderID)
+	}
+
+	var holder *KeyShareHolder
+	for i := range cfg.ShareHolders {
+		if cfg.ShareHolders[i].Index == index {
+			holder = &cfg.ShareHolders[i]
+		}
+	}
+	if holder == nil {
+		return fmt.Errorf("share %d is not defined for tender %s", index, tenderID)
+	}
+	callerID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if holder.HolderID != callerID && holder.HolderID != mspID {
+		return fmt.Errorf("caller does not hold share %d", index)
+	}
+
+	share := bidcrypto.Share{Index: index, Value: value}
+	if err := bidcrypto.VerifyShare(share, holder.Commitment); err != nil {
+		return err
+	}
+	exists, err := s.assetExists(ctx, keyShareKey(tenderID, index))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("share %d has already been released", index)
+	}
+
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	released := ReleasedKeyShare{
+		TenderID:   tenderID,
+		Index:      index,
+		Value:      value,
+		HolderID:   holder.HolderID,
+		ReleasedBy: callerID,
+		ReleasedAt: txTime.Format(time.RFC3339),
+	}
+	releasedBytes, _ := json.Marshal(released)
+	if err := ctx.GetStub().PutState(keyShareKey(tenderID, index), releasedBytes); err != nil {
+		return err
+	}
+
+	shares, err := s.releasedShares(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	// Our own write is not visible to the range query, so add the new share here
+	shares = append(shares, share)
+
+	eventName := events.KeyShareReleased
+	if len(shares) >= cfg.Threshold {
+		key, err := bidcrypto.CombineShares(shares)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild bid decryption key: %v", err)
+		}
+		pub, _ := bidcrypto.ParsePublicKey(cfg.PublicKey)
+		if !key.PublicKey().Equal(pub) {
+			return fmt.Errorf("released shares do not match the tender public key")
+		}
+		cfg.ReleasedKey = hex.EncodeToString(key.Bytes())
+		cfg.ReleasedAt = txTime.Format(time.RFC3339)
+		tender.UpdatedAt = cfg.ReleasedAt
+		if err := putTender(ctx, tender); err != nil {
+			return err
+		}
+		eventName = events.BidKeyReleased
+	}
+
+	return emitEvent(ctx, eventName, tenderID, events.KeySharePayload{
+		TenderID:  tenderID,
+		Index:     index,
+		Released:  len(shares),
+		Threshold: cfg.Threshold,
+	})
+}
+
+// releasedShares returns the shares already released for a tender, in index order
+func (s *EnhancedSmartContract) releasedShares(ctx contractapi.TransactionContextInterface, tenderID string) ([]bidcrypto.Share, error) {
+	iter, err := ctx.GetStub().GetStateByRange(fmt.Sprintf("KEYSHARE_%s_", tenderID), fmt.Sprintf("KEYSHARE_%s_~", tenderID))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var shares []bidcrypto.Share
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var r ReleasedKeyShare
+		if err := json.Unmarshal(kv.Value, &r); err != nil {
+			return nil, err
+		}
+		shares = append(shares, bidcrypto.Share{Index: r.Index, Value: r.Value})
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].Index < shares[j].Index })
+	return shares, nil
+}
+
+// OpenEncryptedBids decrypts sealed bids once the tender key has been released.
+// Each opened bid is checked against its committed hash and validated like a
+// plaintext bid; bids that fail are left sealed and the reason is recorded on the BidRef.
+func (s *EnhancedSmartContract) OpenEncryptedBids(ctx contractapi.TransactionContextInterface, tenderID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	cfg := tender.BidEncryption
+	if cfg == nil {
+		return fmt.Errorf("tender %s does not use encrypted bids", tenderID)
+	}
+	if cfg.ReleasedKey == "" {
+		return fmt.Errorf("bid decryption key for tender %s has not been released", tenderID)
+	}
+	keyBytes, err := hex.DecodeString(cfg.ReleasedKey)
+	if err != nil {
+		return fmt.Errorf("invalid released key: %v", err)
+	}
+	key, err := bidcrypto.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	refs, err := s.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	opened, failed := 0, 0
+	for _, ref := range refs {
+		if !ref.Encrypted || ref.OpenedAt != "" || ref.OpenError != "" {
+			continue
+		}
+		stored, err := s.openBid(ctx, key, tender, ref)
+		if err != nil {
+			ref.OpenError = err.Error()
+			failed++
+		} else {
+			if err := ctx.GetStub().PutPrivateData(privateCollectionName, bidPrivKey(tenderID, ref.BidID), stored); err != nil {
+				return fmt.Errorf("failed to store opened bid: %v", err)
+			}
+			// From here on BidHash covers the stored bid, like a plaintext submission
+			hash := sha256.Sum256(stored)
+			ref.BidHash = hex.EncodeToString(hash[:])
+			ref.OpenedAt = txTime.Format(time.RFC3339)
+			opened++
+		}
+		refBytes, _ := json.Marshal(ref)
+		if err := ctx.GetStub().PutState(bidRefKey(tenderID, ref.BidID), refBytes); err != nil {
+			return err
+		}
+	}
+
+	return emitEvent(ctx, events.EncryptedBidsOpened, tenderID, events.BidsOpenedPayload{TenderID: tenderID, Opened: opened, Failed: failed})
+}
+
+// openBid decrypts and validates one sealed bid, returning the canonical bid to store
+func (s *EnhancedSmartContract) openBid(ctx contractapi.TransactionContextInterface, key *ecdh.PrivateKey, tender *EnhancedTender, ref *BidRef) ([]byte, error) {
+	data, err := ctx.GetStub().GetPrivateData(privateCollectionName, encryptedBidKey(tender.ID, ref.BidID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("encrypted bid not found")
+	}
+	var sealed EncryptedBid
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %v", err)
+	}
+	plaintext, err := bidcrypto.Decrypt(key, bidcrypto.BidContext(tender.ID, ref.BidID), ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if _, hash, err := canonicalHash(plaintext); err != nil || hash != sealed.PayloadHash {
+		return nil, fmt.Errorf("decrypted bid does not match committed hash")
+	}
+
+	if err := checkDocument(schema.EnhancedBidPrivate, plaintext); err != nil {
+		return nil, err
+	}
+	var bid EnhancedBidPrivate
+	if err := json.Unmarshal(plaintext, &bid); err != nil {
+		return nil, fmt.Errorf("invalid bid JSON: %v", err)
+	}
+	if bid.TenderID != tender.ID || bid.BidID != ref.BidID || bid.ContractorID != ref.ContractorID {
+		return nil, fmt.Errorf("decrypted bid identifiers do not match the submission")
+	}
+	if err := s.validateEnhancedBid(&bid, tender); err != nil {
+		return nil, fmt.Errorf("bid validation failed: %v", err)
+	}
+	bid.SubmittedAt = sealed.SubmittedAt
+	return canonicalMarshal(bid)
+}
+
+// requireBidsOpened stops evaluation while any encrypted bid is still sealed
+func (s *EnhancedSmartContract) requireBidsOpened(tender *EnhancedTender, refs []*BidRef) error {
+	if !bidsEncrypted(tender) {
+		return nil
+	}
+	for _, ref := range refs {
+		if ref.Encrypted && ref.OpenedAt == "" && ref.OpenError == "" {
+			return fmt.Errorf("encrypted bids must be opened before evaluation")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package contract
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"tendercc/bidcrypto"
+	"tendercc/events"
+)
+
+// sealedTender holds the tender key and its shares, which stay off-chain in production
+type sealedTender struct {
+	key    *ecdh.PrivateKey
+	shares []bidcrypto.Share
+}
+
+// encryptedTenderFixture is T1 sealed to a fresh key split 2-of-3 between the
+// auditor org, the regulator org and the buyer2 identity
+func (n *testNet) encryptedTenderFixture(id string) (*EnhancedTender, *sealedTender) {
+	n.t.Helper()
+	key, err := bidcrypto.GenerateTenderKey(rand.Reader)
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	shares, commitments, err := bidcrypto.SplitKey(rand.Reader, key, 2, 3)
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	pub, err := bidcrypto.MarshalPublicKey(key.PublicKey())
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	tender := tenderFixture(id, t0.Add(time.Hour))
+	tender.BidRequirements.SubmissionFormat.EncryptionReq = true
+	tender.BidEncryption = &BidEncryptionConfig{
+		PublicKey: pub,
+		Threshold: 2,
+		ShareHolders: []KeyShareHolder{
+			{Index: 1, HolderID: "AuditorMSP", Commitment: commitments[0]},
+			{Index: 2, HolderID: "RegulatorMSP", Commitment: commitments[1]},
+			{Index: 3, HolderID: n.clientID("buyer2"), Commitment: commitments[2]},
+		},
+	}
+	return tender, &sealedTender{key: key, shares: shares}
+}
+
+// seal encrypts bid to the tender key, committing to payloadHash when given
+func (s *sealedTender) seal(t *testing.T, bid *EnhancedBidPrivate, payloadHash string) EncryptedBid {
+	t.Helper()
+	plain, err := json.Marshal(bid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, hash, err := canonicalHash(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payloadHash == "" {
+		payloadHash = hash
+	}
+	ciphertext, err := bidcrypto.Encrypt(rand.Reader, s.key.PublicKey(), bidcrypto.BidContext(bid.TenderID, bid.BidID), plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return EncryptedBid{
+		ContractorID: bid.ContractorID,
+		Ciphertext:   base64.StdEncoding.EncodeToString(ciphertext),
+		PayloadHash:  payloadHash,
+	}
+}
+
+func (n *testNet) submitSealed(who, tenderID, bidID string, sealed EncryptedBid) error {
+	n.t.Helper()
+	return n.tx(who, transientOf(n.t, "encryptedBid", sealed), func(ctx *TransactionContext) error {
+		return n.enh.SubmitEncryptedBid(ctx, tenderID, bidID)
+	})
+}
+
+func (n *testNet) releaseShare(who, tenderID string, share bidcrypto.Share) error {
+	n.t.Helper()
+	return n.tx(who, nil, func(ctx *TransactionContext) error {
+		return n.enh.ReleaseKeyShare(ctx, tenderID, share.Index, share.Value)
+	})
+}
+
+func TestBidEncryptionValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(e *EnhancedTender)
+		wantErr string
+	}{
+		{"valid", func(*EnhancedTender) {}, ""},
+		{"not required by format", func(e *EnhancedTender) { e.BidRequirements.SubmissionFormat.EncryptionReq = false }, "bid encryption key requires submissionFormat.encryptionReq"},
+		{"bad key", func(e *EnhancedTender) { e.BidEncryption.PublicKey = "not a key" }, "public key must be a PEM encoded PUBLIC KEY block"},
+		{"key set early", func(e *EnhancedTender) { e.BidEncryption.ReleasedKey = "00" }, "bid decryption key cannot be set before closing"},
+		{"no holders", func(e *EnhancedTender) { e.BidEncryption.ShareHolders = nil }, "at least one key share holder is required"},
+		{"threshold too high", func(e *EnhancedTender) { e.BidEncryption.Threshold = 4 }, "threshold must be between 1 and 3"},
+		{"index out of range", func(e *EnhancedTender) { e.BidEncryption.ShareHolders[2].Index = 4 }, "share index 4 out of range"},
+		{"duplicate index", func(e *EnhancedTender) { e.BidEncryption.ShareHolders[2].Index = 1 }, "duplicate share index 1"},
+		{"no holder", func(e *EnhancedTender) { e.BidEncryption.ShareHolders[1].HolderID = "" }, "share 2 has no holder"},
+		{"bad commitment", func(e *EnhancedTender) { e.BidEncryption.ShareHolders[0].Commitment = "04ab" }, "share 1 commitment must be a hex encoded P-256 point"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender, _ := n.encryptedTenderFixture("T1")
+			tc.mutate(tender)
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateEnhancedTender(ctx, mustJSON(t, tender))
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestSubmitEncryptedBid(t *testing.T) {
+	tests := []struct {
+		name     string
+		tenderID string
+		bidID    string
+		mutate   func(s *EncryptedBid)
+		wantErr  string
+	}{
+		{name: "valid", tenderID: "T1"},
+		{name: "plain tender", tenderID: "P1", wantErr: "tender P1 does not accept encrypted bids"},
+		{name: "no contractor", tenderID: "T1", mutate: func(s *EncryptedBid) { s.ContractorID = "" }, wantErr: "contractor ID is required"},
+		{name: "not base64", tenderID: "T1", mutate: func(s *EncryptedBid) { s.Ciphertext = "%%%" }, wantErr: "ciphertext must be non-empty base64"},
+		{name: "empty ciphertext", tenderID: "T1", mutate: func(s *EncryptedBid) { s.Ciphertext = "" }, wantErr: "ciphertext must be non-empty base64"},
+		{name: "short hash", tenderID: "T1", mutate: func(s *EncryptedBid) { s.PayloadHash = "abcd" }, wantErr: "payload hash must be a hex SHA-256 digest"},
+		{name: "duplicate", tenderID: "T1", bidID: "B0", wantErr: "bid B0 already exists for tender T1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender, keys := n.encryptedTenderFixture("T1")
+			n.openTender(tender)
+			n.openTender(tenderFixture("P1", t0.Add(time.Hour)))
+			if err := n.submitSealed("contractorB", "T1", "B0", keys.seal(t, bidFixture("T1", "B0", "contractorB", 1), "")); err != nil {
+				t.Fatal(err)
+			}
+			bidID := tc.bidID
+			if bidID == "" {
+				bidID = "B1"
+			}
+			sealed := keys.seal(t, bidFixture(tc.tenderID, bidID, "contractorA", 500000), "")
+			if tc.mutate != nil {
+				tc.mutate(&sealed)
+			}
+			err := n.submitSealed("contractorA", tc.tenderID, bidID, sealed)
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.EnhancedBidSubmitted)
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				ref, err := n.enh.GetBidRef(ctx, "T1", "B1")
+				if err == nil && (!ref.Encrypted || ref.BidHash != sealed.PayloadHash || ref.CiphertextHash == "") {
+					t.Fatalf("ref = %+v", ref)
+				}
+				return err
+			})
+		})
+	}
+
+	t.Run("transient", func(t *testing.T) {
+		n := newTestNet(t)
+		tender, _ := n.encryptedTenderFixture("T1")
+		n.openTender(tender)
+		err := n.tx("contractorA", nil, func(ctx *TransactionContext) error { return n.enh.SubmitEncryptedBid(ctx, "T1", "B1") })
+		expectErr(t, err, "transient map must contain 'encryptedBid'")
+		expectErr(t, n.submitBid("contractorA", bidFixture("T1", "B1", "contractorA", 1)), "tender T1 requires encrypted bids; use SubmitEncryptedBid")
+	})
+
+	t.Run("after deadline", func(t *testing.T) {
+		n := newTestNet(t)
+		tender, keys := n.encryptedTenderFixture("T1")
+		n.openTender(tender)
+		n.ledger.SetTime(t0.Add(time.Hour + time.Second))
+		err := n.submitSealed("contractorA", "T1", "B1", keys.seal(t, bidFixture("T1", "B1", "contractorA", 1), ""))
+		expectErr(t, err, "bid submission deadline has passed")
+	})
+}
+
+func TestEncryptedBidLifecycle(t *testing.T) {
+	n := newTestNet(t)
+	tender, keys := n.encryptedTenderFixture("T1")
+	n.openTender(tender)
+
+	good := bidFixture("T1", "B1", "contractorA", 500000)
+	tampered := bidFixture("T1", "B2", "contractorB", 450000)
+	impostor := bidFixture("T1", "B3", "contractorC", 400000)
+	for who, s := range map[string]struct {
+		bid  *EnhancedBidPrivate
+		hash string
+	}{
+		"contractorA": {good, ""},
+		"contractorB": {tampered, sha256Hex("something else")},
+		"contractorC": {impostor, ""},
+	} {
+		sealed := keys.seal(t, s.bid, s.hash)
+		if who == "contractorC" {
+			// Sealed content names another contractor than the submission
+			other := *impostor
+			other.ContractorID = "contractorA"
+			sealed = keys.seal(t, &other, "")
+			sealed.ContractorID = "contractorC"
+		}
+		if err := n.submitSealed(who, "T1", s.bid.BidID, sealed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectErr(t, n.releaseShare("auditor", "T1", keys.shares[0]), "key shares can only be released after the tender is closed")
+	n.ledger.SetTime(t0.Add(time.Hour))
+	n.closeTender("T1")
+
+	evaluate := func() error {
+		return n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "T1") })
+	}
+	open := func() error {
+		return n.tx("auditor", nil, func(ctx *TransactionContext) error { return n.enh.OpenEncryptedBids(ctx, "T1") })
+	}
+	expectErr(t, evaluate(), "encrypted bids must be opened before evaluation")
+	expectErr(t, open(), "bid decryption key for tender T1 has not been released")
+
+	releases := []struct {
+		name    string
+		who     string
+		share   bidcrypto.Share
+		event   string
+		wantErr string
+	}{
+		{name: "undefined share", who: "auditor", share: bidcrypto.Share{Index: 9, Value: keys.shares[0].Value}, wantErr: "share 9 is not defined for tender T1"},
+		{name: "not the holder", who: "contractorA", share: keys.shares[0], wantErr: "caller does not hold share 1"},
+		{name: "wrong value", who: "regulator", share: bidcrypto.Share{Index: 2, Value: keys.shares[0].Value}, wantErr: "share 2 does not match its commitment"},
+		{name: "first share by msp", who: "auditor", share: keys.shares[0], event: events.KeyShareReleased},
+		{name: "same share again", who: "auditor", share: keys.shares[0], wantErr: "share 1 has already been released"},
+		{name: "threshold share by identity", who: "buyer2", share: keys.shares[2], event: events.BidKeyReleased},
+		{name: "after threshold", who: "regulator", share: keys.shares[1], wantErr: "bid decryption key for tender T1 has already been released"},
+	}
+	for _, r := range releases {
+		expectErr(t, n.releaseShare(r.who, "T1", r.share), r.wantErr)
+		if r.wantErr == "" {
+			n.expectEvents(r.event)
+		}
+	}
+	if cfg := n.tender("T1").BidEncryption; cfg.ReleasedKey == "" || cfg.ReleasedAt != rfc(t0.Add(time.Hour)) {
+		t.Fatalf("encryption config = %+v", cfg)
+	}
+
+	if err := open(); err != nil {
+		t.Fatal(err)
+	}
+	var p events.BidsOpenedPayload
+	if err := n.expectEvents(events.EncryptedBidsOpened)[0].Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Opened != 1 || p.Failed != 2 {
+		t.Fatalf("opened payload = %+v", p)
+	}
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		want := map[string]string{
+			"B1": "",
+			"B2": "decrypted bid does not match committed hash",
+			"B3": "decrypted bid identifiers do not match the submission",
+		}
+		for bidID, openErr := range want {
+			ref, err := n.enh.GetBidRef(ctx, "T1", bidID)
+			if err != nil {
+				return err
+			}
+			if ref.OpenError != openErr || (openErr == "") != (ref.OpenedAt != "") {
+				t.Fatalf("ref %s = %+v", bidID, ref)
+			}
+		}
+		return nil
+	})
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		bid, err := n.enh.GetEnhancedBidPrivate(ctx, "T1", "B1")
+		if err == nil && (bid.TotalAmount != 500000 || bid.SubmittedAt != rfc(t0)) {
+			t.Fatalf("opened bid = %+v", bid)
+		}
+		return err
+	})
+
+	// Opening again leaves settled refs alone
+	if err := open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.expectEvents(events.EncryptedBidsOpened)[0].Decode(&p); err != nil || p.Opened != 0 || p.Failed != 0 {
+		t.Fatalf("second open = %+v, %v", p, err)
+	}
+	if err := evaluate(); err != nil {
+		t.Fatal(err)
+	}
+	n.expectEvents(events.BidEvaluated)
+
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		expectErr(t, n.enh.OpenEncryptedBids(ctx, "T9"), "tender T9 not found")
+		expectErr(t, n.enh.ReleaseKeyShare(ctx, "P1", 1, ""), "tender P1 not found")
+		return nil
+	})
+}
@@ -0,0 +1,597 @@
+package contract
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+func TestCreateEnhancedTender(t *testing.T) {
+	deadline := t0.Add(7 * 24 * time.Hour)
+	tests := []struct {
+		name    string
+		mutate  func(tender *EnhancedTender)
+		raw     string
+		wantErr string
+	}{
+		{name: "valid", mutate: func(*EnhancedTender) {}},
+		{name: "invalid json", raw: `{"id":`, wantErr: "invalid tender JSON"},
+		{name: "missing id", mutate: func(e *EnhancedTender) { e.ID = "" }, wantErr: "tender ID is required"},
+		{name: "missing description", mutate: func(e *EnhancedTender) { e.ProjectScope.Description = "" }, wantErr: "project description is required"},
+		{name: "missing bid deadline", mutate: func(e *EnhancedTender) { e.Deadlines.BidSubmissionDeadline = "" }, wantErr: "bid submission deadline is required"},
+		{name: "missing owner", mutate: func(e *EnhancedTender) { e.OwnerDetails.OrganizationName = "" }, wantErr: "owner organization name is required"},
+		{name: "bid deadline not RFC3339", mutate: func(e *EnhancedTender) { e.Deadlines.BidSubmissionDeadline = "2030-03-11 09:00" }, wantErr: "invalid bid submission deadline format"},
+		{name: "bid deadline without zone", mutate: func(e *EnhancedTender) { e.Deadlines.BidSubmissionDeadline = "2030-03-11T09:00:00" }, wantErr: "invalid bid submission deadline format"},
+		{name: "bid deadline with offset", mutate: func(e *EnhancedTender) { e.Deadlines.BidSubmissionDeadline = "2030-03-11T10:00:00+01:00" }},
+		{name: "bad project start", mutate: func(e *EnhancedTender) { e.Deadlines.ProjectStartDate = "soon" }, wantErr: "invalid project start date format"},
+		{name: "bad project end", mutate: func(e *EnhancedTender) { e.Deadlines.ProjectEndDate = "later" }, wantErr: "invalid project end date format"},
+		{name: "bad milestone deadline", mutate: func(e *EnhancedTender) { e.Deadlines.MilestoneDeadlines[0].Deadline = "" }, wantErr: "invalid milestone deadline format for Base course"},
+		{name: "questions after bid deadline", mutate: func(e *EnhancedTender) { e.Deadlines.QuestionsDeadline = rfc(deadline.Add(time.Hour)) }, wantErr: "deadlines.bidSubmissionDeadline: bid submission deadline must be after the questions deadline"},
+		{name: "issued after questions", mutate: func(e *EnhancedTender) { e.Deadlines.RFQIssueDate = rfc(deadline.Add(-24 * time.Hour)) }, wantErr: "questions deadline must be after the RFQ issue date"},
+		{name: "project starts before bids close", mutate: func(e *EnhancedTender) { e.Deadlines.ProjectStartDate = rfc(deadline) }, wantErr: "project start date must be after the bid submission deadline"},
+		{name: "project ends before start", mutate: func(e *EnhancedTender) { e.Deadlines.ProjectEndDate = rfc(deadline.Add(10 * 24 * time.Hour)) }, wantErr: "deadlines.projectEndDate: project end date must be after the project start date"},
+		{name: "milestone before project", mutate: func(e *EnhancedTender) { e.Deadlines.MilestoneDeadlines[0].Deadline = rfc(deadline.Add(time.Hour)) }, wantErr: "deadlines.milestoneDeadlines.0.deadline: milestone Base course is due before the project start date"},
+		{name: "milestone after project", mutate: func(e *EnhancedTender) {
+			e.Deadlines.MilestoneDeadlines[0].Deadline = rfc(deadline.Add(365 * 24 * time.Hour))
+		}, wantErr: "milestone Base course is due after the project end date"},
+		{name: "milestone without project dates", mutate: func(e *EnhancedTender) {
+			e.Deadlines.ProjectStartDate = ""
+			e.Deadlines.ProjectEndDate = ""
+		}},
+		{name: "payments below 100", mutate: func(e *EnhancedTender) { e.ProjectScope.Budget.PaymentSchedule[1].Percentage = 50 }, wantErr: "projectScope.budget.paymentSchedule: payment schedule percentages must total 100, got 90.00"},
+		{name: "zero payment", mutate: func(e *EnhancedTender) {
+			e.ProjectScope.Budget.PaymentSchedule[0].Percentage = 0
+			e.ProjectScope.Budget.PaymentSchedule[1].Percentage = 100
+		}, wantErr: "paymentSchedule.0.percentage: payment Base course percentage must be above 0"},
+		{name: "no payment schedule", mutate: func(e *EnhancedTender) { e.ProjectScope.Budget.PaymentSchedule = nil }},
+		{name: "negative budget", mutate: func(e *EnhancedTender) { e.ProjectScope.Budget.EstimatedMin = -1 }, wantErr: "projectScope.budget.estimatedMin: estimated minimum must not be negative"},
+		{name: "inverted budget", mutate: func(e *EnhancedTender) { e.ProjectScope.Budget.EstimatedMin = 1000000 }, wantErr: "estimated minimum 1000000.00 exceeds estimated maximum 900000.00"},
+		{name: "budget without maximum", mutate: func(e *EnhancedTender) { e.ProjectScope.Budget.EstimatedMax = 0 }},
+		{name: "sub-criteria match weight", mutate: func(e *EnhancedTender) {
+			e.EvaluationCriteria[1].SubCriteria = []SubCriterion{{Name: "Plan", Weight: 25}, {Name: "Record", Weight: 15}}
+		}},
+		{name: "sub-criteria short of weight", mutate: func(e *EnhancedTender) {
+			e.EvaluationCriteria[1].SubCriteria = []SubCriterion{{Name: "Plan", Weight: 25}, {Name: "Record", Weight: 10}}
+		}, wantErr: "evaluationCriteria.1.subCriteria: sub-criteria weights of Safety must total its weight 40.00, got 35.00"},
+		{name: "negative sub-criterion", mutate: func(e *EnhancedTender) {
+			e.EvaluationCriteria[1].SubCriteria = []SubCriterion{{Name: "Plan", Weight: 45}, {Name: "Record", Weight: -5}}
+		}, wantErr: "subCriteria.1.weight: sub-criterion Record weight must not be negative"},
+		{name: "optional dates empty", mutate: func(e *EnhancedTender) {
+			e.Deadlines.ProjectStartDate = ""
+			e.Deadlines.ProjectEndDate = ""
+			e.Deadlines.MilestoneDeadlines = nil
+		}},
+		{name: "no criteria", mutate: func(e *EnhancedTender) { e.EvaluationCriteria = nil }, wantErr: "at least one evaluation criterion is required"},
+		{name: "unnamed criterion", mutate: func(e *EnhancedTender) { e.EvaluationCriteria[0].Name = "" }, wantErr: "criterion name is required"},
+		{name: "negative weight", mutate: func(e *EnhancedTender) {
+			e.EvaluationCriteria[0].Weight = -10
+			e.EvaluationCriteria[1].Weight = 110
+		}, wantErr: "criterion weight must be between 0 and 100"},
+		{name: "weights below 100", mutate: func(e *EnhancedTender) { e.EvaluationCriteria[1].Weight = 39 }, wantErr: "must equal 100, got 99.00"},
+		{name: "weights within tolerance", mutate: func(e *EnhancedTender) { e.EvaluationCriteria[1].Weight = 40.005 }},
+		{name: "unknown auction type", mutate: func(e *EnhancedTender) { e.AuctionType = "DUTCH" }, wantErr: `auctionType: "DUTCH" is not one of "SEALED", "REVERSE"`},
+		{name: "lot mode without lots", mutate: func(e *EnhancedTender) { e.LotAwardMode = lotAwardPerLot }, wantErr: "lot award mode requires lots"},
+		{name: "unknown procurement method", mutate: func(e *EnhancedTender) { e.ProcurementMethod = "SECRET" }, wantErr: `procurementMethod: "SECRET" is not one of`},
+		{name: "unknown status", mutate: func(e *EnhancedTender) { e.Status = "PENDING" }, wantErr: `status: "PENDING" is not one of "DRAFT", "OPEN"`},
+		{name: "unknown criterion type", mutate: func(e *EnhancedTender) { e.EvaluationCriteria[1].Type = "SUBJECTIVE" }, wantErr: `evaluationCriteria.1.type: "SUBJECTIVE" is not one of`},
+		{name: "unknown scoring method", mutate: func(e *EnhancedTender) { e.EvaluationCriteria[0].ScoringMethod = "CHEAPEST" }, wantErr: `evaluationCriteria.0.scoringMethod: "CHEAPEST" is not one of`},
+		{name: "negative retention", mutate: func(e *EnhancedTender) { e.Retention = &RetentionPolicy{LosingBidDays: -1} }, wantErr: "retention periods must not be negative"},
+		{name: "duplicate", mutate: func(e *EnhancedTender) { e.ID = "EXISTING" }, wantErr: "tender EXISTING already exists"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.createTender(tenderFixture("EXISTING", deadline))
+			js := tc.raw
+			if js == "" {
+				tender := tenderFixture("T1", deadline)
+				tc.mutate(tender)
+				js = mustJSON(t, tender)
+			}
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateEnhancedTender(ctx, js)
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			envs := n.expectEvents(events.EnhancedRFQCreated)
+			var p events.TenderCreatedPayload
+			if err := envs[0].Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.TenderID != "T1" || p.Status != "DRAFT" || p.Owner != "District Roads Authority" {
+				t.Fatalf("payload = %+v", p)
+			}
+			got := n.tender("T1")
+			if got.Status != "DRAFT" || got.Version != 1 || got.CreatedAt != rfc(t0) || got.DocType != docTypeTender {
+				t.Fatalf("tender = %+v", got)
+			}
+		})
+	}
+}
+
+func TestPublishTenderDeadlines(t *testing.T) {
+	tests := []struct {
+		name     string
+		deadline time.Time
+		status   string
+		issued   string
+		wantErr  string
+	}{
+		{name: "deadline next week", deadline: t0.Add(7 * 24 * time.Hour)},
+		{name: "deadline one second ahead", deadline: t0.Add(time.Second)},
+		{name: "deadline is now", deadline: t0},
+		{name: "deadline one second ago", deadline: t0.Add(-time.Second), wantErr: "bid submission deadline must be in the future"},
+		{name: "deadline last year", deadline: t0.AddDate(-1, 0, 0), wantErr: "bid submission deadline must be in the future"},
+		{name: "already open", deadline: t0.Add(time.Hour), status: "OPEN", wantErr: "only draft tenders can be published"},
+		{name: "keeps issue date", deadline: t0.Add(time.Hour), issued: rfc(t0.Add(-96 * time.Hour))},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := tenderFixture("T1", tc.deadline)
+			tender.Status = tc.status
+			tender.Deadlines.RFQIssueDate = tc.issued
+			n.createTender(tender)
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.PublishTender(ctx, "T1")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.TenderPublished)
+			got := n.tender("T1")
+			wantIssued := tc.issued
+			if wantIssued == "" {
+				wantIssued = rfc(t0)
+			}
+			if got.Status != "OPEN" || got.Deadlines.RFQIssueDate != wantIssued {
+				t.Fatalf("tender = %+v", got)
+			}
+		})
+	}
+
+	n := newTestNet(t)
+	err := n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.PublishTender(ctx, "T9") })
+	expectErr(t, err, "tender T9 not found")
+}
+
+func TestSubmitEnhancedBid(t *testing.T) {
+	deadline := t0.Add(7 * 24 * time.Hour)
+	tests := []struct {
+		name      string
+		at        time.Time
+		draft     bool
+		mutate    func(b *EnhancedBidPrivate)
+		transient map[string][]byte
+		wantErr   string
+	}{
+		{name: "valid", at: t0},
+		{name: "exactly at deadline", at: deadline},
+		{name: "one second after deadline", at: deadline.Add(time.Second), wantErr: "bid submission deadline has passed"},
+		{name: "draft tender", at: t0, draft: true, wantErr: "tender is not open for bids"},
+		{name: "no transient", at: t0, transient: map[string][]byte{}, wantErr: "transient map must contain 'bid'"},
+		{name: "bad json", at: t0, transient: map[string][]byte{"bid": []byte("nope")}, wantErr: "invalid bid JSON"},
+		{name: "missing contractor", at: t0, mutate: func(b *EnhancedBidPrivate) { b.ContractorID = "" }, wantErr: "contractor ID are required"},
+		{name: "zero amount", at: t0, mutate: func(b *EnhancedBidPrivate) { b.TotalAmount = 0 }, wantErr: "total amount must be positive"},
+		{name: "no currency", at: t0, mutate: func(b *EnhancedBidPrivate) { b.Currency = "" }, wantErr: "currency is required"},
+		{name: "no methodology", at: t0, mutate: func(b *EnhancedBidPrivate) { b.TechnicalProposal.Methodology = "" }, wantErr: "technical methodology is required"},
+		{name: "no phase breakdown", at: t0, mutate: func(b *EnhancedBidPrivate) { b.FinancialProposal.BreakdownByPhase = nil }, wantErr: "financial breakdown by phase is required"},
+		{name: "no compliance checklist", at: t0, mutate: func(b *EnhancedBidPrivate) { b.ComplianceChecklist = nil }, wantErr: "compliance checklist is required"},
+		{name: "lot bid on single tender", at: t0, mutate: func(b *EnhancedBidPrivate) { b.LotBids = []LotBid{{LotID: "L1", Amount: 1}} }, wantErr: "tender T1 has no lots"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			if tc.draft {
+				n.createTender(tenderFixture("T1", deadline))
+			} else {
+				n.openTender(tenderFixture("T1", deadline))
+			}
+			n.ledger.SetTime(tc.at)
+			bid := bidFixture("T1", "B1", "contractorA", 500000)
+			if tc.mutate != nil {
+				tc.mutate(bid)
+			}
+			transient := tc.transient
+			if transient == nil {
+				transient = transientOf(t, "bid", bid)
+			}
+			err := n.tx("contractorA", transient, func(ctx *TransactionContext) error {
+				return n.enh.SubmitEnhancedBid(ctx, "T1", "B1")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				if n.ledger.State(bidRefKey("T1", "B1")) != nil {
+					t.Fatalf("failed bid left a reference")
+				}
+				return
+			}
+			n.expectEvents(events.EnhancedBidSubmitted)
+			n.mustQuery("buyer", func(ctx *TransactionContext) error {
+				stored, err := n.enh.GetEnhancedBidPrivate(ctx, "T1", "B1")
+				if err == nil && stored.SubmittedAt != rfc(tc.at) {
+					t.Fatalf("submittedAt = %s", stored.SubmittedAt)
+				}
+				return err
+			})
+		})
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(tenderFixture("T1", deadline))
+		n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 1))
+		expectErr(t, n.submitBid("contractorA", bidFixture("T1", "B1", "contractorA", 2)), "bid B1 already exists for tender T1")
+	})
+
+	t.Run("unknown tender", func(t *testing.T) {
+		n := newTestNet(t)
+		expectErr(t, n.submitBid("contractorA", bidFixture("T9", "B1", "contractorA", 1)), "tender T9 not found")
+	})
+
+	t.Run("debarred contractor", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(tenderFixture("T1", deadline))
+		n.mustTx("regulator", nil, func(ctx *TransactionContext) error {
+			return n.enh.DebarContractor(ctx, "contractorA", "fraud", rfc(t0), "")
+		})
+		expectErr(t, n.submitBid("contractorA", bidFixture("T1", "B1", "contractorA", 1)), "contractor contractorA is debarred indefinitely: fraud")
+	})
+}
+
+func TestEnhancedBidReads(t *testing.T) {
+	n := newTestNet(t)
+	n.openTender(tenderFixture("T1", t0.Add(24*time.Hour)))
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+	n.mustSubmitBid("contractorB", bidFixture("T1", "B2", "contractorB", 700000))
+
+	tests := []struct {
+		name    string
+		fn      func(ctx *TransactionContext) error
+		wantErr string
+	}{
+		{"list", func(ctx *TransactionContext) error {
+			refs, err := n.enh.ListBidsPublic(ctx, "T1")
+			if err == nil && (len(refs) != 2 || refs[0].BidID != "B1" || refs[1].BidID != "B2") {
+				t.Fatalf("refs = %+v", refs)
+			}
+			return err
+		}, ""},
+		{"ref", func(ctx *TransactionContext) error {
+			ref, err := n.enh.GetBidRef(ctx, "T1", "B2")
+			if err == nil && (ref.ContractorID != "contractorB" || ref.DocType != docTypeBidRef) {
+				t.Fatalf("ref = %+v", ref)
+			}
+			return err
+		}, ""},
+		{"missing ref", func(ctx *TransactionContext) error {
+			_, err := n.enh.GetBidRef(ctx, "T1", "B3")
+			return err
+		}, "bid B3 not found for tender T1"},
+		{"private", func(ctx *TransactionContext) error {
+			bid, err := n.enh.GetEnhancedBidPrivate(ctx, "T1", "B1")
+			if err == nil && bid.TotalAmount != 500000 {
+				t.Fatalf("bid = %+v", bid)
+			}
+			return err
+		}, ""},
+		{"missing private", func(ctx *TransactionContext) error {
+			_, err := n.enh.GetEnhancedBidPrivate(ctx, "T1", "B3")
+			return err
+		}, "private bid not found"},
+		{"missing tender", func(ctx *TransactionContext) error {
+			_, err := n.enh.GetEnhancedTender(ctx, "T9")
+			return err
+		}, "tender T9 not found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expectErr(t, n.query("auditor", tc.fn), tc.wantErr)
+		})
+	}
+}
+
+func TestCloseTenderEnhanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		publish bool
+		twice   bool
+		wantErr string
+	}{
+		{"open tender", true, false, ""},
+		{"draft tender", false, false, "only open tenders can be closed"},
+		{"already closed", true, true, "only open tenders can be closed"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			if tc.publish {
+				n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+			} else {
+				n.createTender(tenderFixture("T1", t0.Add(time.Hour)))
+			}
+			if tc.twice {
+				n.closeTender("T1")
+			}
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CloseTenderEnhanced(ctx, "T1")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr == "" {
+				n.expectEvents(events.TenderClosed)
+				if got := n.tender("T1"); got.Status != "CLOSED" {
+					t.Fatalf("status = %s", got.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateBids(t *testing.T) {
+	tests := []struct {
+		name    string
+		bids    bool
+		close   bool
+		wantErr string
+	}{
+		{"closed with bids", true, true, ""},
+		{"still open", true, false, "tender must be closed before evaluation"},
+		{"no bids", false, true, "no bids to evaluate"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+			if tc.bids {
+				n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+				b2 := bidFixture("T1", "B2", "contractorB", 700000)
+				b2.ComplianceChecklist = map[string]bool{"Safety": false}
+				n.mustSubmitBid("contractorB", b2)
+			}
+			if tc.close {
+				n.closeTender("T1")
+			}
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.EvaluateBids(ctx, "T1")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.BidEvaluated, events.BidEvaluated)
+			// Price scores 100 - amount/1e6*10 at weight 60; Safety scores 100 when compliant, else 50, at weight 40
+			want := map[string]float64{"B1": 0.6*95 + 0.4*100, "B2": 0.6*93 + 0.4*50}
+			n.mustQuery("buyer", func(ctx *TransactionContext) error {
+				evals, err := n.enh.ListEvaluations(ctx, "T1")
+				if err != nil {
+					return err
+				}
+				if len(evals) != 2 {
+					t.Fatalf("evaluations = %+v", evals)
+				}
+				for _, e := range evals {
+					if math.Abs(e.Score-want[e.BidID]) > 1e-9 {
+						t.Fatalf("score of %s = %v, want %v", e.BidID, e.Score, want[e.BidID])
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestCalculateBidScore(t *testing.T) {
+	s := new(EnhancedSmartContract)
+	bid := &EnhancedBidPrivate{TotalAmount: 2000000, ComplianceChecklist: map[string]bool{"ISO": true, "Bond": false}}
+	tests := []struct {
+		name      string
+		criterion EvalCriterion
+		want      float64
+	}{
+		{"price", EvalCriterion{Name: "Price", Type: "QUANTITATIVE", Weight: 100}, 80},
+		{"price floor", EvalCriterion{Name: "Price", Type: "QUANTITATIVE", Weight: 100}, 0},
+		{"other quantitative", EvalCriterion{Name: "Duration", Type: "QUANTITATIVE", Weight: 100}, 75},
+		{"qualitative met", EvalCriterion{Name: "ISO", Type: "QUALITATIVE", Weight: 100}, 100},
+		{"qualitative missing", EvalCriterion{Name: "Team", Type: "QUALITATIVE", Weight: 100}, 50},
+		{"pass", EvalCriterion{Name: "ISO", Type: "PASS_FAIL", Weight: 100}, 100},
+		{"fail", EvalCriterion{Name: "Bond", Type: "PASS_FAIL", Weight: 100}, 0},
+		{"unknown type", EvalCriterion{Name: "X", Type: "OTHER", Weight: 100}, 50},
+		{"weighted", EvalCriterion{Name: "ISO", Type: "QUALITATIVE", Weight: 25}, 25},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := *bid
+			if tc.name == "price floor" {
+				b.TotalAmount = 20000000
+			}
+			if got := s.calculateBidScore(&b, []EvalCriterion{tc.criterion}); math.Abs(got-tc.want) > 1e-9 {
+				t.Fatalf("score = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnhancedAwardTender(t *testing.T) {
+	tests := []struct {
+		name    string
+		close   bool
+		bidID   string
+		twice   bool
+		wantErr string
+	}{
+		{name: "closed tender", close: true, bidID: "B1"},
+		{name: "open tender", bidID: "B1", wantErr: "tender must be closed before awarding"},
+		{name: "unknown bid", close: true, bidID: "B9", wantErr: "bid B9 not found for tender T1"},
+		{name: "already awarded", close: true, bidID: "B1", twice: true, wantErr: "tender must be closed before awarding"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+			n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+			n.ledger.Advance(2 * time.Hour)
+			if tc.close {
+				n.closeTender("T1")
+			}
+			award := func() error {
+				return n.tx("buyer", nil, func(ctx *TransactionContext) error {
+					return n.enh.AwardTender(ctx, "T1", tc.bidID)
+				})
+			}
+			if tc.twice {
+				if err := award(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expectErr(t, award(), tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.TenderAwarded)
+			got := n.tender("T1")
+			if got.Status != "AWARDED" || got.AwardedBidID != "B1" || got.AwardedAt != rfc(t0.Add(2*time.Hour)) {
+				t.Fatalf("tender = %+v", got)
+			}
+		})
+	}
+
+	n := newTestNet(t)
+	err := n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardTender(ctx, "T9", "B1") })
+	expectErr(t, err, "tender T9 not found")
+}
+
+func TestAwardBestBid(t *testing.T) {
+	tests := []struct {
+		name     string
+		close    bool
+		evaluate bool
+		wantErr  string
+	}{
+		{"evaluated", true, true, ""},
+		{"not evaluated", true, false, "no evaluations found for tender"},
+		{"still open", false, false, "tender must be closed before awarding"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+			n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 900000))
+			n.mustSubmitBid("contractorB", bidFixture("T1", "B2", "contractorB", 400000))
+			if tc.close {
+				n.closeTender("T1")
+			}
+			if tc.evaluate {
+				n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "T1") })
+			}
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.AwardBestBid(ctx, "T1")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr == "" {
+				if got := n.tender("T1"); got.AwardedBidID != "B2" {
+					t.Fatalf("awarded %s, want the cheaper B2", got.AwardedBidID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTenderStatistics(t *testing.T) {
+	n := newTestNet(t)
+	n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+	n.openTender(tenderFixture("T2", t0.Add(time.Hour)))
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 300))
+	n.mustSubmitBid("contractorB", bidFixture("T1", "B2", "contractorB", 500))
+
+	tests := []struct {
+		name      string
+		tenderID  string
+		wantBids  float64
+		wantStats map[string]interface{}
+		wantErr   string
+	}{
+		{name: "with bids", tenderID: "T1", wantBids: 2, wantStats: map[string]interface{}{
+			"totalAmount": 800.0, "averageBid": 400.0, "lowestBid": 300.0, "highestBid": 500.0, "currency": "USD",
+		}},
+		{name: "without bids", tenderID: "T2", wantBids: 0},
+		{name: "unknown tender", tenderID: "T9", wantErr: "tender T9 not found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out string
+			err := n.query("buyer", func(ctx *TransactionContext) error {
+				var err error
+				out, err = n.enh.GetTenderStatistics(ctx, tc.tenderID)
+				return err
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			var stats map[string]interface{}
+			if err := json.Unmarshal([]byte(out), &stats); err != nil {
+				t.Fatal(err)
+			}
+			if stats["totalBids"] != tc.wantBids || stats["status"] != "OPEN" {
+				t.Fatalf("stats = %v", stats)
+			}
+			bidStats, _ := stats["bidStatistics"].(map[string]interface{})
+			if len(bidStats) != len(tc.wantStats) {
+				t.Fatalf("bid statistics = %v", bidStats)
+			}
+			for k, v := range tc.wantStats {
+				if bidStats[k] != v {
+					t.Fatalf("bid statistics %s = %v, want %v", k, bidStats[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTendersByStatus(t *testing.T) {
+	n := newTestNet(t)
+	n.createTender(tenderFixture("D1", t0.Add(time.Hour)))
+	n.openTender(tenderFixture("O1", t0.Add(time.Hour)))
+	n.openTender(tenderFixture("O2", t0.Add(time.Hour)))
+
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{"OPEN", []string{"O1", "O2"}},
+		{"DRAFT", []string{"D1"}},
+		{"AWARDED", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.status, func(t *testing.T) {
+			var out string
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				var err error
+				out, err = n.enh.GetTendersByStatus(ctx, tc.status)
+				return err
+			})
+			var tenders []*EnhancedTender
+			if err := json.Unmarshal([]byte(out), &tenders); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, tender := range tenders {
+				got = append(got, tender.ID)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("tenders = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("tenders = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
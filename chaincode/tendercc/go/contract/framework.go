@@ -0,0 +1,548 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// Call-off methods for orders placed under a framework agreement
+const (
+	callOffDirect          = "DIRECT"
+	callOffMiniCompetition = "MINI_COMPETITION"
+)
+
+func frameworkKey(frameworkID string) string {
+	return fmt.Sprintf("FRAMEWORK_%s", frameworkID)
+}
+
+func callOffKey(frameworkID, orderID string) string {
+	return fmt.Sprintf("CALLOFF_%s_%s", frameworkID, orderID)
+}
+
+func callOffRespRefKey(frameworkID, orderID, contractorID string) string {
+	return fmt.Sprintf("CALLOFFREF_%s_%s_%s", frameworkID, orderID, contractorID)
+}
+
+func callOffRespPrivKey(frameworkID, orderID, contractorID string) string {
+	return fmt.Sprintf("CALLOFFBID_%s_%s_%s", frameworkID, orderID, contractorID)
+}
+
+// isFrameworkMember reports whether the contractor holds a place on the framework
+func isFrameworkMember(f *FrameworkAgreement, contractorID string) bool {
+	for _, m := range f.Members {
+		if m.ContractorID == contractorID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateFrameworkAgreement turns a closed or awarded tender into a framework with several winners.
+// The JSON carries id, tenderId, title, bidIds, ceilingValue, currency, startDate and expiryDate.
+// It passes the same award checks as AwardTender; a required award signature covers the JSON as submitted.
+func (s *EnhancedSmartContract) CreateFrameworkAgreement(ctx contractapi.TransactionContextInterface, frameworkJSON string) error {
+	var req struct {
+		ID           string   `json:"id"`
+		TenderID     string   `json:"tenderId"`
+		Title        string   `json:"title"`
+		BidIDs       []string `json:"bidIds"`
+		CeilingValue float64  `json:"ceilingValue"`
+		Currency     string   `json:"currency"`
+		StartDate    string   `json:"startDate"`
+		ExpiryDate   string   `json:"expiryDate"`
+	}
+	if err := json.Unmarshal([]byte(frameworkJSON), &req); err != nil {
+		return fmt.Errorf("invalid framework JSON: %v", err)
+	}
+	if req.ID == "" || req.TenderID == "" {
+		return fmt.Errorf("framework ID and tender ID are required")
+	}
+	if len(req.BidIDs) == 0 {
+		return fmt.Errorf("at least one winning bid is required")
+	}
+	if req.CeilingValue <= 0 {
+		return fmt.Errorf("ceiling value must be positive")
+	}
+	expiry, err := time.Parse(time.RFC3339, req.ExpiryDate)
+	if err != nil {
+		return fmt.Errorf("invalid expiry date: %v", err)
+	}
+
+	exists, err := s.assetExists(ctx, frameworkKey(req.ID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("framework %s already exists", req.ID)
+	}
+
+	tender, err := s.GetEnhancedTender(ctx, req.TenderID)
+	if err != nil {
+		return err
+	}
+	if tender.FrameworkID != "" {
+		return fmt.Errorf("tender %s already has framework %s", req.TenderID, tender.FrameworkID)
+	}
+	if tender.Status != "CLOSED" && tender.Status != "AWARDED" {
+		return fmt.Errorf("tender must be closed or awarded before creating a framework")
+	}
+
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	if !expiry.After(txTime) {
+		return fmt.Errorf("expiry date must be in the future")
+	}
+	start := txTime
+	if req.StartDate != "" {
+		if start, err = time.Parse(time.RFC3339, req.StartDate); err != nil {
+			return fmt.Errorf("invalid start date: %v", err)
+		}
+		if !expiry.After(start) {
+			return fmt.Errorf("expiry date must be after start date")
+		}
+	}
+
+	framework := FrameworkAgreement{
+		ID:           req.ID,
+		TenderID:     req.TenderID,
+		Title:        req.Title,
+		CeilingValue: req.CeilingValue,
+		Currency:     req.Currency,
+		StartDate:    start.Format(time.RFC3339),
+		ExpiryDate:   req.ExpiryDate,
+		Status:       "ACTIVE",
+		CreatedAt:    txTime.Format(time.RFC3339),
+		UpdatedAt:    txTime.Format(time.RFC3339),
+	}
+	if framework.Currency == "" {
+		framework.Currency = tender.ProjectScope.Budget.Currency
+	}
+	// Members are named by contractor, not by reverse auction alias
+	seen := make(map[string]bool)
+	for _, bidID := range req.BidIDs {
+		contractorID, err := bidContractor(ctx, tender, bidID)
+		if err != nil {
+			return err
+		}
+		if seen[contractorID] {
+			return fmt.Errorf("contractor %s appears more than once", contractorID)
+		}
+		seen[contractorID] = true
+		framework.Members = append(framework.Members, FrameworkMember{ContractorID: contractorID, BidID: bidID})
+	}
+	if tender.Status == "AWARDED" && tender.AwardedBidID != "" {
+		found := false
+		for _, m := range framework.Members {
+			if m.BidID == tender.AwardedBidID {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("awarded bid %s must be one of the framework winners", tender.AwardedBidID)
+		}
+	}
+	if err := s.authorizeAward(ctx, tender, framework.ID, []byte(frameworkJSON), req.BidIDs...); err != nil {
+		return err
+	}
+
+	bytes, _ := json.Marshal(framework)
+	if err := ctx.GetStub().PutState(frameworkKey(framework.ID), bytes); err != nil {
+		return err
+	}
+
+	// Link the tender to its framework and mark it awarded
+	tender.FrameworkID = framework.ID
+	tender.Status = "AWARDED"
+	tender.UpdatedAt = framework.CreatedAt
+	tender.AwardedAt = framework.CreatedAt
+	if err := putTender(ctx, tender); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.FrameworkCreated, framework.TenderID, events.FrameworkPayload{
+		FrameworkID:  framework.ID,
+		TenderID:     framework.TenderID,
+		Members:      len(framework.Members),
+		CeilingValue: framework.CeilingValue,
+		ExpiryDate:   framework.ExpiryDate,
+	})
+}
+
+// GetFrameworkAgreement retrieves a framework agreement
+func (s *EnhancedSmartContract) GetFrameworkAgreement(ctx contractapi.TransactionContextInterface, frameworkID string) (*FrameworkAgreement, error) {
+	data, err := ctx.GetStub().GetState(frameworkKey(frameworkID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("framework %s not found", frameworkID)
+	}
+	var framework FrameworkAgreement
+	if err := json.Unmarshal(data, &framework); err != nil {
+		return nil, err
+	}
+	return &framework, nil
+}
+
+// activeFramework loads a framework and checks it can still take orders
+func (s *EnhancedSmartContract) activeFramework(ctx contractapi.TransactionContextInterface, frameworkID string, now time.Time) (*FrameworkAgreement, error) {
+	framework, err := s.GetFrameworkAgreement(ctx, frameworkID)
+	if err != nil {
+		return nil, err
+	}
+	if framework.Status != "ACTIVE" {
+		return nil, fmt.Errorf("framework %s is %s", frameworkID, framework.Status)
+	}
+	start, _ := time.Parse(time.RFC3339, framework.StartDate)
+	expiry, _ := time.Parse(time.RFC3339, framework.ExpiryDate)
+	if now.Before(start) {
+		return nil, fmt.Errorf("framework %s has not started", frameworkID)
+	}
+	if !now.Before(expiry) {
+		return nil, fmt.Errorf("framework %s expired on %s", frameworkID, framework.ExpiryDate)
+	}
+	return framework, nil
+}
+
+// requireFrameworkBuyer lets only the owner of the framework's source tender place and
+// award call-offs
+func (s *EnhancedSmartContract) requireFrameworkBuyer(ctx contractapi.TransactionContextInterface, framework *FrameworkAgreement, action string) error {
+	tender, err := s.GetEnhancedTender(ctx, framework.TenderID)
+	if err != nil {
+		return err
+	}
+	return requireOwner(ctx, tender, action)
+}
+
+// commitCallOff adds an order value to the framework's cumulative spend
+func (s *EnhancedSmartContract) commitCallOff(ctx contractapi.TransactionContextInterface, framework *FrameworkAgreement, value float64, now time.Time) error {
+	if value <= 0 {
+		return fmt.Errorf("order value must be positive")
+	}
+	if framework.CommittedValue+value > framework.CeilingValue+0.000001 {
+		return fmt.Errorf("order value %.2f exceeds remaining framework ceiling %.2f", value, framework.CeilingValue-framework.CommittedValue)
+	}
+	framework.CommittedValue += value
+	if framework.CeilingValue-framework.CommittedValue < 0.01 {
+		framework.Status = "EXHAUSTED"
+	}
+	framework.UpdatedAt = now.Format(time.RFC3339)
+	bytes, _ := json.Marshal(framework)
+	return ctx.GetStub().PutState(frameworkKey(framework.ID), bytes)
+}
+
+// CreateDirectCallOff places an order directly with one framework member
+func (s *EnhancedSmartContract) CreateDirectCallOff(ctx contractapi.TransactionContextInterface, frameworkID, orderID, contractorID, description string, value float64) error {
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	framework, err := s.activeFramework(ctx, frameworkID, txTime)
+	if err != nil {
+		return err
+	}
+	if err := s.requireFrameworkBuyer(ctx, framework, "place call-offs under"); err != nil {
+		return err
+	}
+	if !isFrameworkMember(framework, contractorID) {
+		return fmt.Errorf("contractor %s is not a member of framework %s", contractorID, frameworkID)
+	}
+	if err := s.checkNotDebarred(ctx, contractorID, txTime); err != nil {
+		return err
+	}
+	exists, err := s.assetExists(ctx, callOffKey(frameworkID, orderID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("call-off order %s already exists", orderID)
+	}
+
+	framework.OrderCount++
+	if err := s.commitCallOff(ctx, framework, value, txTime); err != nil {
+		return err
+	}
+
+	order := CallOffOrder{
+		FrameworkID:  frameworkID,
+		OrderID:      orderID,
+		Description:  description,
+		Method:       callOffDirect,
+		Value:        value,
+		ContractorID: contractorID,
+		Status:       "AWARDED",
+		CreatedAt:    txTime.Format(time.RFC3339),
+		AwardedAt:    txTime.Format(time.RFC3339),
+	}
+	orderBytes, _ := json.Marshal(order)
+	if err := ctx.GetStub().PutState(callOffKey(frameworkID, orderID), orderBytes); err != nil {
+		return err
+	}
+	return emitCallOff(ctx, events.CallOffAwarded, framework.TenderID, &order)
+}
+
+// StartMiniCompetition opens a call-off order to competition among framework members
+func (s *EnhancedSmartContract) StartMiniCompetition(ctx contractapi.TransactionContextInterface, frameworkID, orderID, description string, maxValue float64, deadline string) error {
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	framework, err := s.activeFramework(ctx, frameworkID, txTime)
+	if err != nil {
+		return err
+	}
+	if err := s.requireFrameworkBuyer(ctx, framework, "place call-offs under"); err != nil {
+		return err
+	}
+	closeAt, err := time.Parse(time.RFC3339, deadline)
+	if err != nil {
+		return fmt.Errorf("invalid deadline: %v", err)
+	}
+	if !closeAt.After(txTime) {
+		return fmt.Errorf("deadline must be in the future")
+	}
+	if maxValue <= 0 {
+		return fmt.Errorf("maximum order value must be positive")
+	}
+	if framework.CommittedValue+maxValue > framework.CeilingValue+0.000001 {
+		return fmt.Errorf("maximum order value %.2f exceeds remaining framework ceiling %.2f", maxValue, framework.CeilingValue-framework.CommittedValue)
+	}
+	exists, err := s.assetExists(ctx, callOffKey(frameworkID, orderID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("call-off order %s already exists", orderID)
+	}
+
+	order := CallOffOrder{
+		FrameworkID: frameworkID,
+		OrderID:     orderID,
+		Description: description,
+		Method:      callOffMiniCompetition,
+		MaxValue:    maxValue,
+		Deadline:    deadline,
+		Status:      "OPEN",
+		CreatedAt:   txTime.Format(time.RFC3339),
+	}
+	orderBytes, _ := json.Marshal(order)
+	if err := ctx.GetStub().PutState(callOffKey(frameworkID, orderID), orderBytes); err != nil {
+		return err
+	}
+	return emitCallOff(ctx, events.MiniCompetitionStarted, framework.TenderID, &order)
+}
+
+func emitCallOff(ctx contractapi.TransactionContextInterface, name, tenderID string, order *CallOffOrder) error {
+	return emitEvent(ctx, name, tenderID, events.CallOffPayload{
+		FrameworkID:  order.FrameworkID,
+		OrderID:      order.OrderID,
+		Description:  order.Description,
+		Method:       order.Method,
+		MaxValue:     order.MaxValue,
+		Value:        order.Value,
+		ContractorID: order.ContractorID,
+		Deadline:     order.Deadline,
+		Status:       order.Status,
+		Responses:    order.Responses,
+		CreatedAt:    order.CreatedAt,
+		AwardedAt:    order.AwardedAt,
+	})
+}
+
+// GetCallOffOrder retrieves a call-off order
+func (s *EnhancedSmartContract) GetCallOffOrder(ctx contractapi.TransactionContextInterface, frameworkID, orderID string) (*CallOffOrder, error) {
+	data, err := ctx.GetStub().GetState(callOffKey(frameworkID, orderID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("call-off order %s not found for framework %s", orderID, frameworkID)
+	}
+	var order CallOffOrder
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// SubmitCallOffResponse records a member's offer from the transient map key "callOffBid"
+func (s *EnhancedSmartContract) SubmitCallOffResponse(ctx contractapi.TransactionContextInterface, frameworkID, orderID string) error {
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	framework, err := s.activeFramework(ctx, frameworkID, txTime)
+	if err != nil {
+		return err
+	}
+	order, err := s.GetCallOffOrder(ctx, frameworkID, orderID)
+	if err != nil {
+		return err
+	}
+	if order.Method != callOffMiniCompetition || order.Status != "OPEN" {
+		return fmt.Errorf("call-off order %s is not open for responses", orderID)
+	}
+	closeAt, _ := time.Parse(time.RFC3339, order.Deadline)
+	if txTime.After(closeAt) {
+		return fmt.Errorf("mini-competition deadline has passed")
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient: %v", err)
+	}
+	respBytes, ok := transient["callOffBid"]
+	if !ok {
+		return fmt.Errorf("transient map must contain 'callOffBid'")
+	}
+	var resp CallOffResponse
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return fmt.Errorf("invalid call-off response JSON: %v", err)
+	}
+	if resp.FrameworkID != frameworkID || resp.OrderID != orderID {
+		return fmt.Errorf("frameworkId/orderId mismatch")
+	}
+	if !isFrameworkMember(framework, resp.ContractorID) {
+		return fmt.Errorf("contractor %s is not a member of framework %s", resp.ContractorID, frameworkID)
+	}
+	if err := s.checkNotDebarred(ctx, resp.ContractorID, txTime); err != nil {
+		return err
+	}
+	if resp.Amount <= 0 || resp.Amount > order.MaxValue {
+		return fmt.Errorf("offer must be positive and not exceed %.2f", order.MaxValue)
+	}
+	exists, err := s.assetExists(ctx, callOffRespRefKey(frameworkID, orderID, resp.ContractorID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("contractor %s already responded to order %s", resp.ContractorID, orderID)
+	}
+
+	resp.SubmittedAt = txTime.Format(time.RFC3339)
+	stored, err := canonicalMarshal(resp)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollectionName, callOffRespPrivKey(frameworkID, orderID, resp.ContractorID), stored); err != nil {
+		return fmt.Errorf("failed to store call-off response: %v", err)
+	}
+	hash := sha256.Sum256(stored)
+	ref := CallOffResponseRef{
+		FrameworkID:  frameworkID,
+		OrderID:      orderID,
+		ContractorID: resp.ContractorID,
+		ResponseHash: hex.EncodeToString(hash[:]),
+	}
+	refBytes, _ := json.Marshal(ref)
+	if err := ctx.GetStub().PutState(callOffRespRefKey(frameworkID, orderID, resp.ContractorID), refBytes); err != nil {
+		return err
+	}
+
+	order.Responses++
+	orderBytes, _ := json.Marshal(order)
+	if err := ctx.GetStub().PutState(callOffKey(frameworkID, orderID), orderBytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.CallOffResponseSubmitted, framework.TenderID, events.CallOffResponsePayload{
+		FrameworkID:  ref.FrameworkID,
+		OrderID:      ref.OrderID,
+		ContractorID: ref.ContractorID,
+		ResponseHash: ref.ResponseHash,
+	})
+}
+
+// AwardCallOff closes a mini-competition. With an empty contractorID the lowest offer wins.
+func (s *EnhancedSmartContract) AwardCallOff(ctx contractapi.TransactionContextInterface, frameworkID, orderID, contractorID string) error {
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	framework, err := s.activeFramework(ctx, frameworkID, txTime)
+	if err != nil {
+		return err
+	}
+	if err := s.requireFrameworkBuyer(ctx, framework, "award call-offs under"); err != nil {
+		return err
+	}
+	order, err := s.GetCallOffOrder(ctx, frameworkID, orderID)
+	if err != nil {
+		return err
+	}
+	if order.Method != callOffMiniCompetition || order.Status != "OPEN" {
+		return fmt.Errorf("call-off order %s is not an open mini-competition", orderID)
+	}
+	closeAt, _ := time.Parse(time.RFC3339, order.Deadline)
+	if !txTime.After(closeAt) {
+		return fmt.Errorf("mini-competition is open until %s", order.Deadline)
+	}
+
+	var winner *CallOffResponse
+	for _, m := range framework.Members {
+		if contractorID != "" && m.ContractorID != contractorID {
+			continue
+		}
+		data, err := ctx.GetStub().GetPrivateData(privateCollectionName, callOffRespPrivKey(frameworkID, orderID, m.ContractorID))
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue
+		}
+		var resp CallOffResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return err
+		}
+		if winner == nil || resp.Amount < winner.Amount {
+			winner = &resp
+		}
+	}
+	if winner == nil {
+		return fmt.Errorf("no eligible responses for call-off order %s", orderID)
+	}
+
+	framework.OrderCount++
+	if err := s.commitCallOff(ctx, framework, winner.Amount, txTime); err != nil {
+		return err
+	}
+
+	order.Status = "AWARDED"
+	order.ContractorID = winner.ContractorID
+	order.Value = winner.Amount
+	order.AwardedAt = txTime.Format(time.RFC3339)
+	orderBytes, _ := json.Marshal(order)
+	if err := ctx.GetStub().PutState(callOffKey(frameworkID, orderID), orderBytes); err != nil {
+		return err
+	}
+	return emitCallOff(ctx, events.CallOffAwarded, framework.TenderID, order)
+}
+
+// ListCallOffOrders returns every order placed under a framework
+func (s *EnhancedSmartContract) ListCallOffOrders(ctx contractapi.TransactionContextInterface, frameworkID string) ([]*CallOffOrder, error) {
+	iter, err := ctx.GetStub().GetStateByRange("CALLOFF_"+frameworkID+"_", "CALLOFF_"+frameworkID+"_~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var out []*CallOffOrder
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var o CallOffOrder
+		if err := json.Unmarshal(kv.Value, &o); err == nil && o.FrameworkID == frameworkID {
+			out = append(out, &o)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,315 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+type frameworkRequest struct {
+	ID           string   `json:"id"`
+	TenderID     string   `json:"tenderId"`
+	Title        string   `json:"title"`
+	BidIDs       []string `json:"bidIds"`
+	CeilingValue float64  `json:"ceilingValue"`
+	Currency     string   `json:"currency,omitempty"`
+	StartDate    string   `json:"startDate,omitempty"`
+	ExpiryDate   string   `json:"expiryDate"`
+}
+
+func frameworkFixture() frameworkRequest {
+	return frameworkRequest{
+		ID:           "F1",
+		TenderID:     "T1",
+		Title:        "Road maintenance framework",
+		BidIDs:       []string{"B1", "B2"},
+		CeilingValue: 1000000,
+		ExpiryDate:   rfc(t0.Add(365 * 24 * time.Hour)),
+	}
+}
+
+// frameworkTender closes T1 with bids from all three contractors; the clock ends at the deadline
+func (n *testNet) frameworkTender() {
+	n.t.Helper()
+	n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+	n.mustSubmitBid("contractorB", bidFixture("T1", "B2", "contractorB", 600000))
+	n.mustSubmitBid("contractorC", bidFixture("T1", "B3", "contractorC", 700000))
+	n.ledger.SetTime(t0.Add(time.Hour))
+	n.closeTender("T1")
+}
+
+func (n *testNet) createFramework(req frameworkRequest) error {
+	n.t.Helper()
+	return n.tx("buyer", nil, func(ctx *TransactionContext) error {
+		return n.enh.CreateFrameworkAgreement(ctx, mustJSON(n.t, req))
+	})
+}
+
+func (n *testNet) framework(id string) *FrameworkAgreement {
+	n.t.Helper()
+	var out *FrameworkAgreement
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		var err error
+		out, err = n.enh.GetFrameworkAgreement(ctx, id)
+		return err
+	})
+	return out
+}
+
+func TestCreateFrameworkAgreement(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(r *frameworkRequest)
+		wantErr string
+	}{
+		{name: "valid"},
+		{name: "explicit start", mutate: func(r *frameworkRequest) { r.StartDate = rfc(t0.Add(48 * time.Hour)) }},
+		{name: "no id", mutate: func(r *frameworkRequest) { r.ID = "" }, wantErr: "framework ID and tender ID are required"},
+		{name: "no winners", mutate: func(r *frameworkRequest) { r.BidIDs = nil }, wantErr: "at least one winning bid is required"},
+		{name: "no ceiling", mutate: func(r *frameworkRequest) { r.CeilingValue = 0 }, wantErr: "ceiling value must be positive"},
+		{name: "bad expiry", mutate: func(r *frameworkRequest) { r.ExpiryDate = "next year" }, wantErr: "invalid expiry date"},
+		{name: "expiry now", mutate: func(r *frameworkRequest) { r.ExpiryDate = rfc(t0.Add(time.Hour)) }, wantErr: "expiry date must be in the future"},
+		{name: "bad start", mutate: func(r *frameworkRequest) { r.StartDate = "soon" }, wantErr: "invalid start date"},
+		{name: "start at expiry", mutate: func(r *frameworkRequest) { r.StartDate = r.ExpiryDate }, wantErr: "expiry date must be after start date"},
+		{name: "unknown bid", mutate: func(r *frameworkRequest) { r.BidIDs = []string{"B1", "B9"} }, wantErr: "bid B9 not found for tender T1"},
+		{name: "contractor twice", mutate: func(r *frameworkRequest) { r.BidIDs = []string{"B1", "B1"} }, wantErr: "contractor contractorA appears more than once"},
+		{name: "unknown tender", mutate: func(r *frameworkRequest) { r.TenderID = "T9" }, wantErr: "tender T9 not found"},
+		{name: "open tender", mutate: func(r *frameworkRequest) { r.TenderID = "T2" }, wantErr: "tender must be closed or awarded before creating a framework"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.frameworkTender()
+			n.openTender(tenderFixture("T2", t0.Add(2*time.Hour)))
+			req := frameworkFixture()
+			if tc.mutate != nil {
+				tc.mutate(&req)
+			}
+			expectErr(t, n.createFramework(req), tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.FrameworkCreated)
+			f := n.framework("F1")
+			wantStart := rfc(t0.Add(time.Hour))
+			if req.StartDate != "" {
+				wantStart = req.StartDate
+			}
+			if f.Status != "ACTIVE" || f.Currency != "USD" || len(f.Members) != 2 || f.Members[1].ContractorID != "contractorB" || f.StartDate != wantStart {
+				t.Fatalf("framework = %+v", f)
+			}
+			if got := n.tender("T1"); got.Status != "AWARDED" || got.FrameworkID != "F1" {
+				t.Fatalf("tender = %s/%s", got.Status, got.FrameworkID)
+			}
+
+			req.ID = "F2"
+			expectErr(t, n.createFramework(req), "tender T1 already has framework F1")
+			req.ID, req.TenderID = "F1", "T2"
+			expectErr(t, n.createFramework(req), "framework F1 already exists")
+		})
+	}
+
+	t.Run("awarded bid must be a member", func(t *testing.T) {
+		n := newTestNet(t)
+		n.frameworkTender()
+		n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardTender(ctx, "T1", "B3") })
+		expectErr(t, n.createFramework(frameworkFixture()), "awarded bid B3 must be one of the framework winners")
+		req := frameworkFixture()
+		req.BidIDs = []string{"B3", "B1"}
+		expectErr(t, n.createFramework(req), "")
+	})
+}
+
+func TestCallOffOrders(t *testing.T) {
+	n := newTestNet(t)
+	n.frameworkTender()
+	if err := n.createFramework(frameworkFixture()); err != nil {
+		t.Fatal(err)
+	}
+
+	direct := func(orderID, contractorID string, value float64) error {
+		return n.tx("buyer", nil, func(ctx *TransactionContext) error {
+			return n.enh.CreateDirectCallOff(ctx, "F1", orderID, contractorID, "Pothole repairs", value)
+		})
+	}
+	mini := func(orderID string, maxValue float64, deadline time.Time) error {
+		return n.tx("buyer", nil, func(ctx *TransactionContext) error {
+			return n.enh.StartMiniCompetition(ctx, "F1", orderID, "Bridge deck", maxValue, rfc(deadline))
+		})
+	}
+	respond := func(who, orderID string, amount float64) error {
+		resp := CallOffResponse{FrameworkID: "F1", OrderID: orderID, ContractorID: who, Amount: amount}
+		return n.tx(who, transientOf(t, "callOffBid", resp), func(ctx *TransactionContext) error {
+			return n.enh.SubmitCallOffResponse(ctx, "F1", orderID)
+		})
+	}
+	award := func(orderID, contractorID string) error {
+		return n.tx("buyer", nil, func(ctx *TransactionContext) error {
+			return n.enh.AwardCallOff(ctx, "F1", orderID, contractorID)
+		})
+	}
+	now := t0.Add(time.Hour)
+
+	// Only the buyer of the source tender places and awards call-offs
+	for _, who := range []string{"contractorA", "auditor"} {
+		err := n.tx(who, nil, func(ctx *TransactionContext) error {
+			return n.enh.CreateDirectCallOff(ctx, "F1", "O1", "contractorA", "Pothole repairs", 1000)
+		})
+		expectErr(t, err, "only the tender owner may place call-offs under tender T1")
+		err = n.tx(who, nil, func(ctx *TransactionContext) error {
+			return n.enh.StartMiniCompetition(ctx, "F1", "O2", "Bridge deck", 1000, rfc(now.Add(time.Hour)))
+		})
+		expectErr(t, err, "only the tender owner may place call-offs under tender T1")
+	}
+
+	directs := []struct {
+		name       string
+		orderID    string
+		contractor string
+		value      float64
+		wantErr    string
+	}{
+		{"not a member", "O1", "contractorC", 1000, "contractor contractorC is not a member of framework F1"},
+		{"zero value", "O1", "contractorA", 0, "order value must be positive"},
+		{"above ceiling", "O1", "contractorA", 1000000.01, "order value 1000000.01 exceeds remaining framework ceiling 1000000.00"},
+		{"valid", "O1", "contractorA", 400000, ""},
+		{"duplicate", "O1", "contractorB", 1000, "call-off order O1 already exists"},
+	}
+	for _, d := range directs {
+		expectErr(t, direct(d.orderID, d.contractor, d.value), d.wantErr)
+	}
+	n.expectEvents(events.CallOffAwarded)
+
+	minis := []struct {
+		name     string
+		maxValue float64
+		deadline time.Time
+		wantErr  string
+	}{
+		{"above remaining ceiling", 600001, now.Add(time.Hour), "maximum order value 600001.00 exceeds remaining framework ceiling 600000.00"},
+		{"no maximum", 0, now.Add(time.Hour), "maximum order value must be positive"},
+		{"deadline now", 500000, now, "deadline must be in the future"},
+		{"valid", 500000, now.Add(time.Hour), ""},
+	}
+	for _, m := range minis {
+		expectErr(t, mini("O2", m.maxValue, m.deadline), m.wantErr)
+	}
+	n.expectEvents(events.MiniCompetitionStarted)
+
+	responses := []struct {
+		name    string
+		who     string
+		orderID string
+		amount  float64
+		wantErr string
+	}{
+		{"direct order", "contractorA", "O1", 1, "call-off order O1 is not open for responses"},
+		{"not a member", "contractorC", "O2", 1, "contractor contractorC is not a member of framework F1"},
+		{"above maximum", "contractorA", "O2", 500001, "offer must be positive and not exceed 500000.00"},
+		{"member A", "contractorA", "O2", 450000, ""},
+		{"member B", "contractorB", "O2", 400000, ""},
+		{"second response", "contractorA", "O2", 300000, "contractor contractorA already responded to order O2"},
+	}
+	for _, r := range responses {
+		expectErr(t, respond(r.who, r.orderID, r.amount), r.wantErr)
+	}
+	n.expectEvents(events.CallOffResponseSubmitted)
+	err := n.tx("contractorA", nil, func(ctx *TransactionContext) error { return n.enh.SubmitCallOffResponse(ctx, "F1", "O2") })
+	expectErr(t, err, "transient map must contain 'callOffBid'")
+	resp := CallOffResponse{FrameworkID: "F1", OrderID: "O1", ContractorID: "contractorA", Amount: 1}
+	err = n.tx("contractorA", transientOf(t, "callOffBid", resp), func(ctx *TransactionContext) error { return n.enh.SubmitCallOffResponse(ctx, "F1", "O2") })
+	expectErr(t, err, "frameworkId/orderId mismatch")
+
+	// The deadline itself still accepts responses; awarding needs it to have passed
+	expectErr(t, award("O2", ""), "mini-competition is open until "+rfc(now.Add(time.Hour)))
+	n.ledger.SetTime(now.Add(time.Hour + time.Second))
+	expectErr(t, respond("contractorB", "O2", 1), "mini-competition deadline has passed")
+	expectErr(t, award("O1", ""), "call-off order O1 is not an open mini-competition")
+	err = n.tx("contractorB", nil, func(ctx *TransactionContext) error { return n.enh.AwardCallOff(ctx, "F1", "O2", "contractorB") })
+	expectErr(t, err, "only the tender owner may award call-offs under tender T1")
+	expectErr(t, award("O2", ""), "")
+	n.expectEvents(events.CallOffAwarded)
+	expectErr(t, award("O2", ""), "call-off order O2 is not an open mini-competition")
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		order, err := n.enh.GetCallOffOrder(ctx, "F1", "O2")
+		if err == nil && (order.Status != "AWARDED" || order.ContractorID != "contractorB" || order.Value != 400000 || order.Responses != 2) {
+			t.Fatalf("order = %+v", order)
+		}
+		_, missing := n.enh.GetCallOffOrder(ctx, "F1", "O9")
+		expectErr(t, missing, "call-off order O9 not found for framework F1")
+		return err
+	})
+	if f := n.framework("F1"); f.CommittedValue != 800000 || f.OrderCount != 2 {
+		t.Fatalf("framework = %+v", f)
+	}
+
+	// A named winner is picked even when a lower offer exists, and spending the
+	// rest of the ceiling exhausts the framework
+	if err := mini("O3", 200000, now.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []struct {
+		who    string
+		amount float64
+	}{{"contractorA", 200000}, {"contractorB", 150000}} {
+		if err := respond(r.who, "O3", r.amount); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n.ledger.SetTime(now.Add(3 * time.Hour))
+	expectErr(t, award("O3", "contractorC"), "no eligible responses for call-off order O3")
+	expectErr(t, award("O3", "contractorA"), "")
+	if f := n.framework("F1"); f.Status != "EXHAUSTED" || f.CommittedValue != 1000000 {
+		t.Fatalf("framework = %+v", f)
+	}
+	expectErr(t, direct("O4", "contractorA", 1), "framework F1 is EXHAUSTED")
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		orders, err := n.enh.ListCallOffOrders(ctx, "F1")
+		if err == nil && (len(orders) != 3 || orders[0].Method != callOffDirect || orders[2].ContractorID != "contractorA") {
+			t.Fatalf("orders = %+v", orders)
+		}
+		return err
+	})
+}
+
+func TestFrameworkWindowAndDebarment(t *testing.T) {
+	start := t0.Add(48 * time.Hour)
+	expiry := t0.Add(96 * time.Hour)
+	tests := []struct {
+		name    string
+		at      time.Time
+		debar   bool
+		wantErr string
+	}{
+		{name: "before start", at: start.Add(-time.Second), wantErr: "framework F1 has not started"},
+		{name: "at start", at: start},
+		{name: "before expiry", at: expiry.Add(-time.Second)},
+		{name: "at expiry", at: expiry, wantErr: "framework F1 expired on " + rfc(expiry)},
+		{name: "debarred member", at: start, debar: true, wantErr: "contractor contractorA is debarred indefinitely: fraud"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.frameworkTender()
+			req := frameworkFixture()
+			req.StartDate, req.ExpiryDate = rfc(start), rfc(expiry)
+			if err := n.createFramework(req); err != nil {
+				t.Fatal(err)
+			}
+			if tc.debar {
+				n.mustTx("regulator", nil, func(ctx *TransactionContext) error {
+					return n.enh.DebarContractor(ctx, "contractorA", "fraud", rfc(t0), "")
+				})
+			}
+			n.ledger.SetTime(tc.at)
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateDirectCallOff(ctx, "F1", "O1", "contractorA", "Signage", 1000)
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package contract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+
+	"tendercc/events"
+	"tendercc/mockstub"
+)
+
+// t0 is the ledger clock at the start of every test
+var t0 = time.Date(2030, time.March, 4, 9, 0, 0, 0, time.UTC)
+
+func rfc(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// testNet is one in-memory channel with the identities the tests act as
+type testNet struct {
+	t      *testing.T
+	ledger *mockstub.Ledger
+	ids    map[string]*mockstub.Identity
+	basic  *SmartContract
+	enh    *EnhancedSmartContract
+}
+
+var testIdentities = []struct {
+	name  string
+	msp   string
+	attrs map[string]string
+}{
+	{"buyer", "BuyerMSP", nil},
+	{"buyer2", "BuyerMSP", nil},
+	{"auditor", "AuditorMSP", nil},
+	{"contractorA", "ContractorAMSP", nil},
+	{"contractorB", "ContractorBMSP", nil},
+	{"contractorC", "ContractorCMSP", nil},
+	{"regulator", "RegulatorMSP", map[string]string{"role": roleRegulator}},
+	{"approver1", "BuyerMSP", map[string]string{"role": "approver"}},
+	{"approver2", "BuyerMSP", map[string]string{"role": "approver"}},
+	{"registrar", "RegistryMSP", map[string]string{"role": roleRegistrar}},
+}
+
+func newTestNet(t *testing.T) *testNet {
+	t.Helper()
+	n := &testNet{
+		t:      t,
+		ledger: mockstub.NewLedger(t0),
+		ids:    make(map[string]*mockstub.Identity),
+		basic:  new(SmartContract),
+		enh:    new(EnhancedSmartContract),
+	}
+	for _, id := range testIdentities {
+		ident, err := mockstub.NewIdentity(id.msp, id.name, id.attrs, t0)
+		if err != nil {
+			t.Fatalf("identity %s: %v", id.name, err)
+		}
+		n.ids[id.name] = ident
+	}
+	return n
+}
+
+func (n *testNet) identity(who string) *mockstub.Identity {
+	id, ok := n.ids[who]
+	if !ok {
+		n.t.Fatalf("unknown identity %s", who)
+	}
+	return id
+}
+
+func (n *testNet) context(stub *mockstub.Stub) *TransactionContext {
+	ctx := new(TransactionContext)
+	ctx.SetStub(stub)
+	ci, err := cid.New(stub)
+	if err != nil {
+		n.t.Fatalf("client identity: %v", err)
+	}
+	ctx.SetClientIdentity(ci)
+	return ctx
+}
+
+// tx runs fn as one transaction submitted by who, committing it when fn succeeds
+func (n *testNet) tx(who string, transient map[string][]byte, fn func(ctx *TransactionContext) error) error {
+	n.t.Helper()
+	stub := n.ledger.NewStub(n.identity(who), transient)
+	if err := fn(n.context(stub)); err != nil {
+		return err
+	}
+	return stub.Commit()
+}
+
+// mustTx is tx for steps that are setup rather than the behaviour under test
+func (n *testNet) mustTx(who string, transient map[string][]byte, fn func(ctx *TransactionContext) error) {
+	n.t.Helper()
+	if err := n.tx(who, transient, fn); err != nil {
+		n.t.Fatalf("transaction by %s failed: %v", who, err)
+	}
+}
+
+// query evaluates fn without committing anything
+func (n *testNet) query(who string, fn func(ctx *TransactionContext) error) error {
+	n.t.Helper()
+	return fn(n.context(n.ledger.NewStub(n.identity(who), nil)))
+}
+
+func (n *testNet) mustQuery(who string, fn func(ctx *TransactionContext) error) {
+	n.t.Helper()
+	if err := n.query(who, fn); err != nil {
+		n.t.Fatalf("query by %s failed: %v", who, err)
+	}
+}
+
+// clientID is the cid ID the contract sees for who
+func (n *testNet) clientID(who string) string {
+	n.t.Helper()
+	var id string
+	n.mustQuery(who, func(ctx *TransactionContext) error {
+		var err error
+		id, err = ctx.GetClientIdentity().GetID()
+		return err
+	})
+	return id
+}
+
+// lastEvents returns the envelopes of the most recently committed chaincode event
+func (n *testNet) lastEvents() []events.Envelope {
+	n.t.Helper()
+	all := n.ledger.Events()
+	if len(all) == 0 {
+		n.t.Fatalf("no chaincode events committed")
+	}
+	last := all[len(all)-1]
+	envs, err := events.Parse(last.Name, last.Payload)
+	if err != nil {
+		n.t.Fatalf("parse %s: %v", last.Name, err)
+	}
+	return envs
+}
+
+// expectEvents checks the names carried by the most recent chaincode event
+func (n *testNet) expectEvents(names ...string) []events.Envelope {
+	n.t.Helper()
+	envs := n.lastEvents()
+	var got []string
+	for _, env := range envs {
+		got = append(got, env.Name)
+	}
+	if strings.Join(got, ",") != strings.Join(names, ",") {
+		n.t.Fatalf("events = %v, want %v", got, names)
+	}
+	return envs
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(b)
+}
+
+func transientOf(t *testing.T, key string, v interface{}) map[string][]byte {
+	t.Helper()
+	return map[string][]byte{key: []byte(mustJSON(t, v))}
+}
+
+// expectErr fails unless err is nil when want is empty, or contains want otherwise
+func expectErr(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case want != "" && err == nil:
+		t.Fatalf("expected error containing %q, got nil", want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Fatalf("error = %q, want it to contain %q", err.Error(), want)
+	}
+}
+
+// tenderFixture returns a valid open-procedure RFQ whose bid window closes at deadline
+func tenderFixture(id string, deadline time.Time) *EnhancedTender {
+	return &EnhancedTender{
+		ID: id,
+		ProjectScope: ProjectScope{
+			Description:  "Resurface district road " + id,
+			Deliverables: []string{"Resurfaced carriageway"},
+			Budget: Budget{
+				Currency:     "USD",
+				EstimatedMin: 400000,
+				EstimatedMax: 900000,
+				PaymentTerms: "MILESTONE_BASED",
+				PaymentSchedule: []PaymentMilestone{
+					{Name: "Base course", Percentage: 40},
+					{Name: "Wearing course", Percentage: 60},
+				},
+			},
+		},
+		Deadlines: TenderDeadlines{
+			QuestionsDeadline:     rfc(deadline.Add(-72 * time.Hour)),
+			BidSubmissionDeadline: rfc(deadline),
+			ProjectStartDate:      rfc(deadline.Add(30 * 24 * time.Hour)),
+			ProjectEndDate:        rfc(deadline.Add(180 * 24 * time.Hour)),
+			MilestoneDeadlines: []MilestoneDeadline{
+				{Name: "Base course", Deadline: rfc(deadline.Add(90 * 24 * time.Hour)), Critical: true},
+			},
+		},
+		EvaluationCriteria: []EvalCriterion{
+			{ID: "C1", Name: "Price", Weight: 60, Type: "QUANTITATIVE", ScoringMethod: "LOWEST_PRICE"},
+			{ID: "C2", Name: "Safety", Weight: 40, Type: "QUALITATIVE", ScoringMethod: "HIGHEST_SCORE"},
+		},
+		BidRequirements: BidRequirements{
+			SubmissionFormat: SubmissionFormat{Method: "ONLINE", FileFormats: []string{"PDF"}, MaxFileSize: 10},
+		},
+		OwnerDetails: OwnerInfo{
+			OrganizationName: "District Roads Authority",
+			Address:          Address{City: "Springfield", Country: "US"},
+			AuthorizedBy:     AuthorizedPerson{Name: "Dana Reyes", SignerID: "buyer"},
+		},
+	}
+}
+
+// bidFixture returns a valid enhanced bid for a single-envelope tender
+func bidFixture(tenderID, bidID, contractorID string, amount float64) *EnhancedBidPrivate {
+	return &EnhancedBidPrivate{
+		TenderID:     tenderID,
+		BidID:        bidID,
+		ContractorID: contractorID,
+		TotalAmount:  amount,
+		Currency:     "USD",
+		TechnicalProposal: TechnicalProposal{
+			Methodology: "Cold in-place recycling",
+		},
+		FinancialProposal: FinancialProposal{
+			BreakdownByPhase: []PhaseCosting{{Phase: "Works", Cost: amount}},
+		},
+		ComplianceChecklist: map[string]bool{"Safety": true},
+	}
+}
+
+// createTender stores tender as a draft
+func (n *testNet) createTender(tender *EnhancedTender) {
+	n.t.Helper()
+	js := mustJSON(n.t, tender)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+		return n.enh.CreateEnhancedTender(ctx, js)
+	})
+}
+
+// openTender creates and publishes tender
+func (n *testNet) openTender(tender *EnhancedTender) {
+	n.t.Helper()
+	n.createTender(tender)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+		return n.enh.PublishTender(ctx, tender.ID)
+	})
+}
+
+func (n *testNet) submitBid(who string, bid *EnhancedBidPrivate) error {
+	n.t.Helper()
+	return n.tx(who, transientOf(n.t, "bid", bid), func(ctx *TransactionContext) error {
+		return n.enh.SubmitEnhancedBid(ctx, bid.TenderID, bid.BidID)
+	})
+}
+
+func (n *testNet) mustSubmitBid(who string, bid *EnhancedBidPrivate) {
+	n.t.Helper()
+	if err := n.submitBid(who, bid); err != nil {
+		n.t.Fatalf("submit bid %s: %v", bid.BidID, err)
+	}
+}
+
+func (n *testNet) closeTender(tenderID string) {
+	n.t.Helper()
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+		return n.enh.CloseTenderEnhanced(ctx, tenderID)
+	})
+}
+
+func (n *testNet) tender(tenderID string) *EnhancedTender {
+	n.t.Helper()
+	var out *EnhancedTender
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		var err error
+		out, err = n.enh.GetEnhancedTender(ctx, tenderID)
+		return err
+	})
+	return out
+}
+
+// awardedTender runs T1 through publication, two bids, close and award of B1 to contractorA
+func (n *testNet) awardedTender(tenderID string) *EnhancedTender {
+	n.t.Helper()
+	tender := tenderFixture(tenderID, t0.Add(7*24*time.Hour))
+	n.openTender(tender)
+	n.mustSubmitBid("contractorA", bidFixture(tenderID, "B1", "contractorA", 500000))
+	n.mustSubmitBid("contractorB", bidFixture(tenderID, "B2", "contractorB", 700000))
+	n.ledger.Advance(8 * 24 * time.Hour)
+	n.closeTender(tenderID)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+		return n.enh.AwardTender(ctx, tenderID, "B1")
+	})
+	return n.tender(tenderID)
+}
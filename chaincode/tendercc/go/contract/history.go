@@ -0,0 +1,215 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// History record types
+const (
+	historyTender        = "TENDER"
+	historyBidRef        = "BID_REF"
+	historyEvaluation    = "EVALUATION"
+	historyLotEvaluation = "LOT_EVALUATION"
+	historyMilestone     = "MILESTONE"
+)
+
+// keyHistory reads the full history of one key as typed entries
+func keyHistory(ctx contractapi.TransactionContextInterface, key, recordType, refID string) ([]*HistoryEntry, error) {
+	iter, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []*HistoryEntry
+	for iter.HasNext() {
+		mod, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		entry := &HistoryEntry{
+			Key:        key,
+			RecordType: recordType,
+			RefID:      refID,
+			TxID:       mod.TxId,
+			IsDelete:   mod.IsDelete,
+		}
+		if ts := mod.GetTimestamp(); ts != nil {
+			entry.Timestamp = time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(time.RFC3339Nano)
+		}
+		if !mod.IsDelete {
+			if err := decodeHistoryValue(entry, mod.Value); err != nil {
+				entry.DecodeError = err.Error()
+			}
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func decodeHistoryValue(entry *HistoryEntry, value []byte) error {
+	switch entry.RecordType {
+	case historyTender:
+		// Legacy tenders share the TENDER_ key space and carry an opening window
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(value, &probe); err != nil {
+			return err
+		}
+		if _, legacy := probe["openAt"]; legacy {
+			entry.LegacyTender = &Tender{}
+			return json.Unmarshal(value, entry.LegacyTender)
+		}
+		entry.Tender = &EnhancedTender{}
+		return json.Unmarshal(value, entry.Tender)
+	case historyBidRef:
+		entry.BidRef = &BidRef{}
+		return json.Unmarshal(value, entry.BidRef)
+	case historyEvaluation:
+		entry.Evaluation = &Evaluation{}
+		return json.Unmarshal(value, entry.Evaluation)
+	case historyLotEvaluation:
+		entry.LotEvaluation = &LotEvaluation{}
+		return json.Unmarshal(value, entry.LotEvaluation)
+	case historyMilestone:
+		entry.Milestone = &MilestoneRef{}
+		return json.Unmarshal(value, entry.Milestone)
+	}
+	return fmt.Errorf("unknown record type %s", entry.RecordType)
+}
+
+// sortHistory orders entries by commit time; writes in the same transaction are ordered by key
+func sortHistory(entries []*HistoryEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Timestamp != entries[j].Timestamp {
+			ti, _ := time.Parse(time.RFC3339Nano, entries[i].Timestamp)
+			tj, _ := time.Parse(time.RFC3339Nano, entries[j].Timestamp)
+			return ti.Before(tj)
+		}
+		return entries[i].Key < entries[j].Key
+	})
+}
+
+// paginateHistory cuts a page out of a chronologically sorted history
+func paginateHistory(entries []*HistoryEntry, size int32, bookmark string) (*HistoryPage, error) {
+	offset := 0
+	if bookmark != "" {
+		n, err := strconv.Atoi(bookmark)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid bookmark %s", bookmark)
+		}
+		offset = n
+	}
+	page := &HistoryPage{Entries: []*HistoryEntry{}, Total: len(entries)}
+	if offset >= len(entries) {
+		return page, nil
+	}
+	end := offset + int(pageSize(size))
+	if end > len(entries) {
+		end = len(entries)
+	}
+	page.Entries = entries[offset:end]
+	if end < len(entries) {
+		page.Bookmark = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func (s *EnhancedSmartContract) singleKeyHistory(ctx contractapi.TransactionContextInterface, key, recordType, refID string, size int32, bookmark string) (*HistoryPage, error) {
+	entries, err := keyHistory(ctx, key, recordType, refID)
+	if err != nil {
+		return nil, err
+	}
+	sortHistory(entries)
+	return paginateHistory(entries, size, bookmark)
+}
+
+// GetTenderHistoryEntries returns the typed history of a tender record
+func (s *EnhancedSmartContract) GetTenderHistoryEntries(ctx contractapi.TransactionContextInterface, tenderID string, pageSize int32, bookmark string) (*HistoryPage, error) {
+	return s.singleKeyHistory(ctx, tenderKey(tenderID), historyTender, tenderID, pageSize, bookmark)
+}
+
+// GetBidRefHistory returns the typed history of a public bid reference
+func (s *EnhancedSmartContract) GetBidRefHistory(ctx contractapi.TransactionContextInterface, tenderID, bidID string, pageSize int32, bookmark string) (*HistoryPage, error) {
+	return s.singleKeyHistory(ctx, bidRefKey(tenderID, bidID), historyBidRef, bidID, pageSize, bookmark)
+}
+
+// GetEvaluationHistory returns the typed history of a bid's evaluation
+func (s *EnhancedSmartContract) GetEvaluationHistory(ctx contractapi.TransactionContextInterface, tenderID, bidID string, pageSize int32, bookmark string) (*HistoryPage, error) {
+	return s.singleKeyHistory(ctx, evalKey(tenderID, bidID), historyEvaluation, bidID, pageSize, bookmark)
+}
+
+// GetMilestoneHistory returns the typed history of a public milestone reference
+func (s *EnhancedSmartContract) GetMilestoneHistory(ctx contractapi.TransactionContextInterface, tenderID, milestoneID string, pageSize int32, bookmark string) (*HistoryPage, error) {
+	return s.singleKeyHistory(ctx, milestoneRefKey(tenderID, milestoneID), historyMilestone, milestoneID, pageSize, bookmark)
+}
+
+// GetFullAuditTrail merges the history of a tender, its bid refs, evaluations, lot
+// evaluations and milestones into one chronological trail, returned a page at a time
+func (s *EnhancedSmartContract) GetFullAuditTrail(ctx contractapi.TransactionContextInterface, tenderID string, pageSize int32, bookmark string) (*HistoryPage, error) {
+	exists, err := s.assetExists(ctx, tenderKey(tenderID))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("tender %s not found", tenderID)
+	}
+
+	type source struct{ key, recordType, refID string }
+	sources := []source{{tenderKey(tenderID), historyTender, tenderID}}
+
+	bids, err := s.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range bids {
+		// Range scans over the tender ID prefix also match tenders like "<id>_X"
+		if ref.TenderID != tenderID {
+			continue
+		}
+		sources = append(sources, source{bidRefKey(tenderID, ref.BidID), historyBidRef, ref.BidID})
+	}
+	evals, err := s.ListEvaluations(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range evals {
+		if ev.TenderID != tenderID {
+			continue
+		}
+		sources = append(sources, source{evalKey(tenderID, ev.BidID), historyEvaluation, ev.BidID})
+	}
+	lotEvals, err := s.ListLotEvaluations(ctx, tenderID, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range lotEvals {
+		if ev.TenderID != tenderID {
+			continue
+		}
+		sources = append(sources, source{lotEvalKey(tenderID, ev.LotID, ev.BidID), historyLotEvaluation, ev.LotID + "/" + ev.BidID})
+	}
+	milestones, err := listMilestoneRefs(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range milestones {
+		sources = append(sources, source{milestoneRefKey(tenderID, ref.MilestoneID), historyMilestone, ref.MilestoneID})
+	}
+
+	var trail []*HistoryEntry
+	for _, src := range sources {
+		entries, err := keyHistory(ctx, src.key, src.recordType, src.refID)
+		if err != nil {
+			return nil, err
+		}
+		trail = append(trail, entries...)
+	}
+	sortHistory(trail)
+	return paginateHistory(trail, pageSize, bookmark)
+}
@@ -0,0 +1,193 @@
+package contract
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// historyTender is T1 with one bid evaluated twice and one milestone approved,
+// with the clock moved on between transactions so commit order is unambiguous
+func (n *testNet) historyTender() {
+	n.t.Helper()
+	n.createTender(tenderFixture("T1", t0.Add(time.Hour)))
+	n.ledger.Advance(time.Minute)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.PublishTender(ctx, "T1") })
+	n.ledger.Advance(time.Minute)
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+	n.mustSubmitBid("contractorB", bidFixture("T1", "B2", "contractorB", 700000))
+	n.ledger.SetTime(t0.Add(time.Hour))
+	n.closeTender("T1")
+	for i := 0; i < 2; i++ {
+		n.ledger.Advance(time.Minute)
+		n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "T1") })
+	}
+	n.ledger.Advance(time.Minute)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardBestBid(ctx, "T1") })
+	n.ledger.Advance(time.Minute)
+	ms := MilestonePrivate{TenderID: "T1", MilestoneID: "M1", Title: "Base course"}
+	n.mustTx("contractorA", transientOf(n.t, "milestone", ms), func(ctx *TransactionContext) error {
+		return n.basic.SubmitMilestone(ctx, "T1", "M1")
+	})
+	n.ledger.Advance(time.Minute)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.ApproveMilestone(ctx, "T1", "M1") })
+}
+
+func TestSingleKeyHistory(t *testing.T) {
+	n := newTestNet(t)
+	n.historyTender()
+	n.createLegacy("L1")
+
+	tests := []struct {
+		name  string
+		fetch func(ctx *TransactionContext) (*HistoryPage, error)
+		check func(entries []*HistoryEntry) bool
+	}{
+		{"tender", func(ctx *TransactionContext) (*HistoryPage, error) {
+			return n.enh.GetTenderHistoryEntries(ctx, "T1", 0, "")
+		}, func(e []*HistoryEntry) bool {
+			return len(e) == 4 && e[0].Tender.Status == "DRAFT" && e[1].Tender.Status == "OPEN" && e[3].Tender.Status == "AWARDED"
+		}},
+		{"legacy tender", func(ctx *TransactionContext) (*HistoryPage, error) {
+			return n.enh.GetTenderHistoryEntries(ctx, "L1", 0, "")
+		}, func(e []*HistoryEntry) bool {
+			return len(e) == 1 && e[0].Tender == nil && e[0].LegacyTender != nil && e[0].LegacyTender.ID == "L1"
+		}},
+		{"bid ref", func(ctx *TransactionContext) (*HistoryPage, error) {
+			return n.enh.GetBidRefHistory(ctx, "T1", "B2", 0, "")
+		}, func(e []*HistoryEntry) bool {
+			return len(e) == 1 && e[0].RecordType == historyBidRef && e[0].BidRef.ContractorID == "contractorB"
+		}},
+		{"evaluation", func(ctx *TransactionContext) (*HistoryPage, error) {
+			return n.enh.GetEvaluationHistory(ctx, "T1", "B1", 0, "")
+		}, func(e []*HistoryEntry) bool {
+			return len(e) == 2 && e[0].Evaluation.BidID == "B1" && e[0].Timestamp < e[1].Timestamp
+		}},
+		{"milestone", func(ctx *TransactionContext) (*HistoryPage, error) {
+			return n.enh.GetMilestoneHistory(ctx, "T1", "M1", 0, "")
+		}, func(e []*HistoryEntry) bool {
+			return len(e) == 2 && e[0].Milestone.Status == "SUBMITTED" && e[1].Milestone.Status == "APPROVED"
+		}},
+		{"unknown key", func(ctx *TransactionContext) (*HistoryPage, error) {
+			return n.enh.GetBidRefHistory(ctx, "T1", "B9", 0, "")
+		}, func(e []*HistoryEntry) bool { return len(e) == 0 }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				page, err := tc.fetch(ctx)
+				if err != nil {
+					return err
+				}
+				if !tc.check(page.Entries) || page.Total != len(page.Entries) || page.Bookmark != "" {
+					t.Fatalf("page = %+v", page)
+				}
+				for _, e := range page.Entries {
+					if e.TxID == "" || e.DecodeError != "" || e.IsDelete {
+						t.Fatalf("entry = %+v", e)
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestFullAuditTrail(t *testing.T) {
+	n := newTestNet(t)
+	n.historyTender()
+	// A tender whose ID extends T1 must not leak into its trail
+	n.openTender(tenderFixture("T1_X", n.ledger.Now().Add(time.Hour)))
+	n.mustSubmitBid("contractorC", bidFixture("T1_X", "B1", "contractorC", 1))
+
+	// 4 tender writes, 2 bid refs, 2 evaluations of each bid, 2 milestone writes
+	const total = 4 + 2 + 4 + 2
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		page, err := n.enh.GetFullAuditTrail(ctx, "T1", 0, "")
+		if err != nil {
+			return err
+		}
+		if page.Total != total || len(page.Entries) != total {
+			t.Fatalf("trail has %d of %d entries", len(page.Entries), page.Total)
+		}
+		for i, e := range page.Entries {
+			if e.Key == tenderKey("T1_X") || e.Key == bidRefKey("T1_X", "B1") {
+				t.Fatalf("trail leaks %s", e.Key)
+			}
+			if i > 0 && e.Timestamp < page.Entries[i-1].Timestamp {
+				t.Fatalf("entry %d out of order: %s after %s", i, e.Timestamp, page.Entries[i-1].Timestamp)
+			}
+		}
+		if first, last := page.Entries[0], page.Entries[total-1]; first.RecordType != historyTender || last.RecordType != historyMilestone {
+			t.Fatalf("trail runs from %s to %s", first.RecordType, last.RecordType)
+		}
+		return nil
+	})
+
+	pages := []struct {
+		size     int32
+		bookmark string
+		entries  int
+		next     string
+		wantErr  string
+	}{
+		{5, "", 5, "5", ""},
+		{5, "5", 5, "10", ""},
+		{5, "10", 2, "", ""},
+		{5, "12", 0, "", ""},
+		{5, "99", 0, "", ""},
+		{5, "x", 0, "", "invalid bookmark x"},
+		{5, "-1", 0, "", "invalid bookmark -1"},
+	}
+	for _, p := range pages {
+		err := n.query("auditor", func(ctx *TransactionContext) error {
+			page, err := n.enh.GetFullAuditTrail(ctx, "T1", p.size, p.bookmark)
+			if err == nil && (len(page.Entries) != p.entries || page.Bookmark != p.next || page.Total != total) {
+				t.Fatalf("page at %q = %d entries, bookmark %q", p.bookmark, len(page.Entries), page.Bookmark)
+			}
+			return err
+		})
+		expectErr(t, err, p.wantErr)
+	}
+
+	err := n.query("auditor", func(ctx *TransactionContext) error {
+		_, err := n.enh.GetFullAuditTrail(ctx, "T9", 0, "")
+		return err
+	})
+	expectErr(t, err, "tender T9 not found")
+}
+
+func TestFullAuditTrailLots(t *testing.T) {
+	n := newTestNet(t)
+	// A lot tender whose ID extends LT must not leak into its trail
+	n.openTender(lotsFixture("LT_X", ""))
+	n.mustSubmitBid("contractorA", lotBidFixture("LT_X", "B1", "contractorA", map[string]float64{"N": 200000}))
+	n.lotsTender("")
+	n.closeTender("LT_X")
+	for _, id := range []string{"LT", "LT_X"} {
+		n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, id) })
+	}
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		page, err := n.enh.GetFullAuditTrail(ctx, "LT", 0, "")
+		if err != nil {
+			return err
+		}
+		var refs []string
+		for _, e := range page.Entries {
+			if e.RecordType != historyLotEvaluation {
+				continue
+			}
+			if e.LotEvaluation == nil || e.Key != lotEvalKey("LT", e.LotEvaluation.LotID, e.LotEvaluation.BidID) {
+				t.Fatalf("lot evaluation entry = %+v", e)
+			}
+			refs = append(refs, e.RefID)
+		}
+		sort.Strings(refs)
+		if want := []string{"N/B1", "N/B2", "S/B1", "S/B3"}; !reflect.DeepEqual(refs, want) {
+			t.Fatalf("lot evaluations in trail = %v, want %v", refs, want)
+		}
+		return nil
+	})
+}
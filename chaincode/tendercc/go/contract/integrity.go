@@ -0,0 +1,254 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// How a stored payload matched its recorded hash
+const (
+	integrityCanonical  = "CANONICAL"
+	integrityRaw        = "RAW"        // submitted before canonical hashing; raw bytes were hashed
+	integrityCiphertext = "CIPHERTEXT" // sealed bid not yet opened
+)
+
+// VerifyBidIntegrity recomputes the hash of a stored private bid and compares it with the BidRef.
+// It must run on a peer of an organisation that is a member of the bids collection.
+func (s *EnhancedSmartContract) VerifyBidIntegrity(ctx contractapi.TransactionContextInterface, tenderID, bidID string) (*BidIntegrityReport, error) {
+	ref, err := s.GetBidRef(ctx, tenderID, bidID)
+	if err != nil {
+		return nil, err
+	}
+	report := &BidIntegrityReport{TenderID: tenderID, BidID: bidID, RecordedHash: ref.BidHash}
+
+	// Sealed bids can only be checked against the ciphertext hash
+	if ref.Encrypted && ref.OpenedAt == "" {
+		report.RecordedHash = ref.CiphertextHash
+		sealed, err := ctx.GetStub().GetPrivateData(privateCollectionName, encryptedBidKey(tenderID, bidID))
+		if err != nil {
+			return nil, err
+		}
+		if sealed == nil {
+			report.Error = "encrypted bid not found in private collection"
+			return report, nil
+		}
+		ciphertext, err := encryptedBidCiphertext(sealed)
+		if err != nil {
+			report.Error = err.Error()
+			return report, nil
+		}
+		sum := sha256.Sum256(ciphertext)
+		report.ComputedHash = hex.EncodeToString(sum[:])
+		report.Method = integrityCiphertext
+		report.Match = report.ComputedHash == report.RecordedHash
+		return report, nil
+	}
+
+	data, err := ctx.GetStub().GetPrivateData(privateCollectionName, bidPrivKey(tenderID, bidID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		// Reverse auction offers are stored under the bidder alias
+		if data, err = ctx.GetStub().GetPrivateData(privateCollectionName, auctionOfferKey(tenderID, ref.ContractorID)); err != nil {
+			return nil, err
+		}
+	}
+	if data == nil {
+		report.Error = "private bid not found in private collection"
+		return report, nil
+	}
+
+	_, canonical, err := canonicalHash(data)
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+	report.ComputedHash = canonical
+	report.Method = integrityCanonical
+	if canonical == ref.BidHash {
+		report.Match = true
+		return report, nil
+	}
+	raw := sha256.Sum256(data)
+	if hex.EncodeToString(raw[:]) == ref.BidHash {
+		report.ComputedHash = ref.BidHash
+		report.Method = integrityRaw
+		report.Match = true
+	}
+	return report, nil
+}
+
+func encryptedBidCiphertext(sealed []byte) ([]byte, error) {
+	var bid EncryptedBid
+	if err := json.Unmarshal(sealed, &bid); err != nil {
+		return nil, fmt.Errorf("invalid encrypted bid: %v", err)
+	}
+	return base64.StdEncoding.DecodeString(bid.Ciphertext)
+}
+
+// Private data audit statuses
+const (
+	auditOK           = "OK"
+	auditMissing      = "MISSING"      // no private data hash on the ledger for the ref
+	auditMismatch     = "MISMATCH"     // private data hash differs from the public hash
+	auditSealed       = "SEALED"       // unopened encrypted bid; only presence can be proven
+	auditUnverifiable = "UNVERIFIABLE" // ref predates payload hashing; only presence can be proven
+	auditPurged       = "PURGED"       // removed under the retention policy; LedgerHash is the tombstone hash
+)
+
+// AuditPrivateData checks every bid and milestone ref of a tender against the private data
+// hashes on the ledger. It uses GetPrivateDataHash, so any channel member can run it
+// without access to bidsCollection or milestonesCollection.
+func (s *EnhancedSmartContract) AuditPrivateData(ctx contractapi.TransactionContextInterface, tenderID string) (*PrivateDataAuditReport, error) {
+	report := &PrivateDataAuditReport{TenderID: tenderID, Issues: []PrivateDataAuditEntry{}}
+
+	bids, err := s.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range bids {
+		entry, err := auditBidRef(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		addAuditEntry(report, entry)
+	}
+
+	milestones, err := listMilestoneRefs(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range milestones {
+		entry := PrivateDataAuditEntry{
+			Kind:         "MILESTONE",
+			TenderID:     tenderID,
+			RefID:        ref.MilestoneID,
+			Collection:   milestonePrivateCollection,
+			RecordedHash: ref.PayloadHash,
+		}
+		if err := auditHash(ctx, &entry, milestonePrivKey(tenderID, ref.MilestoneID)); err != nil {
+			return nil, err
+		}
+		if entry.Status == auditOK && ref.PayloadHash == "" {
+			entry.Status = auditUnverifiable
+		}
+		addAuditEntry(report, entry)
+	}
+	return report, nil
+}
+
+func addAuditEntry(r *PrivateDataAuditReport, entry PrivateDataAuditEntry) {
+	r.Checked++
+	if entry.Status == auditOK {
+		r.OK++
+		return
+	}
+	r.Issues = append(r.Issues, entry)
+}
+
+func auditBidRef(ctx contractapi.TransactionContextInterface, ref *BidRef) (PrivateDataAuditEntry, error) {
+	entry := PrivateDataAuditEntry{
+		Kind:         "BID",
+		TenderID:     ref.TenderID,
+		RefID:        ref.BidID,
+		Collection:   privateCollectionName,
+		RecordedHash: ref.BidHash,
+	}
+	if ref.Encrypted && ref.OpenedAt == "" {
+		entry.RecordedHash = ""
+		if err := auditHash(ctx, &entry, encryptedBidKey(ref.TenderID, ref.BidID)); err != nil {
+			return entry, err
+		}
+		if entry.Status == auditOK {
+			entry.Status = auditSealed
+		}
+		return entry, nil
+	}
+
+	// Reverse auction offers are stored under the bidder alias
+	key := bidPrivKey(ref.TenderID, ref.BidID)
+	offerKey := auctionOfferKey(ref.TenderID, ref.ContractorID)
+	hash, err := ctx.GetStub().GetPrivateDataHash(privateCollectionName, key)
+	if err != nil {
+		return entry, err
+	}
+	if hash == nil {
+		offerHash, err := ctx.GetStub().GetPrivateDataHash(privateCollectionName, offerKey)
+		if err != nil {
+			return entry, err
+		}
+		tombstone, err := purgeRecord(ctx, ref.TenderID, offerKey)
+		if err != nil {
+			return entry, err
+		}
+		if offerHash != nil || tombstone != nil {
+			key = offerKey
+		}
+	}
+	return entry, auditHash(ctx, &entry, key)
+}
+
+// auditHash fills LedgerHash and Status for the private data stored under key.
+// An empty RecordedHash only checks presence.
+func auditHash(ctx contractapi.TransactionContextInterface, entry *PrivateDataAuditEntry, key string) error {
+	hash, err := ctx.GetStub().GetPrivateDataHash(entry.Collection, key)
+	if err != nil {
+		return fmt.Errorf("failed to get private data hash: %v", err)
+	}
+	switch {
+	case hash == nil:
+		entry.Status = auditMissing
+		tombstone, err := purgeRecord(ctx, entry.TenderID, key)
+		if err != nil {
+			return err
+		}
+		if tombstone != nil {
+			entry.LedgerHash = tombstone.DataHash
+			entry.Status = auditPurged
+			if entry.RecordedHash != "" && tombstone.DataHash != entry.RecordedHash {
+				entry.Status = auditMismatch
+			}
+		}
+	case entry.RecordedHash == "":
+		entry.LedgerHash = hex.EncodeToString(hash)
+		entry.Status = auditOK
+	default:
+		entry.LedgerHash = hex.EncodeToString(hash)
+		if entry.LedgerHash == entry.RecordedHash {
+			entry.Status = auditOK
+		} else {
+			entry.Status = auditMismatch
+		}
+	}
+	return nil
+}
+
+func listMilestoneRefs(ctx contractapi.TransactionContextInterface, tenderID string) ([]*MilestoneRef, error) {
+	iter, err := ctx.GetStub().GetStateByRange("MSREF_"+tenderID+"_", "MSREF_"+tenderID+"_~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []*MilestoneRef
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var r MilestoneRef
+		if err := json.Unmarshal(kv.Value, &r); err != nil {
+			return nil, err
+		}
+		if r.TenderID == tenderID {
+			out = append(out, &r)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,155 @@
+package contract
+
+import (
+	"testing"
+	"time"
+)
+
+// rogueWrite commits a direct write to a collection, as a misbehaving member peer could
+func (n *testNet) rogueWrite(collection, key string, value []byte) {
+	n.t.Helper()
+	stub := n.ledger.NewStub(n.identity("buyer"), nil)
+	var err error
+	if value == nil {
+		err = stub.DelPrivateData(collection, key)
+	} else {
+		err = stub.PutPrivateData(collection, key, value)
+	}
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	if err := stub.Commit(); err != nil {
+		n.t.Fatal(err)
+	}
+}
+
+func (n *testNet) rogueState(key string, value []byte) {
+	n.t.Helper()
+	stub := n.ledger.NewStub(n.identity("buyer"), nil)
+	if err := stub.PutState(key, value); err != nil {
+		n.t.Fatal(err)
+	}
+	if err := stub.Commit(); err != nil {
+		n.t.Fatal(err)
+	}
+}
+
+func TestVerifyBidIntegrity(t *testing.T) {
+	n := newTestNet(t)
+	n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+	for _, b := range []struct {
+		who   string
+		bidID string
+	}{{"contractorA", "B1"}, {"contractorB", "B2"}, {"contractorC", "B3"}} {
+		n.mustSubmitBid(b.who, bidFixture("T1", b.bidID, b.who, 500000))
+	}
+	n.rogueWrite(privateCollectionName, bidPrivKey("T1", "B2"), []byte(`{"tenderId":"T1","bidId":"B2","totalAmount":1}`))
+	n.rogueWrite(privateCollectionName, bidPrivKey("T1", "B3"), nil)
+
+	// A bid stored before canonical hashing: the ref holds the hash of the raw bytes
+	raw := []byte(`{"tenderId": "T1", "bidId": "B4", "totalAmount": 5}`)
+	n.rogueWrite(privateCollectionName, bidPrivKey("T1", "B4"), raw)
+	n.rogueState(bidRefKey("T1", "B4"), []byte(mustJSON(t, BidRef{TenderID: "T1", BidID: "B4", BidHash: sha256Hex(string(raw))})))
+
+	sealedTender, keys := n.encryptedTenderFixture("S1")
+	n.openTender(sealedTender)
+	if err := n.submitSealed("contractorA", "S1", "B1", keys.seal(t, bidFixture("S1", "B1", "contractorA", 1), "")); err != nil {
+		t.Fatal(err)
+	}
+
+	n.openTender(auctionFixture("A1"))
+	n.ledger.SetTime(auctionStart)
+	if err := n.placeOffer("contractorA", "A1", "B1", 100000); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		tenderID string
+		bidID    string
+		match    bool
+		method   string
+		errMsg   string
+		wantErr  string
+	}{
+		{name: "canonical", tenderID: "T1", bidID: "B1", match: true, method: integrityCanonical},
+		{name: "tampered", tenderID: "T1", bidID: "B2", method: integrityCanonical},
+		{name: "missing", tenderID: "T1", bidID: "B3", errMsg: "private bid not found in private collection"},
+		{name: "raw hash", tenderID: "T1", bidID: "B4", match: true, method: integrityRaw},
+		{name: "sealed", tenderID: "S1", bidID: "B1", match: true, method: integrityCiphertext},
+		{name: "auction offer", tenderID: "A1", bidID: "B1", match: true, method: integrityCanonical},
+		{name: "unknown bid", tenderID: "T1", bidID: "B9", wantErr: "bid B9 not found for tender T1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := n.query("auditor", func(ctx *TransactionContext) error {
+				report, err := n.enh.VerifyBidIntegrity(ctx, tc.tenderID, tc.bidID)
+				if err != nil {
+					return err
+				}
+				if report.Match != tc.match || report.Method != tc.method || report.Error != tc.errMsg {
+					t.Fatalf("report = %+v", report)
+				}
+				if tc.match && report.ComputedHash != report.RecordedHash {
+					t.Fatalf("matching report has differing hashes: %+v", report)
+				}
+				return nil
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestAuditPrivateData(t *testing.T) {
+	n := newTestNet(t)
+	sealedTender, keys := n.encryptedTenderFixture("S1")
+	n.openTender(sealedTender)
+	if err := n.submitSealed("contractorA", "S1", "B1", keys.seal(t, bidFixture("S1", "B1", "contractorA", 1), "")); err != nil {
+		t.Fatal(err)
+	}
+	n.awardedTender("T1")
+	ms := MilestonePrivate{TenderID: "T1", MilestoneID: "M1", Title: "Base course"}
+	n.mustTx("contractorA", transientOf(t, "milestone", ms), func(ctx *TransactionContext) error {
+		return n.basic.SubmitMilestone(ctx, "T1", "M1")
+	})
+	n.rogueWrite(privateCollectionName, bidPrivKey("T1", "B2"), nil)
+
+	tests := []struct {
+		name     string
+		tenderID string
+		tamper   func()
+		checked  int
+		ok       int
+		issues   map[string]string
+	}{
+		{"missing bid", "T1", nil, 3, 2, map[string]string{"B2": auditMissing}},
+		{"tampered milestone", "T1", func() {
+			n.rogueWrite(milestonePrivateCollection, milestonePrivKey("T1", "M1"), []byte(`{}`))
+		}, 3, 1, map[string]string{"B2": auditMissing, "M1": auditMismatch}},
+		{"sealed bid", "S1", nil, 1, 0, map[string]string{"B1": auditSealed}},
+		{"no refs", "T9", nil, 0, 0, map[string]string{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.tamper != nil {
+				tc.tamper()
+			}
+			// Members outside both collections can run the audit
+			n.mustQuery("regulator", func(ctx *TransactionContext) error {
+				report, err := n.enh.AuditPrivateData(ctx, tc.tenderID)
+				if err != nil {
+					return err
+				}
+				if report.Checked != tc.checked || report.OK != tc.ok || len(report.Issues) != len(tc.issues) {
+					t.Fatalf("report = %+v", report)
+				}
+				for _, issue := range report.Issues {
+					if tc.issues[issue.RefID] != issue.Status {
+						t.Fatalf("issue %s = %s, want %s", issue.RefID, issue.Status, tc.issues[issue.RefID])
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
@@ -0,0 +1,535 @@
+package contract
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+// createLegacy opens a legacy tender whose window runs from t0 for a week
+func (n *testNet) createLegacy(tenderID string) {
+	n.t.Helper()
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+		return n.basic.CreateTender(ctx, tenderID, "Bridge repair", rfc(t0), rfc(t0.Add(7*24*time.Hour)), "lowest price")
+	})
+}
+
+func (n *testNet) submitLegacyBid(who string, bid BidPrivate) error {
+	n.t.Helper()
+	return n.tx(who, transientOf(n.t, "bid", bid), func(ctx *TransactionContext) error {
+		return n.basic.SubmitBid(ctx, bid.TenderID, bid.BidID)
+	})
+}
+
+func TestLegacyCreateTender(t *testing.T) {
+	week := t0.Add(7 * 24 * time.Hour)
+	tests := []struct {
+		name    string
+		id      string
+		openAt  string
+		closeAt string
+		wantErr string
+	}{
+		{"valid", "L1", rfc(t0), rfc(week), ""},
+		{"duplicate id", "EXISTING", rfc(t0), rfc(week), "tender EXISTING already exists"},
+		{"missing openAt", "L2", "", rfc(week), "openAt and closeAt must be RFC3339"},
+		{"missing closeAt", "L3", rfc(t0), "", "openAt and closeAt must be RFC3339"},
+		{"bad openAt", "L4", "tomorrow", rfc(week), "invalid openAt"},
+		{"bad closeAt", "L5", rfc(t0), "2030-13-01", "invalid closeAt"},
+		{"close equals open", "L6", rfc(t0), rfc(t0), "closeAt must be after openAt"},
+		{"close before open", "L7", rfc(week), rfc(t0), "closeAt must be after openAt"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.createLegacy("EXISTING")
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.basic.CreateTender(ctx, tc.id, "Bridge repair", tc.openAt, tc.closeAt, "lowest price")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			envs := n.expectEvents(events.RFQCreated)
+			var p events.RFQCreatedPayload
+			if err := envs[0].Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.TenderID != tc.id || p.Status != "OPEN" {
+				t.Fatalf("payload = %+v", p)
+			}
+			if envs[0].Actor.MSPID != "BuyerMSP" {
+				t.Fatalf("actor = %+v", envs[0].Actor)
+			}
+		})
+	}
+}
+
+func TestLegacyGetTender(t *testing.T) {
+	n := newTestNet(t)
+	n.createLegacy("L1")
+
+	var got *Tender
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		var err error
+		got, err = n.basic.GetTender(ctx, "L1")
+		return err
+	})
+	if got.ID != "L1" || got.Status != "OPEN" || got.Criteria != "lowest price" {
+		t.Fatalf("tender = %+v", got)
+	}
+
+	err := n.query("auditor", func(ctx *TransactionContext) error {
+		_, err := n.basic.GetTender(ctx, "missing")
+		return err
+	})
+	expectErr(t, err, "tender missing not found")
+}
+
+func TestLegacySubmitBid(t *testing.T) {
+	valid := BidPrivate{TenderID: "L1", BidID: "B1", ContractorID: "contractorA", Amount: 1000, DocsHash: "abc"}
+	tests := []struct {
+		name      string
+		at        time.Time
+		close     bool
+		transient map[string][]byte
+		bidID     string
+		wantErr   string
+	}{
+		{name: "at window open", at: t0, bidID: "B1"},
+		{name: "one second before close", at: t0.Add(7*24*time.Hour - time.Second), bidID: "B1"},
+		{name: "before window", at: t0.Add(-time.Second), bidID: "B1", wantErr: "submission window closed"},
+		{name: "exactly at close", at: t0.Add(7 * 24 * time.Hour), bidID: "B1", wantErr: "submission window closed"},
+		{name: "tender closed", at: t0, close: true, bidID: "B1", wantErr: "not open for bids"},
+		{name: "no transient", at: t0, transient: map[string][]byte{}, bidID: "B1", wantErr: "transient map must contain 'bid'"},
+		{name: "bad json", at: t0, transient: map[string][]byte{"bid": []byte("{")}, bidID: "B1", wantErr: "invalid bid json"},
+		{name: "id mismatch", at: t0, bidID: "B9", wantErr: "tenderId/bidId mismatch"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.createLegacy("L1")
+			if tc.close {
+				n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.CloseTender(ctx, "L1") })
+			}
+			n.ledger.SetTime(tc.at)
+			transient := tc.transient
+			if transient == nil {
+				transient = transientOf(t, "bid", valid)
+			}
+			err := n.tx("contractorA", transient, func(ctx *TransactionContext) error {
+				return n.basic.SubmitBid(ctx, "L1", tc.bidID)
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.BidSubmitted)
+		})
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+		n := newTestNet(t)
+		n.createLegacy("L1")
+		if err := n.submitLegacyBid("contractorA", valid); err != nil {
+			t.Fatal(err)
+		}
+		expectErr(t, n.submitLegacyBid("contractorA", valid), "bid B1 already exists for tender L1")
+	})
+}
+
+func TestLegacyBidReads(t *testing.T) {
+	n := newTestNet(t)
+	n.createLegacy("L1")
+	for _, b := range []BidPrivate{
+		{TenderID: "L1", BidID: "B1", ContractorID: "contractorA", Amount: 1000},
+		{TenderID: "L1", BidID: "B2", ContractorID: "contractorB", Amount: 900},
+	} {
+		if err := n.submitLegacyBid(b.ContractorID, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		refs, err := n.basic.ListBidsPublic(ctx, "L1")
+		if err != nil {
+			return err
+		}
+		if len(refs) != 2 || refs[0].BidID != "B1" || refs[1].ContractorID != "contractorB" {
+			t.Fatalf("refs = %+v", refs)
+		}
+		ref, err := n.basic.GetBidRef(ctx, "L1", "B2")
+		if err != nil {
+			return err
+		}
+		priv, err := n.basic.ReadBidPrivate(ctx, "L1", "B2")
+		if err != nil {
+			return err
+		}
+		if priv.Amount != 900 {
+			t.Fatalf("private bid = %+v", priv)
+		}
+		// The public hash is the hash of the stored canonical bid
+		stored := n.ledger.PrivateData(privateCollectionName, bidPrivKey("L1", "B2"))
+		_, hash, err := canonicalHash(stored)
+		if err != nil {
+			return err
+		}
+		if ref.BidHash != hash {
+			t.Fatalf("bid hash = %s, want %s", ref.BidHash, hash)
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name    string
+		fn      func(ctx *TransactionContext) error
+		wantErr string
+	}{
+		{"missing ref", func(ctx *TransactionContext) error {
+			_, err := n.basic.GetBidRef(ctx, "L1", "B9")
+			return err
+		}, "bid B9 not found for tender L1"},
+		{"missing private bid", func(ctx *TransactionContext) error {
+			_, err := n.basic.ReadBidPrivate(ctx, "L1", "B9")
+			return err
+		}, "private bid not found"},
+		{"empty list", func(ctx *TransactionContext) error {
+			refs, err := n.basic.ListBidsPublic(ctx, "L2")
+			if err == nil && len(refs) != 0 {
+				t.Fatalf("refs = %+v", refs)
+			}
+			return err
+		}, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expectErr(t, n.query("buyer", tc.fn), tc.wantErr)
+		})
+	}
+}
+
+func TestLegacyCloseAndAward(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []string
+		wantErr string
+	}{
+		{"close open tender", []string{"close"}, ""},
+		{"close twice", []string{"close", "close"}, "tender L1 not open"},
+		{"award without closing", []string{"award"}, ""},
+		{"award after close", []string{"close", "award"}, ""},
+		{"award twice", []string{"award", "award"}, "tender already awarded"},
+		{"award unknown bid", []string{"awardUnknown"}, "bid B9 not found for tender L1"},
+		{"close awarded tender", []string{"award", "close"}, "tender L1 not open"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.createLegacy("L1")
+			if err := n.submitLegacyBid("contractorA", BidPrivate{TenderID: "L1", BidID: "B1", ContractorID: "contractorA", Amount: 10}); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			for _, step := range tc.steps {
+				err = n.tx("buyer", nil, func(ctx *TransactionContext) error {
+					switch step {
+					case "close":
+						return n.basic.CloseTender(ctx, "L1")
+					case "award":
+						return n.basic.AwardTender(ctx, "L1", "B1")
+					default:
+						return n.basic.AwardTender(ctx, "L1", "B9")
+					}
+				})
+				if err != nil {
+					break
+				}
+			}
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+
+	n := newTestNet(t)
+	n.createLegacy("L1")
+	if err := n.submitLegacyBid("contractorA", BidPrivate{TenderID: "L1", BidID: "B1", ContractorID: "contractorA"}); err != nil {
+		t.Fatal(err)
+	}
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.CloseTender(ctx, "L1") })
+	n.expectEvents(events.BidWindowClosed)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.AwardTender(ctx, "L1", "B1") })
+	n.expectEvents(events.TenderAwarded)
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		tender, err := n.basic.GetTender(ctx, "L1")
+		if err == nil && (tender.Status != "AWARDED" || tender.AwardedBidID != "B1") {
+			t.Fatalf("tender = %+v", tender)
+		}
+		return err
+	})
+}
+
+func TestLegacyEvaluations(t *testing.T) {
+	n := newTestNet(t)
+	n.createLegacy("L1")
+	if err := n.submitLegacyBid("contractorA", BidPrivate{TenderID: "L1", BidID: "B1", ContractorID: "contractorA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		bidID   string
+		wantErr string
+	}{
+		{"known bid", "B1", ""},
+		{"unknown bid", "B2", "bid B2 not found for tender L1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.basic.RecordEvaluation(ctx, "L1", tc.bidID, 87.5, "scored off-chain")
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+
+	n.expectEvents(events.BidEvaluated)
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		evals, err := n.basic.ListEvaluations(ctx, "L1")
+		if err == nil && (len(evals) != 1 || evals[0].Score != 87.5 || evals[0].Notes != "scored off-chain") {
+			t.Fatalf("evaluations = %+v", evals)
+		}
+		return err
+	})
+}
+
+func TestLegacyMilestones(t *testing.T) {
+	ms := MilestonePrivate{TenderID: "L1", MilestoneID: "M1", Title: "Deck poured", EvidenceHash: "ev1", Amount: 250}
+	submit := func(n *testNet, transient map[string][]byte, tenderID, milestoneID string) error {
+		return n.tx("contractorA", transient, func(ctx *TransactionContext) error {
+			return n.basic.SubmitMilestone(ctx, tenderID, milestoneID)
+		})
+	}
+
+	t.Run("submit", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			transient   map[string][]byte
+			tenderID    string
+			milestoneID string
+			wantErr     string
+		}{
+			{"valid", transientOf(t, "milestone", ms), "L1", "M1", ""},
+			{"unknown tender", transientOf(t, "milestone", ms), "L9", "M1", "tender L9 not found"},
+			{"no transient", nil, "L1", "M1", "transient map must contain 'milestone'"},
+			{"bad json", map[string][]byte{"milestone": []byte("[")}, "L1", "M1", "invalid milestone json"},
+			{"id mismatch", transientOf(t, "milestone", ms), "L1", "M2", "tenderId/milestoneId mismatch"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				n := newTestNet(t)
+				n.createLegacy("L1")
+				expectErr(t, submit(n, tc.transient, tc.tenderID, tc.milestoneID), tc.wantErr)
+			})
+		}
+	})
+
+	n := newTestNet(t)
+	n.createLegacy("L1")
+	n.ledger.Advance(time.Hour)
+	if err := submit(n, transientOf(t, "milestone", ms), "L1", "M1"); err != nil {
+		t.Fatal(err)
+	}
+	n.expectEvents(events.MilestoneSubmitted)
+	expectErr(t, submit(n, transientOf(t, "milestone", ms), "L1", "M1"), "milestone M1 already exists for tender L1")
+	ms2 := MilestonePrivate{TenderID: "L1", MilestoneID: "M2", Title: "Parapets", Amount: 100}
+	if err := submit(n, transientOf(t, "milestone", ms2), "L1", "M2"); err != nil {
+		t.Fatal(err)
+	}
+
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		priv, err := n.basic.ReadMilestonePrivate(ctx, "L1", "M1")
+		if err != nil {
+			return err
+		}
+		if priv.SubmittedAt != rfc(t0.Add(time.Hour)) || priv.Amount != 250 {
+			t.Fatalf("private milestone = %+v", priv)
+		}
+		refs, err := n.basic.ListMilestonesPublic(ctx, "L1")
+		if err != nil {
+			return err
+		}
+		if len(refs) != 2 || refs[0].Status != "SUBMITTED" || refs[0].PayloadHash == "" {
+			t.Fatalf("milestone refs = %+v", refs)
+		}
+		_, err = n.basic.ReadMilestonePrivate(ctx, "L1", "M9")
+		expectErr(t, err, "private milestone not found")
+		return nil
+	})
+
+	decisions := []struct {
+		name        string
+		milestoneID string
+		approve     bool
+		wantErr     string
+		wantStatus  string
+		wantPaid    bool
+		wantEvents  []string
+	}{
+		{"approve unknown", "M9", true, "milestone not found", "", false, nil},
+		{"reject unknown", "M9", false, "milestone not found", "", false, nil},
+		{"reject", "M1", false, "", "REJECTED", false, []string{events.MilestoneRejected}},
+		{"approve rejected", "M1", true, "milestone M1 is already REJECTED", "", false, nil},
+		{"reject again", "M1", false, "milestone M1 is already REJECTED", "", false, nil},
+		{"approve", "M2", true, "", "APPROVED", true, []string{events.MilestoneApproved, events.PaymentReleased}},
+		{"approve again", "M2", true, "milestone M2 is already APPROVED", "", false, nil},
+		{"reject approved", "M2", false, "milestone M2 is already APPROVED", "", false, nil},
+	}
+	for _, tc := range decisions {
+		t.Run(tc.name, func(t *testing.T) {
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				if tc.approve {
+					return n.basic.ApproveMilestone(ctx, "L1", tc.milestoneID)
+				}
+				return n.basic.RejectMilestone(ctx, "L1", tc.milestoneID, "incomplete evidence")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(tc.wantEvents...)
+			var ref MilestoneRef
+			if err := json.Unmarshal(n.ledger.State(milestoneRefKey("L1", tc.milestoneID)), &ref); err != nil {
+				t.Fatal(err)
+			}
+			if ref.Status != tc.wantStatus || ref.PaymentReleased != tc.wantPaid {
+				t.Fatalf("milestone ref = %+v", ref)
+			}
+		})
+	}
+}
+
+func TestLegacyListAndHistory(t *testing.T) {
+	n := newTestNet(t)
+	n.createLegacy("L1")
+	n.createLegacy("L2")
+	n.ledger.Advance(time.Hour)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.CloseTender(ctx, "L1") })
+	// Enhanced tenders share the TENDER_ range and decode partially
+	n.createTender(tenderFixture("E1", t0.Add(48*time.Hour)))
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		tenders, err := n.basic.ListTenders(ctx)
+		if err != nil {
+			return err
+		}
+		got := map[string]string{}
+		for _, tender := range tenders {
+			got[tender.ID] = tender.Status
+		}
+		want := map[string]string{"L1": "CLOSED", "L2": "OPEN", "E1": "DRAFT"}
+		if len(got) != len(want) {
+			t.Fatalf("tenders = %v", got)
+		}
+		for id, status := range want {
+			if got[id] != status {
+				t.Fatalf("tenders = %v, want %v", got, want)
+			}
+		}
+
+		history, err := n.basic.GetTenderHistory(ctx, "L1")
+		if err != nil {
+			return err
+		}
+		if len(history) != 2 {
+			t.Fatalf("history = %v", history)
+		}
+		var newest Tender
+		if err := json.Unmarshal([]byte(history[0]), &newest); err != nil {
+			return err
+		}
+		if newest.Status != "CLOSED" {
+			t.Fatalf("newest history entry = %+v", newest)
+		}
+
+		none, err := n.basic.GetTenderHistory(ctx, "L9")
+		if err == nil && len(none) != 0 {
+			t.Fatalf("history of unknown tender = %v", none)
+		}
+		return err
+	})
+}
+
+func TestLegacyInit(t *testing.T) {
+	n := newTestNet(t)
+	expectErr(t, n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.Init(ctx) }), "")
+	if len(n.ledger.Keys()) != 0 {
+		t.Fatalf("Init wrote %v", n.ledger.Keys())
+	}
+}
+
+// The legacy and enhanced contracts share the TENDER_ key space, so neither may
+// overwrite a tender the other created
+func TestLegacyEnhancedKeyCollision(t *testing.T) {
+	tests := []struct {
+		name    string
+		first   string
+		second  string
+		wantErr string
+	}{
+		{"legacy then enhanced", "legacy", "enhanced", "tender SHARED already exists"},
+		{"enhanced then legacy", "enhanced", "legacy", "tender SHARED already exists"},
+		{"legacy twice", "legacy", "legacy", "tender SHARED already exists"},
+		{"enhanced twice", "enhanced", "enhanced", "tender SHARED already exists"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			create := func(kind string) error {
+				return n.tx("buyer", nil, func(ctx *TransactionContext) error {
+					if kind == "legacy" {
+						return n.basic.CreateTender(ctx, "SHARED", "legacy", rfc(t0), rfc(t0.Add(time.Hour)), "")
+					}
+					return n.enh.CreateEnhancedTender(ctx, mustJSON(t, tenderFixture("SHARED", t0.Add(time.Hour))))
+				})
+			}
+			if err := create(tc.first); err != nil {
+				t.Fatal(err)
+			}
+			before := string(n.ledger.State(tenderKey("SHARED")))
+			expectErr(t, create(tc.second), tc.wantErr)
+			if after := string(n.ledger.State(tenderKey("SHARED"))); after != before {
+				t.Fatalf("tender overwritten:\n%s\n%s", before, after)
+			}
+		})
+	}
+
+	// Each contract reads the other's tender without panicking, but cannot act on it
+	n := newTestNet(t)
+	n.createLegacy("LEG")
+	n.createTender(tenderFixture("ENH", t0.Add(time.Hour)))
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		legacyView, err := n.basic.GetTender(ctx, "ENH")
+		if err != nil {
+			return err
+		}
+		if legacyView.ID != "ENH" || legacyView.Status != "DRAFT" || legacyView.OpenAt != "" {
+			t.Fatalf("legacy view of enhanced tender = %+v", legacyView)
+		}
+		enhancedView, err := n.enh.GetEnhancedTender(ctx, "LEG")
+		if err != nil {
+			return err
+		}
+		if enhancedView.ID != "LEG" || enhancedView.Status != "OPEN" || enhancedView.Deadlines.BidSubmissionDeadline != "" {
+			t.Fatalf("enhanced view of legacy tender = %+v", enhancedView)
+		}
+		return nil
+	})
+	// A legacy bid on a draft enhanced tender is refused because it is not OPEN
+	err := n.submitLegacyBid("contractorA", BidPrivate{TenderID: "ENH", BidID: "B1", ContractorID: "contractorA"})
+	expectErr(t, err, "tender ENH not open for bids")
+	// The enhanced contract cannot publish a legacy tender
+	err = n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.PublishTender(ctx, "LEG") })
+	expectErr(t, err, "only draft tenders can be published")
+}
@@ -0,0 +1,514 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// Lot award modes supported on EnhancedTender.LotAwardMode
+const (
+	lotAwardPerLot              = "PER_LOT"              // best score wins each lot independently
+	lotAwardCheapestCombination = "CHEAPEST_COMBINATION" // cheapest overall assignment including cross-lot discounts
+)
+
+// maxLotCombinations bounds the search done by the cheapest-combination optimiser
+const maxLotCombinations = 100000
+
+func lotEvalKey(tenderID, lotID, bidID string) string {
+	return fmt.Sprintf("LOTEVAL_%s_%s_%s", tenderID, lotID, bidID)
+}
+
+func hasLots(tender *EnhancedTender) bool {
+	return len(tender.Lots) > 0
+}
+
+func findLot(tender *EnhancedTender, lotID string) (*Lot, error) {
+	for i := range tender.Lots {
+		if tender.Lots[i].ID == lotID {
+			return &tender.Lots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("lot %s not found in tender %s", lotID, tender.ID)
+}
+
+// validateLots checks lot definitions on a tender
+func (s *EnhancedSmartContract) validateLots(tender *EnhancedTender) error {
+	if !hasLots(tender) {
+		if tender.LotAwardMode != "" {
+			return fmt.Errorf("lot award mode requires lots")
+		}
+		return nil
+	}
+	switch tender.LotAwardMode {
+	case "", lotAwardPerLot, lotAwardCheapestCombination:
+	default:
+		return fmt.Errorf("unknown lot award mode %s", tender.LotAwardMode)
+	}
+	if isReverseAuction(tender) {
+		return fmt.Errorf("reverse auctions cannot be split into lots")
+	}
+
+	seen := make(map[string]bool)
+	for _, lot := range tender.Lots {
+		if lot.ID == "" || lot.Name == "" {
+			return fmt.Errorf("lot ID and name are required")
+		}
+		if seen[lot.ID] {
+			return fmt.Errorf("duplicate lot ID %s", lot.ID)
+		}
+		seen[lot.ID] = true
+		if lot.Budget.EstimatedMin < 0 || lot.Budget.EstimatedMax < 0 ||
+			(lot.Budget.EstimatedMax > 0 && lot.Budget.EstimatedMin > lot.Budget.EstimatedMax) {
+			return fmt.Errorf("invalid budget range for lot %s", lot.ID)
+		}
+		if len(lot.EvaluationCriteria) > 0 {
+			if err := s.validateEvaluationCriteria(lot.EvaluationCriteria); err != nil {
+				return fmt.Errorf("lot %s: %v", lot.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateLotBid checks that a bid on a lot tender names valid lots with consistent pricing
+func validateLotBid(bid *EnhancedBidPrivate, tender *EnhancedTender) error {
+	if !hasLots(tender) {
+		if len(bid.LotBids) > 0 || len(bid.CrossLotDiscounts) > 0 {
+			return fmt.Errorf("tender %s has no lots", tender.ID)
+		}
+		return nil
+	}
+	if len(bid.LotBids) == 0 {
+		return fmt.Errorf("bid must name at least one lot")
+	}
+
+	covered := make(map[string]bool)
+	sum := 0.0
+	for _, lb := range bid.LotBids {
+		if _, err := findLot(tender, lb.LotID); err != nil {
+			return err
+		}
+		if covered[lb.LotID] {
+			return fmt.Errorf("lot %s priced more than once", lb.LotID)
+		}
+		if lb.Amount <= 0 {
+			return fmt.Errorf("amount for lot %s must be positive", lb.LotID)
+		}
+		covered[lb.LotID] = true
+		sum += lb.Amount
+	}
+	if math.Abs(sum-bid.TotalAmount) > 0.01 {
+		return fmt.Errorf("total amount %.2f does not match sum of lot prices %.2f", bid.TotalAmount, sum)
+	}
+
+	for _, d := range bid.CrossLotDiscounts {
+		if len(d.LotIDs) < 2 {
+			return fmt.Errorf("cross-lot discount must cover at least two lots")
+		}
+		if d.DiscountPercent <= 0 || d.DiscountPercent >= 100 {
+			return fmt.Errorf("cross-lot discount must be between 0 and 100 percent")
+		}
+		for _, id := range d.LotIDs {
+			if !covered[id] {
+				return fmt.Errorf("cross-lot discount references lot %s not covered by the bid", id)
+			}
+		}
+	}
+	return nil
+}
+
+func bidLotIDs(bid *EnhancedBidPrivate) []string {
+	var ids []string
+	for _, lb := range bid.LotBids {
+		ids = append(ids, lb.LotID)
+	}
+	return ids
+}
+
+func lotAmount(bid *EnhancedBidPrivate, lotID string) (float64, bool) {
+	for _, lb := range bid.LotBids {
+		if lb.LotID == lotID {
+			return lb.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// evaluateLots scores every bid separately for each lot it covers
+func (s *EnhancedSmartContract) evaluateLots(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, bids []*BidRef) error {
+	for _, bidRef := range bids {
+		bid, err := s.GetEnhancedBidPrivate(ctx, tender.ID, bidRef.BidID)
+		if err != nil {
+			continue // Skip bids that can't be retrieved
+		}
+
+		for _, lb := range bid.LotBids {
+			lot, err := findLot(tender, lb.LotID)
+			if err != nil {
+				continue
+			}
+			criteria := lot.EvaluationCriteria
+			if len(criteria) == 0 {
+				criteria = tender.EvaluationCriteria
+			}
+
+			// Score the lot as if it were a bid on its own
+			lotView := *bid
+			lotView.TotalAmount = lb.Amount
+			score := s.calculateBidScore(&lotView, criteria)
+
+			eval := LotEvaluation{
+				TenderID: tender.ID,
+				LotID:    lb.LotID,
+				BidID:    bidRef.BidID,
+				Amount:   lb.Amount,
+				Score:    score,
+				Notes:    "Automated per-lot evaluation",
+			}
+			evalBytes, _ := json.Marshal(eval)
+			if err := ctx.GetStub().PutState(lotEvalKey(tender.ID, lb.LotID, bidRef.BidID), evalBytes); err != nil {
+				return err
+			}
+
+			if err := emitEvent(ctx, events.LotBidEvaluated, tender.ID, events.LotEvaluatedPayload{
+				TenderID: tender.ID,
+				LotID:    lb.LotID,
+				BidID:    bidRef.BidID,
+				Score:    score,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ListLotEvaluations returns all per-lot evaluations of a tender, optionally for one lot
+func (s *EnhancedSmartContract) ListLotEvaluations(ctx contractapi.TransactionContextInterface, tenderID, lotID string) ([]*LotEvaluation, error) {
+	prefix := "LOTEVAL_" + tenderID + "_"
+	if lotID != "" {
+		prefix += lotID + "_"
+	}
+	iter, err := ctx.GetStub().GetStateByRange(prefix, prefix+"~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var out []*LotEvaluation
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var e LotEvaluation
+		if err := json.Unmarshal(kv.Value, &e); err == nil && (lotID == "" || e.LotID == lotID) {
+			out = append(out, &e)
+		}
+	}
+	return out, nil
+}
+
+// AwardLot awards a single lot to a bid that covers it
+func (s *EnhancedSmartContract) AwardLot(ctx contractapi.TransactionContextInterface, tenderID, lotID, bidID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if tender.Status != "CLOSED" {
+		return fmt.Errorf("tender must be closed before awarding")
+	}
+	lot, err := findLot(tender, lotID)
+	if err != nil {
+		return err
+	}
+	if lot.Status == "AWARDED" {
+		return fmt.Errorf("lot %s already awarded", lotID)
+	}
+	bid, err := s.GetEnhancedBidPrivate(ctx, tenderID, bidID)
+	if err != nil {
+		return err
+	}
+	amount, ok := lotAmount(bid, lotID)
+	if !ok {
+		return fmt.Errorf("bid %s does not cover lot %s", bidID, lotID)
+	}
+	payload, err := lotAwardPayload(tenderID, lotID, bidID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeAward(ctx, tender, lotID+"/"+bidID, payload, bidID); err != nil {
+		return err
+	}
+
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	return s.storeLotAwards(ctx, tender, map[string]string{lotID: bidID}, map[string]float64{lotID: amount}, txTime)
+}
+
+// AwardLots awards every lot of a closed tender using the tender's lot award mode.
+// Lots already awarded with AwardLot keep their award.
+func (s *EnhancedSmartContract) AwardLots(ctx contractapi.TransactionContextInterface, tenderID string) (*LotAwardResult, error) {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasLots(tender) {
+		return nil, fmt.Errorf("tender %s has no lots", tenderID)
+	}
+	if tender.Status != "CLOSED" {
+		return nil, fmt.Errorf("tender must be closed before awarding")
+	}
+	payload, err := lotsAwardPayload(tenderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeAward(ctx, tender, "", payload); err != nil {
+		return nil, err
+	}
+
+	bidRefs, err := s.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	var bids []*EnhancedBidPrivate
+	for _, ref := range bidRefs {
+		if bid, err := s.GetEnhancedBidPrivate(ctx, tenderID, ref.BidID); err == nil {
+			bids = append(bids, bid)
+		}
+	}
+
+	mode := tender.LotAwardMode
+	if mode == "" {
+		mode = lotAwardPerLot
+	}
+
+	var awards map[string]string
+	switch mode {
+	case lotAwardCheapestCombination:
+		awards, err = cheapestLotCombination(tender, bids)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		awards = make(map[string]string)
+		for _, lot := range tender.Lots {
+			if lot.Status == "AWARDED" {
+				continue
+			}
+			evals, err := s.ListLotEvaluations(ctx, tenderID, lot.ID)
+			if err != nil {
+				return nil, err
+			}
+			bestScore := -1.0
+			for _, e := range evals {
+				if e.Score > bestScore {
+					bestScore = e.Score
+					awards[lot.ID] = e.BidID
+				}
+			}
+		}
+	}
+
+	amounts := make(map[string]float64)
+	byID := make(map[string]*EnhancedBidPrivate)
+	for _, bid := range bids {
+		byID[bid.BidID] = bid
+	}
+	for lotID, bidID := range awards {
+		if bid, ok := byID[bidID]; ok {
+			amounts[lotID], _ = lotAmount(bid, lotID)
+		}
+	}
+
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storeLotAwards(ctx, tender, awards, amounts, txTime); err != nil {
+		return nil, err
+	}
+
+	result := &LotAwardResult{
+		TenderID:  tenderID,
+		Mode:      mode,
+		Awards:    awards,
+		AwardedAt: txTime.Format(time.RFC3339),
+	}
+	gross, discount := combinationCost(awards, byID)
+	result.TotalCost = gross - discount
+	result.Discount = discount
+	for _, lot := range tender.Lots {
+		if _, ok := awards[lot.ID]; !ok && lot.Status != "AWARDED" {
+			result.Unawarded = append(result.Unawarded, lot.ID)
+		}
+	}
+	return result, nil
+}
+
+// storeLotAwards marks lots awarded and moves the tender to AWARDED once every lot is settled
+func (s *EnhancedSmartContract) storeLotAwards(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, awards map[string]string, amounts map[string]float64, now time.Time) error {
+	ts := now.Format(time.RFC3339)
+	lotIDs := make([]string, 0, len(awards))
+	for lotID := range awards {
+		lotIDs = append(lotIDs, lotID)
+	}
+	sort.Strings(lotIDs)
+
+	for _, lotID := range lotIDs {
+		lot, err := findLot(tender, lotID)
+		if err != nil {
+			return err
+		}
+		if lot.Status == "AWARDED" {
+			return fmt.Errorf("lot %s already awarded", lotID)
+		}
+		lot.Status = "AWARDED"
+		lot.AwardedBidID = awards[lotID]
+		lot.AwardedAmount = amounts[lotID]
+		lot.AwardedAt = ts
+
+		if err := emitEvent(ctx, events.LotAwarded, tender.ID, events.LotAwardedPayload{
+			TenderID:  tender.ID,
+			LotID:     lotID,
+			BidID:     awards[lotID],
+			AwardedAt: ts,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Lots nobody bid on are closed as UNAWARDED when an automatic award runs
+	settled := true
+	for i := range tender.Lots {
+		if tender.Lots[i].Status != "AWARDED" && tender.Lots[i].Status != "UNAWARDED" {
+			settled = false
+		}
+	}
+	if settled {
+		tender.Status = "AWARDED"
+		tender.AwardedAt = ts
+	}
+	tender.UpdatedAt = ts
+
+	return putTender(ctx, tender)
+}
+
+// combinationCost returns the gross price of an assignment and the cross-lot discount it earns
+func combinationCost(awards map[string]string, bids map[string]*EnhancedBidPrivate) (float64, float64) {
+	gross := 0.0
+	for lotID, bidID := range awards {
+		if bid, ok := bids[bidID]; ok {
+			amount, _ := lotAmount(bid, lotID)
+			gross += amount
+		}
+	}
+
+	discount := 0.0
+	for _, bid := range bids {
+		// Several discounts may apply to one bid; only the largest counts
+		best := 0.0
+		for _, d := range bid.CrossLotDiscounts {
+			wonAll := true
+			subtotal := 0.0
+			for _, lotID := range d.LotIDs {
+				if awards[lotID] != bid.BidID {
+					wonAll = false
+					break
+				}
+				amount, _ := lotAmount(bid, lotID)
+				subtotal += amount
+			}
+			if wonAll {
+				if v := subtotal * d.DiscountPercent / 100.0; v > best {
+					best = v
+				}
+			}
+		}
+		discount += best
+	}
+	return gross, discount
+}
+
+// cheapestLotCombination searches all lot-to-bid assignments for the lowest net cost.
+// Lots already awarded are left out, so discounts that need them do not apply.
+func cheapestLotCombination(tender *EnhancedTender, bids []*EnhancedBidPrivate) (map[string]string, error) {
+	byID := make(map[string]*EnhancedBidPrivate)
+	options := make(map[string][]string)
+	var lotIDs []string
+	combinations := 1
+	for _, lot := range tender.Lots {
+		if lot.Status == "AWARDED" {
+			continue
+		}
+		for _, bid := range bids {
+			if _, ok := lotAmount(bid, lot.ID); ok {
+				options[lot.ID] = append(options[lot.ID], bid.BidID)
+				byID[bid.BidID] = bid
+			}
+		}
+		if n := len(options[lot.ID]); n > 0 {
+			lotIDs = append(lotIDs, lot.ID)
+			combinations *= n
+			if combinations > maxLotCombinations {
+				return nil, fmt.Errorf("too many lot combinations to optimise (more than %d)", maxLotCombinations)
+			}
+		}
+	}
+
+	var best map[string]string
+	bestCost := math.Inf(1)
+	current := make(map[string]string)
+	var search func(i int)
+	search = func(i int) {
+		if i == len(lotIDs) {
+			gross, discount := combinationCost(current, byID)
+			if net := gross - discount; net < bestCost-0.000001 {
+				bestCost = net
+				best = make(map[string]string, len(current))
+				for k, v := range current {
+					best[k] = v
+				}
+			}
+			return
+		}
+		for _, bidID := range options[lotIDs[i]] {
+			current[lotIDs[i]] = bidID
+			search(i + 1)
+		}
+		delete(current, lotIDs[i])
+	}
+	search(0)
+
+	if best == nil {
+		best = make(map[string]string)
+	}
+	return best, nil
+}
+
+// markUnbidLots flags lots without any covering bid once bidding closes
+func markUnbidLots(tender *EnhancedTender, bids []*BidRef) {
+	covered := make(map[string]bool)
+	for _, ref := range bids {
+		for _, id := range ref.LotIDs {
+			covered[id] = true
+		}
+	}
+	for i := range tender.Lots {
+		if tender.Lots[i].Status == "" || tender.Lots[i].Status == "OPEN" {
+			if covered[tender.Lots[i].ID] {
+				tender.Lots[i].Status = "OPEN"
+			} else {
+				tender.Lots[i].Status = "UNAWARDED"
+			}
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package contract
+
+import (
+	"testing"
+	"time"
+)
+
+// lotsFixture splits a tender into North, South and East lots
+func lotsFixture(id, mode string) *EnhancedTender {
+	tender := tenderFixture(id, t0.Add(time.Hour))
+	tender.LotAwardMode = mode
+	tender.Lots = []Lot{
+		{ID: "N", Name: "North section"},
+		{ID: "S", Name: "South section"},
+		{ID: "E", Name: "East section"},
+	}
+	return tender
+}
+
+func lotBidFixture(tenderID, bidID, contractorID string, lots map[string]float64, discounts ...CrossLotDiscount) *EnhancedBidPrivate {
+	total := 0.0
+	var lotBids []LotBid
+	for _, id := range []string{"N", "S", "E"} {
+		if amount, ok := lots[id]; ok {
+			lotBids = append(lotBids, LotBid{LotID: id, Amount: amount})
+			total += amount
+		}
+	}
+	bid := bidFixture(tenderID, bidID, contractorID, total)
+	bid.LotBids = lotBids
+	bid.CrossLotDiscounts = discounts
+	return bid
+}
+
+// lotsTender closes a lot tender with three bids; nobody bids on East
+func (n *testNet) lotsTender(mode string) {
+	n.t.Helper()
+	n.openTender(lotsFixture("LT", mode))
+	n.mustSubmitBid("contractorA", lotBidFixture("LT", "B1", "contractorA",
+		map[string]float64{"N": 300000, "S": 500000},
+		CrossLotDiscount{LotIDs: []string{"N", "S"}, DiscountPercent: 15}))
+	n.mustSubmitBid("contractorB", lotBidFixture("LT", "B2", "contractorB", map[string]float64{"N": 250000}))
+	n.mustSubmitBid("contractorC", lotBidFixture("LT", "B3", "contractorC", map[string]float64{"S": 450000}))
+	n.closeTender("LT")
+}
+
+func TestLotValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(e *EnhancedTender)
+		wantErr string
+	}{
+		{"valid", func(*EnhancedTender) {}, ""},
+		{"unknown mode", func(e *EnhancedTender) { e.LotAwardMode = "RANDOM" }, `lotAwardMode: "RANDOM" is not one of "PER_LOT", "CHEAPEST_COMBINATION"`},
+		{"unnamed lot", func(e *EnhancedTender) { e.Lots[0].Name = "" }, "lot ID and name are required"},
+		{"duplicate lot", func(e *EnhancedTender) { e.Lots[1].ID = "N" }, "duplicate lot ID N"},
+		{"inverted budget", func(e *EnhancedTender) { e.Lots[0].Budget = Budget{EstimatedMin: 10, EstimatedMax: 5} }, "invalid budget range for lot N"},
+		{"lot criteria", func(e *EnhancedTender) {
+			e.Lots[0].EvaluationCriteria = []EvalCriterion{{Name: "Price", Weight: 90}}
+		}, "lot N: total evaluation criteria weight must equal 100"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := lotsFixture("LT", "")
+			tc.mutate(tender)
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateEnhancedTender(ctx, mustJSON(t, tender))
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLotBidValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(b *EnhancedBidPrivate)
+		wantErr string
+	}{
+		{"valid", func(*EnhancedBidPrivate) {}, ""},
+		{"no lots", func(b *EnhancedBidPrivate) { b.LotBids = nil }, "bid must name at least one lot"},
+		{"unknown lot", func(b *EnhancedBidPrivate) { b.LotBids[0].LotID = "W" }, "lot W not found in tender LT"},
+		{"lot twice", func(b *EnhancedBidPrivate) { b.LotBids[1].LotID = "N" }, "lot N priced more than once"},
+		{"zero lot amount", func(b *EnhancedBidPrivate) { b.LotBids[0].Amount = 0 }, "amount for lot N must be positive"},
+		{"total mismatch", func(b *EnhancedBidPrivate) { b.TotalAmount++ }, "total amount 301.00 does not match sum of lot prices 300.00"},
+		{"total within tolerance", func(b *EnhancedBidPrivate) { b.TotalAmount += 0.005 }, ""},
+		{"discount on one lot", func(b *EnhancedBidPrivate) {
+			b.CrossLotDiscounts = []CrossLotDiscount{{LotIDs: []string{"N"}, DiscountPercent: 5}}
+		}, "cross-lot discount must cover at least two lots"},
+		{"discount of 100 percent", func(b *EnhancedBidPrivate) {
+			b.CrossLotDiscounts = []CrossLotDiscount{{LotIDs: []string{"N", "S"}, DiscountPercent: 100}}
+		}, "cross-lot discount must be between 0 and 100 percent"},
+		{"discount on uncovered lot", func(b *EnhancedBidPrivate) {
+			b.CrossLotDiscounts = []CrossLotDiscount{{LotIDs: []string{"N", "E"}, DiscountPercent: 5}}
+		}, "cross-lot discount references lot E not covered by the bid"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.openTender(lotsFixture("LT", ""))
+			bid := lotBidFixture("LT", "B1", "contractorA", map[string]float64{"N": 100, "S": 200})
+			tc.mutate(bid)
+			expectErr(t, n.submitBid("contractorA", bid), tc.wantErr)
+			if tc.wantErr == "" {
+				n.mustQuery("buyer", func(ctx *TransactionContext) error {
+					ref, err := n.enh.GetBidRef(ctx, "LT", "B1")
+					if err == nil && len(ref.LotIDs) != 2 {
+						t.Fatalf("ref lots = %v", ref.LotIDs)
+					}
+					return err
+				})
+			}
+		})
+	}
+}
+
+func TestLotEvaluation(t *testing.T) {
+	n := newTestNet(t)
+	n.lotsTender("")
+	if got := n.tender("LT"); got.Lots[2].Status != "UNAWARDED" || got.Lots[0].Status != "OPEN" {
+		t.Fatalf("lots after close = %+v", got.Lots)
+	}
+	err := n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardTender(ctx, "LT", "B1") })
+	expectErr(t, err, "tender LT is split into lots; use AwardLot or AwardLots")
+
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "LT") })
+	n.expectEvents("LotBidEvaluated", "LotBidEvaluated", "LotBidEvaluated", "LotBidEvaluated")
+
+	tests := []struct {
+		lotID string
+		want  map[string]float64
+	}{
+		{"", map[string]float64{"N/B1": 97.0*0.6 + 40, "N/B2": 97.5*0.6 + 40, "S/B1": 95.0*0.6 + 40, "S/B3": 95.5*0.6 + 40}},
+		{"N", map[string]float64{"N/B1": 97.0*0.6 + 40, "N/B2": 97.5*0.6 + 40}},
+		{"E", map[string]float64{}},
+	}
+	for _, tc := range tests {
+		t.Run("lot "+tc.lotID, func(t *testing.T) {
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				evals, err := n.enh.ListLotEvaluations(ctx, "LT", tc.lotID)
+				if err != nil {
+					return err
+				}
+				if len(evals) != len(tc.want) {
+					t.Fatalf("evaluations = %+v", evals)
+				}
+				for _, e := range evals {
+					if want := tc.want[e.LotID+"/"+e.BidID]; e.Score-want > 1e-9 || want-e.Score > 1e-9 {
+						t.Fatalf("score of %s/%s = %v, want %v", e.LotID, e.BidID, e.Score, want)
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestAwardLots(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		want     map[string]string
+		cost     float64
+		discount float64
+	}{
+		{"per lot by score", "", map[string]string{"N": "B2", "S": "B3"}, 700000, 0},
+		{"cheapest combination", lotAwardCheapestCombination, map[string]string{"N": "B1", "S": "B1"}, 680000, 120000},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.lotsTender(tc.mode)
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "LT") })
+			var result *LotAwardResult
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+				var err error
+				result, err = n.enh.AwardLots(ctx, "LT")
+				return err
+			})
+			if len(result.Awards) != len(tc.want) || result.TotalCost != tc.cost || result.Discount != tc.discount {
+				t.Fatalf("result = %+v", result)
+			}
+			for lot, bid := range tc.want {
+				if result.Awards[lot] != bid {
+					t.Fatalf("awards = %v, want %v", result.Awards, tc.want)
+				}
+			}
+			if len(result.Unawarded) != 1 || result.Unawarded[0] != "E" {
+				t.Fatalf("unawarded = %v", result.Unawarded)
+			}
+			n.expectEvents("LotAwarded", "LotAwarded")
+			if got := n.tender("LT"); got.Status != "AWARDED" {
+				t.Fatalf("tender status = %s", got.Status)
+			}
+		})
+	}
+
+	for _, mode := range []string{"", lotAwardCheapestCombination} {
+		t.Run("after AwardLot "+mode, func(t *testing.T) {
+			n := newTestNet(t)
+			n.lotsTender(mode)
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "LT") })
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardLot(ctx, "LT", "S", "B1") })
+			var result *LotAwardResult
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+				var err error
+				result, err = n.enh.AwardLots(ctx, "LT")
+				return err
+			})
+			if len(result.Awards) != 1 || result.Awards["N"] == "" || len(result.Unawarded) != 1 || result.Unawarded[0] != "E" {
+				t.Fatalf("result = %+v", result)
+			}
+			n.expectEvents("LotAwarded")
+			got := n.tender("LT")
+			if lot, _ := findLot(got, "S"); lot.AwardedBidID != "B1" || got.Status != "AWARDED" {
+				t.Fatalf("tender = %+v", got)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(lotsFixture("LT", ""))
+		n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+		for _, tc := range []struct {
+			tenderID string
+			wantErr  string
+		}{
+			{"T1", "tender T1 has no lots"},
+			{"LT", "tender must be closed before awarding"},
+			{"T9", "tender T9 not found"},
+		} {
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				_, err := n.enh.AwardLots(ctx, tc.tenderID)
+				return err
+			})
+			expectErr(t, err, tc.wantErr)
+		}
+	})
+}
+
+func TestAwardLot(t *testing.T) {
+	tests := []struct {
+		name    string
+		lotID   string
+		bidID   string
+		wantErr string
+	}{
+		{"bid misses lot", "N", "B3", "bid B3 does not cover lot N"},
+		{"unknown lot", "W", "B1", "lot W not found in tender LT"},
+		{"unknown bid", "N", "B9", "private bid not found"},
+		{"first lot", "S", "B3", ""},
+		{"already awarded", "S", "B1", "lot S already awarded"},
+		{"second lot settles tender", "N", "B1", ""},
+	}
+	n := newTestNet(t)
+	n.lotsTender("")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.AwardLot(ctx, "LT", tc.lotID, tc.bidID)
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			got := n.tender("LT")
+			lot, _ := findLot(got, tc.lotID)
+			if lot.Status != "AWARDED" || lot.AwardedBidID != tc.bidID {
+				t.Fatalf("lot = %+v", lot)
+			}
+			wantStatus := "CLOSED"
+			if tc.lotID == "N" {
+				wantStatus = "AWARDED"
+			}
+			if got.Status != wantStatus {
+				t.Fatalf("tender status = %s, want %s", got.Status, wantStatus)
+			}
+		})
+	}
+
+	t.Run("open tender", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(lotsFixture("LT", ""))
+		err := n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardLot(ctx, "LT", "N", "B1") })
+		expectErr(t, err, "tender must be closed before awarding")
+	})
+}
@@ -0,0 +1,110 @@
+package contract
+
+import "tendercc/model"
+
+// The ledger data types live in package model so that off-chain clients can import
+// them; the aliases keep the chaincode code and its contract metadata unchanged.
+type (
+	Address                   = model.Address
+	AuctionConfig             = model.AuctionConfig
+	AuctionOffer              = model.AuctionOffer
+	AuctionRank               = model.AuctionRank
+	AuctionResult             = model.AuctionResult
+	AuctionResultEntry        = model.AuctionResultEntry
+	AuctionState              = model.AuctionState
+	AuthorizedPerson          = model.AuthorizedPerson
+	AwardApproval             = model.AwardApproval
+	BidEncryptionConfig       = model.BidEncryptionConfig
+	BidFilter                 = model.BidFilter
+	BidIntegrityReport        = model.BidIntegrityReport
+	BidPrivate                = model.BidPrivate
+	BidQueryResult            = model.BidQueryResult
+	BidRef                    = model.BidRef
+	BidRequirements           = model.BidRequirements
+	BidSecurity               = model.BidSecurity
+	Budget                    = model.Budget
+	BuyerRating               = model.BuyerRating
+	CallOffOrder              = model.CallOffOrder
+	CallOffResponse           = model.CallOffResponse
+	CallOffResponseRef        = model.CallOffResponseRef
+	CategoryCosting           = model.CategoryCosting
+	CertificationReq          = model.CertificationReq
+	ComplianceReq             = model.ComplianceReq
+	ConfidentialityTerms      = model.ConfidentialityTerms
+	ContactPerson             = model.ContactPerson
+	ContractTerms             = model.ContractTerms
+	ContractorPerformance     = model.ContractorPerformance
+	CrossLotDiscount          = model.CrossLotDiscount
+	DebarmentEntry            = model.DebarmentEntry
+	DetachedSignature         = model.DetachedSignature
+	DisputeResolution         = model.DisputeResolution
+	DocumentAttachment        = model.DocumentAttachment
+	DocumentLink              = model.DocumentLink
+	DocumentRef               = model.DocumentRef
+	DocumentReq               = model.DocumentReq
+	DocumentVerification      = model.DocumentVerification
+	EncryptedBid              = model.EncryptedBid
+	EnhancedBidPrivate        = model.EnhancedBidPrivate
+	EnhancedTender            = model.EnhancedTender
+	EvalCriterion             = model.EvalCriterion
+	Evaluation                = model.Evaluation
+	ExperienceReq             = model.ExperienceReq
+	FinancialProposal         = model.FinancialProposal
+	FinancialReq              = model.FinancialReq
+	FinancialYear             = model.FinancialYear
+	FrameworkAgreement        = model.FrameworkAgreement
+	FrameworkMember           = model.FrameworkMember
+	HistoryEntry              = model.HistoryEntry
+	HistoryPage               = model.HistoryPage
+	IPTerms                   = model.IPTerms
+	Innovation                = model.Innovation
+	KeyShareHolder            = model.KeyShareHolder
+	Lot                       = model.Lot
+	LotAwardResult            = model.LotAwardResult
+	LotBid                    = model.LotBid
+	LotEvaluation             = model.LotEvaluation
+	MilestoneDeadline         = model.MilestoneDeadline
+	MilestonePrivate          = model.MilestonePrivate
+	MilestoneRef              = model.MilestoneRef
+	OwnerInfo                 = model.OwnerInfo
+	PastProject               = model.PastProject
+	PaymentMilestone          = model.PaymentMilestone
+	PaymentRequest            = model.PaymentRequest
+	PaymentTermsDetail        = model.PaymentTermsDetail
+	Penalty                   = model.Penalty
+	PenaltyRecord             = model.PenaltyRecord
+	PerformanceBond           = model.PerformanceBond
+	PersonnelReq              = model.PersonnelReq
+	Phase                     = model.Phase
+	PhaseCosting              = model.PhaseCosting
+	PrequalificationReport    = model.PrequalificationReport
+	PrivateDataAuditEntry     = model.PrivateDataAuditEntry
+	PrivateDataAuditReport    = model.PrivateDataAuditReport
+	ProjectScope              = model.ProjectScope
+	ProjectTimeline           = model.ProjectTimeline
+	PurgeRecord               = model.PurgeRecord
+	QAProcess                 = model.QAProcess
+	ReleasedKeyShare          = model.ReleasedKeyShare
+	Resource                  = model.Resource
+	RetentionPolicy           = model.RetentionPolicy
+	RiskMitigation            = model.RiskMitigation
+	SignatureRecord           = model.SignatureRecord
+	SigningKey                = model.SigningKey
+	SingleSourceJustification = model.SingleSourceJustification
+	SubCriterion              = model.SubCriterion
+	SubmissionFormat          = model.SubmissionFormat
+	TaxInfo                   = model.TaxInfo
+	TeamMember                = model.TeamMember
+	TechnicalProposal         = model.TechnicalProposal
+	TechnicalReq              = model.TechnicalReq
+	Tender                    = model.Tender
+	TenderDeadlines           = model.TenderDeadlines
+	TenderFilter              = model.TenderFilter
+	TenderQueryResult         = model.TenderQueryResult
+	TenderValidationReport    = model.TenderValidationReport
+	TerminationClause         = model.TerminationClause
+	ValidationIssue           = model.ValidationIssue
+	VendorCertification       = model.VendorCertification
+	VendorProfile             = model.VendorProfile
+	Warranty                  = model.Warranty
+)
@@ -0,0 +1,666 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// roleRegulator is the client certificate "role" attribute allowed to maintain the debarment list
+const roleRegulator = "regulator"
+
+// roleRegistrar is the client certificate "role" attribute allowed to bind signing keys to anyone
+const roleRegistrar = "registrar"
+
+func debarmentPayload(entry *DebarmentEntry) events.DebarmentPayload {
+	return events.DebarmentPayload{
+		ContractorID: entry.ContractorID,
+		Reason:       entry.Reason,
+		StartDate:    entry.StartDate,
+		EndDate:      entry.EndDate,
+		DebarredBy:   entry.DebarredBy,
+		CreatedAt:    entry.CreatedAt,
+		LiftedAt:     entry.LiftedAt,
+		LiftReason:   entry.LiftReason,
+	}
+}
+
+func performanceKey(contractorID string) string {
+	return fmt.Sprintf("PERF_%s", contractorID)
+}
+
+func penaltyKey(tenderID, penaltyID string) string {
+	return fmt.Sprintf("PENALTY_%s_%s", tenderID, penaltyID)
+}
+
+func debarmentKey(contractorID string) string {
+	return fmt.Sprintf("DEBAR_%s", contractorID)
+}
+
+// requireRole checks the client certificate carries role=<role>
+func requireRole(ctx contractapi.TransactionContextInterface, role string) error {
+	if err := ctx.GetClientIdentity().AssertAttributeValue("role", role); err != nil {
+		return fmt.Errorf("caller must have the %s role: %v", role, err)
+	}
+	return nil
+}
+
+// txTimestamp returns the deterministic transaction time for code shared by both contracts
+func txTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil || ts == nil {
+		return time.Time{}, fmt.Errorf("failed to get tx timestamp: %v", err)
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
+
+// noAwardError reports a tender with no awarded bid, lot or framework
+type noAwardError struct{ tenderID string }
+
+func (e noAwardError) Error() string {
+	return fmt.Sprintf("tender %s has no awarded bid", e.tenderID)
+}
+
+// bidContractor resolves the contractor behind a bid. Reverse auction refs only carry
+// the bidder alias, so the contractor is read from the private offer.
+func bidContractor(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, bidID string) (string, error) {
+	refData, err := ctx.GetStub().GetState(bidRefKey(tender.ID, bidID))
+	if err != nil {
+		return "", err
+	}
+	if refData == nil {
+		return "", fmt.Errorf("bid %s not found for tender %s", bidID, tender.ID)
+	}
+	var ref BidRef
+	if err := json.Unmarshal(refData, &ref); err != nil {
+		return "", err
+	}
+	if !isReverseAuction(tender) {
+		return ref.ContractorID, nil
+	}
+	offerData, err := ctx.GetStub().GetPrivateData(privateCollectionName, auctionOfferKey(tender.ID, ref.ContractorID))
+	if err != nil {
+		return "", err
+	}
+	if offerData == nil {
+		return "", fmt.Errorf("auction offer of bid %s not found for tender %s", bidID, tender.ID)
+	}
+	var offer AuctionOffer
+	if err := json.Unmarshal(offerData, &offer); err != nil {
+		return "", err
+	}
+	return offer.ContractorID, nil
+}
+
+// awardedContractors resolves the contractors behind a tender's award: the members of the
+// framework it created, its awarded bid, or the winning bids of its awarded lots
+func awardedContractors(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) ([]string, error) {
+	var bidIDs []string
+	switch {
+	case tender.FrameworkID != "":
+		data, err := ctx.GetStub().GetState(frameworkKey(tender.FrameworkID))
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return nil, fmt.Errorf("framework %s not found", tender.FrameworkID)
+		}
+		var framework FrameworkAgreement
+		if err := json.Unmarshal(data, &framework); err != nil {
+			return nil, err
+		}
+		for _, m := range framework.Members {
+			bidIDs = append(bidIDs, m.BidID)
+		}
+	case tender.AwardedBidID != "":
+		bidIDs = []string{tender.AwardedBidID}
+	default:
+		for _, lot := range tender.Lots {
+			if lot.AwardedBidID != "" {
+				bidIDs = append(bidIDs, lot.AwardedBidID)
+			}
+		}
+	}
+	if len(bidIDs) == 0 {
+		return nil, noAwardError{tender.ID}
+	}
+
+	seen := make(map[string]bool)
+	var out []string
+	for _, bidID := range bidIDs {
+		contractorID, err := bidContractor(ctx, tender, bidID)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[contractorID] {
+			seen[contractorID] = true
+			out = append(out, contractorID)
+		}
+	}
+	return out, nil
+}
+
+// awardedContractor resolves the one contractor a penalty or rating applies to
+func awardedContractor(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) (string, error) {
+	contractors, err := awardedContractors(ctx, tender)
+	if err != nil {
+		return "", err
+	}
+	if len(contractors) > 1 {
+		return "", fmt.Errorf("tender %s was awarded to %d contractors", tender.ID, len(contractors))
+	}
+	return contractors[0], nil
+}
+
+func loadPerformance(ctx contractapi.TransactionContextInterface, contractorID string) (*ContractorPerformance, error) {
+	data, err := ctx.GetStub().GetState(performanceKey(contractorID))
+	if err != nil {
+		return nil, err
+	}
+	perf := &ContractorPerformance{ContractorID: contractorID}
+	if data != nil {
+		if err := json.Unmarshal(data, perf); err != nil {
+			return nil, err
+		}
+	}
+	return perf, nil
+}
+
+// updatePerformance applies a change to a contractor's record and recomputes the rates
+func updatePerformance(ctx contractapi.TransactionContextInterface, contractorID string, apply func(p *ContractorPerformance)) error {
+	perf, err := loadPerformance(ctx, contractorID)
+	if err != nil {
+		return err
+	}
+	now, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	apply(perf)
+
+	if delivered := perf.MilestonesOnTime + perf.MilestonesLate; delivered > 0 {
+		perf.OnTimeRate = 100.0 * float64(perf.MilestonesOnTime) / float64(delivered)
+	}
+	if decided := perf.MilestonesApproved + perf.MilestonesRejected; decided > 0 {
+		perf.RejectionRate = 100.0 * float64(perf.MilestonesRejected) / float64(decided)
+	}
+	if len(perf.Ratings) > 0 {
+		total := 0
+		for _, r := range perf.Ratings {
+			total += r.Rating
+		}
+		perf.AverageRating = float64(total) / float64(len(perf.Ratings))
+	}
+	perf.UpdatedAt = now.Format(time.RFC3339)
+
+	bytes, _ := json.Marshal(perf)
+	return ctx.GetStub().PutState(performanceKey(contractorID), bytes)
+}
+
+// updateEachPerformance applies the same change to the record of each contractor
+func updateEachPerformance(ctx contractapi.TransactionContextInterface, contractorIDs []string, apply func(p *ContractorPerformance)) error {
+	for _, contractorID := range contractorIDs {
+		if err := updatePerformance(ctx, contractorID, apply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// milestoneDeadline looks up the RFQ deadline of a milestone by its title
+func milestoneDeadline(ctx contractapi.TransactionContextInterface, tenderID, title string) (time.Time, bool) {
+	data, err := ctx.GetStub().GetState(tenderKey(tenderID))
+	if err != nil || data == nil {
+		return time.Time{}, false
+	}
+	var t EnhancedTender
+	if err := json.Unmarshal(data, &t); err != nil {
+		return time.Time{}, false
+	}
+	for _, md := range t.Deadlines.MilestoneDeadlines {
+		if md.Name == title {
+			if d, err := time.Parse(time.RFC3339, md.Deadline); err == nil {
+				return d, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// recordMilestoneOutcome feeds a milestone decision into the record of every awarded
+// contractor. Tenders not awarded yet have no contractor to credit and are skipped.
+func recordMilestoneOutcome(ctx contractapi.TransactionContextInterface, ref *MilestoneRef, approved bool) error {
+	data, err := ctx.GetStub().GetState(tenderKey(ref.TenderID))
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("tender %s not found", ref.TenderID)
+	}
+	var tender EnhancedTender
+	if err := json.Unmarshal(data, &tender); err != nil {
+		return err
+	}
+	contractors, err := awardedContractors(ctx, &tender)
+	if errors.As(err, &noAwardError{}) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return updateEachPerformance(ctx, contractors, func(p *ContractorPerformance) {
+		if !approved {
+			p.MilestonesRejected++
+			return
+		}
+		p.MilestonesApproved++
+		onTime := true
+		if deadline, ok := milestoneDeadline(ctx, ref.TenderID, ref.Title); ok && ref.SubmittedAt != "" {
+			if submitted, err := time.Parse(time.RFC3339, ref.SubmittedAt); err == nil {
+				onTime = !submitted.After(deadline)
+			}
+		}
+		if onTime {
+			p.MilestonesOnTime++
+		} else {
+			p.MilestonesLate++
+		}
+	})
+}
+
+// GetContractorPerformance returns a contractor's performance record
+func (s *EnhancedSmartContract) GetContractorPerformance(ctx contractapi.TransactionContextInterface, contractorID string) (*ContractorPerformance, error) {
+	return loadPerformance(ctx, contractorID)
+}
+
+// requirePenaltyIssuer lets the tender owner's organization or the regulator role record penalties
+func requirePenaltyIssuer(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) error {
+	if ctx.GetClientIdentity().AssertAttributeValue("role", roleRegulator) == nil {
+		return nil
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if tender.OwnerMSP == "" || mspID != tender.OwnerMSP {
+		return fmt.Errorf("only the tender owner or the %s role may record penalties on tender %s", roleRegulator, tender.ID)
+	}
+	return nil
+}
+
+// RecordPenalty applies a contractual penalty to the awarded contractor of a tender
+func (s *EnhancedSmartContract) RecordPenalty(ctx contractapi.TransactionContextInterface, tenderID, penaltyID, penaltyType string, amount float64, reason string) error {
+	if amount <= 0 {
+		return fmt.Errorf("penalty amount must be positive")
+	}
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if err := requirePenaltyIssuer(ctx, tender); err != nil {
+		return err
+	}
+	contractorID, err := awardedContractor(ctx, tender)
+	if err != nil {
+		return err
+	}
+	exists, err := s.assetExists(ctx, penaltyKey(tenderID, penaltyID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("penalty %s already recorded for tender %s", penaltyID, tenderID)
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	penalty := PenaltyRecord{
+		TenderID:     tenderID,
+		PenaltyID:    penaltyID,
+		ContractorID: contractorID,
+		Type:         penaltyType,
+		Amount:       amount,
+		Reason:       reason,
+		RecordedAt:   txTime.Format(time.RFC3339),
+	}
+	bytes, _ := json.Marshal(penalty)
+	if err := ctx.GetStub().PutState(penaltyKey(tenderID, penaltyID), bytes); err != nil {
+		return err
+	}
+	if err := emitEvent(ctx, events.PenaltyRecorded, tenderID, events.PenaltyPayload{
+		TenderID:     penalty.TenderID,
+		PenaltyID:    penalty.PenaltyID,
+		ContractorID: penalty.ContractorID,
+		Type:         penalty.Type,
+		Amount:       penalty.Amount,
+		Reason:       penalty.Reason,
+		RecordedAt:   penalty.RecordedAt,
+	}); err != nil {
+		return err
+	}
+	return updatePerformance(ctx, contractorID, func(p *ContractorPerformance) {
+		p.PenaltiesCount++
+		p.PenaltiesTotal += amount
+	})
+}
+
+// ListPenalties returns the penalties recorded on a tender
+func (s *EnhancedSmartContract) ListPenalties(ctx contractapi.TransactionContextInterface, tenderID string) ([]*PenaltyRecord, error) {
+	iter, err := ctx.GetStub().GetStateByRange("PENALTY_"+tenderID+"_", "PENALTY_"+tenderID+"_~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var out []*PenaltyRecord
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var p PenaltyRecord
+		if err := json.Unmarshal(kv.Value, &p); err == nil {
+			out = append(out, &p)
+		}
+	}
+	return out, nil
+}
+
+// closeContract moves an awarded tender to a terminal contract status
+func (s *EnhancedSmartContract) closeContract(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, status, eventName, reason string) error {
+	if tender.Status != "AWARDED" {
+		return fmt.Errorf("only awarded tenders can be closed out")
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	tender.Status = status
+	tender.UpdatedAt = txTime.Format(time.RFC3339)
+	tender.ClosedOutAt = tender.UpdatedAt
+	if err := putTender(ctx, tender); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, eventName, tender.ID, events.TenderStatusPayload{
+		TenderID: tender.ID,
+		Status:   status,
+		At:       tender.UpdatedAt,
+		Reason:   reason,
+	})
+}
+
+// RecordContractCompletion closes out an awarded contract as completed; tender owner only
+func (s *EnhancedSmartContract) RecordContractCompletion(ctx contractapi.TransactionContextInterface, tenderID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if err := requireOwner(ctx, tender, "complete the contract of"); err != nil {
+		return err
+	}
+	contractors, err := awardedContractors(ctx, tender)
+	if err != nil {
+		return err
+	}
+	if err := s.closeContract(ctx, tender, "COMPLETED", events.ContractCompleted, ""); err != nil {
+		return err
+	}
+	return updateEachPerformance(ctx, contractors, func(p *ContractorPerformance) {
+		p.ContractsCompleted++
+	})
+}
+
+// TerminateContract ends an awarded contract early; tender owner only
+func (s *EnhancedSmartContract) TerminateContract(ctx contractapi.TransactionContextInterface, tenderID, reason string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if err := requireOwner(ctx, tender, "terminate the contract of"); err != nil {
+		return err
+	}
+	contractors, err := awardedContractors(ctx, tender)
+	if err != nil {
+		return err
+	}
+	if err := s.closeContract(ctx, tender, "TERMINATED", events.ContractTerminated, reason); err != nil {
+		return err
+	}
+	return updateEachPerformance(ctx, contractors, func(p *ContractorPerformance) {
+		p.Terminations++
+	})
+}
+
+// RateContractor records the buyer's rating from 1 to 5; only the tender owner rates,
+// once per tender
+func (s *EnhancedSmartContract) RateContractor(ctx contractapi.TransactionContextInterface, tenderID string, rating int, comment string) error {
+	if rating < 1 || rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5")
+	}
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if err := requireOwner(ctx, tender, "rate the contractor of"); err != nil {
+		return err
+	}
+	contractorID, err := awardedContractor(ctx, tender)
+	if err != nil {
+		return err
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	perf, err := loadPerformance(ctx, contractorID)
+	if err != nil {
+		return err
+	}
+	for _, r := range perf.Ratings {
+		if r.TenderID == tenderID && r.RatedBy == mspID {
+			return fmt.Errorf("%s has already rated tender %s", mspID, tenderID)
+		}
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	entry := BuyerRating{
+		TenderID: tenderID,
+		Rating:   rating,
+		Comment:  comment,
+		RatedBy:  mspID,
+		RatedAt:  txTime.Format(time.RFC3339),
+	}
+	if err := updatePerformance(ctx, contractorID, func(p *ContractorPerformance) {
+		p.Ratings = append(p.Ratings, entry)
+	}); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.ContractorRated, tenderID, events.RatingPayload{
+		TenderID:     tenderID,
+		ContractorID: contractorID,
+		Rating:       entry.Rating,
+		Comment:      entry.Comment,
+		RatedBy:      entry.RatedBy,
+		RatedAt:      entry.RatedAt,
+	})
+}
+
+// DebarContractor adds or replaces a contractor's debarment; regulators only
+func (s *EnhancedSmartContract) DebarContractor(ctx contractapi.TransactionContextInterface, contractorID, reason, startDate, endDate string) error {
+	if err := requireRole(ctx, roleRegulator); err != nil {
+		return err
+	}
+	if contractorID == "" || reason == "" {
+		return fmt.Errorf("contractor ID and reason are required")
+	}
+	start, err := time.Parse(time.RFC3339, startDate)
+	if err != nil {
+		return fmt.Errorf("invalid start date: %v", err)
+	}
+	if endDate != "" {
+		end, err := time.Parse(time.RFC3339, endDate)
+		if err != nil {
+			return fmt.Errorf("invalid end date: %v", err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("end date must be after start date")
+		}
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	entry := DebarmentEntry{
+		ContractorID: contractorID,
+		Reason:       reason,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		DebarredBy:   mspID,
+		CreatedAt:    txTime.Format(time.RFC3339),
+	}
+	bytes, _ := json.Marshal(entry)
+	if err := ctx.GetStub().PutState(debarmentKey(contractorID), bytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.ContractorDebarred, "", debarmentPayload(&entry))
+}
+
+// LiftDebarment ends a debarment early; regulators only
+func (s *EnhancedSmartContract) LiftDebarment(ctx contractapi.TransactionContextInterface, contractorID, reason string) error {
+	if err := requireRole(ctx, roleRegulator); err != nil {
+		return err
+	}
+	entry, err := s.GetDebarment(ctx, contractorID)
+	if err != nil {
+		return err
+	}
+	if entry.LiftedAt != "" {
+		return fmt.Errorf("debarment of %s already lifted", contractorID)
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	entry.LiftedAt = txTime.Format(time.RFC3339)
+	entry.LiftReason = reason
+	bytes, _ := json.Marshal(entry)
+	if err := ctx.GetStub().PutState(debarmentKey(contractorID), bytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.DebarmentLifted, "", debarmentPayload(entry))
+}
+
+// GetDebarment returns a contractor's debarment entry
+func (s *EnhancedSmartContract) GetDebarment(ctx contractapi.TransactionContextInterface, contractorID string) (*DebarmentEntry, error) {
+	data, err := ctx.GetStub().GetState(debarmentKey(contractorID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no debarment found for %s", contractorID)
+	}
+	var entry DebarmentEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListDebarments returns the full debarment list, including expired and lifted entries
+func (s *EnhancedSmartContract) ListDebarments(ctx contractapi.TransactionContextInterface) ([]*DebarmentEntry, error) {
+	iter, err := ctx.GetStub().GetStateByRange("DEBAR_", "DEBAR_~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var out []*DebarmentEntry
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var e DebarmentEntry
+		if err := json.Unmarshal(kv.Value, &e); err == nil {
+			out = append(out, &e)
+		}
+	}
+	return out, nil
+}
+
+// debarmentActiveAt reports whether the debarment applies at the given time
+func debarmentActiveAt(e *DebarmentEntry, now time.Time) bool {
+	if e.LiftedAt != "" {
+		return false
+	}
+	start, err := time.Parse(time.RFC3339, e.StartDate)
+	if err != nil || now.Before(start) {
+		return false
+	}
+	if e.EndDate == "" {
+		return true
+	}
+	end, err := time.Parse(time.RFC3339, e.EndDate)
+	return err == nil && now.Before(end)
+}
+
+// IsContractorDebarred reports whether a contractor is debarred at transaction time
+func (s *EnhancedSmartContract) IsContractorDebarred(ctx contractapi.TransactionContextInterface, contractorID string) (bool, error) {
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return false, err
+	}
+	entry, err := activeDebarment(ctx, contractorID, txTime)
+	if err != nil {
+		return false, err
+	}
+	return entry != nil, nil
+}
+
+// activeDebarment returns the contractor's debarment if it applies at the given time
+func activeDebarment(ctx contractapi.TransactionContextInterface, contractorID string, now time.Time) (*DebarmentEntry, error) {
+	data, err := ctx.GetStub().GetState(debarmentKey(contractorID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+	var entry DebarmentEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if !debarmentActiveAt(&entry, now) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// checkNotDebarred rejects contractors with an active debarment
+func (s *EnhancedSmartContract) checkNotDebarred(ctx contractapi.TransactionContextInterface, contractorID string, now time.Time) error {
+	entry, err := activeDebarment(ctx, contractorID, now)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		until := "indefinitely"
+		if entry.EndDate != "" {
+			until = "until " + entry.EndDate
+		}
+		return fmt.Errorf("contractor %s is debarred %s: %s", contractorID, until, entry.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,432 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+func (n *testNet) performance(contractorID string) *ContractorPerformance {
+	n.t.Helper()
+	var perf *ContractorPerformance
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		var err error
+		perf, err = n.enh.GetContractorPerformance(ctx, contractorID)
+		return err
+	})
+	return perf
+}
+
+func TestRecordPenalty(t *testing.T) {
+	tests := []struct {
+		name     string
+		who      string
+		tenderID string
+		id       string
+		amount   float64
+		wantErr  string
+	}{
+		{"valid", "buyer", "T1", "P2", 1500, ""},
+		{"regulator", "regulator", "T1", "P2", 1500, ""},
+		{"zero amount", "buyer", "T1", "P2", 0, "penalty amount must be positive"},
+		{"duplicate", "buyer", "T1", "P1", 10, "penalty P1 already recorded for tender T1"},
+		{"not awarded", "buyer", "T2", "P1", 10, "tender T2 has no awarded bid"},
+		{"unknown tender", "buyer", "T9", "P1", 10, "tender T9 not found"},
+		{"contractor", "contractorA", "T1", "P2", 1500, "only the tender owner or the regulator role may record penalties on tender T1"},
+		{"other org", "auditor", "T1", "P2", 1500, "only the tender owner or the regulator role may record penalties on tender T1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.awardedTender("T1")
+			n.createTender(tenderFixture("T2", n.ledger.Now().Add(time.Hour)))
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.RecordPenalty(ctx, "T1", "P1", "DELAY", 500, "late start")
+			})
+			err := n.tx(tc.who, nil, func(ctx *TransactionContext) error {
+				return n.enh.RecordPenalty(ctx, tc.tenderID, tc.id, "QUALITY", tc.amount, "rework")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.PenaltyRecorded)
+			perf := n.performance("contractorA")
+			if perf.PenaltiesCount != 2 || perf.PenaltiesTotal != 2000 {
+				t.Fatalf("performance = %+v", perf)
+			}
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				penalties, err := n.enh.ListPenalties(ctx, "T1")
+				if err == nil && (len(penalties) != 2 || penalties[1].ContractorID != "contractorA") {
+					t.Fatalf("penalties = %+v", penalties)
+				}
+				return err
+			})
+		})
+	}
+}
+
+func TestContractCloseOut(t *testing.T) {
+	tests := []struct {
+		name       string
+		who        string
+		terminate  bool
+		tenderID   string
+		wantStatus string
+		wantEvent  string
+		wantErr    string
+	}{
+		{"complete", "buyer", false, "T1", "COMPLETED", events.ContractCompleted, ""},
+		{"terminate", "buyer", true, "T1", "TERMINATED", events.ContractTerminated, ""},
+		{"complete unawarded", "buyer", false, "T2", "", "", "tender T2 has no awarded bid"},
+		{"terminate unawarded", "buyer", true, "T2", "", "", "tender T2 has no awarded bid"},
+		{"complete by contractor", "contractorA", false, "T1", "", "", "only the tender owner may complete the contract of tender T1"},
+		{"terminate by other org", "contractorB", true, "T1", "", "", "only the tender owner may terminate the contract of tender T1"},
+		{"terminate by regulator", "regulator", true, "T1", "", "", "only the tender owner may terminate the contract of tender T1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.awardedTender("T1")
+			n.createTender(tenderFixture("T2", n.ledger.Now().Add(time.Hour)))
+			closeOut := func() error {
+				return n.tx(tc.who, nil, func(ctx *TransactionContext) error {
+					if tc.terminate {
+						return n.enh.TerminateContract(ctx, tc.tenderID, "insolvency")
+					}
+					return n.enh.RecordContractCompletion(ctx, tc.tenderID)
+				})
+			}
+			expectErr(t, closeOut(), tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(tc.wantEvent)
+			got := n.tender("T1")
+			if got.Status != tc.wantStatus || got.ClosedOutAt != rfc(n.ledger.Now()) {
+				t.Fatalf("tender = %+v", got)
+			}
+			perf := n.performance("contractorA")
+			if perf.ContractsCompleted+perf.Terminations != 1 {
+				t.Fatalf("performance = %+v", perf)
+			}
+			expectErr(t, closeOut(), "only awarded tenders can be closed out")
+		})
+	}
+}
+
+func TestRateContractor(t *testing.T) {
+	tests := []struct {
+		name    string
+		who     string
+		rating  int
+		wantErr string
+	}{
+		{"valid", "buyer", 4, ""},
+		{"lowest", "buyer", 1, ""},
+		{"zero", "buyer", 0, "rating must be between 1 and 5"},
+		{"six", "buyer", 6, "rating must be between 1 and 5"},
+		{"contractor rates itself", "contractorA", 5, "only the tender owner may rate the contractor of tender T1"},
+		{"other org", "auditor", 4, "only the tender owner may rate the contractor of tender T1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.awardedTender("T1")
+			rate := func(who string) error {
+				return n.tx(who, nil, func(ctx *TransactionContext) error {
+					return n.enh.RateContractor(ctx, "T1", tc.rating, "")
+				})
+			}
+			expectErr(t, rate(tc.who), tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.ContractorRated)
+			perf := n.performance("contractorA")
+			if len(perf.Ratings) != 1 || perf.AverageRating != float64(tc.rating) {
+				t.Fatalf("performance = %+v", perf)
+			}
+			expectErr(t, rate("buyer2"), "BuyerMSP has already rated tender T1")
+		})
+	}
+}
+
+func TestMilestoneOutcomeUpdatesPerformance(t *testing.T) {
+	n := newTestNet(t)
+	tender := n.awardedTender("T1")
+	due, _ := time.Parse(time.RFC3339, tender.Deadlines.MilestoneDeadlines[0].Deadline)
+
+	milestones := []struct {
+		id       string
+		title    string
+		at       time.Time
+		approve  bool
+		onTime   int
+		late     int
+		rejected int
+	}{
+		{"M1", "Base course", due, true, 1, 0, 0},
+		{"M2", "Base course", due.Add(time.Second), true, 1, 1, 0},
+		{"M3", "Unscheduled", due.Add(time.Hour), true, 2, 1, 0},
+		{"M4", "Base course", due, false, 2, 1, 1},
+	}
+	for _, m := range milestones {
+		n.ledger.SetTime(m.at)
+		ms := MilestonePrivate{TenderID: "T1", MilestoneID: m.id, Title: m.title}
+		n.mustTx("contractorA", transientOf(t, "milestone", ms), func(ctx *TransactionContext) error {
+			return n.basic.SubmitMilestone(ctx, "T1", m.id)
+		})
+		n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+			if m.approve {
+				return n.basic.ApproveMilestone(ctx, "T1", m.id)
+			}
+			return n.basic.RejectMilestone(ctx, "T1", m.id, "defects")
+		})
+		perf := n.performance("contractorA")
+		if perf.MilestonesOnTime != m.onTime || perf.MilestonesLate != m.late || perf.MilestonesRejected != m.rejected {
+			t.Fatalf("after %s performance = %+v", m.id, perf)
+		}
+	}
+	if perf := n.performance("contractorA"); perf.OnTimeRate != 200.0/3 || perf.RejectionRate != 25 {
+		t.Fatalf("rates = %+v", perf)
+	}
+	// A decided milestone cannot be decided again, so it is counted once
+	err := n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.ApproveMilestone(ctx, "T1", "M4") })
+	expectErr(t, err, "milestone M4 is already REJECTED")
+	if perf := n.performance("contractorA"); perf.MilestonesApproved != 3 || perf.MilestonesRejected != 1 {
+		t.Fatalf("performance after repeat = %+v", perf)
+	}
+	// Contractors without a record read back as empty
+	if perf := n.performance("nobody"); perf.ContractorID != "nobody" || perf.MilestonesApproved != 0 {
+		t.Fatalf("empty performance = %+v", perf)
+	}
+}
+
+func TestDebarContractor(t *testing.T) {
+	tests := []struct {
+		name       string
+		who        string
+		contractor string
+		reason     string
+		start      string
+		end        string
+		wantErr    string
+	}{
+		{"indefinite", "regulator", "contractorA", "fraud", rfc(t0), "", ""},
+		{"fixed term", "regulator", "contractorA", "fraud", rfc(t0), rfc(t0.AddDate(1, 0, 0)), ""},
+		{"without role", "buyer", "contractorA", "fraud", rfc(t0), "", "caller must have the regulator role"},
+		{"approver role", "approver1", "contractorA", "fraud", rfc(t0), "", "caller must have the regulator role"},
+		{"no contractor", "regulator", "", "fraud", rfc(t0), "", "contractor ID and reason are required"},
+		{"no reason", "regulator", "contractorA", "", rfc(t0), "", "contractor ID and reason are required"},
+		{"bad start", "regulator", "contractorA", "fraud", "today", "", "invalid start date"},
+		{"bad end", "regulator", "contractorA", "fraud", rfc(t0), "never", "invalid end date"},
+		{"end equals start", "regulator", "contractorA", "fraud", rfc(t0), rfc(t0), "end date must be after start date"},
+		{"end before start", "regulator", "contractorA", "fraud", rfc(t0), rfc(t0.Add(-time.Hour)), "end date must be after start date"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			err := n.tx(tc.who, nil, func(ctx *TransactionContext) error {
+				return n.enh.DebarContractor(ctx, tc.contractor, tc.reason, tc.start, tc.end)
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			envs := n.expectEvents(events.ContractorDebarred)
+			if envs[0].TenderID != "" || envs[0].Actor.MSPID != "RegulatorMSP" {
+				t.Fatalf("envelope = %+v", envs[0])
+			}
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				entry, err := n.enh.GetDebarment(ctx, tc.contractor)
+				if err == nil && (entry.DebarredBy != "RegulatorMSP" || entry.EndDate != tc.end) {
+					t.Fatalf("entry = %+v", entry)
+				}
+				return err
+			})
+		})
+	}
+}
+
+func TestDebarmentWindow(t *testing.T) {
+	start := t0.Add(24 * time.Hour)
+	end := start.Add(30 * 24 * time.Hour)
+	tests := []struct {
+		name   string
+		end    string
+		lifted bool
+		at     time.Time
+		want   bool
+	}{
+		{"before start", rfc(end), false, start.Add(-time.Second), false},
+		{"at start", rfc(end), false, start, true},
+		{"during", rfc(end), false, start.Add(time.Hour), true},
+		{"one second before end", rfc(end), false, end.Add(-time.Second), true},
+		{"at end", rfc(end), false, end, false},
+		{"indefinite", "", false, end.AddDate(5, 0, 0), true},
+		{"lifted", "", true, start.Add(time.Hour), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.mustTx("regulator", nil, func(ctx *TransactionContext) error {
+				return n.enh.DebarContractor(ctx, "contractorA", "collusion", rfc(start), tc.end)
+			})
+			if tc.lifted {
+				n.mustTx("regulator", nil, func(ctx *TransactionContext) error {
+					return n.enh.LiftDebarment(ctx, "contractorA", "appeal upheld")
+				})
+			}
+			n.ledger.SetTime(tc.at)
+			var got bool
+			n.mustQuery("buyer", func(ctx *TransactionContext) error {
+				var err error
+				got, err = n.enh.IsContractorDebarred(ctx, "contractorA")
+				return err
+			})
+			if got != tc.want {
+				t.Fatalf("debarred = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	n := newTestNet(t)
+	n.mustQuery("buyer", func(ctx *TransactionContext) error {
+		got, err := n.enh.IsContractorDebarred(ctx, "contractorA")
+		if err == nil && got {
+			t.Fatalf("contractor without an entry reported debarred")
+		}
+		return err
+	})
+}
+
+func TestLiftDebarment(t *testing.T) {
+	tests := []struct {
+		name       string
+		who        string
+		contractor string
+		twice      bool
+		wantErr    string
+	}{
+		{"regulator", "regulator", "contractorA", false, ""},
+		{"without role", "buyer", "contractorA", false, "caller must have the regulator role"},
+		{"no entry", "regulator", "contractorB", false, "no debarment found for contractorB"},
+		{"already lifted", "regulator", "contractorA", true, "debarment of contractorA already lifted"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.mustTx("regulator", nil, func(ctx *TransactionContext) error {
+				return n.enh.DebarContractor(ctx, "contractorA", "fraud", rfc(t0), "")
+			})
+			lift := func() error {
+				return n.tx(tc.who, nil, func(ctx *TransactionContext) error {
+					return n.enh.LiftDebarment(ctx, tc.contractor, "cleared")
+				})
+			}
+			if tc.twice {
+				if err := lift(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expectErr(t, lift(), tc.wantErr)
+			if tc.wantErr == "" {
+				n.expectEvents(events.DebarmentLifted)
+			}
+		})
+	}
+}
+
+func TestListDebarments(t *testing.T) {
+	n := newTestNet(t)
+	for _, id := range []string{"contractorB", "contractorA"} {
+		n.mustTx("regulator", nil, func(ctx *TransactionContext) error {
+			return n.enh.DebarContractor(ctx, id, "fraud", rfc(t0), rfc(t0.Add(time.Hour)))
+		})
+	}
+	n.mustTx("regulator", nil, func(ctx *TransactionContext) error {
+		return n.enh.LiftDebarment(ctx, "contractorB", "cleared")
+	})
+	// Expired and lifted entries stay on the list
+	n.ledger.Advance(48 * time.Hour)
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		list, err := n.enh.ListDebarments(ctx)
+		if err == nil && (len(list) != 2 || list[0].ContractorID != "contractorA" || list[1].LiftedAt == "") {
+			t.Fatalf("debarments = %+v", list)
+		}
+		return err
+	})
+}
+
+func TestPerformanceOfAwardedContractors(t *testing.T) {
+	tests := []struct {
+		name     string
+		tenderID string
+		award    func(n *testNet)
+		want     []string
+	}{
+		{"reverse auction", "A1", func(n *testNet) {
+			n.openTender(auctionFixture("A1"))
+			n.ledger.SetTime(auctionStart)
+			for _, o := range []struct {
+				who, bidID string
+				amount     float64
+			}{{"contractorA", "B1", 100000}, {"contractorB", "B2", 105000}} {
+				if err := n.placeOffer(o.who, "A1", o.bidID, o.amount); err != nil {
+					n.t.Fatal(err)
+				}
+			}
+			n.ledger.SetTime(auctionEnd)
+			n.closeTender("A1")
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardBestBid(ctx, "A1") })
+		}, []string{"contractorA"}},
+		{"lots", "LT", func(n *testNet) {
+			n.lotsTender("")
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "LT") })
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+				_, err := n.enh.AwardLots(ctx, "LT")
+				return err
+			})
+		}, []string{"contractorB", "contractorC"}},
+		{"framework", "T1", func(n *testNet) {
+			n.frameworkTender()
+			if err := n.createFramework(frameworkFixture()); err != nil {
+				n.t.Fatal(err)
+			}
+		}, []string{"contractorA", "contractorB"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tc.award(n)
+
+			ms := MilestonePrivate{TenderID: tc.tenderID, MilestoneID: "M1", Title: "Base course"}
+			n.mustTx(tc.want[0], transientOf(t, "milestone", ms), func(ctx *TransactionContext) error {
+				return n.basic.SubmitMilestone(ctx, tc.tenderID, "M1")
+			})
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.basic.ApproveMilestone(ctx, tc.tenderID, "M1") })
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.RecordPenalty(ctx, tc.tenderID, "P1", "DELAY", 500, "late start")
+			})
+			if len(tc.want) > 1 {
+				expectErr(t, err, "was awarded to 2 contractors")
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.RecordContractCompletion(ctx, tc.tenderID) })
+
+			penalties := 0
+			if len(tc.want) == 1 {
+				penalties = 1
+			}
+			for _, id := range tc.want {
+				perf := n.performance(id)
+				if perf.MilestonesApproved != 1 || perf.ContractsCompleted != 1 || perf.PenaltiesCount != penalties {
+					t.Fatalf("performance of %s = %+v", id, perf)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// Procurement methods supported on EnhancedTender.ProcurementMethod
+const (
+	procurementOpen         = "OPEN"
+	procurementRestricted   = "RESTRICTED"
+	procurementInvited      = "INVITED"
+	procurementSingleSource = "SINGLE_SOURCE"
+)
+
+// roleAwardApprover is the client certificate "role" attribute allowed to approve single-source awards
+const roleAwardApprover = "approver"
+
+// defaultSingleSourceApprovals applies when a single-source tender does not set RequiredApprovals
+const defaultSingleSourceApprovals = 2
+
+func procurementMethod(tender *EnhancedTender) string {
+	if tender.ProcurementMethod == "" {
+		return procurementOpen
+	}
+	return tender.ProcurementMethod
+}
+
+// validateProcurementMethod checks the method and invitee list of a tender
+func validateProcurementMethod(tender *EnhancedTender) error {
+	switch procurementMethod(tender) {
+	case procurementOpen:
+		if len(tender.Invitees) > 0 {
+			return fmt.Errorf("open tenders cannot have an invitee list")
+		}
+	case procurementRestricted, procurementInvited:
+		if len(tender.Invitees) == 0 {
+			return fmt.Errorf("%s tenders need at least one invitee", tender.ProcurementMethod)
+		}
+	case procurementSingleSource:
+		if len(tender.Invitees) != 1 {
+			return fmt.Errorf("single-source tenders must name exactly one invitee")
+		}
+		if tender.RequiredApprovals < 0 {
+			return fmt.Errorf("required approvals must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown procurement method %s", tender.ProcurementMethod)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range tender.Invitees {
+		if id == "" {
+			return fmt.Errorf("invitee IDs must not be empty")
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate invitee %s", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// checkInvited rejects bids on non-open tenders from anyone outside the invitee list.
+// An invitee entry may be a vendor/contractor ID or the MSP ID of the submitting org.
+func checkInvited(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, contractorID string) error {
+	if procurementMethod(tender) == procurementOpen {
+		return nil
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range tender.Invitees {
+		if id == contractorID || id == mspID {
+			return nil
+		}
+	}
+	return fmt.Errorf("contractor %s is not invited to %s tender %s", contractorID, tender.ProcurementMethod, tender.ID)
+}
+
+// requireOwner lets only the organization that created the tender perform action on it
+func requireOwner(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, action string) error {
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if tender.OwnerMSP == "" || mspID != tender.OwnerMSP {
+		return fmt.Errorf("only the tender owner may %s tender %s", action, tender.ID)
+	}
+	return nil
+}
+
+// AddInvitees extends the invitee list of a restricted or invited tender before it closes
+func (s *EnhancedSmartContract) AddInvitees(ctx contractapi.TransactionContextInterface, tenderID, inviteesJSON string) error {
+	var invitees []string
+	if err := json.Unmarshal([]byte(inviteesJSON), &invitees); err != nil {
+		return fmt.Errorf("invalid invitees JSON: %v", err)
+	}
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if err := requireOwner(ctx, tender, "add invitees to"); err != nil {
+		return err
+	}
+	if tender.Status != "DRAFT" && tender.Status != "OPEN" {
+		return fmt.Errorf("invitees can only be added to draft or open tenders")
+	}
+	method := procurementMethod(tender)
+	if method != procurementRestricted && method != procurementInvited {
+		return fmt.Errorf("invitees can only be added to restricted or invited tenders")
+	}
+
+	tender.Invitees = append(tender.Invitees, invitees...)
+	if err := validateProcurementMethod(tender); err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	tender.UpdatedAt = txTime.Format(time.RFC3339)
+	if err := putTender(ctx, tender); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.InviteesAdded, tenderID, events.InviteesPayload{TenderID: tenderID, Added: len(invitees), Total: len(tender.Invitees)})
+}
+
+// RecordSingleSourceJustification stores the justification required before a single-source award
+func (s *EnhancedSmartContract) RecordSingleSourceJustification(ctx contractapi.TransactionContextInterface, tenderID, reason, details, documentHash string) error {
+	if reason == "" || details == "" {
+		return fmt.Errorf("justification reason and details are required")
+	}
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if procurementMethod(tender) != procurementSingleSource {
+		return fmt.Errorf("tender %s is not single-source", tenderID)
+	}
+	if err := requireOwner(ctx, tender, "justify"); err != nil {
+		return err
+	}
+	if tender.Status == "AWARDED" {
+		return fmt.Errorf("tender already awarded")
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// A new justification invalidates approvals given for the previous one
+	tender.Justification = &SingleSourceJustification{
+		Reason:       reason,
+		Details:      details,
+		DocumentHash: documentHash,
+		RecordedBy:   mspID,
+		RecordedAt:   txTime.Format(time.RFC3339),
+	}
+	tender.AwardApprovals = nil
+	tender.UpdatedAt = txTime.Format(time.RFC3339)
+	if err := putTender(ctx, tender); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.SingleSourceJustified, tenderID, events.SingleSourcePayload{TenderID: tenderID, Reason: reason})
+}
+
+// ApproveSingleSourceAward adds an approver's sign-off; each identity approves once
+func (s *EnhancedSmartContract) ApproveSingleSourceAward(ctx contractapi.TransactionContextInterface, tenderID, comment string) error {
+	if err := requireRole(ctx, roleAwardApprover); err != nil {
+		return err
+	}
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if procurementMethod(tender) != procurementSingleSource {
+		return fmt.Errorf("tender %s is not single-source", tenderID)
+	}
+	if tender.Justification == nil {
+		return fmt.Errorf("a justification must be recorded before approval")
+	}
+	if tender.Status == "AWARDED" {
+		return fmt.Errorf("tender already awarded")
+	}
+	approverID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	for _, a := range tender.AwardApprovals {
+		if a.ApproverID == approverID {
+			return fmt.Errorf("approver has already approved tender %s", tenderID)
+		}
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	tender.AwardApprovals = append(tender.AwardApprovals, AwardApproval{
+		ApproverID: approverID,
+		MSPID:      mspID,
+		Comment:    comment,
+		ApprovedAt: txTime.Format(time.RFC3339),
+	})
+	tender.UpdatedAt = txTime.Format(time.RFC3339)
+	if err := putTender(ctx, tender); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.SingleSourceAwardApproved, tenderID, events.AwardApprovalPayload{
+		TenderID:  tenderID,
+		Approvals: len(tender.AwardApprovals),
+		Required:  requiredApprovals(tender),
+	})
+}
+
+func requiredApprovals(tender *EnhancedTender) int {
+	if tender.RequiredApprovals > 0 {
+		return tender.RequiredApprovals
+	}
+	return defaultSingleSourceApprovals
+}
+
+// authorizeAward runs the checks shared by every award path: single-source justification
+// and approvals, the final reverse auction ranking, and the awarding officer's signature
+// over payload, recorded as the AWARD signature for refID
+func (s *EnhancedSmartContract) authorizeAward(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, refID string, payload []byte, bidIDs ...string) error {
+	// Single-source awards need a justification and extra approvals
+	if err := validateSingleSourceAward(tender); err != nil {
+		return err
+	}
+	// Reverse auctions are awarded from the final ranking
+	if isReverseAuction(tender) {
+		if err := s.validateAuctionAward(ctx, tender, bidIDs...); err != nil {
+			return err
+		}
+	}
+	_, err := applySignature(ctx, tender.ID, sigSubjectAward, refID, payload, ownerSigner(tender), signaturesRequired(tender))
+	return err
+}
+
+// validateSingleSourceAward checks justification and approvals before a single-source award
+func validateSingleSourceAward(tender *EnhancedTender) error {
+	if procurementMethod(tender) != procurementSingleSource {
+		return nil
+	}
+	if tender.Justification == nil {
+		return fmt.Errorf("single-source award requires a recorded justification")
+	}
+	if got, want := len(tender.AwardApprovals), requiredApprovals(tender); got < want {
+		return fmt.Errorf("single-source award requires %d approvals, has %d", want, got)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+func TestProcurementMethodValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		invitees []string
+		wantErr  string
+	}{
+		{"open", "", nil, ""},
+		{"explicit open", procurementOpen, nil, ""},
+		{"open with invitees", procurementOpen, []string{"contractorA"}, "open tenders cannot have an invitee list"},
+		{"restricted", procurementRestricted, []string{"contractorA", "ContractorBMSP"}, ""},
+		{"restricted without invitees", procurementRestricted, nil, "RESTRICTED tenders need at least one invitee"},
+		{"invited without invitees", procurementInvited, nil, "INVITED tenders need at least one invitee"},
+		{"single source", procurementSingleSource, []string{"contractorA"}, ""},
+		{"single source with two", procurementSingleSource, []string{"contractorA", "contractorB"}, "exactly one invitee"},
+		{"empty invitee", procurementInvited, []string{""}, "invitee IDs must not be empty"},
+		{"duplicate invitee", procurementInvited, []string{"contractorA", "contractorA"}, "duplicate invitee contractorA"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := tenderFixture("T1", t0.Add(time.Hour))
+			tender.ProcurementMethod = tc.method
+			tender.Invitees = tc.invitees
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateEnhancedTender(ctx, mustJSON(t, tender))
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestInvitedBidders(t *testing.T) {
+	tests := []struct {
+		name    string
+		who     string
+		wantErr string
+	}{
+		{"invited by contractor id", "contractorA", ""},
+		{"invited by msp", "contractorB", ""},
+		{"not invited", "contractorC", "contractor contractorC is not invited to RESTRICTED tender T1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := tenderFixture("T1", t0.Add(time.Hour))
+			tender.ProcurementMethod = procurementRestricted
+			tender.Invitees = []string{"contractorA", "ContractorBMSP"}
+			n.openTender(tender)
+			expectErr(t, n.submitBid(tc.who, bidFixture("T1", "B1", tc.who, 100)), tc.wantErr)
+		})
+	}
+}
+
+func TestAddInvitees(t *testing.T) {
+	tests := []struct {
+		name     string
+		who      string
+		method   string
+		close    bool
+		tenderID string
+		invitees string
+		wantErr  string
+	}{
+		{name: "restricted", method: procurementRestricted, tenderID: "T1", invitees: `["contractorC"]`},
+		{name: "invited", method: procurementInvited, tenderID: "T1", invitees: `["contractorC","contractorB"]`},
+		{name: "bad json", method: procurementRestricted, tenderID: "T1", invitees: `"contractorC"`, wantErr: "invalid invitees JSON"},
+		{name: "open tender", method: "", tenderID: "T1", invitees: `["contractorC"]`, wantErr: "invitees can only be added to restricted or invited tenders"},
+		{name: "single source", method: procurementSingleSource, tenderID: "T1", invitees: `["contractorC"]`, wantErr: "invitees can only be added to restricted or invited tenders"},
+		{name: "closed", method: procurementRestricted, close: true, tenderID: "T1", invitees: `["contractorC"]`, wantErr: "invitees can only be added to draft or open tenders"},
+		{name: "duplicate", method: procurementRestricted, tenderID: "T1", invitees: `["contractorA"]`, wantErr: "duplicate invitee contractorA"},
+		{name: "unknown tender", method: procurementRestricted, tenderID: "T9", invitees: `["contractorC"]`, wantErr: "tender T9 not found"},
+		{name: "same org", who: "buyer2", method: procurementRestricted, tenderID: "T1", invitees: `["contractorC"]`},
+		{name: "not the owner", who: "contractorC", method: procurementRestricted, tenderID: "T1", invitees: `["contractorC"]`, wantErr: "only the tender owner may add invitees to tender T1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := tenderFixture("T1", t0.Add(time.Hour))
+			tender.ProcurementMethod = tc.method
+			if tc.method != "" {
+				tender.Invitees = []string{"contractorA"}
+			}
+			n.openTender(tender)
+			if tc.close {
+				n.closeTender("T1")
+			}
+			who := tc.who
+			if who == "" {
+				who = "buyer"
+			}
+			err := n.tx(who, nil, func(ctx *TransactionContext) error {
+				return n.enh.AddInvitees(ctx, tc.tenderID, tc.invitees)
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.InviteesAdded)
+			// The new invitee can now bid
+			n.mustSubmitBid("contractorC", bidFixture("T1", "B1", "contractorC", 100))
+		})
+	}
+}
+
+// singleSourceTender opens and closes a single-source tender with one bid from contractorA
+func (n *testNet) singleSourceTender(tenderID string, approvals int) {
+	n.t.Helper()
+	tender := tenderFixture(tenderID, t0.Add(time.Hour))
+	tender.ProcurementMethod = procurementSingleSource
+	tender.Invitees = []string{"contractorA"}
+	tender.RequiredApprovals = approvals
+	n.openTender(tender)
+	n.mustSubmitBid("contractorA", bidFixture(tenderID, "B1", "contractorA", 100))
+	n.closeTender(tenderID)
+}
+
+func TestRecordSingleSourceJustification(t *testing.T) {
+	tests := []struct {
+		name     string
+		who      string
+		tenderID string
+		reason   string
+		details  string
+		wantErr  string
+	}{
+		{"valid", "buyer", "SS", "EMERGENCY", "Flood damage", ""},
+		{"no reason", "buyer", "SS", "", "Flood damage", "justification reason and details are required"},
+		{"no details", "buyer", "SS", "EMERGENCY", "", "justification reason and details are required"},
+		{"open tender", "buyer", "OPEN", "EMERGENCY", "Flood damage", "tender OPEN is not single-source"},
+		{"unknown tender", "buyer", "T9", "EMERGENCY", "Flood damage", "tender T9 not found"},
+		{"not the owner", "contractorA", "SS", "EMERGENCY", "Flood damage", "only the tender owner may justify tender SS"},
+		{"regulator", "regulator", "SS", "EMERGENCY", "Flood damage", "only the tender owner may justify tender SS"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.singleSourceTender("SS", 0)
+			n.createTender(tenderFixture("OPEN", t0.Add(time.Hour)))
+			err := n.tx(tc.who, nil, func(ctx *TransactionContext) error {
+				return n.enh.RecordSingleSourceJustification(ctx, tc.tenderID, tc.reason, tc.details, "doc-hash")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.SingleSourceJustified)
+			got := n.tender("SS")
+			if got.Justification == nil || got.Justification.RecordedBy != "BuyerMSP" {
+				t.Fatalf("justification = %+v", got.Justification)
+			}
+		})
+	}
+}
+
+func TestSingleSourceApprovalAndAward(t *testing.T) {
+	justify := func(n *testNet) {
+		n.mustTx("buyer", nil, func(ctx *TransactionContext) error {
+			return n.enh.RecordSingleSourceJustification(ctx, "SS", "SOLE_SUPPLIER", "Patented process", "")
+		})
+	}
+	approve := func(n *testNet, who string) error {
+		return n.tx(who, nil, func(ctx *TransactionContext) error {
+			return n.enh.ApproveSingleSourceAward(ctx, "SS", "ok")
+		})
+	}
+	award := func(n *testNet) error {
+		return n.tx("buyer", nil, func(ctx *TransactionContext) error {
+			return n.enh.AwardTender(ctx, "SS", "B1")
+		})
+	}
+
+	t.Run("approve", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			justified bool
+			approvers []string
+			wantErr   string
+		}{
+			{"first approval", true, []string{"approver1"}, ""},
+			{"second approver", true, []string{"approver1", "approver2"}, ""},
+			{"without role", true, []string{"buyer"}, "caller must have the approver role"},
+			{"without justification", false, []string{"approver1"}, "a justification must be recorded before approval"},
+			{"same approver twice", true, []string{"approver1", "approver1"}, "approver has already approved tender SS"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				n := newTestNet(t)
+				n.singleSourceTender("SS", 0)
+				if tc.justified {
+					justify(n)
+				}
+				var err error
+				for _, who := range tc.approvers {
+					if err = approve(n, who); err != nil {
+						break
+					}
+				}
+				expectErr(t, err, tc.wantErr)
+				if tc.wantErr == "" {
+					n.expectEvents(events.SingleSourceAwardApproved)
+				}
+			})
+		}
+	})
+
+	t.Run("award", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			required  int
+			justified bool
+			approvers []string
+			rejustify bool
+			wantErr   string
+		}{
+			{"default two approvals", 0, true, []string{"approver1", "approver2"}, false, ""},
+			{"one of two approvals", 0, true, []string{"approver1"}, false, "requires 2 approvals, has 1"},
+			{"custom one approval", 1, true, []string{"approver1"}, false, ""},
+			{"no justification", 1, false, nil, false, "single-source award requires a recorded justification"},
+			{"new justification resets approvals", 0, true, []string{"approver1", "approver2"}, true, "requires 2 approvals, has 0"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				n := newTestNet(t)
+				n.singleSourceTender("SS", tc.required)
+				if tc.justified {
+					justify(n)
+				}
+				for _, who := range tc.approvers {
+					if err := approve(n, who); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if tc.rejustify {
+					justify(n)
+				}
+				expectErr(t, award(n), tc.wantErr)
+			})
+		}
+	})
+
+	// Lots and frameworks are awards too
+	t.Run("other award paths", func(t *testing.T) {
+		n := newTestNet(t)
+		n.singleSourceTender("SS", 1)
+		req := frameworkFixture()
+		req.TenderID, req.BidIDs = "SS", []string{"B1"}
+		expectErr(t, n.createFramework(req), "single-source award requires a recorded justification")
+		justify(n)
+		expectErr(t, n.createFramework(req), "single-source award requires 1 approvals, has 0")
+		if err := approve(n, "approver1"); err != nil {
+			t.Fatal(err)
+		}
+		expectErr(t, n.createFramework(req), "")
+	})
+
+	t.Run("after award", func(t *testing.T) {
+		n := newTestNet(t)
+		n.singleSourceTender("SS", 1)
+		justify(n)
+		if err := approve(n, "approver1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := award(n); err != nil {
+			t.Fatal(err)
+		}
+		expectErr(t, approve(n, "approver2"), "tender already awarded")
+		err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+			return n.enh.RecordSingleSourceJustification(ctx, "SS", "late", "late", "")
+		})
+		expectErr(t, err, "tender already awarded")
+	})
+}
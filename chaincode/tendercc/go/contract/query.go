@@ -0,0 +1,400 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Document types stored on records that can be searched with rich queries
+const (
+	docTypeTender = "tender"
+	docTypeBidRef = "bidRef"
+)
+
+// Composite-key indexes kept for state databases without rich query support (LevelDB)
+const (
+	idxTenderStatus   = "tender~status"
+	idxTenderCountry  = "tender~country"
+	idxTenderSector   = "tender~sector"
+	idxTenderStandard = "tender~standard"
+	idxBidContractor  = "bid~contractor"
+)
+
+const (
+	defaultQueryPageSize = 20
+	maxQueryPageSize     = 200
+)
+
+func pageSize(n int32) int32 {
+	if n <= 0 {
+		return defaultQueryPageSize
+	}
+	if n > maxQueryPageSize {
+		return maxQueryPageSize
+	}
+	return n
+}
+
+// richQueryUnsupported reports whether the state database rejected a rich query (LevelDB)
+func richQueryUnsupported(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "not supported")
+}
+
+// putTender stores an enhanced tender and keeps its composite-key indexes in step
+func putTender(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) error {
+	stub := ctx.GetStub()
+	tender.DocType = docTypeTender
+
+	oldBytes, err := stub.GetState(tenderKey(tender.ID))
+	if err != nil {
+		return err
+	}
+	if oldBytes != nil {
+		var old EnhancedTender
+		if err := json.Unmarshal(oldBytes, &old); err == nil {
+			for _, key := range tenderIndexKeys(stub, &old) {
+				if err := stub.DelState(key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, key := range tenderIndexKeys(stub, tender) {
+		if err := stub.PutState(key, []byte{0x00}); err != nil {
+			return err
+		}
+	}
+
+	bytes, _ := json.Marshal(tender)
+	return stub.PutState(tenderKey(tender.ID), bytes)
+}
+
+func tenderIndexKeys(stub shim.ChaincodeStubInterface, tender *EnhancedTender) []string {
+	var keys []string
+	add := func(index, value string) {
+		if value == "" {
+			return
+		}
+		if key, err := stub.CreateCompositeKey(index, []string{value, tender.ID}); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	add(idxTenderStatus, tender.Status)
+	add(idxTenderCountry, tender.OwnerDetails.Address.Country)
+	for _, sector := range tender.BidRequirements.ExperienceRequirements.RelevantSectors {
+		add(idxTenderSector, sector)
+	}
+	for _, req := range tender.ComplianceReqs {
+		add(idxTenderStandard, req.Standard)
+	}
+	return keys
+}
+
+// indexBidRef adds the contractor index entry for a new bid reference
+func indexBidRef(ctx contractapi.TransactionContextInterface, ref *BidRef) error {
+	key, err := ctx.GetStub().CreateCompositeKey(idxBidContractor, []string{ref.ContractorID, ref.TenderID, ref.BidID})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, []byte{0x00})
+}
+
+// tenderSelector builds the CouchDB selector for a filter, so that each page holds only
+// matching tenders. matchesTenderFilter re-checks every condition. Deadline bounds are left
+// to it: deadlines are stored with the offset they were written in, so CouchDB's string
+// comparison would misplace any that are not in UTC.
+func tenderSelector(f *TenderFilter) map[string]interface{} {
+	selector := map[string]interface{}{"docType": docTypeTender}
+	if f.Status != "" {
+		selector["status"] = f.Status
+	}
+	if f.Country != "" {
+		selector["ownerDetails.address.country"] = f.Country
+	}
+	if f.Sector != "" {
+		selector["bidRequirements.experienceRequirements.relevantSectors"] = map[string]interface{}{"$elemMatch": map[string]interface{}{"$eq": f.Sector}}
+	}
+	if f.ComplianceStandard != "" {
+		selector["complianceRequirements"] = map[string]interface{}{"$elemMatch": map[string]interface{}{"standard": f.ComplianceStandard}}
+	}
+	switch f.ProcurementMethod {
+	case "":
+	case procurementOpen:
+		// Tenders that leave the method out are open
+		selector["$or"] = []interface{}{
+			map[string]interface{}{"procurementMethod": procurementOpen},
+			map[string]interface{}{"procurementMethod": map[string]interface{}{"$exists": false}},
+			map[string]interface{}{"procurementMethod": ""},
+		}
+	default:
+		selector["procurementMethod"] = f.ProcurementMethod
+	}
+	if f.Currency != "" {
+		selector["projectScope.budget.currency"] = f.Currency
+	}
+	if f.BudgetMin > 0 {
+		selector["projectScope.budget.estimatedMax"] = map[string]interface{}{"$gte": f.BudgetMin}
+	}
+	if f.BudgetMax > 0 {
+		selector["projectScope.budget.estimatedMin"] = map[string]interface{}{"$lte": f.BudgetMax}
+	}
+	return selector
+}
+
+func validateTenderFilter(f *TenderFilter) error {
+	if f.BudgetMin < 0 || f.BudgetMax < 0 {
+		return fmt.Errorf("budget bounds must not be negative")
+	}
+	if f.BudgetMin > 0 && f.BudgetMax > 0 && f.BudgetMin > f.BudgetMax {
+		return fmt.Errorf("budgetMin must not exceed budgetMax")
+	}
+	for _, ts := range []string{f.DeadlineFrom, f.DeadlineTo} {
+		if ts == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, ts); err != nil {
+			return fmt.Errorf("invalid deadline bound %s: %v", ts, err)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTenderFilter applies every filter condition in memory
+func matchesTenderFilter(t *EnhancedTender, f *TenderFilter) bool {
+	if t.DocType != docTypeTender {
+		return false
+	}
+	if f.Status != "" && t.Status != f.Status {
+		return false
+	}
+	if f.Country != "" && t.OwnerDetails.Address.Country != f.Country {
+		return false
+	}
+	if f.Sector != "" && !containsString(t.BidRequirements.ExperienceRequirements.RelevantSectors, f.Sector) {
+		return false
+	}
+	if f.ComplianceStandard != "" {
+		found := false
+		for _, req := range t.ComplianceReqs {
+			if req.Standard == f.ComplianceStandard {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ProcurementMethod != "" && procurementMethod(t) != f.ProcurementMethod {
+		return false
+	}
+	budget := t.ProjectScope.Budget
+	if f.Currency != "" && budget.Currency != f.Currency {
+		return false
+	}
+	if f.BudgetMin > 0 && budget.EstimatedMax < f.BudgetMin {
+		return false
+	}
+	if f.BudgetMax > 0 && budget.EstimatedMin > f.BudgetMax {
+		return false
+	}
+	if f.DeadlineFrom != "" || f.DeadlineTo != "" {
+		deadline, err := time.Parse(time.RFC3339, t.Deadlines.BidSubmissionDeadline)
+		if err != nil {
+			return false
+		}
+		if from, err := time.Parse(time.RFC3339, f.DeadlineFrom); err == nil && deadline.Before(from) {
+			return false
+		}
+		if to, err := time.Parse(time.RFC3339, f.DeadlineTo); err == nil && deadline.After(to) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryTenders searches enhanced tenders with a structured filter and pagination.
+// CouchDB rich queries are used when available; on LevelDB the most selective
+// composite-key index is scanned instead.
+func (s *EnhancedSmartContract) QueryTenders(ctx contractapi.TransactionContextInterface, filterJSON string) (*TenderQueryResult, error) {
+	var filter TenderFilter
+	if filterJSON != "" {
+		if err := json.Unmarshal([]byte(filterJSON), &filter); err != nil {
+			return nil, fmt.Errorf("invalid filter JSON: %v", err)
+		}
+	}
+	if err := validateTenderFilter(&filter); err != nil {
+		return nil, err
+	}
+	size := pageSize(filter.PageSize)
+
+	query, _ := json.Marshal(map[string]interface{}{"selector": tenderSelector(&filter)})
+	iter, meta, err := ctx.GetStub().GetQueryResultWithPagination(string(query), size, filter.Bookmark)
+	if richQueryUnsupported(err) {
+		return s.queryTendersByIndex(ctx, &filter, size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	result := &TenderQueryResult{Tenders: []*EnhancedTender{}, Bookmark: meta.GetBookmark(), FetchedCount: meta.GetFetchedRecordsCount()}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var t EnhancedTender
+		if err := json.Unmarshal(kv.Value, &t); err != nil {
+			continue
+		}
+		if matchesTenderFilter(&t, &filter) {
+			result.Tenders = append(result.Tenders, &t)
+		}
+	}
+	return result, nil
+}
+
+// queryTendersByIndex is the LevelDB path: scan one composite-key index, or all tenders
+// when no indexed field is filtered, and apply the remaining conditions in memory
+func (s *EnhancedSmartContract) queryTendersByIndex(ctx contractapi.TransactionContextInterface, filter *TenderFilter, size int32) (*TenderQueryResult, error) {
+	stub := ctx.GetStub()
+	var index, value string
+	switch {
+	case filter.Sector != "":
+		index, value = idxTenderSector, filter.Sector
+	case filter.ComplianceStandard != "":
+		index, value = idxTenderStandard, filter.ComplianceStandard
+	case filter.Country != "":
+		index, value = idxTenderCountry, filter.Country
+	case filter.Status != "":
+		index, value = idxTenderStatus, filter.Status
+	}
+
+	var iter shim.StateQueryIteratorInterface
+	var meta interface {
+		GetBookmark() string
+		GetFetchedRecordsCount() int32
+	}
+	var err error
+	if index != "" {
+		iter, meta, err = stub.GetStateByPartialCompositeKeyWithPagination(index, []string{value}, size, filter.Bookmark)
+	} else {
+		iter, meta, err = stub.GetStateByRangeWithPagination("TENDER_", "TENDER_~", size, filter.Bookmark)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	result := &TenderQueryResult{Tenders: []*EnhancedTender{}, Bookmark: meta.GetBookmark(), FetchedCount: meta.GetFetchedRecordsCount()}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		data := kv.Value
+		if index != "" {
+			_, parts, err := stub.SplitCompositeKey(kv.Key)
+			if err != nil || len(parts) != 2 {
+				continue
+			}
+			if data, err = stub.GetState(tenderKey(parts[1])); err != nil {
+				return nil, err
+			}
+		}
+		var t EnhancedTender
+		if data == nil || json.Unmarshal(data, &t) != nil {
+			continue
+		}
+		if matchesTenderFilter(&t, filter) {
+			result.Tenders = append(result.Tenders, &t)
+		}
+	}
+	return result, nil
+}
+
+// QueryBids searches public bid references by tender and/or contractor with pagination
+func (s *EnhancedSmartContract) QueryBids(ctx contractapi.TransactionContextInterface, filterJSON string) (*BidQueryResult, error) {
+	var filter BidFilter
+	if filterJSON != "" {
+		if err := json.Unmarshal([]byte(filterJSON), &filter); err != nil {
+			return nil, fmt.Errorf("invalid filter JSON: %v", err)
+		}
+	}
+	if filter.TenderID == "" && filter.ContractorID == "" {
+		return nil, fmt.Errorf("filter must name a tender or a contractor")
+	}
+	size := pageSize(filter.PageSize)
+	stub := ctx.GetStub()
+
+	selector := map[string]interface{}{"docType": docTypeBidRef}
+	if filter.TenderID != "" {
+		selector["tenderId"] = filter.TenderID
+	}
+	if filter.ContractorID != "" {
+		selector["contractorId"] = filter.ContractorID
+	}
+	query, _ := json.Marshal(map[string]interface{}{"selector": selector})
+	iter, meta, err := stub.GetQueryResultWithPagination(string(query), size, filter.Bookmark)
+	byIndex := false
+	if richQueryUnsupported(err) {
+		byIndex = filter.ContractorID != ""
+		if byIndex {
+			attrs := []string{filter.ContractorID}
+			if filter.TenderID != "" {
+				attrs = append(attrs, filter.TenderID)
+			}
+			iter, meta, err = stub.GetStateByPartialCompositeKeyWithPagination(idxBidContractor, attrs, size, filter.Bookmark)
+		} else {
+			iter, meta, err = stub.GetStateByRangeWithPagination("BIDREF_"+filter.TenderID+"_", "BIDREF_"+filter.TenderID+"_~", size, filter.Bookmark)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	result := &BidQueryResult{Bids: []*BidRef{}, Bookmark: meta.GetBookmark(), FetchedCount: meta.GetFetchedRecordsCount()}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		data := kv.Value
+		if byIndex {
+			_, parts, err := stub.SplitCompositeKey(kv.Key)
+			if err != nil || len(parts) != 3 {
+				continue
+			}
+			if data, err = stub.GetState(bidRefKey(parts[1], parts[2])); err != nil {
+				return nil, err
+			}
+		}
+		var ref BidRef
+		if data == nil || json.Unmarshal(data, &ref) != nil {
+			continue
+		}
+		if filter.TenderID != "" && ref.TenderID != filter.TenderID {
+			continue
+		}
+		if filter.ContractorID != "" && ref.ContractorID != filter.ContractorID {
+			continue
+		}
+		result.Bids = append(result.Bids, &ref)
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package contract
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+// queryTenders stores T1 (US roads, open), T2 (KE water, open, ISO 9001, method OPEN
+// spelled out, deadline written in +05:30) and T3 (US water, draft, later deadline) with
+// bids from two contractors
+func (n *testNet) queryTenders() {
+	n.t.Helper()
+	t1 := tenderFixture("T1", t0.Add(24*time.Hour))
+	t1.BidRequirements.ExperienceRequirements.RelevantSectors = []string{"roads"}
+	t2 := tenderFixture("T2", t0.Add(48*time.Hour))
+	t2.OwnerDetails.Address.Country = "KE"
+	t2.ProjectScope.Budget.EstimatedMin, t2.ProjectScope.Budget.EstimatedMax = 100000, 200000
+	t2.BidRequirements.ExperienceRequirements.RelevantSectors = []string{"water", "roads"}
+	t2.ComplianceReqs = []ComplianceReq{{Standard: "ISO 9001", Mandatory: true}}
+	t2.ProcurementMethod = procurementOpen
+	t2.Deadlines.BidSubmissionDeadline = t0.Add(48 * time.Hour).In(time.FixedZone("IST", 5*3600+1800)).Format(time.RFC3339)
+	t3 := tenderFixture("T3", t0.Add(96*time.Hour))
+	t3.BidRequirements.ExperienceRequirements.RelevantSectors = []string{"water"}
+	n.openTender(t1)
+	n.openTender(t2)
+	n.createTender(t3)
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+	n.mustSubmitBid("contractorB", bidFixture("T1", "B2", "contractorB", 600000))
+	n.mustSubmitBid("contractorA", bidFixture("T2", "B1", "contractorA", 150000))
+}
+
+func TestQueryTenders(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    []string
+		wantErr string
+	}{
+		{"no filter", "", []string{"T1", "T2", "T3"}, ""},
+		{"status", `{"status":"OPEN"}`, []string{"T1", "T2"}, ""},
+		{"country", `{"country":"US"}`, []string{"T1", "T3"}, ""},
+		{"sector", `{"sector":"water"}`, []string{"T2", "T3"}, ""},
+		{"sector and status", `{"sector":"water","status":"OPEN"}`, []string{"T2"}, ""},
+		{"compliance standard", `{"complianceStandard":"ISO 9001"}`, []string{"T2"}, ""},
+		{"budget overlap", `{"budgetMin":300000}`, []string{"T1", "T3"}, ""},
+		{"budget ceiling", `{"budgetMax":250000}`, []string{"T2"}, ""},
+		{"currency", `{"currency":"EUR"}`, nil, ""},
+		{"open method, set or left out", `{"procurementMethod":"OPEN"}`, []string{"T1", "T2", "T3"}, ""},
+		{"restricted method", `{"procurementMethod":"RESTRICTED"}`, nil, ""},
+		{"deadline from", `{"deadlineFrom":"` + rfc(t0.Add(48*time.Hour)) + `"}`, []string{"T2", "T3"}, ""},
+		{"deadline to, stored with an offset", `{"deadlineTo":"` + rfc(t0.Add(48*time.Hour)) + `"}`, []string{"T1", "T2"}, ""},
+		{"deadline window", `{"deadlineFrom":"` + rfc(t0.Add(36*time.Hour)) + `","deadlineTo":"` + rfc(t0.Add(72*time.Hour)) + `"}`, []string{"T2"}, ""},
+		{"malformed", `{"status":`, nil, "invalid filter JSON"},
+		{"negative budget", `{"budgetMin":-1}`, nil, "budget bounds must not be negative"},
+		{"inverted budget", `{"budgetMin":5,"budgetMax":1}`, nil, "budgetMin must not exceed budgetMax"},
+		{"bad deadline", `{"deadlineTo":"tomorrow"}`, nil, "invalid deadline bound tomorrow"},
+	}
+	// The same filters must give the same answers on CouchDB and on the LevelDB fallback
+	for _, rich := range []bool{false, true} {
+		n := newTestNet(t)
+		n.ledger.RichQueries = rich
+		n.queryTenders()
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				err := n.query("auditor", func(ctx *TransactionContext) error {
+					result, err := n.enh.QueryTenders(ctx, tc.filter)
+					if err != nil {
+						return err
+					}
+					var got []string
+					for _, tender := range result.Tenders {
+						got = append(got, tender.ID)
+					}
+					sort.Strings(got)
+					if !equalStrings(got, tc.want) {
+						t.Fatalf("rich=%v: tenders = %v, want %v", rich, got, tc.want)
+					}
+					return nil
+				})
+				expectErr(t, err, tc.wantErr)
+			})
+		}
+	}
+}
+
+func TestQueryTendersPagination(t *testing.T) {
+	for _, rich := range []bool{false, true} {
+		n := newTestNet(t)
+		n.ledger.RichQueries = rich
+		n.queryTenders()
+		seen := map[string]bool{}
+		bookmark := ""
+		for page := 0; page < 4; page++ {
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				result, err := n.enh.QueryTenders(ctx, `{"pageSize":2,"bookmark":"`+bookmark+`"}`)
+				if err != nil {
+					return err
+				}
+				if len(result.Tenders) > 2 {
+					t.Fatalf("rich=%v: page of %d", rich, len(result.Tenders))
+				}
+				for _, tender := range result.Tenders {
+					if seen[tender.ID] {
+						t.Fatalf("rich=%v: %s returned twice", rich, tender.ID)
+					}
+					seen[tender.ID] = true
+				}
+				bookmark = result.Bookmark
+				return nil
+			})
+			if bookmark == "" || len(seen) == 3 {
+				break
+			}
+		}
+		if len(seen) != 3 {
+			t.Fatalf("rich=%v: paged through %v", rich, seen)
+		}
+	}
+}
+
+// On CouchDB every condition but the deadline bounds is in the selector, so a page is
+// not cut short by tenders that the in-memory check drops
+func TestQueryTendersFullPages(t *testing.T) {
+	n := newTestNet(t)
+	n.ledger.RichQueries = true
+	n.queryTenders()
+	filters := map[string][]string{
+		`{"pageSize":1,"procurementMethod":"OPEN","status":"DRAFT"}`: {"T3"},
+	}
+	for filter, want := range filters {
+		n.mustQuery("auditor", func(ctx *TransactionContext) error {
+			result, err := n.enh.QueryTenders(ctx, filter)
+			if err != nil {
+				return err
+			}
+			var got []string
+			for _, tender := range result.Tenders {
+				got = append(got, tender.ID)
+			}
+			if !equalStrings(got, want) {
+				t.Fatalf("%s: first page = %v, want %v", filter, got, want)
+			}
+			return nil
+		})
+	}
+}
+
+func TestQueryBids(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    []string
+		wantErr string
+	}{
+		{"by tender", `{"tenderId":"T1"}`, []string{"T1/B1", "T1/B2"}, ""},
+		{"by contractor", `{"contractorId":"contractorA"}`, []string{"T1/B1", "T2/B1"}, ""},
+		{"by both", `{"tenderId":"T2","contractorId":"contractorA"}`, []string{"T2/B1"}, ""},
+		{"no bids", `{"contractorId":"contractorC"}`, nil, ""},
+		{"tender id prefix", `{"tenderId":"T"}`, nil, ""},
+		{"unscoped", `{}`, nil, "filter must name a tender or a contractor"},
+		{"empty", "", nil, "filter must name a tender or a contractor"},
+		{"malformed", `[`, nil, "invalid filter JSON"},
+	}
+	for _, rich := range []bool{false, true} {
+		n := newTestNet(t)
+		n.ledger.RichQueries = rich
+		n.queryTenders()
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				err := n.query("auditor", func(ctx *TransactionContext) error {
+					result, err := n.enh.QueryBids(ctx, tc.filter)
+					if err != nil {
+						return err
+					}
+					var got []string
+					for _, ref := range result.Bids {
+						got = append(got, ref.TenderID+"/"+ref.BidID)
+					}
+					sort.Strings(got)
+					if !equalStrings(got, tc.want) {
+						t.Fatalf("rich=%v: bids = %v, want %v", rich, got, tc.want)
+					}
+					return nil
+				})
+				expectErr(t, err, tc.wantErr)
+			})
+		}
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	for _, tc := range []struct{ in, want int32 }{{0, defaultQueryPageSize}, {-5, defaultQueryPageSize}, {7, 7}, {maxQueryPageSize + 1, maxQueryPageSize}} {
+		if got := pageSize(tc.in); got != tc.want {
+			t.Errorf("pageSize(%d) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
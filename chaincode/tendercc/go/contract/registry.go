@@ -0,0 +1,475 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+func vendorKey(vendorID string) string {
+	return fmt.Sprintf("VENDOR_%s", vendorID)
+}
+
+// clientMSPID returns the MSP of the submitting client
+func clientMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	return mspID, nil
+}
+
+func validateVendorProfile(v *VendorProfile) error {
+	if v.ID == "" || v.LegalName == "" {
+		return fmt.Errorf("vendor ID and legal name are required")
+	}
+	if v.TaxInfo.TaxID == "" {
+		return fmt.Errorf("tax ID is required")
+	}
+	if v.Address.Country == "" {
+		return fmt.Errorf("address country is required")
+	}
+	if v.IncorporationDate != "" {
+		if _, err := time.Parse(time.RFC3339, v.IncorporationDate); err != nil {
+			return fmt.Errorf("invalid incorporation date: %v", err)
+		}
+	}
+	for _, c := range v.Certifications {
+		if c.Name == "" || c.DocumentHash == "" {
+			return fmt.Errorf("certification name and document hash are required")
+		}
+		if _, err := time.Parse(time.RFC3339, c.ValidUntil); err != nil {
+			return fmt.Errorf("invalid expiry for certification %s: %v", c.Name, err)
+		}
+	}
+	years := make(map[int]bool)
+	for _, f := range v.Financials {
+		if f.Year <= 0 || f.DocumentHash == "" {
+			return fmt.Errorf("financial year and document hash are required")
+		}
+		if years[f.Year] {
+			return fmt.Errorf("duplicate financials for year %d", f.Year)
+		}
+		years[f.Year] = true
+	}
+	for _, p := range v.PastProjects {
+		if p.Name == "" || p.DocumentHash == "" {
+			return fmt.Errorf("project name and document hash are required")
+		}
+	}
+	return nil
+}
+
+// keepVerification carries verification flags over only for entries whose claims did
+// not change; editing any field of a verified entry, not just its document, resets it
+func keepVerification(updated, previous *VendorProfile) {
+	certs := make(map[VendorCertification]VendorCertification)
+	for _, c := range previous.Certifications {
+		claim := c
+		claim.Verified, claim.VerifiedAt = false, ""
+		certs[claim] = c
+	}
+	for i := range updated.Certifications {
+		claim := updated.Certifications[i]
+		claim.Verified, claim.VerifiedAt = false, ""
+		prev := certs[claim]
+		updated.Certifications[i].Verified = prev.Verified
+		updated.Certifications[i].VerifiedAt = prev.VerifiedAt
+	}
+	fins := make(map[FinancialYear]FinancialYear)
+	for _, f := range previous.Financials {
+		claim := f
+		claim.Verified, claim.VerifiedAt = false, ""
+		fins[claim] = f
+	}
+	for i := range updated.Financials {
+		claim := updated.Financials[i]
+		claim.Verified, claim.VerifiedAt = false, ""
+		prev := fins[claim]
+		updated.Financials[i].Verified = prev.Verified
+		updated.Financials[i].VerifiedAt = prev.VerifiedAt
+	}
+	projects := make(map[PastProject]PastProject)
+	for _, p := range previous.PastProjects {
+		claim := p
+		claim.Verified, claim.VerifiedAt = false, ""
+		projects[claim] = p
+	}
+	for i := range updated.PastProjects {
+		claim := updated.PastProjects[i]
+		claim.Verified, claim.VerifiedAt = false, ""
+		prev := projects[claim]
+		updated.PastProjects[i].Verified = prev.Verified
+		updated.PastProjects[i].VerifiedAt = prev.VerifiedAt
+	}
+}
+
+// RegisterVendor adds a vendor to the registry; documents start unverified
+func (s *EnhancedSmartContract) RegisterVendor(ctx contractapi.TransactionContextInterface, vendorJSON string) error {
+	var vendor VendorProfile
+	if err := json.Unmarshal([]byte(vendorJSON), &vendor); err != nil {
+		return fmt.Errorf("invalid vendor JSON: %v", err)
+	}
+	if err := validateVendorProfile(&vendor); err != nil {
+		return fmt.Errorf("vendor validation failed: %v", err)
+	}
+	exists, err := s.assetExists(ctx, vendorKey(vendor.ID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("vendor %s already exists", vendor.ID)
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Claims only count once the registering org has checked the documents
+	keepVerification(&vendor, &VendorProfile{})
+	vendor.Status = "ACTIVE"
+	vendor.RegisteredBy = mspID
+	vendor.CreatedAt = txTime.Format(time.RFC3339)
+	vendor.UpdatedAt = vendor.CreatedAt
+
+	bytes, _ := json.Marshal(vendor)
+	if err := ctx.GetStub().PutState(vendorKey(vendor.ID), bytes); err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, events.VendorRegistered, "", events.VendorPayload{
+		VendorID:     vendor.ID,
+		LegalName:    vendor.LegalName,
+		RegisteredBy: vendor.RegisteredBy,
+	})
+}
+
+// UpdateVendor replaces a vendor profile; only the registering org may update it
+func (s *EnhancedSmartContract) UpdateVendor(ctx contractapi.TransactionContextInterface, vendorJSON string) error {
+	var vendor VendorProfile
+	if err := json.Unmarshal([]byte(vendorJSON), &vendor); err != nil {
+		return fmt.Errorf("invalid vendor JSON: %v", err)
+	}
+	if err := validateVendorProfile(&vendor); err != nil {
+		return fmt.Errorf("vendor validation failed: %v", err)
+	}
+	previous, err := s.GetVendor(ctx, vendor.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.requireVendorRegistrar(ctx, previous); err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	keepVerification(&vendor, previous)
+	vendor.Status = previous.Status
+	vendor.RegisteredBy = previous.RegisteredBy
+	vendor.CreatedAt = previous.CreatedAt
+	vendor.UpdatedAt = txTime.Format(time.RFC3339)
+
+	bytes, _ := json.Marshal(vendor)
+	if err := ctx.GetStub().PutState(vendorKey(vendor.ID), bytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.VendorUpdated, "", events.VendorPayload{VendorID: vendor.ID, LegalName: vendor.LegalName})
+}
+
+func (s *EnhancedSmartContract) requireVendorRegistrar(ctx contractapi.TransactionContextInterface, vendor *VendorProfile) error {
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if mspID != vendor.RegisteredBy {
+		return fmt.Errorf("only %s may change vendor %s", vendor.RegisteredBy, vendor.ID)
+	}
+	return nil
+}
+
+// VerifyVendorDocument marks every entry backed by the given document hash as verified
+func (s *EnhancedSmartContract) VerifyVendorDocument(ctx contractapi.TransactionContextInterface, vendorID, documentHash string) error {
+	vendor, err := s.GetVendor(ctx, vendorID)
+	if err != nil {
+		return err
+	}
+	if err := s.requireVendorRegistrar(ctx, vendor); err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	now := txTime.Format(time.RFC3339)
+
+	matched := 0
+	for i := range vendor.Certifications {
+		if vendor.Certifications[i].DocumentHash == documentHash {
+			vendor.Certifications[i].Verified = true
+			vendor.Certifications[i].VerifiedAt = now
+			matched++
+		}
+	}
+	for i := range vendor.Financials {
+		if vendor.Financials[i].DocumentHash == documentHash {
+			vendor.Financials[i].Verified = true
+			vendor.Financials[i].VerifiedAt = now
+			matched++
+		}
+	}
+	for i := range vendor.PastProjects {
+		if vendor.PastProjects[i].DocumentHash == documentHash {
+			vendor.PastProjects[i].Verified = true
+			vendor.PastProjects[i].VerifiedAt = now
+			matched++
+		}
+	}
+	if matched == 0 {
+		return fmt.Errorf("document %s is not referenced by vendor %s", documentHash, vendorID)
+	}
+	vendor.UpdatedAt = now
+
+	bytes, _ := json.Marshal(vendor)
+	if err := ctx.GetStub().PutState(vendorKey(vendorID), bytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.VendorDocumentVerified, "", events.VendorDocumentPayload{VendorID: vendorID, DocumentHash: documentHash, VerifiedAt: now})
+}
+
+// SetVendorStatus suspends or reactivates a vendor
+func (s *EnhancedSmartContract) SetVendorStatus(ctx contractapi.TransactionContextInterface, vendorID, status string) error {
+	if status != "ACTIVE" && status != "SUSPENDED" {
+		return fmt.Errorf("status must be ACTIVE or SUSPENDED")
+	}
+	vendor, err := s.GetVendor(ctx, vendorID)
+	if err != nil {
+		return err
+	}
+	if err := s.requireVendorRegistrar(ctx, vendor); err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	previous := vendor.Status
+	now := txTime.Format(time.RFC3339)
+	vendor.Status = status
+	vendor.UpdatedAt = now
+	bytes, _ := json.Marshal(vendor)
+	if err := ctx.GetStub().PutState(vendorKey(vendorID), bytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.VendorStatusChanged, "", events.VendorStatusPayload{
+		VendorID:       vendorID,
+		PreviousStatus: previous,
+		Status:         status,
+		ChangedAt:      now,
+	})
+}
+
+// GetVendor retrieves a vendor profile
+func (s *EnhancedSmartContract) GetVendor(ctx contractapi.TransactionContextInterface, vendorID string) (*VendorProfile, error) {
+	data, err := ctx.GetStub().GetState(vendorKey(vendorID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("vendor %s not found", vendorID)
+	}
+	var vendor VendorProfile
+	if err := json.Unmarshal(data, &vendor); err != nil {
+		return nil, err
+	}
+	return &vendor, nil
+}
+
+// ListVendors returns all registered vendors
+func (s *EnhancedSmartContract) ListVendors(ctx contractapi.TransactionContextInterface) ([]*VendorProfile, error) {
+	iter, err := ctx.GetStub().GetStateByRange("VENDOR_", "VENDOR_~")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var out []*VendorProfile
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var v VendorProfile
+		if err := json.Unmarshal(kv.Value, &v); err == nil {
+			out = append(out, &v)
+		}
+	}
+	return out, nil
+}
+
+// CheckPrequalification evaluates a vendor against a tender without submitting a bid
+func (s *EnhancedSmartContract) CheckPrequalification(ctx contractapi.TransactionContextInterface, tenderID, vendorID string) (*PrequalificationReport, error) {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.prequalify(ctx, tender, vendorID, txTime), nil
+}
+
+// prequalify checks registry data (verified entries only) against the tender requirements
+func (s *EnhancedSmartContract) prequalify(ctx contractapi.TransactionContextInterface, tender *EnhancedTender, vendorID string, now time.Time) *PrequalificationReport {
+	report := &PrequalificationReport{
+		TenderID:  tender.ID,
+		VendorID:  vendorID,
+		CheckedAt: now.Format(time.RFC3339),
+	}
+	vendor, err := s.GetVendor(ctx, vendorID)
+	if err != nil {
+		report.Failures = append(report.Failures, err.Error())
+		return report
+	}
+	if vendor.Status != "ACTIVE" {
+		report.Failures = append(report.Failures, fmt.Sprintf("vendor %s is %s", vendorID, vendor.Status))
+	}
+
+	report.Failures = append(report.Failures, checkFinancials(vendor, &tender.BidRequirements.FinancialRequirements)...)
+	report.Failures = append(report.Failures, checkExperience(vendor, &tender.BidRequirements.ExperienceRequirements, now)...)
+	report.Failures = append(report.Failures, checkCertifications(vendor, tender.BidRequirements.CertificationRequirements, now)...)
+
+	report.Qualified = len(report.Failures) == 0
+	return report
+}
+
+func checkFinancials(vendor *VendorProfile, req *FinancialReq) []string {
+	var failures []string
+	var years []FinancialYear
+	for _, f := range vendor.Financials {
+		if !f.Verified {
+			continue
+		}
+		if req.Currency != "" && f.Currency != "" && f.Currency != req.Currency {
+			continue
+		}
+		if req.AuditedFinancials && !f.Audited {
+			continue
+		}
+		years = append(years, f)
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].Year > years[j].Year })
+
+	needed := req.YearsOfFinancials
+	if needed == 0 && (req.MinTurnover > 0 || req.MinNetWorth > 0) {
+		needed = 1
+	}
+	if len(years) < needed {
+		failures = append(failures, fmt.Sprintf("%d years of verified financials required, %d available", needed, len(years)))
+		return failures
+	}
+	if needed == 0 {
+		return failures
+	}
+	recent := years[:needed]
+
+	if req.MinTurnover > 0 {
+		total := 0.0
+		for _, f := range recent {
+			total += f.Turnover
+		}
+		if avg := total / float64(len(recent)); avg < req.MinTurnover {
+			failures = append(failures, fmt.Sprintf("average turnover %.2f below required %.2f", avg, req.MinTurnover))
+		}
+	}
+	if req.MinNetWorth > 0 && recent[0].NetWorth < req.MinNetWorth {
+		failures = append(failures, fmt.Sprintf("net worth %.2f below required %.2f", recent[0].NetWorth, req.MinNetWorth))
+	}
+	return failures
+}
+
+func checkExperience(vendor *VendorProfile, req *ExperienceReq, now time.Time) []string {
+	var failures []string
+	if req.MinYearsInBusiness > 0 {
+		inc, err := time.Parse(time.RFC3339, vendor.IncorporationDate)
+		if err != nil {
+			failures = append(failures, "incorporation date required to check years in business")
+		} else if inc.AddDate(req.MinYearsInBusiness, 0, 0).After(now) {
+			failures = append(failures, fmt.Sprintf("at least %d years in business required", req.MinYearsInBusiness))
+		}
+	}
+
+	if req.SimilarProjectsMin > 0 {
+		similar := 0
+		for _, p := range vendor.PastProjects {
+			if !p.Verified || p.Value < req.MinProjectValue {
+				continue
+			}
+			if len(req.RelevantSectors) > 0 && !containsFold(req.RelevantSectors, p.Sector) {
+				continue
+			}
+			if len(req.GeographicalExp) > 0 && !containsFold(req.GeographicalExp, p.Country) {
+				continue
+			}
+			similar++
+		}
+		if similar < req.SimilarProjectsMin {
+			failures = append(failures, fmt.Sprintf("%d verified similar projects required, %d found", req.SimilarProjectsMin, similar))
+		}
+	}
+	return failures
+}
+
+func checkCertifications(vendor *VendorProfile, reqs []CertificationReq, now time.Time) []string {
+	var failures []string
+	for _, req := range reqs {
+		if !req.Mandatory {
+			continue
+		}
+		// The certificate must be valid now and, if the tender says so, until the given date
+		validTo := now
+		if req.ValidUntil != "" {
+			if t, err := time.Parse(time.RFC3339, req.ValidUntil); err == nil {
+				validTo = t
+			}
+		}
+		held := false
+		for _, c := range vendor.Certifications {
+			if !c.Verified || !strings.EqualFold(c.Name, req.Name) {
+				continue
+			}
+			if req.IssuingBody != "" && c.IssuingBody != "" && !strings.EqualFold(c.IssuingBody, req.IssuingBody) {
+				continue
+			}
+			expiry, err := time.Parse(time.RFC3339, c.ValidUntil)
+			if err == nil && expiry.After(validTo) && expiry.After(now) {
+				held = true
+				break
+			}
+		}
+		if !held {
+			failures = append(failures, fmt.Sprintf("valid verified certification %s required", req.Name))
+		}
+	}
+	return failures
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
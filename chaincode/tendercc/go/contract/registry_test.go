@@ -0,0 +1,364 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+// vendorFixture is a roads contractor incorporated eight years before t0
+func vendorFixture(id string) *VendorProfile {
+	return &VendorProfile{
+		ID:                id,
+		LegalName:         id + " Construction Ltd",
+		IncorporationDate: rfc(t0.AddDate(-8, 0, 0)),
+		TaxInfo:           TaxInfo{TaxID: "TAX-" + id},
+		Address:           Address{City: "Shelbyville", Country: "US"},
+		Certifications: []VendorCertification{
+			{Name: "ISO 9001", IssuingBody: "BSI", ValidUntil: rfc(t0.AddDate(2, 0, 0)), DocumentHash: "cert-iso", Verified: true},
+		},
+		Financials: []FinancialYear{
+			{Year: 2028, Turnover: 4000000, NetWorth: 1500000, Currency: "USD", Audited: true, DocumentHash: "fin-2028"},
+			{Year: 2029, Turnover: 6000000, NetWorth: 2000000, Currency: "USD", Audited: true, DocumentHash: "fin-2029"},
+		},
+		PastProjects: []PastProject{
+			{Name: "Route 9", Sector: "roads", Country: "US", Value: 2000000, DocumentHash: "proj-r9"},
+			{Name: "Harbour quay", Sector: "marine", Country: "US", Value: 9000000, DocumentHash: "proj-hq"},
+		},
+	}
+}
+
+func (n *testNet) registerVendor(who string, v *VendorProfile) {
+	n.t.Helper()
+	js := mustJSON(n.t, v)
+	n.mustTx(who, nil, func(ctx *TransactionContext) error { return n.enh.RegisterVendor(ctx, js) })
+}
+
+func (n *testNet) verifyVendorDocs(who, vendorID string, hashes ...string) {
+	n.t.Helper()
+	for _, h := range hashes {
+		n.mustTx(who, nil, func(ctx *TransactionContext) error { return n.enh.VerifyVendorDocument(ctx, vendorID, h) })
+	}
+}
+
+func (n *testNet) vendor(vendorID string) *VendorProfile {
+	n.t.Helper()
+	var v *VendorProfile
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		var err error
+		v, err = n.enh.GetVendor(ctx, vendorID)
+		return err
+	})
+	return v
+}
+
+func TestRegisterVendor(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(v *VendorProfile)
+		raw     string
+		wantErr string
+	}{
+		{name: "valid", mutate: func(*VendorProfile) {}},
+		{name: "bad json", raw: "{", wantErr: "invalid vendor JSON"},
+		{name: "no id", mutate: func(v *VendorProfile) { v.ID = "" }, wantErr: "vendor ID and legal name are required"},
+		{name: "no legal name", mutate: func(v *VendorProfile) { v.LegalName = "" }, wantErr: "vendor ID and legal name are required"},
+		{name: "no tax id", mutate: func(v *VendorProfile) { v.TaxInfo.TaxID = "" }, wantErr: "tax ID is required"},
+		{name: "no country", mutate: func(v *VendorProfile) { v.Address.Country = "" }, wantErr: "address country is required"},
+		{name: "bad incorporation date", mutate: func(v *VendorProfile) { v.IncorporationDate = "1999" }, wantErr: "invalid incorporation date"},
+		{name: "certificate without hash", mutate: func(v *VendorProfile) { v.Certifications[0].DocumentHash = "" }, wantErr: "certification name and document hash are required"},
+		{name: "certificate without expiry", mutate: func(v *VendorProfile) { v.Certifications[0].ValidUntil = "" }, wantErr: "invalid expiry for certification ISO 9001"},
+		{name: "financials without year", mutate: func(v *VendorProfile) { v.Financials[0].Year = 0 }, wantErr: "financial year and document hash are required"},
+		{name: "duplicate financial year", mutate: func(v *VendorProfile) { v.Financials[1].Year = 2028 }, wantErr: "duplicate financials for year 2028"},
+		{name: "project without hash", mutate: func(v *VendorProfile) { v.PastProjects[0].DocumentHash = "" }, wantErr: "project name and document hash are required"},
+		{name: "duplicate", mutate: func(v *VendorProfile) { v.ID = "existing" }, wantErr: "vendor existing already exists"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.registerVendor("buyer", vendorFixture("existing"))
+			js := tc.raw
+			if js == "" {
+				v := vendorFixture("contractorA")
+				tc.mutate(v)
+				js = mustJSON(t, v)
+			}
+			err := n.tx("auditor", nil, func(ctx *TransactionContext) error { return n.enh.RegisterVendor(ctx, js) })
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			n.expectEvents(events.VendorRegistered)
+			v := n.vendor("contractorA")
+			// Self-declared verification flags are cleared on registration
+			if v.Status != "ACTIVE" || v.RegisteredBy != "AuditorMSP" || v.Certifications[0].Verified {
+				t.Fatalf("vendor = %+v", v)
+			}
+		})
+	}
+}
+
+func TestVendorRegistrarActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		who     string
+		action  func(n *testNet, ctx *TransactionContext) error
+		wantErr string
+		event   string
+	}{
+		{"verify document", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.VerifyVendorDocument(ctx, "contractorA", "fin-2029")
+		}, "", events.VendorDocumentVerified},
+		{"verify unknown document", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.VerifyVendorDocument(ctx, "contractorA", "nope")
+		}, "document nope is not referenced by vendor contractorA", ""},
+		{"verify by other org", "auditor", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.VerifyVendorDocument(ctx, "contractorA", "fin-2029")
+		}, "only BuyerMSP may change vendor contractorA", ""},
+		{"verify unknown vendor", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.VerifyVendorDocument(ctx, "ghost", "fin-2029")
+		}, "vendor ghost not found", ""},
+		{"suspend", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.SetVendorStatus(ctx, "contractorA", "SUSPENDED")
+		}, "", events.VendorStatusChanged},
+		{"bad status", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.SetVendorStatus(ctx, "contractorA", "DELETED")
+		}, "status must be ACTIVE or SUSPENDED", ""},
+		{"suspend by other org", "auditor", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.SetVendorStatus(ctx, "contractorA", "SUSPENDED")
+		}, "only BuyerMSP may change vendor contractorA", ""},
+		{"update", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			v := vendorFixture("contractorA")
+			v.LegalName = "Renamed Ltd"
+			return n.enh.UpdateVendor(ctx, mustJSON(n.t, v))
+		}, "", events.VendorUpdated},
+		{"update by other org", "auditor", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.UpdateVendor(ctx, mustJSON(n.t, vendorFixture("contractorA")))
+		}, "only BuyerMSP may change vendor contractorA", ""},
+		{"update invalid", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			v := vendorFixture("contractorA")
+			v.TaxInfo.TaxID = ""
+			return n.enh.UpdateVendor(ctx, mustJSON(n.t, v))
+		}, "tax ID is required", ""},
+		{"update unknown", "buyer", func(n *testNet, ctx *TransactionContext) error {
+			return n.enh.UpdateVendor(ctx, mustJSON(n.t, vendorFixture("ghost")))
+		}, "vendor ghost not found", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.registerVendor("buyer", vendorFixture("contractorA"))
+			before := len(n.ledger.Events())
+			err := n.tx(tc.who, nil, func(ctx *TransactionContext) error { return tc.action(n, ctx) })
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			if tc.event != "" {
+				n.expectEvents(tc.event)
+			} else if len(n.ledger.Events()) != before {
+				t.Fatalf("unexpected event %+v", n.lastEvents())
+			}
+		})
+	}
+}
+
+func TestUpdateVendorKeepsVerification(t *testing.T) {
+	n := newTestNet(t)
+	n.registerVendor("buyer", vendorFixture("contractorA"))
+	n.verifyVendorDocs("buyer", "contractorA", "fin-2028", "fin-2029", "proj-r9")
+
+	updated := vendorFixture("contractorA")
+	updated.Financials[1].DocumentHash = "fin-2029-restated"
+	js := mustJSON(t, updated)
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.UpdateVendor(ctx, js) })
+
+	v := n.vendor("contractorA")
+	if !v.Financials[0].Verified || v.Financials[1].Verified || !v.PastProjects[0].Verified || v.PastProjects[1].Verified {
+		t.Fatalf("verification after update = %+v / %+v", v.Financials, v.PastProjects)
+	}
+
+	// Changing a claim behind a verified document also needs checking again
+	edits := []struct {
+		name string
+		edit func(v *VendorProfile)
+	}{
+		{"turnover", func(v *VendorProfile) { v.Financials[0].Turnover *= 10 }},
+		{"net worth", func(v *VendorProfile) { v.Financials[0].NetWorth *= 10 }},
+		{"year", func(v *VendorProfile) { v.Financials[0].Year = 2030 }},
+		{"project value", func(v *VendorProfile) { v.PastProjects[0].Value *= 10 }},
+		{"certification expiry", func(v *VendorProfile) { v.Certifications[0].ValidUntil = rfc(t0.AddDate(9, 0, 0)) }},
+	}
+	for _, e := range edits {
+		n.verifyVendorDocs("buyer", "contractorA", "cert-iso", "fin-2028", "proj-r9")
+		edited := n.vendor("contractorA")
+		e.edit(edited)
+		js := mustJSON(t, edited)
+		n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.UpdateVendor(ctx, js) })
+		v := n.vendor("contractorA")
+		if verified := v.Certifications[0].Verified && v.Financials[0].Verified && v.PastProjects[0].Verified; verified {
+			t.Fatalf("%s: verification kept after edit = %+v", e.name, v)
+		}
+	}
+	if v.RegisteredBy != "BuyerMSP" || v.CreatedAt != rfc(t0) {
+		t.Fatalf("vendor = %+v", v)
+	}
+
+	n.registerVendor("buyer", vendorFixture("contractorB"))
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		list, err := n.enh.ListVendors(ctx)
+		if err == nil && (len(list) != 2 || list[0].ID != "contractorA" || list[1].ID != "contractorB") {
+			t.Fatalf("vendors = %+v", list)
+		}
+		return err
+	})
+}
+
+func TestCheckPrequalification(t *testing.T) {
+	tests := []struct {
+		name     string
+		require  func(r *BidRequirements)
+		verify   []string
+		suspend  bool
+		vendorID string
+		failures []string
+	}{
+		{
+			name:     "no requirements",
+			require:  func(*BidRequirements) {},
+			vendorID: "contractorA",
+		},
+		{
+			name: "financials met",
+			require: func(r *BidRequirements) {
+				r.FinancialRequirements = FinancialReq{MinTurnover: 5000000, MinNetWorth: 2000000, YearsOfFinancials: 2, AuditedFinancials: true, Currency: "USD"}
+			},
+			verify:   []string{"fin-2028", "fin-2029"},
+			vendorID: "contractorA",
+		},
+		{
+			name: "financials unverified",
+			require: func(r *BidRequirements) {
+				r.FinancialRequirements = FinancialReq{MinTurnover: 1, YearsOfFinancials: 2}
+			},
+			verify:   []string{"fin-2029"},
+			vendorID: "contractorA",
+			failures: []string{"2 years of verified financials required, 1 available"},
+		},
+		{
+			name: "turnover too low",
+			require: func(r *BidRequirements) {
+				r.FinancialRequirements = FinancialReq{MinTurnover: 5500000, MinNetWorth: 2500000, YearsOfFinancials: 2}
+			},
+			verify:   []string{"fin-2028", "fin-2029"},
+			vendorID: "contractorA",
+			failures: []string{"average turnover 5000000.00 below required 5500000.00", "net worth 2000000.00 below required 2500000.00"},
+		},
+		{
+			name: "experience met",
+			require: func(r *BidRequirements) {
+				r.ExperienceRequirements = ExperienceReq{MinYearsInBusiness: 8, SimilarProjectsMin: 1, MinProjectValue: 1000000, RelevantSectors: []string{"Roads"}}
+			},
+			verify:   []string{"proj-r9", "proj-hq"},
+			vendorID: "contractorA",
+		},
+		{
+			name: "experience not met",
+			require: func(r *BidRequirements) {
+				r.ExperienceRequirements = ExperienceReq{MinYearsInBusiness: 9, SimilarProjectsMin: 2, RelevantSectors: []string{"roads"}}
+			},
+			verify:   []string{"proj-r9", "proj-hq"},
+			vendorID: "contractorA",
+			failures: []string{"at least 9 years in business required", "2 verified similar projects required, 1 found"},
+		},
+		{
+			name: "certificate held",
+			require: func(r *BidRequirements) {
+				r.CertificationRequirements = []CertificationReq{{Name: "iso 9001", IssuingBody: "bsi", Mandatory: true, ValidUntil: rfc(t0.AddDate(1, 0, 0))}}
+			},
+			verify:   []string{"cert-iso"},
+			vendorID: "contractorA",
+		},
+		{
+			name: "certificate expires too soon",
+			require: func(r *BidRequirements) {
+				r.CertificationRequirements = []CertificationReq{
+					{Name: "ISO 9001", Mandatory: true, ValidUntil: rfc(t0.AddDate(3, 0, 0))},
+					{Name: "ISO 14001", Mandatory: false},
+				}
+			},
+			verify:   []string{"cert-iso"},
+			vendorID: "contractorA",
+			failures: []string{"valid verified certification ISO 9001 required"},
+		},
+		{
+			name:     "suspended",
+			require:  func(*BidRequirements) {},
+			suspend:  true,
+			vendorID: "contractorA",
+			failures: []string{"vendor contractorA is SUSPENDED"},
+		},
+		{
+			name:     "unregistered",
+			require:  func(*BidRequirements) {},
+			vendorID: "ghost",
+			failures: []string{"vendor ghost not found"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.registerVendor("contractorA", vendorFixture("contractorA"))
+			n.verifyVendorDocs("contractorA", "contractorA", tc.verify...)
+			if tc.suspend {
+				n.mustTx("contractorA", nil, func(ctx *TransactionContext) error {
+					return n.enh.SetVendorStatus(ctx, "contractorA", "SUSPENDED")
+				})
+			}
+			tender := tenderFixture("T1", t0.Add(time.Hour))
+			tender.BidRequirements.PrequalificationRequired = true
+			tc.require(&tender.BidRequirements)
+			n.openTender(tender)
+
+			var report *PrequalificationReport
+			n.mustQuery("contractorA", func(ctx *TransactionContext) error {
+				var err error
+				report, err = n.enh.CheckPrequalification(ctx, "T1", tc.vendorID)
+				return err
+			})
+			if report.Qualified != (len(tc.failures) == 0) || strings.Join(report.Failures, "|") != strings.Join(tc.failures, "|") {
+				t.Fatalf("report = %+v, want failures %v", report, tc.failures)
+			}
+
+			// SubmitEnhancedBid applies the same check when the tender requires it
+			err := n.submitBid("contractorA", bidFixture("T1", "B1", tc.vendorID, 100))
+			if len(tc.failures) == 0 {
+				expectErr(t, err, "")
+			} else {
+				expectErr(t, err, "contractor "+tc.vendorID+" is not prequalified: "+tc.failures[0])
+			}
+		})
+	}
+
+	// A bidder cannot borrow another organization's registry entry
+	n := newTestNet(t)
+	n.registerVendor("contractorA", vendorFixture("contractorA"))
+	n.registerVendor("buyer", vendorFixture("contractorB"))
+	tender := tenderFixture("T1", t0.Add(time.Hour))
+	tender.BidRequirements.PrequalificationRequired = true
+	n.openTender(tender)
+	err := n.submitBid("contractorB", bidFixture("T1", "B1", "contractorA", 100))
+	expectErr(t, err, "vendor contractorA is registered by ContractorAMSP, not ContractorBMSP")
+	err = n.submitBid("contractorB", bidFixture("T1", "B1", "contractorB", 100))
+	expectErr(t, err, "vendor contractorB is registered by BuyerMSP, not ContractorBMSP")
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 100))
+
+	n = newTestNet(t)
+	err = n.query("buyer", func(ctx *TransactionContext) error {
+		_, err := n.enh.CheckPrequalification(ctx, "T9", "contractorA")
+		return err
+	})
+	expectErr(t, err, "tender T9 not found")
+}
@@ -0,0 +1,309 @@
+package contract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+)
+
+// Retention defaults used when a tender has no RetentionPolicy
+const (
+	defaultLosingBidRetentionDays = 180
+	defaultMilestoneArchiveDays   = 0
+)
+
+// How private data was removed
+const (
+	purgeMethodPurge  = "PURGE"  // PurgePrivateData: removed from the peer's private history as well
+	purgeMethodDelete = "DELETE" // DelPrivateData fallback on peers older than Fabric 2.5
+)
+
+func purgeRecordKey(tenderID, privateKey string) string {
+	return fmt.Sprintf("PURGE_%s_%s", tenderID, privateKey)
+}
+
+func validateRetentionPolicy(policy *RetentionPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.LosingBidDays < 0 || policy.MilestoneArchiveDays < 0 {
+		return fmt.Errorf("retention periods must not be negative")
+	}
+	return nil
+}
+
+func losingBidDays(tender *EnhancedTender) int {
+	if tender.Retention == nil {
+		return defaultLosingBidRetentionDays
+	}
+	return tender.Retention.LosingBidDays
+}
+
+func milestoneArchiveDays(tender *EnhancedTender) int {
+	if tender.Retention == nil {
+		return defaultMilestoneArchiveDays
+	}
+	return tender.Retention.MilestoneArchiveDays
+}
+
+// warrantyMonths is the longest warranty period of the contract
+func warrantyMonths(tender *EnhancedTender) int {
+	months := 0
+	for _, w := range tender.ContractTerms.Warranties {
+		if w.Period > months {
+			months = w.Period
+		}
+	}
+	return months
+}
+
+// recordedTime parses a recorded timestamp, falling back to the tender's last update
+// for tenders written before the timestamp was recorded
+func recordedTime(ts string, tender *EnhancedTender) (time.Time, error) {
+	if ts == "" {
+		ts = tender.UpdatedAt
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp on tender %s: %v", tender.ID, err)
+	}
+	return t, nil
+}
+
+// winningBids returns the bids that must be kept: the award, lot awards and framework members
+func (s *EnhancedSmartContract) winningBids(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) (map[string]bool, error) {
+	winners := make(map[string]bool)
+	if tender.AwardedBidID != "" {
+		winners[tender.AwardedBidID] = true
+	}
+	for _, lot := range tender.Lots {
+		if lot.AwardedBidID != "" {
+			winners[lot.AwardedBidID] = true
+		}
+	}
+	if tender.FrameworkID != "" {
+		framework, err := s.GetFrameworkAgreement(ctx, tender.FrameworkID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range framework.Members {
+			winners[m.BidID] = true
+		}
+	}
+	return winners, nil
+}
+
+// purgeKey removes one private data key and writes its tombstone. Keys without
+// private data (already purged or never written) are skipped.
+func purgeKey(ctx contractapi.TransactionContextInterface, tenderID, collection, key, refType, refID, reason string, txTime time.Time) (bool, error) {
+	hash, err := ctx.GetStub().GetPrivateDataHash(collection, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to get private data hash: %v", err)
+	}
+	if hash == nil {
+		return false, nil
+	}
+
+	method := purgeMethodPurge
+	if err := ctx.GetStub().PurgePrivateData(collection, key); err != nil {
+		method = purgeMethodDelete
+		if err := ctx.GetStub().DelPrivateData(collection, key); err != nil {
+			return false, fmt.Errorf("failed to delete private data: %v", err)
+		}
+	}
+
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return false, err
+	}
+	record := PurgeRecord{
+		TenderID:   tenderID,
+		Collection: collection,
+		Key:        key,
+		RefType:    refType,
+		RefID:      refID,
+		DataHash:   hex.EncodeToString(hash),
+		Reason:     reason,
+		Method:     method,
+		PurgedBy:   mspID,
+		PurgedAt:   txTime.Format(time.RFC3339),
+	}
+	bytes, _ := json.Marshal(record)
+	if err := ctx.GetStub().PutState(purgeRecordKey(tenderID, key), bytes); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// requirePurger lets the tender owner's organization or the regulator role purge its private data
+func requirePurger(ctx contractapi.TransactionContextInterface, tender *EnhancedTender) error {
+	if ctx.GetClientIdentity().AssertAttributeValue("role", roleRegulator) == nil {
+		return nil
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if tender.OwnerMSP == "" || mspID != tender.OwnerMSP {
+		return fmt.Errorf("only the tender owner or the %s role may purge private data of tender %s", roleRegulator, tender.ID)
+	}
+	return nil
+}
+
+// PurgeLosingBids purges the private data of every bid that did not win, once the
+// tender's losing-bid retention period after award has passed
+func (s *EnhancedSmartContract) PurgeLosingBids(ctx contractapi.TransactionContextInterface, tenderID, reason string) (int, error) {
+	if reason == "" {
+		return 0, fmt.Errorf("purge reason is required")
+	}
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return 0, err
+	}
+	if err := requirePurger(ctx, tender); err != nil {
+		return 0, err
+	}
+	if tender.Status != "AWARDED" && tender.Status != "COMPLETED" && tender.Status != "TERMINATED" {
+		return 0, fmt.Errorf("losing bids can only be purged after award")
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	awardedAt, err := recordedTime(tender.AwardedAt, tender)
+	if err != nil {
+		return 0, err
+	}
+	if eligible := awardedAt.AddDate(0, 0, losingBidDays(tender)); txTime.Before(eligible) {
+		return 0, fmt.Errorf("losing bids of tender %s are retained until %s", tenderID, eligible.Format(time.RFC3339))
+	}
+
+	winners, err := s.winningBids(ctx, tender)
+	if err != nil {
+		return 0, err
+	}
+	refs, err := s.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, ref := range refs {
+		if winners[ref.BidID] {
+			continue
+		}
+		keys := []struct{ key, refType string }{
+			{bidPrivKey(tenderID, ref.BidID), "BID"},
+			{auctionOfferKey(tenderID, ref.ContractorID), "AUCTION_OFFER"},
+			{encryptedBidKey(tenderID, ref.BidID), "ENCRYPTED_BID"},
+			{signatureKey(tenderID, sigSubjectBid, ref.BidID), "BID_SIGNATURE"},
+		}
+		for _, k := range keys {
+			ok, err := purgeKey(ctx, tenderID, privateCollectionName, k.key, k.refType, ref.BidID, reason, txTime)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				purged++
+			}
+		}
+	}
+
+	if err := emitEvent(ctx, events.PrivateDataPurged, tenderID, events.PurgePayload{TenderID: tenderID, Kind: "LOSING_BIDS", Purged: purged, Reason: reason}); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// PurgeMilestoneDetails purges a closed-out contract's private milestone details once
+// the longest warranty period plus the archive period has passed
+func (s *EnhancedSmartContract) PurgeMilestoneDetails(ctx contractapi.TransactionContextInterface, tenderID, reason string) (int, error) {
+	if reason == "" {
+		return 0, fmt.Errorf("purge reason is required")
+	}
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return 0, err
+	}
+	if err := requirePurger(ctx, tender); err != nil {
+		return 0, err
+	}
+	if tender.Status != "COMPLETED" && tender.Status != "TERMINATED" {
+		return 0, fmt.Errorf("milestone details can only be purged after contract close-out")
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	closedAt, err := recordedTime(tender.ClosedOutAt, tender)
+	if err != nil {
+		return 0, err
+	}
+	eligible := closedAt.AddDate(0, warrantyMonths(tender), milestoneArchiveDays(tender))
+	if txTime.Before(eligible) {
+		return 0, fmt.Errorf("milestone details of tender %s are retained until %s", tenderID, eligible.Format(time.RFC3339))
+	}
+
+	refs, err := listMilestoneRefs(ctx, tenderID)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, ref := range refs {
+		ok, err := purgeKey(ctx, tenderID, milestonePrivateCollection, milestonePrivKey(tenderID, ref.MilestoneID), "MILESTONE", ref.MilestoneID, reason, txTime)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			purged++
+		}
+	}
+
+	if err := emitEvent(ctx, events.PrivateDataPurged, tenderID, events.PurgePayload{TenderID: tenderID, Kind: "MILESTONE_DETAILS", Purged: purged, Reason: reason}); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// ListPurgeRecords returns the tombstones of a tender's purged private data
+func (s *EnhancedSmartContract) ListPurgeRecords(ctx contractapi.TransactionContextInterface, tenderID string) ([]*PurgeRecord, error) {
+	iter, err := ctx.GetStub().GetStateByRange(fmt.Sprintf("PURGE_%s_", tenderID), fmt.Sprintf("PURGE_%s_~", tenderID))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []*PurgeRecord
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var record PurgeRecord
+		if err := json.Unmarshal(kv.Value, &record); err != nil {
+			return nil, err
+		}
+		if record.TenderID == tenderID {
+			out = append(out, &record)
+		}
+	}
+	return out, nil
+}
+
+// purgeRecord returns the tombstone for a private key, or nil if it was never purged
+func purgeRecord(ctx contractapi.TransactionContextInterface, tenderID, privateKey string) (*PurgeRecord, error) {
+	data, err := ctx.GetStub().GetState(purgeRecordKey(tenderID, privateKey))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var record PurgeRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,212 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+// retentionTender awards T1 to B1 out of bids B1..B3 at t0+2h
+func (n *testNet) retentionTender(policy *RetentionPolicy, warrantyMonths int) {
+	n.t.Helper()
+	tender := tenderFixture("T1", t0.Add(time.Hour))
+	tender.Retention = policy
+	if warrantyMonths > 0 {
+		tender.ContractTerms.Warranties = []Warranty{{Type: "DEFECTS", Period: warrantyMonths}}
+	}
+	n.openTender(tender)
+	n.mustSubmitBid("contractorA", bidFixture("T1", "B1", "contractorA", 500000))
+	n.mustSubmitBid("contractorB", bidFixture("T1", "B2", "contractorB", 600000))
+	n.mustSubmitBid("contractorC", bidFixture("T1", "B3", "contractorC", 700000))
+	n.ledger.SetTime(t0.Add(2 * time.Hour))
+	n.closeTender("T1")
+	n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.AwardTender(ctx, "T1", "B1") })
+}
+
+func (n *testNet) purgeLosingBids(who, reason string) (int, error) {
+	n.t.Helper()
+	var purged int
+	err := n.tx(who, nil, func(ctx *TransactionContext) error {
+		var err error
+		purged, err = n.enh.PurgeLosingBids(ctx, "T1", reason)
+		return err
+	})
+	return purged, err
+}
+
+func TestPurgeLosingBids(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name      string
+		policy    *RetentionPolicy
+		framework bool
+		after     time.Duration
+		reason    string
+		purged    int
+		wantErr   string
+	}{
+		{name: "default period", after: 180 * day, reason: "retention", purged: 2},
+		{name: "default period not reached", after: 180*day - time.Second, reason: "retention", wantErr: "losing bids of tender T1 are retained until " + rfc(t0.Add(2*time.Hour+180*day))},
+		{name: "custom period", policy: &RetentionPolicy{LosingBidDays: 30}, after: 30 * day, reason: "retention", purged: 2},
+		{name: "immediate", policy: &RetentionPolicy{}, reason: "data subject request", purged: 2},
+		{name: "framework members kept", policy: &RetentionPolicy{}, framework: true, reason: "retention", purged: 1},
+		{name: "no reason", policy: &RetentionPolicy{}, wantErr: "purge reason is required"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			if tc.framework {
+				tender := tenderFixture("T1", t0.Add(time.Hour))
+				tender.Retention = tc.policy
+				n.openTender(tender)
+				for _, b := range []struct{ who, bidID string }{{"contractorA", "B1"}, {"contractorB", "B2"}, {"contractorC", "B3"}} {
+					n.mustSubmitBid(b.who, bidFixture("T1", b.bidID, b.who, 500000))
+				}
+				n.ledger.SetTime(t0.Add(2 * time.Hour))
+				n.closeTender("T1")
+				if err := n.createFramework(frameworkFixture()); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				n.retentionTender(tc.policy, 0)
+			}
+			n.ledger.Advance(tc.after)
+
+			purged, err := n.purgeLosingBids("buyer", tc.reason)
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			if purged != tc.purged {
+				t.Fatalf("purged %d, want %d", purged, tc.purged)
+			}
+			n.expectEvents(events.PrivateDataPurged)
+			if n.ledger.PrivateData(privateCollectionName, bidPrivKey("T1", "B1")) == nil {
+				t.Fatal("winning bid was purged")
+			}
+			if n.ledger.PrivateData(privateCollectionName, bidPrivKey("T1", "B3")) != nil {
+				t.Fatal("losing bid B3 was kept")
+			}
+
+			n.mustQuery("regulator", func(ctx *TransactionContext) error {
+				records, err := n.enh.ListPurgeRecords(ctx, "T1")
+				if err != nil {
+					return err
+				}
+				if len(records) != tc.purged {
+					t.Fatalf("records = %+v", records)
+				}
+				for _, r := range records {
+					if r.RefType != "BID" || r.Method != purgeMethodPurge || r.PurgedBy != "BuyerMSP" || r.Reason != tc.reason || r.DataHash == "" {
+						t.Fatalf("record = %+v", r)
+					}
+				}
+				// The audit reports purged bids with their tombstone hash
+				report, err := n.enh.AuditPrivateData(ctx, "T1")
+				if err == nil && (len(report.Issues) != tc.purged || report.Issues[0].Status != auditPurged || report.Issues[0].LedgerHash != records[0].DataHash) {
+					t.Fatalf("audit = %+v", report)
+				}
+				return err
+			})
+
+			// Nothing is left to purge the second time
+			if purged, err := n.purgeLosingBids("buyer", tc.reason); err != nil || purged != 0 {
+				t.Fatalf("second purge = %d, %v", purged, err)
+			}
+		})
+	}
+
+	t.Run("before award", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(tenderFixture("T1", t0.Add(time.Hour)))
+		_, err := n.purgeLosingBids("buyer", "retention")
+		expectErr(t, err, "losing bids can only be purged after award")
+	})
+
+	t.Run("callers", func(t *testing.T) {
+		callers := []struct {
+			who     string
+			wantErr string
+		}{
+			{"auditor", "only the tender owner or the regulator role may purge private data of tender T1"},
+			{"contractorB", "only the tender owner or the regulator role may purge private data of tender T1"},
+			{"buyer2", ""},
+			{"regulator", ""},
+		}
+		for _, c := range callers {
+			t.Run(c.who, func(t *testing.T) {
+				n := newTestNet(t)
+				n.retentionTender(&RetentionPolicy{}, 0)
+				_, err := n.purgeLosingBids(c.who, "retention")
+				expectErr(t, err, c.wantErr)
+			})
+		}
+	})
+}
+
+func TestPurgeMilestoneDetails(t *testing.T) {
+	purge := func(n *testNet, who string) (int, error) {
+		var purged int
+		err := n.tx(who, nil, func(ctx *TransactionContext) error {
+			var err error
+			purged, err = n.enh.PurgeMilestoneDetails(ctx, "T1", "contract archived")
+			return err
+		})
+		return purged, err
+	}
+
+	tests := []struct {
+		name     string
+		policy   *RetentionPolicy
+		warranty int
+		after    func(closedAt time.Time) time.Time
+		wantErr  string
+	}{
+		{"no warranty or archive", nil, 0, func(c time.Time) time.Time { return c }, ""},
+		{"warranty and archive", &RetentionPolicy{MilestoneArchiveDays: 30}, 12, func(c time.Time) time.Time { return c.AddDate(0, 12, 30) }, ""},
+		{"inside warranty", &RetentionPolicy{MilestoneArchiveDays: 30}, 12, func(c time.Time) time.Time { return c.AddDate(0, 12, 30).Add(-time.Second) }, "milestone details of tender T1 are retained until"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.retentionTender(tc.policy, tc.warranty)
+			ms := MilestonePrivate{TenderID: "T1", MilestoneID: "M1", Title: "Base course"}
+			n.mustTx("contractorA", transientOf(t, "milestone", ms), func(ctx *TransactionContext) error {
+				return n.basic.SubmitMilestone(ctx, "T1", "M1")
+			})
+			_, err := purge(n, "buyer")
+			expectErr(t, err, "milestone details can only be purged after contract close-out")
+
+			n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.RecordContractCompletion(ctx, "T1") })
+			n.ledger.SetTime(tc.after(n.ledger.Now()))
+			_, err = purge(n, "contractorA")
+			expectErr(t, err, "only the tender owner or the regulator role may purge private data of tender T1")
+			purged, err := purge(n, "buyer")
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			if purged != 1 || n.ledger.PrivateData(milestonePrivateCollection, milestonePrivKey("T1", "M1")) != nil {
+				t.Fatalf("purged %d", purged)
+			}
+			n.mustQuery("auditor", func(ctx *TransactionContext) error {
+				report, err := n.enh.AuditPrivateData(ctx, "T1")
+				if err == nil && (len(report.Issues) != 1 || report.Issues[0].RefID != "M1" || report.Issues[0].Status != auditPurged) {
+					t.Fatalf("audit = %+v", report)
+				}
+				return err
+			})
+		})
+	}
+}
+
+func TestRetentionPolicyValidation(t *testing.T) {
+	n := newTestNet(t)
+	tender := tenderFixture("T1", t0.Add(time.Hour))
+	tender.Retention = &RetentionPolicy{LosingBidDays: -1}
+	err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+		return n.enh.CreateEnhancedTender(ctx, mustJSON(t, tender))
+	})
+	expectErr(t, err, "retention periods must not be negative")
+}
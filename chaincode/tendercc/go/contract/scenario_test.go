@@ -0,0 +1,136 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/events"
+	"tendercc/mockstub"
+	"tendercc/scenario"
+)
+
+// scenarioDir holds the scenario suites shared with tender-scenario
+const scenarioDir = "../../../../scenarios"
+
+// ledgerInvoker runs scenario calls through the contract API dispatcher, as the
+// peer does, against a mock ledger
+type ledgerInvoker struct {
+	ledger *mockstub.Ledger
+	cc     *contractapi.ContractChaincode
+	ids    map[string]*mockstub.Identity
+	start  time.Time
+}
+
+func newLedgerInvoker(start time.Time) (*ledgerInvoker, error) {
+	cc, err := New()
+	if err != nil {
+		return nil, err
+	}
+	return &ledgerInvoker{ledger: mockstub.NewLedger(start), cc: cc, ids: make(map[string]*mockstub.Identity), start: start}, nil
+}
+
+func (l *ledgerInvoker) Now() time.Time { return l.ledger.Now() }
+
+func (l *ledgerInvoker) Advance(_ context.Context, d time.Duration) error {
+	l.ledger.Advance(d)
+	return nil
+}
+
+// identity returns the actor's enrolment, created on first use
+func (l *ledgerInvoker) identity(call *scenario.Call) (*mockstub.Identity, error) {
+	if id, ok := l.ids[call.Actor]; ok {
+		return id, nil
+	}
+	id, err := mockstub.NewIdentity(call.Identity.MSP, call.Identity.Name, call.Identity.Attrs, l.start)
+	if err != nil {
+		return nil, err
+	}
+	l.ids[call.Actor] = id
+	return id, nil
+}
+
+// Sign signs payload with the actor's enrolment key, which the chaincode checks for signatures without a key ID
+func (l *ledgerInvoker) Sign(_ context.Context, call *scenario.Call, payload []byte) (string, error) {
+	id, err := l.identity(call)
+	if err != nil {
+		return "", err
+	}
+	return id.Sign(payload)
+}
+
+func (l *ledgerInvoker) Invoke(_ context.Context, call *scenario.Call) (*scenario.Result, error) {
+	id, err := l.identity(call)
+	if err != nil {
+		return nil, err
+	}
+	stub := l.ledger.NewStub(id, call.Transient, append([]string{call.Contract + ":" + call.Function}, call.Args...)...)
+	resp := l.cc.Invoke(stub)
+	if resp.Status != shim.OK {
+		return nil, fmt.Errorf("%s", resp.Message)
+	}
+	result := &scenario.Result{Payload: resp.Payload}
+	if !call.Submit {
+		return result, nil
+	}
+	if err := stub.Commit(); err != nil {
+		return nil, err
+	}
+	if ev := stub.Event(); ev != nil {
+		envs, err := events.Parse(ev.Name, ev.Payload)
+		if err != nil {
+			return nil, err
+		}
+		result.Events = envs
+	}
+	return result, nil
+}
+
+// runScenario runs sc on a fresh ledger and fails the test at the first failed step
+func runScenario(t *testing.T, sc *scenario.Scenario) *scenario.Report {
+	t.Helper()
+	start, err := sc.StartTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start.IsZero() {
+		start = t0
+	}
+	inv, err := newLedgerInvoker(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	report, err := (&scenario.Runner{Invoker: inv, Log: &log}).Run(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Passed {
+		t.Fatalf("scenario %s failed:\n%s", sc.Name, log.String())
+	}
+	return report
+}
+
+// TestScenarios runs every suite in scenarios/ in process
+func TestScenarios(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(scenarioDir, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no scenarios in %s", scenarioDir)
+	}
+	for _, path := range paths {
+		sc, err := scenario.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(sc.Name, func(t *testing.T) { runScenario(t, sc) })
+	}
+}
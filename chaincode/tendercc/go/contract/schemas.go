@@ -0,0 +1,33 @@
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/schema"
+)
+
+// GetSchemas returns the JSON Schemas of the RFQ, bid and milestone documents, keyed
+// by type name, so clients can validate a document before submitting it
+func (s *EnhancedSmartContract) GetSchemas(ctx contractapi.TransactionContextInterface) (string, error) {
+	all, err := schema.All()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(all)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// checkDocument validates a submitted document against its schema before it is
+// decoded, so type errors are reported with every other issue. Malformed JSON is
+// left to the decoder, whose error the caller already reports.
+func checkDocument(name string, data []byte) error {
+	if !json.Valid(data) {
+		return nil
+	}
+	return schema.Validate(name, data)
+}
@@ -0,0 +1,128 @@
+package contract
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tendercc/schema"
+)
+
+// patchJSON encodes v and applies edits to top-level or dotted object paths; a nil
+// value deletes the key
+func patchJSON(t *testing.T, v interface{}, edits map[string]interface{}) []byte {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(mustJSON(t, v)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for path, value := range edits {
+		obj := doc
+		keys := strings.Split(path, ".")
+		for _, k := range keys[:len(keys)-1] {
+			obj = obj[k].(map[string]interface{})
+		}
+		if value == nil {
+			delete(obj, keys[len(keys)-1])
+		} else {
+			obj[keys[len(keys)-1]] = value
+		}
+	}
+	return []byte(mustJSON(t, doc))
+}
+
+func TestTenderSchema(t *testing.T) {
+	deadline := t0.Add(7 * 24 * time.Hour)
+	tests := []struct {
+		name    string
+		edits   map[string]interface{}
+		wantErr string
+	}{
+		{"valid", nil, ""},
+		{"omitted fields", map[string]interface{}{"contractTerms": nil, "complianceRequirements": nil, "status": nil}, ""},
+		{"misspelled field", map[string]interface{}{"ownerDetails.organisationName": "Roads"}, "EnhancedTender schema validation failed: ownerDetails.organisationName: unknown field"},
+		{"unknown top-level field", map[string]interface{}{"priority": "HIGH"}, "priority: unknown field"},
+		{"wrong type", map[string]interface{}{"version": "one"}, "version: expected integer, got string"},
+		{"every issue reported", map[string]interface{}{"projectScope.budgt": 1, "status": "LIVE", "version": 1.5}, `projectScope.budgt: unknown field; status: "LIVE" is not one of "DRAFT", "OPEN", "CLOSED", "AWARDED", "CANCELLED", "COMPLETED", "TERMINATED"; version: expected integer, got number`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			js := string(patchJSON(t, tenderFixture("T1", deadline), tc.edits))
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateEnhancedTender(ctx, js)
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestBidAndMilestoneSchemas(t *testing.T) {
+	t.Run("bid", func(t *testing.T) {
+		n := newTestNet(t)
+		n.openTender(tenderFixture("T1", t0.Add(7*24*time.Hour)))
+		bid := bidFixture("T1", "B1", "contractorA", 500000)
+		bid.TechnicalProposal.RiskMitigation = []RiskMitigation{{Risk: "Rain", Probability: "SOMETIMES", Impact: "HIGH"}}
+		transient := map[string][]byte{"bid": patchJSON(t, bid, map[string]interface{}{"totalAmmount": 1})}
+		err := n.tx("contractorA", transient, func(ctx *TransactionContext) error {
+			return n.enh.SubmitEnhancedBid(ctx, "T1", "B1")
+		})
+		expectErr(t, err, `EnhancedBidPrivate schema validation failed: technicalProposal.riskMitigation.0.probability: "SOMETIMES" is not one of "LOW", "MEDIUM", "HIGH"; totalAmmount: unknown field`)
+	})
+
+	t.Run("milestone", func(t *testing.T) {
+		n := newTestNet(t)
+		n.createLegacy("L1")
+		ms := MilestonePrivate{TenderID: "L1", MilestoneID: "M1", Title: "Deck poured", Amount: 250}
+		transient := map[string][]byte{"milestone": patchJSON(t, ms, map[string]interface{}{"evidence": "ev1"})}
+		err := n.tx("contractorA", transient, func(ctx *TransactionContext) error {
+			return n.basic.SubmitMilestone(ctx, "L1", "M1")
+		})
+		expectErr(t, err, "MilestonePrivate schema validation failed: evidence: unknown field")
+	})
+}
+
+func TestGetSchemas(t *testing.T) {
+	n := newTestNet(t)
+	var out string
+	n.mustQuery("contractorA", func(ctx *TransactionContext) error {
+		var err error
+		out, err = n.enh.GetSchemas(ctx)
+		return err
+	})
+	var schemas map[string]struct {
+		Title                string                     `json:"title"`
+		AdditionalProperties bool                       `json:"additionalProperties"`
+		Properties           map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(out), &schemas); err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 3 || schemas[schema.EnhancedTender].Title != "EnhancedTender" || schemas[schema.MilestonePrivate].AdditionalProperties {
+		t.Fatalf("schemas = %s", out)
+	}
+	if got := string(schemas[schema.EnhancedTender].Properties["status"]); !strings.Contains(got, `"enum":["","DRAFT","OPEN"`) {
+		t.Fatalf("status schema = %s", got)
+	}
+}
+
+// TestSamplesMatchSchemas keeps the documents in samples/ valid for clients that copy them
+func TestSamplesMatchSchemas(t *testing.T) {
+	samples := map[string]string{
+		"rfq/construction-rfq-sample.json": schema.EnhancedTender,
+		"bid/construction-bid-sample.json": schema.EnhancedBidPrivate,
+		"milestone/milestone-sample.json":  schema.MilestonePrivate,
+	}
+	for path, name := range samples {
+		data, err := os.ReadFile(filepath.Join("../../../../samples", path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := schema.Validate(name, data); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,525 @@
+package contract
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/canonical"
+	"tendercc/events"
+)
+
+// Payload types that can carry a detached signature
+const (
+	sigSubjectBid       = "BID"
+	sigSubjectAward     = "AWARD"
+	sigSubjectContract  = "CONTRACT"
+	sigSubjectMilestone = "MILESTONE_APPROVAL"
+)
+
+// How a signature was verified
+const (
+	sigMethodEnrolledCert  = "ENROLLED_CERT"
+	sigMethodRegisteredKey = "REGISTERED_KEY"
+)
+
+func signingKeyKey(keyID string) string {
+	return fmt.Sprintf("SIGKEY_%s", keyID)
+}
+
+func signatureKey(tenderID, subject, refID string) string {
+	return fmt.Sprintf("SIG_%s_%s_%s", tenderID, subject, refID)
+}
+
+func signaturesRequired(tender *EnhancedTender) bool {
+	return tender != nil && tender.BidRequirements.SubmissionFormat.DigitalSignature
+}
+
+// ownerSigner returns the signer the tender owner authorized for awards, milestone
+// approvals and the contract; it is empty when the tender names none
+func ownerSigner(tender *EnhancedTender) string {
+	if tender == nil {
+		return ""
+	}
+	return tender.OwnerDetails.AuthorizedBy.SignerID
+}
+
+// loadTenderForSignatures reads a tender of either contract type; missing tenders need no signatures
+func loadTenderForSignatures(ctx contractapi.TransactionContextInterface, tenderID string) (*EnhancedTender, error) {
+	data, err := ctx.GetStub().GetState(tenderKey(tenderID))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var tender EnhancedTender
+	if err := json.Unmarshal(data, &tender); err != nil {
+		return nil, err
+	}
+	return &tender, nil
+}
+
+// applySignature verifies the transient detached signature over payload and records the result.
+// Missing or invalid signatures fail the transaction only when required.
+// ownerID must own the registered key or enrolled certificate used for signing.
+func applySignature(ctx contractapi.TransactionContextInterface, tenderID, subject, refID string, payload []byte, ownerID string, required bool) (*SignatureRecord, error) {
+	record, err := verifySignature(ctx, tenderID, subject, refID, payload, ownerID, required)
+	if err != nil || record == nil {
+		return record, err
+	}
+	recordBytes, _ := json.Marshal(record)
+	if err := ctx.GetStub().PutState(signatureKey(tenderID, subject, refID), recordBytes); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// verifySignature is applySignature without recording the result; the record is nil
+// when no signature was given and none is required
+func verifySignature(ctx contractapi.TransactionContextInterface, tenderID, subject, refID string, payload []byte, ownerID string, required bool) (*SignatureRecord, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient: %v", err)
+	}
+	sigBytes, ok := transient["signature"]
+	if !ok {
+		if required {
+			return nil, fmt.Errorf("tender %s requires a signed %s payload", tenderID, subject)
+		}
+		return nil, nil
+	}
+	var sig DetachedSignature
+	if err := json.Unmarshal(sigBytes, &sig); err != nil {
+		return nil, fmt.Errorf("invalid signature JSON: %v", err)
+	}
+	signed, err := canonical.JSON(payload)
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(signed)
+	record := SignatureRecord{
+		TenderID:    tenderID,
+		Subject:     subject,
+		RefID:       refID,
+		KeyID:       sig.KeyID,
+		PayloadHash: hex.EncodeToString(digest[:]),
+		Signature:   sig.Signature,
+		RecordedAt:  txTime.Format(time.RFC3339),
+	}
+	verr := fmt.Errorf("tender %s names no authorized signer", tenderID)
+	if ownerID != "" {
+		verr = verifyDetachedSignature(ctx, &record, &sig, signed, ownerID, txTime)
+	}
+	if verr != nil {
+		if required {
+			return nil, fmt.Errorf("signature verification failed: %v", verr)
+		}
+		record.Error = verr.Error()
+	} else {
+		record.Verified = true
+	}
+	return &record, nil
+}
+
+// verifyDetachedSignature resolves the signer's key, fills in the signer fields and checks the signature
+func verifyDetachedSignature(ctx contractapi.TransactionContextInterface, record *SignatureRecord, sig *DetachedSignature, payload []byte, ownerID string, txTime time.Time) error {
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	record.SignerMSP = mspID
+
+	var pub crypto.PublicKey
+	if sig.KeyID == "" {
+		record.Method = sigMethodEnrolledCert
+		cert, err := ctx.GetClientIdentity().GetX509Certificate()
+		if err != nil || cert == nil {
+			return fmt.Errorf("failed to get enrolled certificate: %v", err)
+		}
+		record.SignerID = cert.Subject.CommonName
+		if txTime.Before(cert.NotBefore) || txTime.After(cert.NotAfter) {
+			return fmt.Errorf("enrolled certificate is not valid at transaction time")
+		}
+		if record.SignerID != ownerID {
+			return fmt.Errorf("enrolled certificate of %s does not belong to %s", record.SignerID, ownerID)
+		}
+		pub = cert.PublicKey
+	} else {
+		record.Method = sigMethodRegisteredKey
+		key, err := getSigningKey(ctx, sig.KeyID)
+		if err != nil {
+			return err
+		}
+		record.SignerID = key.OwnerID
+		record.SignerMSP = key.RegisteredMSP
+		if key.Revoked {
+			return fmt.Errorf("signing key %s has been revoked", sig.KeyID)
+		}
+		if key.OwnerID != ownerID {
+			return fmt.Errorf("signing key %s does not belong to %s", sig.KeyID, ownerID)
+		}
+		pub, err = parsePKIXPublicKey(key.PublicKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil || len(raw) == 0 {
+		return fmt.Errorf("signature must be non-empty base64")
+	}
+	return verifyWithKey(pub, payload, raw)
+}
+
+func verifyWithKey(pub crypto.PublicKey, payload, sig []byte) error {
+	digest := sha256.Sum256(payload)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], sig) {
+			return fmt.Errorf("invalid ECDSA signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig); err != nil {
+			return fmt.Errorf("invalid RSA signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return fmt.Errorf("invalid Ed25519 signature")
+		}
+	default:
+		return fmt.Errorf("unsupported signing key type %T", pub)
+	}
+	return nil
+}
+
+func parsePKIXPublicKey(pemKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("public key must be a PEM encoded PUBLIC KEY block")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	return pub, nil
+}
+
+func getSigningKey(ctx contractapi.TransactionContextInterface, keyID string) (*SigningKey, error) {
+	data, err := ctx.GetStub().GetState(signingKeyKey(keyID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("signing key %s not found", keyID)
+	}
+	var key SigningKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// awardPayload is the payload signed when awarding a tender
+func awardPayload(tenderID, bidID string) ([]byte, error) {
+	return canonicalMarshal(map[string]string{"tenderId": tenderID, "bidId": bidID, "action": "AWARD"})
+}
+
+// lotAwardPayload is the payload signed when awarding one lot of a tender
+func lotAwardPayload(tenderID, lotID, bidID string) ([]byte, error) {
+	return canonicalMarshal(map[string]string{"tenderId": tenderID, "lotId": lotID, "bidId": bidID, "action": "AWARD"})
+}
+
+// lotsAwardPayload is the payload signed when awarding every lot with the tender's lot award mode
+func lotsAwardPayload(tenderID string) ([]byte, error) {
+	return canonicalMarshal(map[string]string{"tenderId": tenderID, "action": "AWARD_LOTS"})
+}
+
+// milestoneApprovalPayload is the payload signed when approving a milestone
+func milestoneApprovalPayload(tenderID, milestoneID string) ([]byte, error) {
+	return canonicalMarshal(map[string]string{"tenderId": tenderID, "milestoneId": milestoneID, "action": "APPROVE_MILESTONE"})
+}
+
+// contractPayload is the payload each party signs to execute the awarded contract
+func contractPayload(tender *EnhancedTender) ([]byte, error) {
+	return canonicalMarshal(map[string]interface{}{
+		"tenderId":      tender.ID,
+		"awardedBidId":  tender.AwardedBidID,
+		"contractTerms": tender.ContractTerms,
+		"action":        "SIGN_CONTRACT",
+	})
+}
+
+// requireKeyRegistrar lets the registrar role bind a key to anyone; otherwise ownerID must
+// be a vendor registered by the caller's organization
+func (s *EnhancedSmartContract) requireKeyRegistrar(ctx contractapi.TransactionContextInterface, ownerID string) error {
+	if ctx.GetClientIdentity().AssertAttributeValue("role", roleRegistrar) == nil {
+		return nil
+	}
+	data, err := ctx.GetStub().GetState(vendorKey(ownerID))
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("caller must have the %s role to bind a key to %s", roleRegistrar, ownerID)
+	}
+	var vendor VendorProfile
+	if err := json.Unmarshal(data, &vendor); err != nil {
+		return err
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if mspID != vendor.RegisteredBy {
+		return fmt.Errorf("only %s or the %s role may bind a key to vendor %s", vendor.RegisteredBy, roleRegistrar, ownerID)
+	}
+	return nil
+}
+
+// RegisterSigningKey registers a public key that can sign payloads on behalf of ownerID.
+// Only the organization that registered the vendor ownerID, or the registrar role, may do so.
+func (s *EnhancedSmartContract) RegisterSigningKey(ctx contractapi.TransactionContextInterface, keyID, ownerID, publicKeyPEM string) error {
+	if keyID == "" || ownerID == "" {
+		return fmt.Errorf("key ID and owner ID are required")
+	}
+	if err := s.requireKeyRegistrar(ctx, ownerID); err != nil {
+		return err
+	}
+	if _, err := parsePKIXPublicKey(publicKeyPEM); err != nil {
+		return err
+	}
+	exists, err := s.assetExists(ctx, signingKeyKey(keyID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("signing key %s already exists", keyID)
+	}
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	key := SigningKey{
+		KeyID:         keyID,
+		OwnerID:       ownerID,
+		PublicKey:     publicKeyPEM,
+		RegisteredBy:  clientID,
+		RegisteredMSP: mspID,
+		RegisteredAt:  txTime.Format(time.RFC3339),
+	}
+	bytes, _ := json.Marshal(key)
+	if err := ctx.GetStub().PutState(signingKeyKey(keyID), bytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.SigningKeyRegistered, "", events.SigningKeyPayload{KeyID: keyID, OwnerID: ownerID})
+}
+
+// RevokeSigningKey revokes a registered key; only the registering identity may revoke it
+func (s *EnhancedSmartContract) RevokeSigningKey(ctx contractapi.TransactionContextInterface, keyID string) error {
+	key, err := getSigningKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.Revoked {
+		return fmt.Errorf("signing key %s is already revoked", keyID)
+	}
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if clientID != key.RegisteredBy {
+		return fmt.Errorf("only the registering identity can revoke signing key %s", keyID)
+	}
+	txTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	key.Revoked = true
+	key.RevokedAt = txTime.Format(time.RFC3339)
+	bytes, _ := json.Marshal(key)
+	if err := ctx.GetStub().PutState(signingKeyKey(keyID), bytes); err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.SigningKeyRevoked, "", events.SigningKeyPayload{KeyID: keyID, OwnerID: key.OwnerID, RevokedAt: key.RevokedAt})
+}
+
+// GetSigningKey returns a registered signing key
+func (s *EnhancedSmartContract) GetSigningKey(ctx contractapi.TransactionContextInterface, keyID string) (*SigningKey, error) {
+	return getSigningKey(ctx, keyID)
+}
+
+// GetSigningPayload returns the canonical payload a client must sign for an award,
+// contract signature or milestone approval. refID is the bid ID for AWARD, LOT/BID when
+// awarding a single lot, or empty for AwardLots; it is the milestone ID for
+// MILESTONE_APPROVAL and is ignored for CONTRACT. Frameworks are signed as submitted.
+func (s *EnhancedSmartContract) GetSigningPayload(ctx contractapi.TransactionContextInterface, tenderID, subject, refID string) (string, error) {
+	var payload []byte
+	var err error
+	switch subject {
+	case sigSubjectAward:
+		if lotID, bidID, ok := strings.Cut(refID, "/"); ok {
+			payload, err = lotAwardPayload(tenderID, lotID, bidID)
+		} else if refID == "" {
+			payload, err = lotsAwardPayload(tenderID)
+		} else {
+			payload, err = awardPayload(tenderID, refID)
+		}
+	case sigSubjectMilestone:
+		payload, err = milestoneApprovalPayload(tenderID, refID)
+	case sigSubjectContract:
+		tender, terr := s.GetEnhancedTender(ctx, tenderID)
+		if terr != nil {
+			return "", terr
+		}
+		payload, err = contractPayload(tender)
+	default:
+		return "", fmt.Errorf("signing payload is not derived for subject %s; bids are signed as submitted", subject)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// SignContract records a party's signature on the awarded contract.
+// The signature is mandatory and is keyed by the signing key, or by MSP for enrolled certificates.
+// Only the owner's authorized signer and the awarded contractors may sign.
+func (s *EnhancedSmartContract) SignContract(ctx contractapi.TransactionContextInterface, tenderID string) error {
+	tender, err := s.GetEnhancedTender(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	if tender.Status != "AWARDED" {
+		return fmt.Errorf("only awarded tenders have a contract to sign")
+	}
+	payload, err := contractPayload(tender)
+	if err != nil {
+		return err
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient: %v", err)
+	}
+	var sig DetachedSignature
+	if err := json.Unmarshal(transient["signature"], &sig); err != nil {
+		return fmt.Errorf("transient map must contain a valid 'signature'")
+	}
+	refID := sig.KeyID
+	if refID == "" {
+		if refID, err = clientMSPID(ctx); err != nil {
+			return err
+		}
+	}
+	exists, err := s.assetExists(ctx, signatureKey(tenderID, sigSubjectContract, refID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("contract for tender %s already signed by %s", tenderID, refID)
+	}
+
+	signer := ownerSigner(tender)
+	if contractors, err := awardedContractors(ctx, tender); err == nil {
+		if claimed, err := claimedSigner(ctx, &sig); err == nil {
+			for _, contractor := range contractors {
+				if claimed == contractor {
+					signer = contractor
+				}
+			}
+		}
+	}
+	record, err := applySignature(ctx, tenderID, sigSubjectContract, refID, payload, signer, true)
+	if err != nil {
+		return err
+	}
+	return emitEvent(ctx, events.ContractSigned, tenderID, events.ContractSignedPayload{
+		TenderID:  tenderID,
+		SignerID:  record.SignerID,
+		SignerMSP: record.SignerMSP,
+		SignedAt:  record.RecordedAt,
+	})
+}
+
+// claimedSigner returns who a signature claims to be from: the owner of its registered key,
+// or the enrollment ID of the caller's certificate
+func claimedSigner(ctx contractapi.TransactionContextInterface, sig *DetachedSignature) (string, error) {
+	if sig.KeyID != "" {
+		key, err := getSigningKey(ctx, sig.KeyID)
+		if err != nil {
+			return "", err
+		}
+		return key.OwnerID, nil
+	}
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil || cert == nil {
+		return "", fmt.Errorf("failed to get enrolled certificate: %v", err)
+	}
+	return cert.Subject.CommonName, nil
+}
+
+// GetSignatureRecord returns the verification record for a signed payload
+func (s *EnhancedSmartContract) GetSignatureRecord(ctx contractapi.TransactionContextInterface, tenderID, subject, refID string) (*SignatureRecord, error) {
+	data, err := ctx.GetStub().GetState(signatureKey(tenderID, subject, refID))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no signature recorded for %s %s on tender %s", subject, refID, tenderID)
+	}
+	var record SignatureRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListSignatures returns all signature records for a tender
+func (s *EnhancedSmartContract) ListSignatures(ctx contractapi.TransactionContextInterface, tenderID string) ([]*SignatureRecord, error) {
+	iter, err := ctx.GetStub().GetStateByRange(fmt.Sprintf("SIG_%s_", tenderID), fmt.Sprintf("SIG_%s_~", tenderID))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []*SignatureRecord
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		var record SignatureRecord
+		if err := json.Unmarshal(kv.Value, &record); err != nil {
+			return nil, err
+		}
+		if record.TenderID != tenderID {
+			continue // tender IDs sharing a prefix
+		}
+		out = append(out, &record)
+	}
+	return out, nil
+}
@@ -0,0 +1,389 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"tendercc/canonical"
+	"tendercc/events"
+	"tendercc/mockstub"
+)
+
+// signedBy returns a transient map holding signer's detached signature over the canonical payload
+func signedBy(t *testing.T, signer *mockstub.Identity, keyID string, payload []byte) map[string][]byte {
+	t.Helper()
+	signed, err := canonical.JSON(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.Sign(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transientOf(t, "signature", DetachedSignature{Signature: sig, KeyID: keyID})
+}
+
+func with(maps ...map[string][]byte) map[string][]byte {
+	out := make(map[string][]byte)
+	for _, m := range maps {
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// registerKey registers a fresh key pair for ownerID and returns the identity holding its private key
+func (n *testNet) registerKey(who, keyID, ownerID string) *mockstub.Identity {
+	n.t.Helper()
+	holder, err := mockstub.NewIdentity("OfflineMSP", keyID, nil, t0)
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	pem, err := holder.PublicKeyPEM()
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	n.mustTx(who, nil, func(ctx *TransactionContext) error {
+		return n.enh.RegisterSigningKey(ctx, keyID, ownerID, pem)
+	})
+	return holder
+}
+
+func TestSigningKeys(t *testing.T) {
+	n := newTestNet(t)
+	n.registerKey("registrar", "K1", "contractorA")
+	n.expectEvents(events.SigningKeyRegistered)
+	pem, _ := n.identity("buyer").PublicKeyPEM()
+
+	n.registerVendor("buyer", vendorFixture("contractorV"))
+
+	registrations := []struct {
+		name    string
+		who     string
+		keyID   string
+		ownerID string
+		pem     string
+		wantErr string
+	}{
+		{"no key id", "registrar", "", "contractorA", pem, "key ID and owner ID are required"},
+		{"no owner", "registrar", "K2", "", pem, "key ID and owner ID are required"},
+		{"not pem", "registrar", "K2", "contractorA", "ssh-ed25519 AAAA", "public key must be a PEM encoded PUBLIC KEY block"},
+		{"duplicate", "registrar", "K1", "contractorA", pem, "signing key K1 already exists"},
+		{"self-registered", "contractorA", "K2", "contractorA", pem, "caller must have the registrar role to bind a key to contractorA"},
+		{"officer without role", "buyer", "K2", "buyer", pem, "caller must have the registrar role to bind a key to buyer"},
+		{"vendor of another org", "auditor", "K3", "contractorV", pem, "only BuyerMSP or the registrar role may bind a key to vendor contractorV"},
+		{"vendor registrar", "buyer", "K3", "contractorV", pem, ""},
+		{"registrar", "registrar", "K2", "buyer", pem, ""},
+	}
+	for _, r := range registrations {
+		err := n.tx(r.who, nil, func(ctx *TransactionContext) error {
+			return n.enh.RegisterSigningKey(ctx, r.keyID, r.ownerID, r.pem)
+		})
+		expectErr(t, err, r.wantErr)
+	}
+
+	revocations := []struct {
+		name    string
+		who     string
+		keyID   string
+		wantErr string
+	}{
+		{"unknown key", "registrar", "K9", "signing key K9 not found"},
+		{"same org, other identity", "buyer2", "K3", "only the registering identity can revoke signing key K3"},
+		{"registrar", "registrar", "K1", ""},
+		{"twice", "registrar", "K1", "signing key K1 is already revoked"},
+	}
+	for _, r := range revocations {
+		err := n.tx(r.who, nil, func(ctx *TransactionContext) error { return n.enh.RevokeSigningKey(ctx, r.keyID) })
+		expectErr(t, err, r.wantErr)
+	}
+	n.expectEvents(events.SigningKeyRevoked)
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		key, err := n.enh.GetSigningKey(ctx, "K1")
+		if err == nil && (!key.Revoked || key.RevokedAt != rfc(t0) || key.RegisteredMSP != "RegistryMSP" || key.RegisteredBy != n.clientID("registrar")) {
+			t.Fatalf("key = %+v", key)
+		}
+		return err
+	})
+}
+
+func TestBidSignatures(t *testing.T) {
+	bid := bidFixture("T1", "B1", "contractorA", 500000)
+	bidBytes := []byte(mustJSON(t, bid))
+
+	tests := []struct {
+		name     string
+		required bool
+		signer   string // identity signing with its enrolment key
+		keyID    string // registered key to sign with instead
+		method   string
+		verified bool
+		sigErr   string
+		wantErr  string
+	}{
+		{name: "required, missing", required: true, wantErr: "tender T1 requires a signed BID payload"},
+		{name: "optional, missing", required: false},
+		{name: "enrolled certificate", required: true, signer: "contractorA", method: sigMethodEnrolledCert, verified: true},
+		{name: "registered key", required: true, keyID: "K1", method: sigMethodRegisteredKey, verified: true},
+		{name: "someone else's certificate", required: true, signer: "contractorB", wantErr: "signature verification failed: invalid ECDSA signature"},
+		{name: "key of another owner", required: true, keyID: "KB", wantErr: "signature verification failed: signing key KB does not belong to contractorA"},
+		{name: "revoked key", required: true, keyID: "KR", wantErr: "signature verification failed: signing key KR has been revoked"},
+		{name: "unknown key", required: true, keyID: "K9", wantErr: "signature verification failed: signing key K9 not found"},
+		{name: "optional, invalid", required: false, signer: "contractorB", method: sigMethodEnrolledCert, sigErr: "invalid ECDSA signature"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			keys := map[string]*mockstub.Identity{
+				"K1": n.registerKey("registrar", "K1", "contractorA"),
+				"KB": n.registerKey("registrar", "KB", "contractorB"),
+				"KR": n.registerKey("registrar", "KR", "contractorA"),
+				"K9": n.identity("contractorA"),
+			}
+			n.mustTx("registrar", nil, func(ctx *TransactionContext) error { return n.enh.RevokeSigningKey(ctx, "KR") })
+			tender := tenderFixture("T1", t0.Add(time.Hour))
+			tender.BidRequirements.SubmissionFormat.DigitalSignature = tc.required
+			n.openTender(tender)
+
+			transient := map[string][]byte{"bid": bidBytes}
+			switch {
+			case tc.signer != "":
+				transient = with(transient, signedBy(t, n.identity(tc.signer), "", bidBytes))
+			case tc.keyID != "":
+				transient = with(transient, signedBy(t, keys[tc.keyID], tc.keyID, bidBytes))
+			}
+			err := n.tx("contractorA", transient, func(ctx *TransactionContext) error {
+				return n.enh.SubmitEnhancedBid(ctx, "T1", "B1")
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+
+			err = n.query("auditor", func(ctx *TransactionContext) error {
+				record, err := n.enh.GetSignatureRecord(ctx, "T1", sigSubjectBid, "B1")
+				if err != nil {
+					return err
+				}
+				_, hash, _ := canonicalHash(bidBytes)
+				if record.Verified != tc.verified || record.Method != tc.method || record.Error != tc.sigErr || record.PayloadHash != hash {
+					t.Fatalf("record = %+v", record)
+				}
+				if tc.keyID != "" && (record.SignerID != "contractorA" || record.KeyID != tc.keyID) {
+					t.Fatalf("key signer = %+v", record)
+				}
+				// Enrolment certificate signatures are always attributed to the submitter
+				if tc.signer != "" && (record.SignerID != "contractorA" || record.SignerMSP != "ContractorAMSP") {
+					t.Fatalf("certificate signer = %+v", record)
+				}
+				return nil
+			})
+			if tc.method == "" {
+				expectErr(t, err, "no signature recorded for BID B1 on tender T1")
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestAwardContractAndMilestoneSignatures(t *testing.T) {
+	n := newTestNet(t)
+	k1 := n.registerKey("registrar", "K1", "contractorA")
+	tender := tenderFixture("T1", t0.Add(time.Hour))
+	tender.BidRequirements.SubmissionFormat.DigitalSignature = true
+	unsigned := *tender
+	unsigned.OwnerDetails.AuthorizedBy.SignerID = ""
+	err := n.tx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.CreateEnhancedTender(ctx, mustJSON(t, &unsigned)) })
+	expectErr(t, err, "ownerDetails.authorizedBy.signerId: an authorized signer is required when digital signatures are required")
+	n.openTender(tender)
+	bidBytes := []byte(mustJSON(t, bidFixture("T1", "B1", "contractorA", 500000)))
+	n.mustTx("contractorA", with(map[string][]byte{"bid": bidBytes}, signedBy(t, n.identity("contractorA"), "", bidBytes)), func(ctx *TransactionContext) error {
+		return n.enh.SubmitEnhancedBid(ctx, "T1", "B1")
+	})
+	n.ledger.SetTime(t0.Add(time.Hour))
+	n.closeTender("T1")
+
+	payload := func(subject, refID string) []byte {
+		var out string
+		n.mustQuery("buyer", func(ctx *TransactionContext) error {
+			var err error
+			out, err = n.enh.GetSigningPayload(ctx, "T1", subject, refID)
+			return err
+		})
+		return []byte(out)
+	}
+	err = n.query("buyer", func(ctx *TransactionContext) error {
+		_, err := n.enh.GetSigningPayload(ctx, "T1", sigSubjectBid, "B1")
+		return err
+	})
+	expectErr(t, err, "signing payload is not derived for subject BID; bids are signed as submitted")
+
+	sign := func(who string, transient map[string][]byte) error {
+		return n.tx(who, transient, func(ctx *TransactionContext) error { return n.enh.SignContract(ctx, "T1") })
+	}
+	expectErr(t, sign("buyer", signedBy(t, n.identity("buyer"), "", payload(sigSubjectContract, ""))), "only awarded tenders have a contract to sign")
+
+	award := func(transient map[string][]byte) error {
+		return n.tx("buyer", transient, func(ctx *TransactionContext) error { return n.enh.AwardTender(ctx, "T1", "B1") })
+	}
+	expectErr(t, award(nil), "tender T1 requires a signed AWARD payload")
+	expectErr(t, award(signedBy(t, n.identity("buyer"), "", payload(sigSubjectAward, "B2"))), "signature verification failed: invalid ECDSA signature")
+	// Only the owner's authorized signer may sign the award
+	expectErr(t, award(signedBy(t, k1, "K1", payload(sigSubjectAward, "B1"))), "signature verification failed: signing key K1 does not belong to buyer")
+	err = n.tx("buyer2", signedBy(t, n.identity("buyer2"), "", payload(sigSubjectAward, "B1")), func(ctx *TransactionContext) error {
+		return n.enh.AwardTender(ctx, "T1", "B1")
+	})
+	expectErr(t, err, "signature verification failed: enrolled certificate of buyer2 does not belong to buyer")
+	expectErr(t, award(signedBy(t, n.identity("buyer"), "", payload(sigSubjectAward, "B1"))), "")
+
+	contract := payload(sigSubjectContract, "")
+	signings := []struct {
+		name      string
+		who       string
+		transient map[string][]byte
+		wantErr   string
+	}{
+		{"no signature", "buyer", nil, "transient map must contain a valid 'signature'"},
+		{"buyer certificate", "buyer", signedBy(t, n.identity("buyer"), "", contract), ""},
+		{"buyer again", "buyer2", signedBy(t, n.identity("buyer2"), "", contract), "contract for tender T1 already signed by BuyerMSP"},
+		{"other contractor", "contractorB", signedBy(t, n.identity("contractorB"), "", contract), "signature verification failed: enrolled certificate of contractorB does not belong to buyer"},
+		{"contractor key, wrong payload", "contractorA", signedBy(t, k1, "K1", []byte(`{"action":"SIGN_CONTRACT"}`)), "signature verification failed: invalid ECDSA signature"},
+		{"contractor key", "contractorA", signedBy(t, k1, "K1", contract), ""},
+	}
+	for _, s := range signings {
+		expectErr(t, sign(s.who, s.transient), s.wantErr)
+		if s.wantErr == "" {
+			n.expectEvents(events.ContractSigned)
+		}
+	}
+
+	ms := MilestonePrivate{TenderID: "T1", MilestoneID: "M1", Title: "Base course"}
+	n.mustTx("contractorA", transientOf(t, "milestone", ms), func(ctx *TransactionContext) error {
+		return n.basic.SubmitMilestone(ctx, "T1", "M1")
+	})
+	approve := func(transient map[string][]byte) error {
+		return n.tx("buyer", transient, func(ctx *TransactionContext) error { return n.basic.ApproveMilestone(ctx, "T1", "M1") })
+	}
+	expectErr(t, approve(nil), "tender T1 requires a signed MILESTONE_APPROVAL payload")
+	err = n.tx("contractorA", signedBy(t, n.identity("contractorA"), "", payload(sigSubjectMilestone, "M1")), func(ctx *TransactionContext) error {
+		return n.basic.ApproveMilestone(ctx, "T1", "M1")
+	})
+	expectErr(t, err, "signature verification failed: enrolled certificate of contractorA does not belong to buyer")
+	expectErr(t, approve(signedBy(t, n.identity("buyer"), "", payload(sigSubjectMilestone, "M1"))), "")
+
+	n.mustQuery("auditor", func(ctx *TransactionContext) error {
+		records, err := n.enh.ListSignatures(ctx, "T1")
+		if err != nil {
+			return err
+		}
+		want := map[string]string{
+			sigSubjectBid + "/B1":            "contractorA",
+			sigSubjectAward + "/B1":          "buyer",
+			sigSubjectContract + "/BuyerMSP": "buyer",
+			sigSubjectContract + "/K1":       "contractorA",
+			sigSubjectMilestone + "/M1":      "buyer",
+		}
+		if len(records) != len(want) {
+			t.Fatalf("records = %d, want %d", len(records), len(want))
+		}
+		for _, r := range records {
+			if signer, ok := want[r.Subject+"/"+r.RefID]; !ok || r.SignerID != signer || !r.Verified {
+				t.Fatalf("record = %+v", r)
+			}
+		}
+		return nil
+	})
+
+	// Enrolment certificates expire; the signature is checked at transaction time
+	t.Run("expired certificate", func(t *testing.T) {
+		n.ledger.SetTime(t0.AddDate(11, 0, 0))
+		err := sign("contractorB", signedBy(t, n.identity("contractorB"), "", contract))
+		expectErr(t, err, "signature verification failed: enrolled certificate is not valid at transaction time")
+	})
+}
+
+func TestLotAndFrameworkAwardSignatures(t *testing.T) {
+	// signedTender closes a tender that requires signatures, with signed bids
+	signedTender := func(n *testNet, tender *EnhancedTender, bids ...*EnhancedBidPrivate) {
+		tender.BidRequirements.SubmissionFormat.DigitalSignature = true
+		n.openTender(tender)
+		for _, bid := range bids {
+			bidBytes := []byte(mustJSON(t, bid))
+			n.mustTx(bid.ContractorID, with(map[string][]byte{"bid": bidBytes}, signedBy(t, n.identity(bid.ContractorID), "", bidBytes)), func(ctx *TransactionContext) error {
+				return n.enh.SubmitEnhancedBid(ctx, bid.TenderID, bid.BidID)
+			})
+		}
+		n.ledger.SetTime(t0.Add(time.Hour))
+		n.closeTender(tender.ID)
+	}
+	payload := func(n *testNet, tenderID, refID string) []byte {
+		var out string
+		n.mustQuery("buyer", func(ctx *TransactionContext) error {
+			var err error
+			out, err = n.enh.GetSigningPayload(ctx, tenderID, sigSubjectAward, refID)
+			return err
+		})
+		return []byte(out)
+	}
+	verified := func(n *testNet, tenderID, refID string) {
+		n.t.Helper()
+		n.mustQuery("auditor", func(ctx *TransactionContext) error {
+			record, err := n.enh.GetSignatureRecord(ctx, tenderID, sigSubjectAward, refID)
+			if err == nil && (!record.Verified || record.SignerID != "buyer") {
+				t.Fatalf("record = %+v", record)
+			}
+			return err
+		})
+	}
+
+	t.Run("lots", func(t *testing.T) {
+		n := newTestNet(t)
+		signedTender(n, lotsFixture("LT", ""),
+			lotBidFixture("LT", "B1", "contractorA", map[string]float64{"N": 300000, "S": 500000}),
+			lotBidFixture("LT", "B2", "contractorB", map[string]float64{"N": 250000}))
+		n.mustTx("buyer", nil, func(ctx *TransactionContext) error { return n.enh.EvaluateBids(ctx, "LT") })
+		buyer := n.identity("buyer")
+
+		awardLot := func(transient map[string][]byte) error {
+			return n.tx("buyer", transient, func(ctx *TransactionContext) error { return n.enh.AwardLot(ctx, "LT", "S", "B1") })
+		}
+		expectErr(t, awardLot(nil), "tender LT requires a signed AWARD payload")
+		expectErr(t, awardLot(signedBy(t, buyer, "", payload(n, "LT", "N/B1"))), "signature verification failed: invalid ECDSA signature")
+		expectErr(t, awardLot(signedBy(t, buyer, "", payload(n, "LT", "S/B1"))), "")
+		verified(n, "LT", "S/B1")
+
+		awardLots := func(transient map[string][]byte) error {
+			return n.tx("buyer", transient, func(ctx *TransactionContext) error {
+				_, err := n.enh.AwardLots(ctx, "LT")
+				return err
+			})
+		}
+		expectErr(t, awardLots(nil), "tender LT requires a signed AWARD payload")
+		expectErr(t, awardLots(signedBy(t, buyer, "", payload(n, "LT", ""))), "")
+		verified(n, "LT", "")
+		if got := n.tender("LT"); got.Status != "AWARDED" {
+			t.Fatalf("tender status = %s", got.Status)
+		}
+	})
+
+	t.Run("framework", func(t *testing.T) {
+		n := newTestNet(t)
+		signedTender(n, tenderFixture("T1", t0.Add(time.Hour)),
+			bidFixture("T1", "B1", "contractorA", 500000),
+			bidFixture("T1", "B2", "contractorB", 600000))
+		req := []byte(mustJSON(t, frameworkFixture()))
+
+		create := func(transient map[string][]byte) error {
+			return n.tx("buyer", transient, func(ctx *TransactionContext) error {
+				return n.enh.CreateFrameworkAgreement(ctx, string(req))
+			})
+		}
+		expectErr(t, create(nil), "tender T1 requires a signed AWARD payload")
+		expectErr(t, create(signedBy(t, n.identity("buyer"), "", payload(n, "T1", "B1"))), "signature verification failed: invalid ECDSA signature")
+		expectErr(t, create(signedBy(t, n.identity("buyer"), "", req)), "")
+		verified(n, "T1", "F1")
+	})
+}
@@ -0,0 +1,158 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/schema"
+)
+
+// issueList collects validation problems so that a document is reported in one pass
+type issueList []ValidationIssue
+
+func (l *issueList) add(field, format string, args ...interface{}) {
+	*l = append(*l, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// check records err, if any, against field
+func (l *issueList) check(field string, err error) {
+	if err != nil {
+		l.add(field, "%v", err)
+	}
+}
+
+// issuesError joins the issues into one error, or returns nil when there are none
+func issuesError(issues issueList) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	parts := make([]string, len(issues))
+	for i, issue := range issues {
+		parts[i] = issue.Field + ": " + issue.Message
+	}
+	return errors.New(strings.Join(parts, "; "))
+}
+
+// budgetIssues checks that the estimated range is non-negative and ordered, and that
+// the payment schedule adds up. EstimatedMax of 0 means no upper estimate.
+func budgetIssues(field string, budget *Budget) issueList {
+	var issues issueList
+	if budget.EstimatedMin < 0 {
+		issues.add(field+".estimatedMin", "estimated minimum must not be negative")
+	}
+	if budget.EstimatedMax < 0 {
+		issues.add(field+".estimatedMax", "estimated maximum must not be negative")
+	}
+	if budget.EstimatedMax > 0 && budget.EstimatedMin > budget.EstimatedMax {
+		issues.add(field+".estimatedMin", "estimated minimum %.2f exceeds estimated maximum %.2f", budget.EstimatedMin, budget.EstimatedMax)
+	}
+	return append(issues, paymentScheduleIssues(field, budget.PaymentSchedule)...)
+}
+
+// paymentScheduleIssues checks that a non-empty schedule pays out exactly 100 percent
+func paymentScheduleIssues(field string, schedule []PaymentMilestone) issueList {
+	if len(schedule) == 0 {
+		return nil
+	}
+	var issues issueList
+	total := 0.0
+	for i, payment := range schedule {
+		if payment.Percentage <= 0 || payment.Percentage > 100 {
+			issues.add(fmt.Sprintf("%s.paymentSchedule.%d.percentage", field, i), "payment %s percentage must be above 0 and at most 100", payment.Name)
+		}
+		total += payment.Percentage
+	}
+	if math.Abs(total-100.0) > 0.01 {
+		issues.add(field+".paymentSchedule", "payment schedule percentages must total 100, got %.2f", total)
+	}
+	return issues
+}
+
+// subCriteriaIssues checks that the sub-criteria of each criterion share out its weight
+func subCriteriaIssues(field string, criteria []EvalCriterion) issueList {
+	var issues issueList
+	for i, criterion := range criteria {
+		if len(criterion.SubCriteria) == 0 {
+			continue
+		}
+		path := fmt.Sprintf("%s.%d.subCriteria", field, i)
+		total := 0.0
+		for j, sub := range criterion.SubCriteria {
+			if sub.Weight < 0 {
+				issues.add(fmt.Sprintf("%s.%d.weight", path, j), "sub-criterion %s weight must not be negative", sub.Name)
+			}
+			total += sub.Weight
+		}
+		if math.Abs(total-criterion.Weight) > 0.01 {
+			issues.add(path, "sub-criteria weights of %s must total its weight %.2f, got %.2f", criterion.Name, criterion.Weight, total)
+		}
+	}
+	return issues
+}
+
+// managedFieldIssues rejects fields that only later transactions may set, so that a
+// tender cannot be created already justified, approved or awarded
+func managedFieldIssues(tender *EnhancedTender) issueList {
+	var issues issueList
+	managed := func(field string, set bool) {
+		if set {
+			issues.add(field, "%s is set by the chaincode and cannot be submitted", field)
+		}
+	}
+	managed("awardedBidId", tender.AwardedBidID != "")
+	managed("awardedAt", tender.AwardedAt != "")
+	managed("frameworkId", tender.FrameworkID != "")
+	managed("justification", tender.Justification != nil)
+	managed("awardApprovals", len(tender.AwardApprovals) > 0)
+	managed("closedOutAt", tender.ClosedOutAt != "")
+	managed("retentionReleased", tender.RetentionReleased || tender.RetentionReleasedAt != "")
+	for i, lot := range tender.Lots {
+		field := fmt.Sprintf("lots.%d.", i)
+		managed(field+"status", lot.Status != "")
+		managed(field+"awardedBidId", lot.AwardedBidID != "")
+		managed(field+"awardedAmount", lot.AwardedAmount != 0)
+		managed(field+"awardedAt", lot.AwardedAt != "")
+	}
+	return issues
+}
+
+// ValidateTender runs the checks of CreateEnhancedTender on an RFQ document without
+// writing anything, and reports every issue found
+func (s *EnhancedSmartContract) ValidateTender(ctx contractapi.TransactionContextInterface, tenderJSON string) (*TenderValidationReport, error) {
+	var issues issueList
+	var schemaErr *schema.Error
+	if err := checkDocument(schema.EnhancedTender, []byte(tenderJSON)); errors.As(err, &schemaErr) {
+		for _, issue := range schemaErr.Issues {
+			field, message, _ := strings.Cut(issue, ": ")
+			issues.add(field, "%s", message)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var tender EnhancedTender
+	if err := json.Unmarshal([]byte(tenderJSON), &tender); err != nil {
+		// Type errors are already in the schema issues
+		if len(issues) == 0 {
+			return nil, fmt.Errorf("invalid tender JSON: %v", err)
+		}
+		return &TenderValidationReport{Issues: issues}, nil
+	}
+	issues = append(issues, s.tenderIssues(&tender)...)
+	if tender.ID != "" {
+		exists, err := s.assetExists(ctx, tenderKey(tender.ID))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			issues.add("id", "tender %s already exists", tender.ID)
+		}
+	}
+
+	return &TenderValidationReport{TenderID: tender.ID, Valid: len(issues) == 0, Issues: issues}, nil
+}
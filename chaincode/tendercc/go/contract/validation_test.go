@@ -0,0 +1,108 @@
+package contract
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateTender(t *testing.T) {
+	deadline := t0.Add(7 * 24 * time.Hour)
+	broken := func() string {
+		tender := tenderFixture("T2", deadline)
+		tender.Deadlines.QuestionsDeadline = rfc(deadline.Add(time.Hour))
+		tender.Deadlines.ProjectEndDate = rfc(deadline.Add(10 * 24 * time.Hour))
+		tender.ProjectScope.Budget.EstimatedMin = 1000000
+		tender.ProjectScope.Budget.PaymentSchedule[1].Percentage = 50
+		tender.EvaluationCriteria[0].SubCriteria = []SubCriterion{{Name: "Capex", Weight: 30}}
+		return mustJSON(t, tender)
+	}
+	tests := []struct {
+		name       string
+		tenderJSON string
+		wantValid  bool
+		wantFields []string
+		wantErr    string
+	}{
+		{name: "valid", tenderJSON: mustJSON(t, tenderFixture("T2", deadline)), wantValid: true},
+		{name: "every issue reported", tenderJSON: broken(), wantFields: []string{
+			"deadlines.bidSubmissionDeadline",
+			"deadlines.projectEndDate",
+			"deadlines.milestoneDeadlines.0.deadline",
+			"projectScope.budget.estimatedMin",
+			"projectScope.budget.paymentSchedule",
+			"evaluationCriteria.0.subCriteria",
+		}},
+		{name: "schema and semantic issues together", tenderJSON: string(patchJSON(t, tenderFixture("T2", deadline), map[string]interface{}{
+			"priority": "HIGH",
+			"id":       "",
+		})), wantFields: []string{"priority", "id"}},
+		{name: "type error", tenderJSON: string(patchJSON(t, tenderFixture("T2", deadline), map[string]interface{}{"version": "one"})), wantFields: []string{"version"}},
+		{name: "existing tender", tenderJSON: mustJSON(t, tenderFixture("T1", deadline)), wantFields: []string{"id"}},
+		{name: "invalid json", tenderJSON: `{"id":`, wantErr: "invalid tender JSON"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			n.createTender(tenderFixture("T1", deadline))
+			var report *TenderValidationReport
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				var err error
+				report, err = n.enh.ValidateTender(ctx, tc.tenderJSON)
+				return err
+			})
+			expectErr(t, err, tc.wantErr)
+			if tc.wantErr != "" {
+				return
+			}
+			var fields []string
+			for _, issue := range report.Issues {
+				fields = append(fields, issue.Field)
+			}
+			if report.Valid != tc.wantValid || !reflect.DeepEqual(fields, tc.wantFields) {
+				t.Fatalf("report = %+v, want fields %v", report, tc.wantFields)
+			}
+			// A dry run writes nothing, even as a committed transaction
+			if got := len(n.ledger.Events()); got != 1 {
+				t.Fatalf("%d chaincode events, want only the one from creating T1", got)
+			}
+			if err := n.query("buyer", func(ctx *TransactionContext) error {
+				_, err := n.enh.GetEnhancedTender(ctx, "T2")
+				return err
+			}); err == nil {
+				t.Fatal("ValidateTender stored the tender")
+			}
+		})
+	}
+}
+
+func TestManagedFieldsRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(e *EnhancedTender)
+		wantErr string
+	}{
+		{"pre-approved single source", func(e *EnhancedTender) {
+			e.ProcurementMethod = procurementSingleSource
+			e.Invitees = []string{"contractorA"}
+			e.Justification = &SingleSourceJustification{Reason: "EMERGENCY", Details: "Flood damage"}
+			e.AwardApprovals = []AwardApproval{{ApproverID: "x"}, {ApproverID: "y"}}
+		}, "justification: justification is set by the chaincode and cannot be submitted; awardApprovals: awardApprovals is set by the chaincode"},
+		{"awarded", func(e *EnhancedTender) { e.AwardedBidID, e.AwardedAt = "B1", rfc(t0) }, "awardedBidId: awardedBidId is set by the chaincode"},
+		{"framework", func(e *EnhancedTender) { e.FrameworkID = "FW1" }, "frameworkId: frameworkId is set by the chaincode"},
+		{"awarded lot", func(e *EnhancedTender) {
+			e.Lots = []Lot{{ID: "N", Name: "North section", Status: "AWARDED", AwardedBidID: "B1"}}
+		}, "lots.0.status: lots.0.status is set by the chaincode"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := newTestNet(t)
+			tender := tenderFixture("T1", t0.Add(time.Hour))
+			tc.mutate(tender)
+			err := n.tx("buyer", nil, func(ctx *TransactionContext) error {
+				return n.enh.CreateEnhancedTender(ctx, mustJSON(t, tender))
+			})
+			expectErr(t, err, tc.wantErr)
+		})
+	}
+}
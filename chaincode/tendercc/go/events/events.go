@@ -0,0 +1,452 @@
+// Package events defines the chaincode events emitted by tendercc.
+//
+// Every event is wrapped in an Envelope carrying the event name, schema version,
+// tender, actor and transaction time. Fabric keeps a single chaincode event per
+// transaction, so when a transaction raises more than one event they are delivered
+// together as a Batch under the chaincode event name BatchEventName. Use Parse to
+// read either form.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the envelope and payload types in this package.
+// It is incremented whenever a payload changes incompatibly.
+const SchemaVersion = 1
+
+// BatchEventName is the chaincode event name used when a transaction emits several events
+const BatchEventName = "TenderEvents"
+
+// Event names
+const (
+	RFQCreated                = "RFQCreated"
+	BidSubmitted              = "BidSubmitted"
+	TenderAwarded             = "TenderAwarded"
+	BidWindowClosed           = "BidWindowClosed"
+	BidEvaluated              = "BidEvaluated"
+	MilestoneSubmitted        = "MilestoneSubmitted"
+	MilestoneApproved         = "MilestoneApproved"
+	MilestoneRejected         = "MilestoneRejected"
+	PaymentReleased           = "PaymentReleased"
+	EnhancedRFQCreated        = "EnhancedRFQCreated"
+	TenderPublished           = "TenderPublished"
+	EnhancedBidSubmitted      = "EnhancedBidSubmitted"
+	TenderClosed              = "TenderClosed"
+	AuctionBidPlaced          = "AuctionBidPlaced"
+	DocumentAttached          = "DocumentAttached"
+	KeyShareReleased          = "KeyShareReleased"
+	BidKeyReleased            = "BidKeyReleased"
+	EncryptedBidsOpened       = "EncryptedBidsOpened"
+	FrameworkCreated          = "FrameworkCreated"
+	CallOffAwarded            = "CallOffAwarded"
+	MiniCompetitionStarted    = "MiniCompetitionStarted"
+	CallOffResponseSubmitted  = "CallOffResponseSubmitted"
+	LotBidEvaluated           = "LotBidEvaluated"
+	LotAwarded                = "LotAwarded"
+	PenaltyRecorded           = "PenaltyRecorded"
+	ContractCompleted         = "ContractCompleted"
+	ContractTerminated        = "ContractTerminated"
+	ContractorRated           = "ContractorRated"
+	ContractorDebarred        = "ContractorDebarred"
+	DebarmentLifted           = "DebarmentLifted"
+	InviteesAdded             = "InviteesAdded"
+	SingleSourceJustified     = "SingleSourceJustified"
+	SingleSourceAwardApproved = "SingleSourceAwardApproved"
+	VendorRegistered          = "VendorRegistered"
+	VendorUpdated             = "VendorUpdated"
+	VendorDocumentVerified    = "VendorDocumentVerified"
+	VendorStatusChanged       = "VendorStatusChanged"
+	PrivateDataPurged         = "PrivateDataPurged"
+	SigningKeyRegistered      = "SigningKeyRegistered"
+	SigningKeyRevoked         = "SigningKeyRevoked"
+	ContractSigned            = "ContractSigned"
+)
+
+// Actor identifies the client that submitted the transaction
+type Actor struct {
+	MSPID    string `json:"mspId"`
+	ClientID string `json:"clientId,omitempty"`
+}
+
+// Envelope wraps one event. Payload holds the JSON of the payload type for Name.
+type Envelope struct {
+	Name          string          `json:"name"`
+	SchemaVersion int             `json:"schemaVersion"`
+	TenderID      string          `json:"tenderId,omitempty"`
+	Actor         Actor           `json:"actor"`
+	TxID          string          `json:"txId"`
+	TxTime        string          `json:"txTime"` // RFC3339
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Batch carries every event of a transaction that emitted more than one, in emission order
+type Batch struct {
+	SchemaVersion int        `json:"schemaVersion"`
+	Events        []Envelope `json:"events"`
+}
+
+// Parse decodes a chaincode event into its envelopes. A single event yields one envelope;
+// a batch yields all of them.
+func Parse(eventName string, data []byte) ([]Envelope, error) {
+	if eventName == BatchEventName {
+		var batch Batch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("invalid event batch: %v", err)
+		}
+		return batch.Events, nil
+	}
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid event %s: %v", eventName, err)
+	}
+	if env.Name != eventName {
+		return nil, fmt.Errorf("event %s carries envelope for %s", eventName, env.Name)
+	}
+	return []Envelope{env}, nil
+}
+
+// Decode unmarshals the envelope payload into v
+func (e Envelope) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("invalid %s payload: %v", e.Name, err)
+	}
+	return nil
+}
+
+// DecodePayload unmarshals the payload into a new value of the payload type registered
+// for the event name and returns a pointer to it
+func (e Envelope) DecodePayload() (interface{}, error) {
+	v := NewPayload(e.Name)
+	if v == nil {
+		return nil, fmt.Errorf("unknown event %s", e.Name)
+	}
+	if err := e.Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewPayload returns a pointer to a zero payload of the type carried by the named event,
+// or nil for an unknown event
+func NewPayload(name string) interface{} {
+	switch name {
+	case RFQCreated:
+		return &RFQCreatedPayload{}
+	case BidSubmitted, EnhancedBidSubmitted:
+		return &BidSubmittedPayload{}
+	case TenderAwarded:
+		return &TenderAwardedPayload{}
+	case BidWindowClosed, TenderPublished, TenderClosed, ContractCompleted, ContractTerminated:
+		return &TenderStatusPayload{}
+	case BidEvaluated:
+		return &BidEvaluatedPayload{}
+	case MilestoneSubmitted, MilestoneApproved, MilestoneRejected:
+		return &MilestonePayload{}
+	case PaymentReleased:
+		return &PaymentReleasedPayload{}
+	case EnhancedRFQCreated:
+		return &TenderCreatedPayload{}
+	case AuctionBidPlaced:
+		return &AuctionBidPayload{}
+	case DocumentAttached:
+		return &DocumentPayload{}
+	case KeyShareReleased, BidKeyReleased:
+		return &KeySharePayload{}
+	case EncryptedBidsOpened:
+		return &BidsOpenedPayload{}
+	case FrameworkCreated:
+		return &FrameworkPayload{}
+	case CallOffAwarded, MiniCompetitionStarted:
+		return &CallOffPayload{}
+	case CallOffResponseSubmitted:
+		return &CallOffResponsePayload{}
+	case LotBidEvaluated:
+		return &LotEvaluatedPayload{}
+	case LotAwarded:
+		return &LotAwardedPayload{}
+	case PenaltyRecorded:
+		return &PenaltyPayload{}
+	case ContractorRated:
+		return &RatingPayload{}
+	case ContractorDebarred, DebarmentLifted:
+		return &DebarmentPayload{}
+	case InviteesAdded:
+		return &InviteesPayload{}
+	case SingleSourceJustified:
+		return &SingleSourcePayload{}
+	case SingleSourceAwardApproved:
+		return &AwardApprovalPayload{}
+	case VendorRegistered, VendorUpdated:
+		return &VendorPayload{}
+	case VendorDocumentVerified:
+		return &VendorDocumentPayload{}
+	case VendorStatusChanged:
+		return &VendorStatusPayload{}
+	case PrivateDataPurged:
+		return &PurgePayload{}
+	case SigningKeyRegistered, SigningKeyRevoked:
+		return &SigningKeyPayload{}
+	case ContractSigned:
+		return &ContractSignedPayload{}
+	}
+	return nil
+}
+
+// RFQCreatedPayload is emitted when a legacy tender is created
+type RFQCreatedPayload struct {
+	TenderID    string `json:"tenderId"`
+	Description string `json:"description"`
+	OpenAt      string `json:"openAt"`
+	CloseAt     string `json:"closeAt"`
+	Criteria    string `json:"criteria"`
+	Status      string `json:"status"`
+}
+
+// TenderCreatedPayload is emitted when an enhanced tender is created
+type TenderCreatedPayload struct {
+	TenderID    string `json:"tenderId"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"createdAt"`
+	Owner       string `json:"owner"`
+	Description string `json:"description"`
+}
+
+// TenderStatusPayload is emitted when a tender or its contract changes status:
+// BidWindowClosed, TenderPublished, TenderClosed, ContractCompleted and ContractTerminated
+type TenderStatusPayload struct {
+	TenderID string `json:"tenderId"`
+	Status   string `json:"status"`
+	At       string `json:"at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// BidSubmittedPayload is emitted for BidSubmitted (legacy) and EnhancedBidSubmitted
+type BidSubmittedPayload struct {
+	TenderID     string `json:"tenderId"`
+	BidID        string `json:"bidId"`
+	ContractorID string `json:"contractorId"`
+	BidHash      string `json:"bidHash,omitempty"`
+	SubmittedAt  string `json:"submittedAt,omitempty"`
+	Encrypted    bool   `json:"encrypted,omitempty"`
+}
+
+// BidEvaluatedPayload is emitted once per evaluated bid
+type BidEvaluatedPayload struct {
+	TenderID string  `json:"tenderId"`
+	BidID    string  `json:"bidId"`
+	Score    float64 `json:"score"`
+	Notes    string  `json:"notes,omitempty"`
+}
+
+// TenderAwardedPayload is emitted when a tender is awarded to a bid
+type TenderAwardedPayload struct {
+	TenderID  string `json:"tenderId"`
+	BidID     string `json:"bidId"`
+	Status    string `json:"status"`
+	AwardedAt string `json:"awardedAt,omitempty"`
+}
+
+// MilestonePayload is the public milestone reference after a submission, approval or rejection
+type MilestonePayload struct {
+	TenderID        string `json:"tenderId"`
+	MilestoneID     string `json:"milestoneId"`
+	Title           string `json:"title"`
+	EvidenceHash    string `json:"evidenceHash"`
+	PayloadHash     string `json:"payloadHash,omitempty"`
+	Status          string `json:"status"`
+	PaymentReleased bool   `json:"paymentReleased"`
+	SubmittedAt     string `json:"submittedAt,omitempty"`
+}
+
+// PaymentReleasedPayload is emitted with MilestoneApproved when the milestone payment is released
+type PaymentReleasedPayload struct {
+	TenderID    string `json:"tenderId"`
+	MilestoneID string `json:"milestoneId"`
+}
+
+// AuctionBidPayload is emitted for each reverse auction offer; it carries no amounts or identities
+type AuctionBidPayload struct {
+	TenderID    string `json:"tenderId"`
+	BidID       string `json:"bidId"`
+	BidderAlias string `json:"bidderAlias"`
+	OfferCount  int    `json:"offerCount"` // offers made on this bid so far
+	EndTime     string `json:"endTime"`
+	Extended    bool   `json:"extended"`
+}
+
+// DocumentPayload is emitted when a document version is attached
+type DocumentPayload struct {
+	LinkType     string `json:"linkType"`
+	TenderID     string `json:"tenderId"`
+	RefID        string `json:"refId,omitempty"`
+	Name         string `json:"name"`
+	Version      int    `json:"version"`
+	PreviousHash string `json:"previousHash,omitempty"`
+	Hash         string `json:"hash"`
+	AttachedBy   string `json:"attachedBy"`
+	AttachedAt   string `json:"attachedAt"`
+}
+
+// KeySharePayload is emitted when a key share is released; BidKeyReleased marks the threshold being reached
+type KeySharePayload struct {
+	TenderID  string `json:"tenderId"`
+	Index     int    `json:"index"`
+	Released  int    `json:"released"`
+	Threshold int    `json:"threshold"`
+}
+
+// BidsOpenedPayload is emitted when sealed bids are decrypted
+type BidsOpenedPayload struct {
+	TenderID string `json:"tenderId"`
+	Opened   int    `json:"opened"`
+	Failed   int    `json:"failed"`
+}
+
+// FrameworkPayload is emitted when a framework agreement is created
+type FrameworkPayload struct {
+	FrameworkID  string  `json:"frameworkId"`
+	TenderID     string  `json:"tenderId"`
+	Members      int     `json:"members"`
+	CeilingValue float64 `json:"ceilingValue"`
+	ExpiryDate   string  `json:"expiryDate"`
+}
+
+// CallOffPayload is the call-off order after a mini-competition starts or an order is awarded
+type CallOffPayload struct {
+	FrameworkID  string  `json:"frameworkId"`
+	OrderID      string  `json:"orderId"`
+	Description  string  `json:"description"`
+	Method       string  `json:"method"`
+	MaxValue     float64 `json:"maxValue,omitempty"`
+	Value        float64 `json:"value,omitempty"`
+	ContractorID string  `json:"contractorId,omitempty"`
+	Deadline     string  `json:"deadline,omitempty"`
+	Status       string  `json:"status"`
+	Responses    int     `json:"responses,omitempty"`
+	CreatedAt    string  `json:"createdAt"`
+	AwardedAt    string  `json:"awardedAt,omitempty"`
+}
+
+// CallOffResponsePayload is the public reference of a mini-competition response
+type CallOffResponsePayload struct {
+	FrameworkID  string `json:"frameworkId"`
+	OrderID      string `json:"orderId"`
+	ContractorID string `json:"contractorId"`
+	ResponseHash string `json:"responseHash"`
+}
+
+// LotEvaluatedPayload is emitted once per evaluated lot of a bid
+type LotEvaluatedPayload struct {
+	TenderID string  `json:"tenderId"`
+	LotID    string  `json:"lotId"`
+	BidID    string  `json:"bidId"`
+	Score    float64 `json:"score"`
+}
+
+// LotAwardedPayload is emitted once per awarded lot
+type LotAwardedPayload struct {
+	TenderID  string `json:"tenderId"`
+	LotID     string `json:"lotId"`
+	BidID     string `json:"bidId"`
+	AwardedAt string `json:"awardedAt"`
+}
+
+// PenaltyPayload is emitted when a contract penalty is recorded
+type PenaltyPayload struct {
+	TenderID     string  `json:"tenderId"`
+	PenaltyID    string  `json:"penaltyId"`
+	ContractorID string  `json:"contractorId"`
+	Type         string  `json:"type"`
+	Amount       float64 `json:"amount"`
+	Reason       string  `json:"reason"`
+	RecordedAt   string  `json:"recordedAt"`
+}
+
+// RatingPayload is emitted when a buyer rates a contractor
+type RatingPayload struct {
+	TenderID     string `json:"tenderId"`
+	ContractorID string `json:"contractorId"`
+	Rating       int    `json:"rating"`
+	Comment      string `json:"comment,omitempty"`
+	RatedBy      string `json:"ratedBy"`
+	RatedAt      string `json:"ratedAt"`
+}
+
+// DebarmentPayload is emitted when a contractor is debarred or a debarment is lifted
+type DebarmentPayload struct {
+	ContractorID string `json:"contractorId"`
+	Reason       string `json:"reason"`
+	StartDate    string `json:"startDate"`
+	EndDate      string `json:"endDate,omitempty"`
+	DebarredBy   string `json:"debarredBy"`
+	CreatedAt    string `json:"createdAt"`
+	LiftedAt     string `json:"liftedAt,omitempty"`
+	LiftReason   string `json:"liftReason,omitempty"`
+}
+
+// InviteesPayload is emitted when contractors are invited to a restricted tender
+type InviteesPayload struct {
+	TenderID string `json:"tenderId"`
+	Added    int    `json:"added"`
+	Total    int    `json:"total"`
+}
+
+// SingleSourcePayload is emitted when a single-source justification is recorded
+type SingleSourcePayload struct {
+	TenderID string `json:"tenderId"`
+	Reason   string `json:"reason"`
+}
+
+// AwardApprovalPayload is emitted for each single-source award approval
+type AwardApprovalPayload struct {
+	TenderID  string `json:"tenderId"`
+	Approvals int    `json:"approvals"`
+	Required  int    `json:"required"`
+}
+
+// VendorPayload is emitted when a vendor profile is registered or updated
+type VendorPayload struct {
+	VendorID     string `json:"vendorId"`
+	LegalName    string `json:"legalName,omitempty"`
+	RegisteredBy string `json:"registeredBy,omitempty"`
+}
+
+// VendorDocumentPayload is emitted when a vendor document is verified
+type VendorDocumentPayload struct {
+	VendorID     string `json:"vendorId"`
+	DocumentHash string `json:"documentHash"`
+	VerifiedAt   string `json:"verifiedAt"`
+}
+
+// VendorStatusPayload is emitted when a vendor is suspended or reactivated
+type VendorStatusPayload struct {
+	VendorID       string `json:"vendorId"`
+	PreviousStatus string `json:"previousStatus"`
+	Status         string `json:"status"`
+	ChangedAt      string `json:"changedAt"`
+}
+
+// PurgePayload is emitted when private data is purged under the retention policy
+type PurgePayload struct {
+	TenderID string `json:"tenderId"`
+	Kind     string `json:"kind"` // LOSING_BIDS, MILESTONE_DETAILS
+	Purged   int    `json:"purged"`
+	Reason   string `json:"reason"`
+}
+
+// SigningKeyPayload is emitted when a signing key is registered or revoked
+type SigningKeyPayload struct {
+	KeyID     string `json:"keyId"`
+	OwnerID   string `json:"ownerId"`
+	RevokedAt string `json:"revokedAt,omitempty"`
+}
+
+// ContractSignedPayload is emitted when a party signs the awarded contract
+type ContractSignedPayload struct {
+	TenderID  string `json:"tenderId"`
+	SignerID  string `json:"signerId"`
+	SignerMSP string `json:"signerMsp"`
+	SignedAt  string `json:"signedAt"`
+}
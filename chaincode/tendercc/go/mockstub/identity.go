@@ -0,0 +1,102 @@
+package mockstub
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// attrOID is the certificate extension Fabric CA uses for identity attributes
+var attrOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// Identity is a client identity with a self-signed enrolment certificate. The certificate
+// carries Attrs the way Fabric CA does, so cid attribute checks such as role work.
+type Identity struct {
+	MSPID string
+	Name  string
+	Attrs map[string]string
+
+	Key     *ecdsa.PrivateKey
+	Cert    *x509.Certificate
+	CertPEM []byte
+}
+
+// NewIdentity creates an identity valid for ten years from the given time
+func NewIdentity(mspID, name string, attrs map[string]string, notBefore time.Time) (*Identity, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{mspID}},
+		Issuer:       pkix.Name{CommonName: "ca." + mspID},
+		NotBefore:    notBefore.Add(-time.Hour),
+		NotAfter:     notBefore.AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if len(attrs) > 0 {
+		value, err := json.Marshal(map[string]interface{}{"attrs": attrs})
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = []pkix.Extension{{Id: attrOID, Value: value}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{
+		MSPID:   mspID,
+		Name:    name,
+		Attrs:   attrs,
+		Key:     key,
+		Cert:    cert,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+// Creator returns the serialized identity a peer hands to GetCreator
+func (id *Identity) Creator() ([]byte, error) {
+	return proto.Marshal(&msp.SerializedIdentity{Mspid: id.MSPID, IdBytes: id.CertPEM})
+}
+
+// Sign returns a base64 ASN.1 ECDSA signature over the SHA-256 of payload,
+// made with the enrolment key
+func (id *Identity) Sign(payload []byte) (string, error) {
+	digest := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, id.Key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// PublicKeyPEM returns the enrolment public key as a PKIX PEM block
+func (id *Identity) PublicKeyPEM() (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(&id.Key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
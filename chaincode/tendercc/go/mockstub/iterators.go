@@ -0,0 +1,63 @@
+package mockstub
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+type stateIterator struct {
+	kvs    []*queryresult.KV
+	pos    int
+	closed bool
+}
+
+func newStateIterator(kvs []*queryresult.KV) *stateIterator {
+	return &stateIterator{kvs: kvs}
+}
+
+func (it *stateIterator) HasNext() bool {
+	return !it.closed && it.pos < len(it.kvs)
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("iterator exhausted")
+	}
+	kv := it.kvs[it.pos]
+	it.pos++
+	return kv, nil
+}
+
+func (it *stateIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+type historyIterator struct {
+	mods   []*queryresult.KeyModification
+	pos    int
+	closed bool
+}
+
+func newHistoryIterator(mods []*queryresult.KeyModification) *historyIterator {
+	return &historyIterator{mods: mods}
+}
+
+func (it *historyIterator) HasNext() bool {
+	return !it.closed && it.pos < len(it.mods)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, errors.New("iterator exhausted")
+	}
+	m := it.mods[it.pos]
+	it.pos++
+	return m, nil
+}
+
+func (it *historyIterator) Close() error {
+	it.closed = true
+	return nil
+}
@@ -0,0 +1,219 @@
+// Package mockstub is an in-memory Fabric peer for exercising tendercc without a network.
+//
+// A Ledger holds committed world state, private data collections, key history, chaincode
+// events and a settable clock. Each transaction runs against its own Stub, which implements
+// shim.ChaincodeStubInterface. Like a real peer, a Stub buffers its writes and does not read
+// them back; Commit applies them to the ledger, records history and keeps the transaction's
+// chaincode event. A Stub that is never committed behaves like a transaction that failed
+// endorsement. Validate reports the MVCC check a committing peer would make, for callers
+// that run transactions concurrently.
+//
+// Rich queries are off by default, so the chaincode takes its LevelDB fallbacks. Setting
+// RichQueries enables a small CouchDB Mango matcher supporting the operators tendercc uses.
+package mockstub
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Event is a chaincode event committed by a transaction
+type Event struct {
+	TxID    string
+	Name    string
+	Payload []byte
+}
+
+// Ledger is the committed state shared by every Stub created from it
+type Ledger struct {
+	// RichQueries enables GetQueryResult; when false the stub answers like LevelDB
+	RichQueries bool
+
+	mu      sync.Mutex
+	state   map[string][]byte
+	private map[string]map[string][]byte
+	history map[string][]*queryresult.KeyModification
+	members map[string]map[string]bool
+	events  []Event
+	now     time.Time
+	seq     int
+	// versions counts the commits that wrote each public or private key
+	versions map[versionKey]uint64
+}
+
+// versionKey names a public key, with an empty collection, or a private one
+type versionKey struct {
+	collection string
+	key        string
+}
+
+// NewLedger returns an empty ledger whose clock starts at now
+func NewLedger(now time.Time) *Ledger {
+	return &Ledger{
+		state:    make(map[string][]byte),
+		private:  make(map[string]map[string][]byte),
+		history:  make(map[string][]*queryresult.KeyModification),
+		members:  make(map[string]map[string]bool),
+		now:      now.UTC(),
+		versions: make(map[versionKey]uint64),
+	}
+}
+
+// Now returns the ledger clock, which becomes the timestamp of the next transaction
+func (l *Ledger) Now() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.now
+}
+
+// SetTime moves the ledger clock to t
+func (l *Ledger) SetTime(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = t.UTC()
+}
+
+// Advance moves the ledger clock forward by d
+func (l *Ledger) Advance(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = l.now.Add(d)
+}
+
+// SetCollectionMembers restricts reads of a private collection to the given MSPs.
+// Collections without members are readable by everyone.
+func (l *Ledger) SetCollectionMembers(collection string, mspIDs ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	set := make(map[string]bool, len(mspIDs))
+	for _, id := range mspIDs {
+		set[id] = true
+	}
+	l.members[collection] = set
+}
+
+// NewStub starts a transaction for the given identity. The transaction timestamp is the
+// current ledger clock and transient may be nil.
+func (l *Ledger) NewStub(id *Identity, transient map[string][]byte, args ...string) *Stub {
+	l.mu.Lock()
+	l.seq++
+	txID := fmt.Sprintf("tx%06d", l.seq)
+	now := l.now
+	l.mu.Unlock()
+
+	byteArgs := make([][]byte, len(args))
+	for i, a := range args {
+		byteArgs[i] = []byte(a)
+	}
+	return &Stub{
+		ledger:    l,
+		identity:  id,
+		txID:      txID,
+		timestamp: timestamppb.New(now),
+		transient: transient,
+		args:      byteArgs,
+		writes:    make(map[string]*write),
+		private:   make(map[string]map[string]*write),
+		reads:     make(map[versionKey]uint64),
+	}
+}
+
+// State returns the committed value of a world-state key
+func (l *Ledger) State(key string) []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state[key]
+}
+
+// PrivateData returns the committed value of a private key
+func (l *Ledger) PrivateData(collection, key string) []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.private[collection][key]
+}
+
+// Keys returns the committed world-state keys in order, composite index keys included
+func (l *Ledger) Keys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return sortedKeys(l.state)
+}
+
+// Events returns every chaincode event committed so far, oldest first
+func (l *Ledger) Events() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Event(nil), l.events...)
+}
+
+func (l *Ledger) canRead(collection string, id *Identity) bool {
+	set, ok := l.members[collection]
+	if !ok || len(set) == 0 {
+		return true
+	}
+	return id != nil && set[id.MSPID]
+}
+
+// commit applies a stub's write set. Callers hold no lock.
+func (l *Ledger) commit(s *Stub) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ts := s.timestamp
+	for _, key := range sortedWriteKeys(s.writes) {
+		w := s.writes[key]
+		if w.del {
+			delete(l.state, key)
+		} else {
+			l.state[key] = w.value
+		}
+		l.history[key] = append(l.history[key], &queryresult.KeyModification{
+			TxId:      s.txID,
+			Value:     w.value,
+			Timestamp: ts,
+			IsDelete:  w.del,
+		})
+		l.versions[versionKey{key: key}]++
+	}
+	for collection, writes := range s.private {
+		coll := l.private[collection]
+		if coll == nil {
+			coll = make(map[string][]byte)
+			l.private[collection] = coll
+		}
+		for key, w := range writes {
+			if w.del {
+				delete(coll, key)
+			} else {
+				coll[key] = w.value
+			}
+			l.versions[versionKey{collection, key}]++
+		}
+	}
+	if s.event != nil {
+		l.events = append(l.events, *s.event)
+	}
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedWriteKeys(m map[string]*write) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,180 @@
+package mockstub
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// selector is a CouchDB Mango selector. Field names may be dotted paths; conditions are
+// literal values (equality) or objects of $eq, $ne, $gt, $gte, $lt, $lte, $in, $exists and
+// $elemMatch. A top-level $or holds a list of selectors, one of which must match.
+type selector map[string]interface{}
+
+func parseQuery(query string) (selector, error) {
+	var q struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	if err := json.Unmarshal([]byte(query), &q); err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	if q.Selector == nil {
+		return nil, fmt.Errorf("invalid query: missing selector")
+	}
+	return selector(q.Selector), nil
+}
+
+func (s selector) match(doc map[string]interface{}) bool {
+	for field, cond := range s {
+		if field == "$or" {
+			if !matchAny(doc, cond) {
+				return false
+			}
+			continue
+		}
+		value, found := lookup(doc, field)
+		if !matchCondition(value, found, cond) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(doc map[string]interface{}, arg interface{}) bool {
+	list, _ := arg.([]interface{})
+	for _, item := range list {
+		if cond, ok := item.(map[string]interface{}); ok && selector(cond).match(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+func lookup(doc map[string]interface{}, path string) (interface{}, bool) {
+	var cur interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func matchCondition(value interface{}, found bool, cond interface{}) bool {
+	ops, ok := cond.(map[string]interface{})
+	if !ok || !hasOperator(ops) {
+		return found && reflect.DeepEqual(value, cond)
+	}
+	for op, arg := range ops {
+		if !matchOperator(value, found, op, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasOperator(m map[string]interface{}) bool {
+	for k := range m {
+		if strings.HasPrefix(k, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperator(value interface{}, found bool, op string, arg interface{}) bool {
+	switch op {
+	case "$eq":
+		return found && reflect.DeepEqual(value, arg)
+	case "$ne":
+		return !found || !reflect.DeepEqual(value, arg)
+	case "$exists":
+		want, ok := arg.(bool)
+		return ok && found == want
+	case "$gt", "$gte", "$lt", "$lte":
+		if !found {
+			return false
+		}
+		c, ok := compare(value, arg)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "$gt":
+			return c > 0
+		case "$gte":
+			return c >= 0
+		case "$lt":
+			return c < 0
+		default:
+			return c <= 0
+		}
+	case "$in":
+		list, ok := arg.([]interface{})
+		if !found || !ok {
+			return false
+		}
+		for _, v := range list {
+			if reflect.DeepEqual(value, v) {
+				return true
+			}
+		}
+		return false
+	case "$elemMatch":
+		elems, ok := value.([]interface{})
+		if !found || !ok {
+			return false
+		}
+		for _, elem := range elems {
+			if matchElem(elem, arg) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// matchElem applies an $elemMatch argument to one array element, which may be a
+// scalar (operators only) or an object (field conditions)
+func matchElem(elem, arg interface{}) bool {
+	cond, ok := arg.(map[string]interface{})
+	if !ok {
+		return reflect.DeepEqual(elem, arg)
+	}
+	if hasOperator(cond) {
+		return matchCondition(elem, true, cond)
+	}
+	doc, ok := elem.(map[string]interface{})
+	return ok && selector(cond).match(doc)
+}
+
+func compare(a, b interface{}) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	}
+	return 0, false
+}
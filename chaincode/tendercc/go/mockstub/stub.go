@@ -0,0 +1,557 @@
+package mockstub
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+const (
+	compositeKeyNamespace = "\x00"
+	minUnicodeRune        = 0
+	maxUnicodeRune        = utf8.MaxRune
+	emptyKeySubstitute    = "\x01"
+)
+
+type write struct {
+	value []byte
+	del   bool
+}
+
+// Stub is one transaction against a Ledger
+type Stub struct {
+	ledger    *Ledger
+	identity  *Identity
+	txID      string
+	timestamp *timestamp.Timestamp
+	transient map[string][]byte
+	args      [][]byte
+
+	writes  map[string]*write
+	private map[string]map[string]*write
+	event   *Event
+	done    bool
+
+	// reads and ranges are the read set a peer validates at commit
+	reads  map[versionKey]uint64
+	ranges []rangeRead
+}
+
+// rangeRead is a range scan and the keys it returned
+type rangeRead struct {
+	startKey, endKey string
+	keys             []string
+}
+
+// Validation codes, as named by peer.TxValidationCode
+const (
+	Valid               = "VALID"
+	MVCCReadConflict    = "MVCC_READ_CONFLICT"
+	PhantomReadConflict = "PHANTOM_READ_CONFLICT"
+)
+
+var _ shim.ChaincodeStubInterface = (*Stub)(nil)
+
+// Commit applies the transaction's writes and event to the ledger. A stub commits once.
+func (s *Stub) Commit() error {
+	if s.done {
+		return fmt.Errorf("transaction %s already committed", s.txID)
+	}
+	s.done = true
+	s.ledger.commit(s)
+	return nil
+}
+
+// Validate checks the transaction's read set against the ledger as a committing peer
+// does. A key read and written by a later commit is an MVCC_READ_CONFLICT and a range
+// scan that would now return other keys a PHANTOM_READ_CONFLICT. Rich queries are not
+// rechecked, as on a peer.
+func (s *Stub) Validate() string {
+	return s.ledger.validate(s)
+}
+
+// Event returns the chaincode event set by the transaction, or nil
+func (s *Stub) Event() *Event {
+	return s.event
+}
+
+// Identity returns the identity that submitted the transaction
+func (s *Stub) Identity() *Identity {
+	return s.identity
+}
+
+func (s *Stub) GetArgs() [][]byte {
+	return s.args
+}
+
+func (s *Stub) GetStringArgs() []string {
+	out := make([]string, len(s.args))
+	for i, a := range s.args {
+		out[i] = string(a)
+	}
+	return out
+}
+
+func (s *Stub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", nil
+	}
+	return args[0], args[1:]
+}
+
+func (s *Stub) GetArgsSlice() ([]byte, error) {
+	var out []byte
+	for _, a := range s.args {
+		out = append(out, a...)
+	}
+	return out, nil
+}
+
+func (s *Stub) GetTxID() string {
+	return s.txID
+}
+
+func (s *Stub) GetChannelID() string {
+	return "mychannel"
+}
+
+func (s *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	return shim.Error("InvokeChaincode is not supported by mockstub")
+}
+
+func (s *Stub) GetState(key string) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("key must not be an empty string")
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	s.recordRead(versionKey{key: key})
+	return s.ledger.state[key], nil
+}
+
+func (s *Stub) PutState(key string, value []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	s.writes[key] = &write{value: append([]byte(nil), value...)}
+	return nil
+}
+
+func (s *Stub) DelState(key string) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	s.writes[key] = &write{del: true}
+	return nil
+}
+
+func (s *Stub) SetStateValidationParameter(key string, ep []byte) error {
+	return nil
+}
+
+func (s *Stub) GetStateValidationParameter(key string) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *Stub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	if err := validateRangeKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	kvs := s.ledger.rangeState(startKey, endKey)
+	s.recordRange(startKey, endKey, kvs)
+	return newStateIterator(kvs), nil
+}
+
+func (s *Stub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	if err := validateRangeKeys(startKey, endKey); err != nil {
+		return nil, nil, err
+	}
+	if bookmark != "" {
+		startKey = bookmark
+	}
+	kvs := s.ledger.rangeState(startKey, endKey)
+	page, meta := paginate(kvs, pageSize)
+	return newStateIterator(page), meta, nil
+}
+
+func (s *Stub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	kvs := s.ledger.rangeState(prefix, prefix+string(rune(maxUnicodeRune)))
+	s.recordRange(prefix, prefix+string(rune(maxUnicodeRune)), kvs)
+	return newStateIterator(kvs), nil
+}
+
+func (s *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	prefix, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	start := prefix
+	if bookmark != "" {
+		start = bookmark
+	}
+	kvs := s.ledger.rangeState(start, prefix+string(rune(maxUnicodeRune)))
+	page, meta := paginate(kvs, pageSize)
+	return newStateIterator(page), meta, nil
+}
+
+func (s *Stub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	if err := validateCompositeKeyAttribute(objectType); err != nil {
+		return "", err
+	}
+	ck := compositeKeyNamespace + objectType + string(rune(minUnicodeRune))
+	for _, att := range attributes {
+		if err := validateCompositeKeyAttribute(att); err != nil {
+			return "", err
+		}
+		ck += att + string(rune(minUnicodeRune))
+	}
+	return ck, nil
+}
+
+func (s *Stub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	var components []string
+	start := 1
+	for i := 1; i < len(compositeKey); i++ {
+		if compositeKey[i] == minUnicodeRune {
+			components = append(components, compositeKey[start:i])
+			start = i + 1
+		}
+	}
+	if len(components) == 0 {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	return components[0], components[1:], nil
+}
+
+func (s *Stub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	iter, _, err := s.GetQueryResultWithPagination(query, 0, "")
+	return iter, err
+}
+
+func (s *Stub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if !s.ledger.RichQueries {
+		return nil, nil, errors.New("ExecuteQuery not supported for leveldb")
+	}
+	sel, err := parseQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	start := emptyKeySubstitute
+	if bookmark != "" {
+		start = bookmark
+	}
+	var matched []*queryresult.KV
+	for _, kv := range s.ledger.rangeState(start, "") {
+		var doc map[string]interface{}
+		if json.Unmarshal(kv.Value, &doc) != nil {
+			continue
+		}
+		if sel.match(doc) {
+			matched = append(matched, kv)
+		}
+	}
+	if pageSize <= 0 {
+		return newStateIterator(matched), &pb.QueryResponseMetadata{FetchedRecordsCount: int32(len(matched))}, nil
+	}
+	page, meta := paginate(matched, pageSize)
+	return newStateIterator(page), meta, nil
+}
+
+func (s *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return newHistoryIterator(s.ledger.keyHistory(key)), nil
+}
+
+func (s *Stub) GetPrivateData(collection, key string) ([]byte, error) {
+	if err := s.checkCollection(collection); err != nil {
+		return nil, err
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	s.recordRead(versionKey{collection, key})
+	return s.ledger.private[collection][key], nil
+}
+
+func (s *Stub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	if collection == "" {
+		return nil, errors.New("collection must not be an empty string")
+	}
+	s.ledger.mu.Lock()
+	s.recordRead(versionKey{collection, key})
+	value := s.ledger.private[collection][key]
+	s.ledger.mu.Unlock()
+	if value == nil {
+		return nil, nil
+	}
+	sum := sha256.Sum256(value)
+	return sum[:], nil
+}
+
+func (s *Stub) PutPrivateData(collection string, key string, value []byte) error {
+	if collection == "" {
+		return errors.New("collection must not be an empty string")
+	}
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	s.privateWrites(collection)[key] = &write{value: append([]byte(nil), value...)}
+	return nil
+}
+
+func (s *Stub) DelPrivateData(collection, key string) error {
+	if collection == "" {
+		return errors.New("collection must not be an empty string")
+	}
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	s.privateWrites(collection)[key] = &write{del: true}
+	return nil
+}
+
+// PurgePrivateData deletes the key like DelPrivateData; the mock keeps no private history
+func (s *Stub) PurgePrivateData(collection, key string) error {
+	return s.DelPrivateData(collection, key)
+}
+
+func (s *Stub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	return nil
+}
+
+func (s *Stub) GetPrivateDataValidationParameter(collection, key string) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *Stub) GetPrivateDataByRange(collection, startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := s.checkCollection(collection); err != nil {
+		return nil, err
+	}
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	return newStateIterator(s.ledger.rangePrivate(collection, startKey, endKey)), nil
+}
+
+func (s *Stub) GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	if err := s.checkCollection(collection); err != nil {
+		return nil, err
+	}
+	prefix, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return newStateIterator(s.ledger.rangePrivate(collection, prefix, prefix+string(rune(maxUnicodeRune)))), nil
+}
+
+func (s *Stub) GetPrivateDataQueryResult(collection, query string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errors.New("ExecuteQuery not supported for leveldb")
+}
+
+func (s *Stub) GetCreator() ([]byte, error) {
+	if s.identity == nil {
+		return nil, errors.New("transaction has no creator")
+	}
+	return s.identity.Creator()
+}
+
+func (s *Stub) GetTransient() (map[string][]byte, error) {
+	if s.transient == nil {
+		return map[string][]byte{}, nil
+	}
+	return s.transient, nil
+}
+
+func (s *Stub) GetBinding() ([]byte, error) {
+	return nil, nil
+}
+
+func (s *Stub) GetDecorations() map[string][]byte {
+	return nil
+}
+
+func (s *Stub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return nil, errors.New("GetSignedProposal is not supported by mockstub")
+}
+
+func (s *Stub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return s.timestamp, nil
+}
+
+// SetEvent replaces any earlier event; Fabric keeps one chaincode event per transaction
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be empty string")
+	}
+	s.event = &Event{TxID: s.txID, Name: name, Payload: append([]byte(nil), payload...)}
+	return nil
+}
+
+func (s *Stub) checkOpen() error {
+	if s.done {
+		return fmt.Errorf("transaction %s already committed", s.txID)
+	}
+	return nil
+}
+
+func (s *Stub) checkCollection(collection string) error {
+	if collection == "" {
+		return errors.New("collection must not be an empty string")
+	}
+	if !s.ledger.canRead(collection, s.identity) {
+		return fmt.Errorf("tx creator does not have read access permission on privatedata in chaincodeName:tendercc collectionName: %s", collection)
+	}
+	return nil
+}
+
+// recordRead notes the committed version of a key the first time the transaction reads
+// it. Callers hold the ledger lock.
+func (s *Stub) recordRead(k versionKey) {
+	if _, ok := s.reads[k]; !ok {
+		s.reads[k] = s.ledger.versions[k]
+	}
+}
+
+func (s *Stub) recordRange(startKey, endKey string, kvs []*queryresult.KV) {
+	keys := make([]string, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = kv.Key
+	}
+	s.ranges = append(s.ranges, rangeRead{startKey: startKey, endKey: endKey, keys: keys})
+}
+
+func (s *Stub) privateWrites(collection string) map[string]*write {
+	w := s.private[collection]
+	if w == nil {
+		w = make(map[string]*write)
+		s.private[collection] = w
+	}
+	return w
+}
+
+func (l *Ledger) rangeState(startKey, endKey string) []*queryresult.KV {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return rangeOf(l.state, startKey, endKey)
+}
+
+func (l *Ledger) rangePrivate(collection, startKey, endKey string) []*queryresult.KV {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return rangeOf(l.private[collection], startKey, endKey)
+}
+
+func (l *Ledger) validate(s *Stub) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, version := range s.reads {
+		if l.versions[k] != version {
+			return MVCCReadConflict
+		}
+	}
+	for _, r := range s.ranges {
+		kvs := rangeOf(l.state, r.startKey, r.endKey)
+		if len(kvs) != len(r.keys) {
+			return PhantomReadConflict
+		}
+		for i, kv := range kvs {
+			if kv.Key != r.keys[i] {
+				return PhantomReadConflict
+			}
+		}
+	}
+	return Valid
+}
+
+// keyHistory returns a key's modifications newest first, as Fabric v2 does
+func (l *Ledger) keyHistory(key string) []*queryresult.KeyModification {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	mods := l.history[key]
+	out := make([]*queryresult.KeyModification, len(mods))
+	for i, m := range mods {
+		out[len(mods)-1-i] = m
+	}
+	return out
+}
+
+// rangeOf returns the entries with startKey <= key < endKey in order; an empty endKey is unbounded
+func rangeOf(m map[string][]byte, startKey, endKey string) []*queryresult.KV {
+	var kvs []*queryresult.KV
+	for key, value := range m {
+		if key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		kvs = append(kvs, &queryresult.KV{Namespace: "tendercc", Key: key, Value: value})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
+}
+
+// paginate cuts a page from kvs; the bookmark is the first key of the next page
+func paginate(kvs []*queryresult.KV, pageSize int32) ([]*queryresult.KV, *pb.QueryResponseMetadata) {
+	meta := &pb.QueryResponseMetadata{}
+	if pageSize > 0 && len(kvs) > int(pageSize) {
+		meta.Bookmark = kvs[pageSize].Key
+		kvs = kvs[:pageSize]
+	}
+	meta.FetchedRecordsCount = int32(len(kvs))
+	return kvs, meta
+}
+
+func validateSimpleKey(key string) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if strings.HasPrefix(key, compositeKeyNamespace) {
+		return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+	}
+	return nil
+}
+
+func validateRangeKeys(startKey, endKey string) error {
+	if err := validateSimpleKey(startKey); err != nil {
+		return err
+	}
+	if endKey != "" {
+		return validateSimpleKey(endKey)
+	}
+	return nil
+}
+
+func validateCompositeKeyAttribute(str string) error {
+	if !utf8.ValidString(str) {
+		return fmt.Errorf("not a valid utf8 string: [%x]", str)
+	}
+	for index, runeValue := range str {
+		if runeValue == minUnicodeRune || runeValue == maxUnicodeRune {
+			return fmt.Errorf("input contains unicode %#U starting at position [%d]. %#U and %#U are not allowed in the input attribute of a composite key",
+				runeValue, index, minUnicodeRune, maxUnicodeRune)
+		}
+	}
+	return nil
+}
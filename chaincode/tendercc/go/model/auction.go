@@ -0,0 +1,66 @@
+package model
+
+// AuctionConfig describes the bidding rules of a reverse auction
+type AuctionConfig struct {
+	StartTime           string  `json:"startTime"`                                          // Auction window opens (RFC3339)
+	EndTime             string  `json:"endTime"`                                            // Scheduled close before any extension (RFC3339)
+	StartingPrice       float64 `json:"startingPrice,omitempty" metadata:",optional"`       // Ceiling; first offers must be at or below it
+	MinDecrement        float64 `json:"minDecrement"`                                       // Absolute amount each new offer must improve by
+	MinDecrementPercent float64 `json:"minDecrementPercent,omitempty" metadata:",optional"` // Alternative relative decrement
+	ExtensionWindowSecs int     `json:"extensionWindowSecs,omitempty" metadata:",optional"` // Offers inside this window before close extend the auction
+	ExtensionSecs       int     `json:"extensionSecs,omitempty" metadata:",optional"`       // How long each extension adds
+	MaxExtensions       int     `json:"maxExtensions,omitempty" metadata:",optional"`       // 0 means unlimited
+}
+
+// AuctionState is the public, identity-free view of a running auction
+type AuctionState struct {
+	TenderID      string  `json:"tenderId"`
+	Status        string  `json:"status"` // SCHEDULED, RUNNING, CLOSED
+	EndTime       string  `json:"endTime"`
+	Extensions    int     `json:"extensions"`
+	OfferCount    int     `json:"offerCount"`
+	BidderCount   int     `json:"bidderCount"`
+	LeadingAmount float64 `json:"leadingAmount,omitempty" metadata:",optional"`
+	LastOfferAt   string  `json:"lastOfferAt,omitempty" metadata:",optional"`
+}
+
+// AuctionOffer is a bidder's current best offer, kept in the private bids collection
+type AuctionOffer struct {
+	TenderID     string  `json:"tenderId"`
+	BidID        string  `json:"bidId"`
+	ContractorID string  `json:"contractorId"`
+	BidderAlias  string  `json:"bidderAlias"`
+	BidderID     string  `json:"bidderId"` // submitting client identity
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	PlacedAt     string  `json:"placedAt"`
+	OfferCount   int     `json:"offerCount"`
+}
+
+// AuctionRank is what a bidder is allowed to see about their own position
+type AuctionRank struct {
+	TenderID      string  `json:"tenderId"`
+	BidID         string  `json:"bidId"`
+	Rank          int     `json:"rank"`
+	BidderCount   int     `json:"bidderCount"`
+	MyAmount      float64 `json:"myAmount"`
+	LeadingAmount float64 `json:"leadingAmount"`
+	EndTime       string  `json:"endTime"`
+	Status        string  `json:"status"`
+}
+
+// AuctionResultEntry is one line of the final ranking; competitors are only named by alias
+type AuctionResultEntry struct {
+	Rank        int     `json:"rank"`
+	BidID       string  `json:"bidId"`
+	BidderAlias string  `json:"bidderAlias"`
+	Amount      float64 `json:"amount"`
+	PlacedAt    string  `json:"placedAt"`
+}
+
+// AuctionResult is the final ranking recorded when the auction closes
+type AuctionResult struct {
+	TenderID string               `json:"tenderId"`
+	ClosedAt string               `json:"closedAt"`
+	Ranking  []AuctionResultEntry `json:"ranking"`
+}
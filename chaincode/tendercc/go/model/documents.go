@@ -0,0 +1,47 @@
+package model
+
+// DocumentRef describes an off-chain file by its content hash
+type DocumentRef struct {
+	Hash       string         `json:"hash"` // lowercase hex sha256
+	Name       string         `json:"name"`
+	MimeType   string         `json:"mimeType"`
+	SizeBytes  int64          `json:"sizeBytes"`
+	StorageURI string         `json:"storageUri"`
+	Uploader   string         `json:"uploader"` // MSP ID of the attaching org
+	CreatedAt  string         `json:"createdAt"`
+	Links      []DocumentLink `json:"links"`
+}
+
+// DocumentLink records that a record references a document version
+type DocumentLink struct {
+	LinkType     string `json:"linkType"` // TENDER, BID, MILESTONE
+	TenderID     string `json:"tenderId"`
+	RefID        string `json:"refId,omitempty" metadata:",optional"` // bidId or milestoneId
+	Name         string `json:"name"`                                 // logical document name, e.g. "Technical Proposal"
+	Version      int    `json:"version"`
+	PreviousHash string `json:"previousHash,omitempty" metadata:",optional"`
+	Hash         string `json:"hash"`
+	AttachedBy   string `json:"attachedBy"`
+	AttachedAt   string `json:"attachedAt"`
+	TxID         string `json:"txId"`
+}
+
+// DocumentVerification answers VerifyDocument queries
+type DocumentVerification struct {
+	Hash     string         `json:"hash"`
+	Found    bool           `json:"found"`
+	Document *DocumentRef   `json:"document,omitempty" metadata:",optional"`
+	Links    []DocumentLink `json:"links,omitempty" metadata:",optional"`
+}
+
+// DocumentAttachment is the AttachDocument request: an off-chain file described by its hash
+type DocumentAttachment struct {
+	Name       string `json:"name"`
+	MimeType   string `json:"mimeType"`
+	SizeBytes  int64  `json:"sizeBytes"`
+	SHA256     string `json:"sha256"`
+	StorageURI string `json:"storageUri"`
+	LinkType   string `json:"linkType"`
+	TenderID   string `json:"tenderId"`
+	RefID      string `json:"refId,omitempty" metadata:",optional"`
+}
@@ -0,0 +1,41 @@
+package model
+
+// BidEncryptionConfig publishes the tender key that bids must be encrypted to.
+// The matching private key is split off-chain into Shamir shares (see package
+// bidcrypto); each holder releases their share after the tender is closed and
+// the key is rebuilt on-chain once Threshold shares are in.
+type BidEncryptionConfig struct {
+	PublicKey    string           `json:"publicKey"` // PKIX PEM, curve P-256
+	Threshold    int              `json:"threshold"`
+	ShareHolders []KeyShareHolder `json:"shareHolders"`
+	ReleasedKey  string           `json:"releasedKey,omitempty" metadata:",optional"` // hex private key, set once the threshold is reached
+	ReleasedAt   string           `json:"releasedAt,omitempty" metadata:",optional"`
+}
+
+// KeyShareHolder names the evaluator holding one key share and the public commitment to it
+type KeyShareHolder struct {
+	Index      int    `json:"index"`
+	HolderID   string `json:"holderId"`   // client identity ID or MSP ID of the evaluator
+	Commitment string `json:"commitment"` // hex share * G, from bidcrypto.SplitKey
+}
+
+// EncryptedBid is the sealed bid kept in the private collection until opening
+type EncryptedBid struct {
+	TenderID       string `json:"tenderId"`
+	BidID          string `json:"bidId"`
+	ContractorID   string `json:"contractorId"`
+	Ciphertext     string `json:"ciphertext"`     // base64 bidcrypto.Encrypt output
+	PayloadHash    string `json:"payloadHash"`    // hex SHA-256 of the canonical plaintext EnhancedBidPrivate JSON
+	CiphertextHash string `json:"ciphertextHash"` // hex SHA-256 of the ciphertext
+	SubmittedAt    string `json:"submittedAt"`
+}
+
+// ReleasedKeyShare is a key share released by its holder after closing
+type ReleasedKeyShare struct {
+	TenderID   string `json:"tenderId"`
+	Index      int    `json:"index"`
+	Value      string `json:"value"`
+	HolderID   string `json:"holderId"`
+	ReleasedBy string `json:"releasedBy"`
+	ReleasedAt string `json:"releasedAt"`
+}
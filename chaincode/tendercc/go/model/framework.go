@@ -0,0 +1,58 @@
+package model
+
+// FrameworkAgreement is a multi-supplier agreement created from an awarded tender
+type FrameworkAgreement struct {
+	ID             string            `json:"id"`
+	TenderID       string            `json:"tenderId"`
+	Title          string            `json:"title"`
+	Members        []FrameworkMember `json:"members"`
+	CeilingValue   float64           `json:"ceilingValue"`
+	CommittedValue float64           `json:"committedValue"`
+	Currency       string            `json:"currency"`
+	StartDate      string            `json:"startDate"`
+	ExpiryDate     string            `json:"expiryDate"`
+	Status         string            `json:"status"` // ACTIVE, EXHAUSTED (expiry is checked against ExpiryDate)
+	OrderCount     int               `json:"orderCount"`
+	CreatedAt      string            `json:"createdAt"`
+	UpdatedAt      string            `json:"updatedAt"`
+}
+
+// FrameworkMember is a supplier admitted to a framework through its winning bid
+type FrameworkMember struct {
+	ContractorID string `json:"contractorId"`
+	BidID        string `json:"bidId"`
+}
+
+// CallOffOrder is an order placed under a framework agreement
+type CallOffOrder struct {
+	FrameworkID  string  `json:"frameworkId"`
+	OrderID      string  `json:"orderId"`
+	Description  string  `json:"description"`
+	Method       string  `json:"method"` // DIRECT, MINI_COMPETITION
+	MaxValue     float64 `json:"maxValue,omitempty" metadata:",optional"`
+	Value        float64 `json:"value,omitempty" metadata:",optional"`
+	ContractorID string  `json:"contractorId,omitempty" metadata:",optional"`
+	Deadline     string  `json:"deadline,omitempty" metadata:",optional"` // Mini-competition response deadline
+	Status       string  `json:"status"`                                  // OPEN, AWARDED, CANCELLED
+	Responses    int     `json:"responses,omitempty" metadata:",optional"`
+	CreatedAt    string  `json:"createdAt"`
+	AwardedAt    string  `json:"awardedAt,omitempty" metadata:",optional"`
+}
+
+// CallOffResponse is a framework member's confidential offer in a mini-competition
+type CallOffResponse struct {
+	FrameworkID  string  `json:"frameworkId"`
+	OrderID      string  `json:"orderId"`
+	ContractorID string  `json:"contractorId"`
+	Amount       float64 `json:"amount"`
+	Details      string  `json:"details,omitempty" metadata:",optional"`
+	SubmittedAt  string  `json:"submittedAt"`
+}
+
+// CallOffResponseRef is the public reference of a mini-competition response
+type CallOffResponseRef struct {
+	FrameworkID  string `json:"frameworkId"`
+	OrderID      string `json:"orderId"`
+	ContractorID string `json:"contractorId"`
+	ResponseHash string `json:"responseHash"`
+}
@@ -0,0 +1,27 @@
+package model
+
+// HistoryEntry is one committed write to a key. Exactly one of the value fields is set
+// unless the write was a delete.
+type HistoryEntry struct {
+	Key           string          `json:"key"`
+	RecordType    string          `json:"recordType"` // TENDER, BID_REF, EVALUATION, LOT_EVALUATION, MILESTONE
+	RefID         string          `json:"refId,omitempty" metadata:",optional"`
+	TxID          string          `json:"txId"`
+	Timestamp     string          `json:"timestamp"` // RFC3339 with nanoseconds
+	IsDelete      bool            `json:"isDelete"`
+	Tender        *EnhancedTender `json:"tender,omitempty" metadata:",optional"`
+	LegacyTender  *Tender         `json:"legacyTender,omitempty" metadata:",optional"`
+	BidRef        *BidRef         `json:"bidRef,omitempty" metadata:",optional"`
+	Evaluation    *Evaluation     `json:"evaluation,omitempty" metadata:",optional"`
+	LotEvaluation *LotEvaluation  `json:"lotEvaluation,omitempty" metadata:",optional"`
+	Milestone     *MilestoneRef   `json:"milestone,omitempty" metadata:",optional"`
+	DecodeError   string          `json:"decodeError,omitempty" metadata:",optional"`
+}
+
+// HistoryPage is one page of history entries in chronological order.
+// Bookmark is the offset of the next page and is empty on the last page.
+type HistoryPage struct {
+	Entries  []*HistoryEntry `json:"entries"`
+	Bookmark string          `json:"bookmark"`
+	Total    int             `json:"total"`
+}
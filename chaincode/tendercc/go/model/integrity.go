@@ -0,0 +1,32 @@
+package model
+
+// BidIntegrityReport compares a bid's recorded public hash with its stored private payload
+type BidIntegrityReport struct {
+	TenderID     string `json:"tenderId"`
+	BidID        string `json:"bidId"`
+	RecordedHash string `json:"recordedHash"`
+	ComputedHash string `json:"computedHash"`
+	Method       string `json:"method,omitempty" metadata:",optional"`
+	Match        bool   `json:"match"`
+	Error        string `json:"error,omitempty" metadata:",optional"`
+}
+
+// PrivateDataAuditEntry is the audit result for one public ref. It never carries private content.
+type PrivateDataAuditEntry struct {
+	Kind         string `json:"kind"` // BID or MILESTONE
+	TenderID     string `json:"tenderId"`
+	RefID        string `json:"refId"`
+	Collection   string `json:"collection"`
+	RecordedHash string `json:"recordedHash,omitempty" metadata:",optional"`
+	LedgerHash   string `json:"ledgerHash,omitempty" metadata:",optional"`
+	Status       string `json:"status"`
+}
+
+// PrivateDataAuditReport summarises a tender's private data audit; Issues lists every ref not OK,
+// including refs purged under the retention policy
+type PrivateDataAuditReport struct {
+	TenderID string                  `json:"tenderId"`
+	Checked  int                     `json:"checked"`
+	OK       int                     `json:"ok"`
+	Issues   []PrivateDataAuditEntry `json:"issues"`
+}
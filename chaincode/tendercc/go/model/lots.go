@@ -0,0 +1,51 @@
+package model
+
+// Lot is an independently biddable part of a tender
+type Lot struct {
+	ID                 string          `json:"id"`
+	Name               string          `json:"name"`
+	Description        string          `json:"description"`
+	Deliverables       []string        `json:"deliverables,omitempty" metadata:",optional"`
+	TechnicalSpecs     []string        `json:"technicalSpecs,omitempty" metadata:",optional"`
+	Budget             Budget          `json:"budget,omitempty" metadata:",optional"`
+	EvaluationCriteria []EvalCriterion `json:"evaluationCriteria,omitempty" metadata:",optional"` // Falls back to the tender criteria when empty
+	Status             string          `json:"status,omitempty" metadata:",optional" enum:"OPEN,AWARDED,UNAWARDED"`
+	AwardedBidID       string          `json:"awardedBidId,omitempty" metadata:",optional"`
+	AwardedAmount      float64         `json:"awardedAmount,omitempty" metadata:",optional"`
+	AwardedAt          string          `json:"awardedAt,omitempty" metadata:",optional"`
+}
+
+// LotBid is a bid's price for a single lot
+type LotBid struct {
+	LotID       string  `json:"lotId"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description,omitempty" metadata:",optional"`
+}
+
+// CrossLotDiscount applies when the same bid wins every listed lot
+type CrossLotDiscount struct {
+	LotIDs          []string `json:"lotIds"`
+	DiscountPercent float64  `json:"discountPercent"`
+	Description     string   `json:"description,omitempty" metadata:",optional"`
+}
+
+// LotEvaluation is the per-lot score of a bid
+type LotEvaluation struct {
+	TenderID string  `json:"tenderId"`
+	LotID    string  `json:"lotId"`
+	BidID    string  `json:"bidId"`
+	Amount   float64 `json:"amount"`
+	Score    float64 `json:"score"`
+	Notes    string  `json:"notes,omitempty" metadata:",optional"`
+}
+
+// LotAwardResult summarises the outcome of AwardLots
+type LotAwardResult struct {
+	TenderID  string            `json:"tenderId"`
+	Mode      string            `json:"mode"`
+	Awards    map[string]string `json:"awards"` // lotId -> bidId
+	TotalCost float64           `json:"totalCost"`
+	Discount  float64           `json:"discount,omitempty" metadata:",optional"`
+	Unawarded []string          `json:"unawarded,omitempty" metadata:",optional"`
+	AwardedAt string            `json:"awardedAt"`
+}
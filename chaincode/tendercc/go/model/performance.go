@@ -0,0 +1,51 @@
+package model
+
+// ContractorPerformance aggregates how a contractor has delivered on awarded tenders
+type ContractorPerformance struct {
+	ContractorID       string        `json:"contractorId"`
+	MilestonesApproved int           `json:"milestonesApproved"`
+	MilestonesRejected int           `json:"milestonesRejected"`
+	MilestonesOnTime   int           `json:"milestonesOnTime"`
+	MilestonesLate     int           `json:"milestonesLate"`
+	OnTimeRate         float64       `json:"onTimeRate"`    // Percentage of approved milestones delivered by their deadline
+	RejectionRate      float64       `json:"rejectionRate"` // Percentage of milestone decisions that were rejections
+	PenaltiesCount     int           `json:"penaltiesCount"`
+	PenaltiesTotal     float64       `json:"penaltiesTotal"`
+	Terminations       int           `json:"terminations"`
+	ContractsCompleted int           `json:"contractsCompleted"`
+	Ratings            []BuyerRating `json:"ratings,omitempty" metadata:",optional"`
+	AverageRating      float64       `json:"averageRating"`
+	UpdatedAt          string        `json:"updatedAt"`
+}
+
+// BuyerRating is a buyer's score (1-5) of a contractor on one tender
+type BuyerRating struct {
+	TenderID string `json:"tenderId"`
+	Rating   int    `json:"rating"`
+	Comment  string `json:"comment,omitempty" metadata:",optional"`
+	RatedBy  string `json:"ratedBy"` // MSP ID of the rating org
+	RatedAt  string `json:"ratedAt"`
+}
+
+// PenaltyRecord is a penalty applied to a contractor under a contract
+type PenaltyRecord struct {
+	TenderID     string  `json:"tenderId"`
+	PenaltyID    string  `json:"penaltyId"`
+	ContractorID string  `json:"contractorId"`
+	Type         string  `json:"type"` // DELAY, PERFORMANCE, QUALITY
+	Amount       float64 `json:"amount"`
+	Reason       string  `json:"reason"`
+	RecordedAt   string  `json:"recordedAt"`
+}
+
+// DebarmentEntry excludes a contractor from bidding for a period
+type DebarmentEntry struct {
+	ContractorID string `json:"contractorId"`
+	Reason       string `json:"reason"`
+	StartDate    string `json:"startDate"`
+	EndDate      string `json:"endDate,omitempty" metadata:",optional"` // Empty means indefinite
+	DebarredBy   string `json:"debarredBy"`
+	CreatedAt    string `json:"createdAt"`
+	LiftedAt     string `json:"liftedAt,omitempty" metadata:",optional"`
+	LiftReason   string `json:"liftReason,omitempty" metadata:",optional"`
+}
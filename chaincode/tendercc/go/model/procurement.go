@@ -0,0 +1,18 @@
+package model
+
+// SingleSourceJustification records why competition was waived
+type SingleSourceJustification struct {
+	Reason       string `json:"reason"` // e.g. PROPRIETARY, URGENCY, CONTINUITY
+	Details      string `json:"details"`
+	DocumentHash string `json:"documentHash,omitempty" metadata:",optional"`
+	RecordedBy   string `json:"recordedBy"`
+	RecordedAt   string `json:"recordedAt"`
+}
+
+// AwardApproval is one sign-off required before a single-source award
+type AwardApproval struct {
+	ApproverID string `json:"approverId"` // client identity of the approver
+	MSPID      string `json:"mspId"`
+	Comment    string `json:"comment,omitempty" metadata:",optional"`
+	ApprovedAt string `json:"approvedAt"`
+}
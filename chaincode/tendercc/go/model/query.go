@@ -0,0 +1,40 @@
+package model
+
+// TenderFilter selects enhanced tenders. Empty fields do not filter.
+type TenderFilter struct {
+	Status             string  `json:"status,omitempty" metadata:",optional"`
+	Country            string  `json:"country,omitempty" metadata:",optional"`            // ownerDetails.address.country
+	Sector             string  `json:"sector,omitempty" metadata:",optional"`             // one of experienceRequirements.relevantSectors
+	ComplianceStandard string  `json:"complianceStandard,omitempty" metadata:",optional"` // one of complianceRequirements[].standard
+	ProcurementMethod  string  `json:"procurementMethod,omitempty" metadata:",optional"`
+	Currency           string  `json:"currency,omitempty" metadata:",optional"`
+	BudgetMin          float64 `json:"budgetMin,omitempty" metadata:",optional"`    // tender's estimated maximum must be at least this
+	BudgetMax          float64 `json:"budgetMax,omitempty" metadata:",optional"`    // tender's estimated minimum must be at most this
+	DeadlineFrom       string  `json:"deadlineFrom,omitempty" metadata:",optional"` // RFC3339, bid submission deadline on or after
+	DeadlineTo         string  `json:"deadlineTo,omitempty" metadata:",optional"`   // RFC3339, bid submission deadline on or before
+	PageSize           int32   `json:"pageSize,omitempty" metadata:",optional"`
+	Bookmark           string  `json:"bookmark,omitempty" metadata:",optional"`
+}
+
+// BidFilter selects public bid references
+type BidFilter struct {
+	TenderID     string `json:"tenderId,omitempty" metadata:",optional"`
+	ContractorID string `json:"contractorId,omitempty" metadata:",optional"`
+	PageSize     int32  `json:"pageSize,omitempty" metadata:",optional"`
+	Bookmark     string `json:"bookmark,omitempty" metadata:",optional"`
+}
+
+// TenderQueryResult is one page of tenders. Pass Bookmark back to fetch the next page;
+// on the composite-key fallback a page may hold fewer than PageSize matches.
+type TenderQueryResult struct {
+	Tenders      []*EnhancedTender `json:"tenders"`
+	Bookmark     string            `json:"bookmark"`
+	FetchedCount int32             `json:"fetchedCount"`
+}
+
+// BidQueryResult is one page of bid references
+type BidQueryResult struct {
+	Bids         []*BidRef `json:"bids"`
+	Bookmark     string    `json:"bookmark"`
+	FetchedCount int32     `json:"fetchedCount"`
+}
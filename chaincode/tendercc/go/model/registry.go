@@ -0,0 +1,69 @@
+package model
+
+// VendorProfile is the registry record of a contractor; its ID is the contractorId used in bids
+type VendorProfile struct {
+	ID                 string                `json:"id"`
+	LegalName          string                `json:"legalName"`
+	LegalEntity        string                `json:"legalEntity"`
+	RegistrationNumber string                `json:"registrationNumber"`
+	IncorporationDate  string                `json:"incorporationDate"` // RFC3339, drives years in business
+	TaxInfo            TaxInfo               `json:"taxInfo"`
+	Address            Address               `json:"address"`
+	ContactPerson      ContactPerson         `json:"contactPerson"`
+	Sectors            []string              `json:"sectors,omitempty" metadata:",optional"`
+	Certifications     []VendorCertification `json:"certifications,omitempty" metadata:",optional"`
+	Financials         []FinancialYear       `json:"financials,omitempty" metadata:",optional"`
+	PastProjects       []PastProject         `json:"pastProjects,omitempty" metadata:",optional"`
+	Status             string                `json:"status"`       // ACTIVE, SUSPENDED
+	RegisteredBy       string                `json:"registeredBy"` // MSP ID of the registering org
+	CreatedAt          string                `json:"createdAt"`
+	UpdatedAt          string                `json:"updatedAt"`
+}
+
+// VendorCertification is a certificate held by a vendor
+type VendorCertification struct {
+	Name              string `json:"name"`
+	IssuingBody       string `json:"issuingBody"`
+	CertificateNumber string `json:"certificateNumber,omitempty" metadata:",optional"`
+	IssuedAt          string `json:"issuedAt,omitempty" metadata:",optional"`
+	ValidUntil        string `json:"validUntil"`
+	DocumentHash      string `json:"documentHash"`
+	Verified          bool   `json:"verified"`
+	VerifiedAt        string `json:"verifiedAt,omitempty" metadata:",optional"`
+}
+
+// FinancialYear is one year of a vendor's financial statements
+type FinancialYear struct {
+	Year         int     `json:"year"`
+	Turnover     float64 `json:"turnover"`
+	NetWorth     float64 `json:"netWorth"`
+	Currency     string  `json:"currency"`
+	Audited      bool    `json:"audited"`
+	DocumentHash string  `json:"documentHash"`
+	Verified     bool    `json:"verified"`
+	VerifiedAt   string  `json:"verifiedAt,omitempty" metadata:",optional"`
+}
+
+// PastProject is a completed reference project
+type PastProject struct {
+	Name         string  `json:"name"`
+	Client       string  `json:"client"`
+	Sector       string  `json:"sector"`
+	Country      string  `json:"country"`
+	Value        float64 `json:"value"`
+	Currency     string  `json:"currency"`
+	StartDate    string  `json:"startDate,omitempty" metadata:",optional"`
+	EndDate      string  `json:"endDate,omitempty" metadata:",optional"`
+	DocumentHash string  `json:"documentHash"`
+	Verified     bool    `json:"verified"`
+	VerifiedAt   string  `json:"verifiedAt,omitempty" metadata:",optional"`
+}
+
+// PrequalificationReport explains whether a vendor meets a tender's requirements
+type PrequalificationReport struct {
+	TenderID  string   `json:"tenderId"`
+	VendorID  string   `json:"vendorId"`
+	Qualified bool     `json:"qualified"`
+	Failures  []string `json:"failures,omitempty" metadata:",optional"`
+	CheckedAt string   `json:"checkedAt"`
+}
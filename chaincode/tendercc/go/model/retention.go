@@ -0,0 +1,22 @@
+package model
+
+// RetentionPolicy controls when confidential data may be purged
+type RetentionPolicy struct {
+	LosingBidDays        int `json:"losingBidDays"`        // days after award before losing bids may be purged
+	MilestoneArchiveDays int `json:"milestoneArchiveDays"` // days after the warranty period ends before milestone details may be purged
+}
+
+// PurgeRecord is the on-chain tombstone left for a purged private data key.
+// DataHash is the private data hash at purge time, so earlier public hashes stay verifiable.
+type PurgeRecord struct {
+	TenderID   string `json:"tenderId"`
+	Collection string `json:"collection"`
+	Key        string `json:"key"`
+	RefType    string `json:"refType"` // BID, AUCTION_OFFER, ENCRYPTED_BID, BID_SIGNATURE, MILESTONE
+	RefID      string `json:"refId"`
+	DataHash   string `json:"dataHash"`
+	Reason     string `json:"reason"`
+	Method     string `json:"method"`
+	PurgedBy   string `json:"purgedBy"`
+	PurgedAt   string `json:"purgedAt"`
+}
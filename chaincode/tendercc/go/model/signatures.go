@@ -0,0 +1,36 @@
+package model
+
+// DetachedSignature is passed in the transient map under "signature".
+// Without a KeyID the signature is checked against the caller's enrolled certificate.
+type DetachedSignature struct {
+	Signature string `json:"signature"` // base64; ECDSA (ASN.1) or RSA PKCS#1 v1.5 over SHA-256, or Ed25519
+	KeyID     string `json:"keyId,omitempty" metadata:",optional"`
+}
+
+// SigningKey is a public key registered for signing outside the Fabric identity
+type SigningKey struct {
+	KeyID         string `json:"keyId"`
+	OwnerID       string `json:"ownerId"`   // contractor, vendor or officer the key belongs to
+	PublicKey     string `json:"publicKey"` // PKIX PEM
+	RegisteredBy  string `json:"registeredBy"`
+	RegisteredMSP string `json:"registeredMsp"`
+	RegisteredAt  string `json:"registeredAt"`
+	Revoked       bool   `json:"revoked"`
+	RevokedAt     string `json:"revokedAt,omitempty" metadata:",optional"`
+}
+
+// SignatureRecord is the on-chain result of verifying a detached signature
+type SignatureRecord struct {
+	TenderID    string `json:"tenderId"`
+	Subject     string `json:"subject"` // BID, AWARD, CONTRACT, MILESTONE_APPROVAL
+	RefID       string `json:"refId"`
+	SignerID    string `json:"signerId"` // certificate common name or key owner
+	SignerMSP   string `json:"signerMsp"`
+	KeyID       string `json:"keyId,omitempty" metadata:",optional"`
+	Method      string `json:"method"`
+	PayloadHash string `json:"payloadHash"` // hex SHA-256 of the canonical payload
+	Signature   string `json:"signature"`
+	Verified    bool   `json:"verified"`
+	Error       string `json:"error,omitempty" metadata:",optional"`
+	RecordedAt  string `json:"recordedAt"`
+}
@@ -0,0 +1,489 @@
+// Package model defines the tender, bid, milestone and supporting records that the
+// tendercc chaincode stores on the ledger and returns from its transactions.
+// Off-chain clients import it to decode chaincode results into the same types.
+package model
+
+// Enhanced Tender structure with comprehensive RFQ requirements
+type EnhancedTender struct {
+	ID                  string                     `json:"id"`
+	ProjectScope        ProjectScope               `json:"projectScope"`
+	Deadlines           TenderDeadlines            `json:"deadlines"`
+	EvaluationCriteria  []EvalCriterion            `json:"evaluationCriteria"`
+	BidRequirements     BidRequirements            `json:"bidRequirements"`
+	ContractTerms       ContractTerms              `json:"contractTerms"`
+	ComplianceReqs      []ComplianceReq            `json:"complianceRequirements"`
+	OwnerDetails        OwnerInfo                  `json:"ownerDetails"`
+	OwnerMSP            string                     `json:"ownerMsp,omitempty" metadata:",optional"` // MSP ID of the creating org, set on creation
+	Status              string                     `json:"status" enum:"DRAFT,OPEN,CLOSED,AWARDED,CANCELLED,COMPLETED,TERMINATED"`
+	AwardedBidID        string                     `json:"awardedBidId,omitempty" metadata:",optional"`
+	CreatedAt           string                     `json:"createdAt"`
+	UpdatedAt           string                     `json:"updatedAt"`
+	Version             int                        `json:"version"`
+	DocumentHashes      map[string]string          `json:"documentHashes,omitempty" metadata:",optional"`
+	RetentionReleased   bool                       `json:"retentionReleased,omitempty" metadata:",optional"`
+	RetentionReleasedAt string                     `json:"retentionReleasedAt,omitempty" metadata:",optional"`
+	AuctionType         string                     `json:"auctionType,omitempty" metadata:",optional" enum:"SEALED,REVERSE"` // SEALED is the default
+	Auction             *AuctionConfig             `json:"auction,omitempty" metadata:",optional"`
+	Lots                []Lot                      `json:"lots,omitempty" metadata:",optional"`
+	LotAwardMode        string                     `json:"lotAwardMode,omitempty" metadata:",optional" enum:"PER_LOT,CHEAPEST_COMBINATION"`               // PER_LOT is the default
+	FrameworkID         string                     `json:"frameworkId,omitempty" metadata:",optional"`                                                    // Set when the award created a framework agreement
+	ProcurementMethod   string                     `json:"procurementMethod,omitempty" metadata:",optional" enum:"OPEN,RESTRICTED,INVITED,SINGLE_SOURCE"` // OPEN is the default
+	Invitees            []string                   `json:"invitees,omitempty" metadata:",optional"`                                                       // Contractor/vendor IDs or MSP IDs allowed to bid
+	Justification       *SingleSourceJustification `json:"justification,omitempty" metadata:",optional"`
+	RequiredApprovals   int                        `json:"requiredApprovals,omitempty" metadata:",optional"` // Single-source sign-offs needed before award
+	AwardApprovals      []AwardApproval            `json:"awardApprovals,omitempty" metadata:",optional"`
+	BidEncryption       *BidEncryptionConfig       `json:"bidEncryption,omitempty" metadata:",optional"` // Bids must be sealed to this key when set
+	AwardedAt           string                     `json:"awardedAt,omitempty" metadata:",optional"`
+	ClosedOutAt         string                     `json:"closedOutAt,omitempty" metadata:",optional"` // Set when the contract is completed or terminated
+	Retention           *RetentionPolicy           `json:"retention,omitempty" metadata:",optional"`   // Private data purge periods; defaults apply when unset
+	DocType             string                     `json:"docType,omitempty" metadata:",optional"`     // Set by putTender for rich queries
+}
+
+// Comprehensive project scope definition
+type ProjectScope struct {
+	Description             string   `json:"description"`
+	Objectives              []string `json:"objectives"`
+	Deliverables            []string `json:"deliverables"`
+	TechnicalSpecs          []string `json:"technicalSpecs"`
+	QualityStandards        []string `json:"qualityStandards"`
+	GeographicalConstraints string   `json:"geographicalConstraints,omitempty" metadata:",optional"`
+	OperationalConstraints  []string `json:"operationalConstraints,omitempty" metadata:",optional"`
+	Assumptions             []string `json:"assumptions,omitempty" metadata:",optional"`
+	Exclusions              []string `json:"exclusions,omitempty" metadata:",optional"`
+	Budget                  Budget   `json:"budget,omitempty" metadata:",optional"`
+}
+
+// Budget information
+type Budget struct {
+	Currency        string             `json:"currency"`
+	EstimatedMin    float64            `json:"estimatedMin,omitempty" metadata:",optional"`
+	EstimatedMax    float64            `json:"estimatedMax,omitempty" metadata:",optional"`
+	PaymentTerms    string             `json:"paymentTerms"`
+	PaymentSchedule []PaymentMilestone `json:"paymentSchedule,omitempty" metadata:",optional"`
+}
+
+type PaymentMilestone struct {
+	Name        string  `json:"name"`
+	Percentage  float64 `json:"percentage"`
+	Description string  `json:"description"`
+}
+
+// Comprehensive deadline management
+type TenderDeadlines struct {
+	RFQIssueDate          string              `json:"rfqIssueDate"`          // When RFQ was published
+	QuestionsDeadline     string              `json:"questionsDeadline"`     // Last date for clarification questions
+	BidSubmissionDeadline string              `json:"bidSubmissionDeadline"` // Final bid submission deadline
+	ProjectStartDate      string              `json:"projectStartDate"`      // Expected project start
+	ProjectEndDate        string              `json:"projectEndDate"`        // Expected project completion
+	MilestoneDeadlines    []MilestoneDeadline `json:"milestoneDeadlines,omitempty" metadata:",optional"`
+}
+
+type MilestoneDeadline struct {
+	Name        string `json:"name"`
+	Deadline    string `json:"deadline"`
+	Description string `json:"description"`
+	Critical    bool   `json:"critical"` // If missing this affects overall timeline
+}
+
+// Structured evaluation criteria with weights
+type EvalCriterion struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	Weight               float64        `json:"weight"` // Percentage weight (total should be 100)
+	Type                 string         `json:"type" enum:"QUANTITATIVE,QUALITATIVE,PASS_FAIL"`
+	Description          string         `json:"description"`
+	ScoringMethod        string         `json:"scoringMethod" enum:"LOWEST_PRICE,HIGHEST_SCORE,WEIGHTED_AVERAGE"`
+	PassFailThreshold    float64        `json:"passFailThreshold,omitempty" metadata:",optional"`
+	SubCriteria          []SubCriterion `json:"subCriteria,omitempty" metadata:",optional"`
+	MandatoryRequirement bool           `json:"mandatoryRequirement"`
+}
+
+type SubCriterion struct {
+	Name        string  `json:"name"`
+	Weight      float64 `json:"weight"`
+	Description string  `json:"description"`
+}
+
+// Comprehensive bid requirements
+type BidRequirements struct {
+	RequiredDocuments         []DocumentReq      `json:"requiredDocuments"`
+	TechnicalRequirements     []TechnicalReq     `json:"technicalRequirements"`
+	FinancialRequirements     FinancialReq       `json:"financialRequirements"`
+	ExperienceRequirements    ExperienceReq      `json:"experienceRequirements"`
+	CertificationRequirements []CertificationReq `json:"certificationRequirements"`
+	BidSecurity               BidSecurity        `json:"bidSecurity,omitempty" metadata:",optional"`
+	SubmissionFormat          SubmissionFormat   `json:"submissionFormat"`
+	PrequalificationRequired  bool               `json:"prequalificationRequired,omitempty" metadata:",optional"` // Bidders must pass the vendor registry checks
+}
+
+type DocumentReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Mandatory   bool   `json:"mandatory"`
+	Format      string `json:"format"` // PDF, DOC, etc.
+	MaxSizeMB   int    `json:"maxSizeMB,omitempty" metadata:",optional"`
+}
+
+type TechnicalReq struct {
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Standards   []string `json:"standards,omitempty" metadata:",optional"`
+	Mandatory   bool     `json:"mandatory"`
+}
+
+type FinancialReq struct {
+	MinTurnover       float64 `json:"minTurnover,omitempty" metadata:",optional"`
+	MinNetWorth       float64 `json:"minNetWorth,omitempty" metadata:",optional"`
+	CreditRating      string  `json:"creditRating,omitempty" metadata:",optional"`
+	AuditedFinancials bool    `json:"auditedFinancials"`
+	YearsOfFinancials int     `json:"yearsOfFinancials"`
+	Currency          string  `json:"currency"`
+}
+
+type ExperienceReq struct {
+	MinYearsInBusiness int            `json:"minYearsInBusiness"`
+	SimilarProjectsMin int            `json:"similarProjectsMin"`
+	MinProjectValue    float64        `json:"minProjectValue,omitempty" metadata:",optional"`
+	RelevantSectors    []string       `json:"relevantSectors,omitempty" metadata:",optional"`
+	GeographicalExp    []string       `json:"geographicalExp,omitempty" metadata:",optional"`
+	KeyPersonnelReqs   []PersonnelReq `json:"keyPersonnelReqs,omitempty" metadata:",optional"`
+}
+
+type PersonnelReq struct {
+	Role                   string   `json:"role"`
+	MinExperience          int      `json:"minExperience"` // years
+	RequiredSkills         []string `json:"requiredSkills"`
+	CertificationsRequired []string `json:"certificationsRequired,omitempty" metadata:",optional"`
+}
+
+type CertificationReq struct {
+	Name        string `json:"name"`
+	IssuingBody string `json:"issuingBody"`
+	Mandatory   bool   `json:"mandatory"`
+	ValidUntil  string `json:"validUntil,omitempty" metadata:",optional"`
+}
+
+type BidSecurity struct {
+	Required     bool    `json:"required"`
+	Type         string  `json:"type"` // BANK_GUARANTEE, CASH, BOND
+	Amount       float64 `json:"amount"`
+	Percentage   float64 `json:"percentage"` // Alternative to fixed amount
+	Currency     string  `json:"currency"`
+	ValidityDays int     `json:"validityDays"`
+}
+
+type SubmissionFormat struct {
+	Method           string   `json:"method"`      // ONLINE, PHYSICAL, HYBRID
+	FileFormats      []string `json:"fileFormats"` // PDF, DOC, XLS, etc.
+	MaxFileSize      int      `json:"maxFileSize"` // MB
+	EncryptionReq    bool     `json:"encryptionReq"`
+	DigitalSignature bool     `json:"digitalSignature"`
+	Language         string   `json:"language"`
+}
+
+type ContractTerms struct {
+	ContractType         string               `json:"contractType"` // FIXED_PRICE, TIME_MATERIAL, etc.
+	PaymentTerms         PaymentTermsDetail   `json:"paymentTerms"`
+	PerformanceBond      PerformanceBond      `json:"performanceBond,omitempty" metadata:",optional"`
+	Warranties           []Warranty           `json:"warranties,omitempty" metadata:",optional"`
+	Penalties            []Penalty            `json:"penalties,omitempty" metadata:",optional"`
+	IntellectualProperty IPTerms              `json:"intellectualProperty,omitempty" metadata:",optional"`
+	DisputeResolution    DisputeResolution    `json:"disputeResolution"`
+	Termination          TerminationClause    `json:"termination"`
+	Confidentiality      ConfidentialityTerms `json:"confidentiality,omitempty" metadata:",optional"`
+}
+
+type PaymentTermsDetail struct {
+	AdvancePayment      float64 `json:"advancePayment,omitempty" metadata:",optional"` // Percentage
+	PaymentCycle        string  `json:"paymentCycle"`                                  // MONTHLY, MILESTONE_BASED, etc.
+	PaymentDays         int     `json:"paymentDays"`                                   // Days after invoice
+	RetentionPercentage float64 `json:"retentionPercentage,omitempty" metadata:",optional"`
+	RetentionPeriod     int     `json:"retentionPeriod,omitempty" metadata:",optional"` // Months
+	Currency            string  `json:"currency"`
+}
+
+type PerformanceBond struct {
+	Required     bool    `json:"required"`
+	Percentage   float64 `json:"percentage"`
+	ValidityDays int     `json:"validityDays"`
+	Type         string  `json:"type"` // BANK_GUARANTEE, INSURANCE, CASH
+}
+
+type Warranty struct {
+	Type        string `json:"type"`   // DEFECTS, PERFORMANCE, etc.
+	Period      int    `json:"period"` // Months
+	Description string `json:"description"`
+	Coverage    string `json:"coverage"` // FULL, PARTIAL, SPECIFIC
+}
+
+type Penalty struct {
+	Type        string  `json:"type"` // DELAY, PERFORMANCE, QUALITY
+	Amount      float64 `json:"amount,omitempty" metadata:",optional"`
+	Percentage  float64 `json:"percentage,omitempty" metadata:",optional"`
+	Cap         float64 `json:"cap,omitempty" metadata:",optional"` // Maximum penalty amount
+	Description string  `json:"description"`
+}
+
+type IPTerms struct {
+	OwnershipOfWork    string `json:"ownershipOfWork"`    // CLIENT, CONTRACTOR, SHARED
+	ExistingIPHandling string `json:"existingIPHandling"` // LICENSE, OWNERSHIP, etc.
+	NewIPOwnership     string `json:"newIPOwnership"`
+	LicenseTerms       string `json:"licenseTerms,omitempty" metadata:",optional"`
+}
+
+type DisputeResolution struct {
+	Method       string `json:"method"` // ARBITRATION, LITIGATION, MEDIATION
+	Jurisdiction string `json:"jurisdiction"`
+	GoverningLaw string `json:"governingLaw"`
+	Venue        string `json:"venue,omitempty" metadata:",optional"`
+}
+
+type TerminationClause struct {
+	TerminationForCause       bool    `json:"terminationForCause"`
+	TerminationForConvenience bool    `json:"terminationForConvenience"`
+	NoticePeriod              int     `json:"noticePeriod"` // Days
+	TerminationPenalty        float64 `json:"terminationPenalty,omitempty" metadata:",optional"`
+}
+
+type ConfidentialityTerms struct {
+	Required   bool     `json:"required"`
+	Duration   int      `json:"duration"` // Years
+	Scope      string   `json:"scope"`
+	Exceptions []string `json:"exceptions,omitempty" metadata:",optional"`
+}
+
+type ComplianceReq struct {
+	Type        string   `json:"type"`     // ENVIRONMENTAL, SAFETY, LABOR, etc.
+	Standard    string   `json:"standard"` // ISO14001, OHSAS18001, etc.
+	Description string   `json:"description"`
+	Mandatory   bool     `json:"mandatory"`
+	Evidence    []string `json:"evidence"` // Required proof documents
+	Auditable   bool     `json:"auditable"`
+}
+
+type OwnerInfo struct {
+	OrganizationName  string           `json:"organizationName"`
+	LegalEntity       string           `json:"legalEntity"`
+	Address           Address          `json:"address"`
+	ContactPerson     ContactPerson    `json:"contactPerson"`
+	AlternateContacts []ContactPerson  `json:"alternateContacts,omitempty" metadata:",optional"`
+	AuthorizedBy      AuthorizedPerson `json:"authorizedBy"`
+	TaxInfo           TaxInfo          `json:"taxInfo,omitempty" metadata:",optional"`
+}
+
+type Address struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty" metadata:",optional"`
+	Country    string `json:"country"`
+	PostalCode string `json:"postalCode"`
+}
+
+type ContactPerson struct {
+	Name       string `json:"name"`
+	Title      string `json:"title"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	Mobile     string `json:"mobile,omitempty" metadata:",optional"`
+	Department string `json:"department,omitempty" metadata:",optional"`
+}
+
+type AuthorizedPerson struct {
+	Name           string `json:"name"`
+	Title          string `json:"title"`
+	SignatureHash  string `json:"signatureHash,omitempty" metadata:",optional"`
+	SignerID       string `json:"signerId,omitempty" metadata:",optional"` // Enrollment ID or key owner that signs awards, approvals and the contract
+	AuthorityLevel string `json:"authorityLevel"`
+	Date           string `json:"date"`
+}
+
+type TaxInfo struct {
+	TaxID     string `json:"taxId"`
+	VATNumber string `json:"vatNumber,omitempty" metadata:",optional"`
+	TaxStatus string `json:"taxStatus"`
+}
+
+type EnhancedBidPrivate struct {
+	TenderID            string             `json:"tenderId"`
+	BidID               string             `json:"bidId"`
+	ContractorID        string             `json:"contractorId"`
+	TotalAmount         float64            `json:"totalAmount"`
+	Currency            string             `json:"currency"`
+	TechnicalProposal   TechnicalProposal  `json:"technicalProposal"`
+	FinancialProposal   FinancialProposal  `json:"financialProposal"`
+	ComplianceChecklist map[string]bool    `json:"complianceChecklist"`
+	DocumentHashes      map[string]string  `json:"documentHashes"`
+	SubmittedAt         string             `json:"submittedAt"`
+	ValidUntil          string             `json:"validUntil"`
+	LotBids             []LotBid           `json:"lotBids,omitempty" metadata:",optional"`
+	CrossLotDiscounts   []CrossLotDiscount `json:"crossLotDiscounts,omitempty" metadata:",optional"`
+}
+
+type TechnicalProposal struct {
+	Methodology      string           `json:"methodology"`
+	Timeline         ProjectTimeline  `json:"timeline"`
+	TeamComposition  []TeamMember     `json:"teamComposition"`
+	Resources        []Resource       `json:"resources"`
+	QualityAssurance QAProcess        `json:"qualityAssurance"`
+	RiskMitigation   []RiskMitigation `json:"riskMitigation"`
+	Innovation       []Innovation     `json:"innovation,omitempty" metadata:",optional"`
+}
+
+type ProjectTimeline struct {
+	StartDate    string   `json:"startDate"`
+	EndDate      string   `json:"endDate"`
+	Phases       []Phase  `json:"phases"`
+	CriticalPath []string `json:"criticalPath"`
+}
+
+type Phase struct {
+	Name         string   `json:"name"`
+	StartDate    string   `json:"startDate"`
+	EndDate      string   `json:"endDate"`
+	Deliverables []string `json:"deliverables"`
+	Dependencies []string `json:"dependencies,omitempty" metadata:",optional"`
+}
+
+type TeamMember struct {
+	Name                 string   `json:"name"`
+	Role                 string   `json:"role"`
+	Experience           int      `json:"experience"` // years
+	Skills               []string `json:"skills"`
+	Certifications       []string `json:"certifications,omitempty" metadata:",optional"`
+	AllocationPercentage float64  `json:"allocationPercentage"`
+}
+
+type Resource struct {
+	Type     string  `json:"type"` // EQUIPMENT, SOFTWARE, FACILITY
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Duration int     `json:"duration"` // days
+	Cost     float64 `json:"cost,omitempty" metadata:",optional"`
+}
+
+type QAProcess struct {
+	Standards     []string `json:"standards"`
+	Procedures    []string `json:"procedures"`
+	TestingPlan   string   `json:"testingPlan"`
+	Documentation string   `json:"documentation"`
+}
+
+type RiskMitigation struct {
+	Risk        string `json:"risk"`
+	Probability string `json:"probability" enum:"LOW,MEDIUM,HIGH"`
+	Impact      string `json:"impact" enum:"LOW,MEDIUM,HIGH"`
+	Mitigation  string `json:"mitigation"`
+	Contingency string `json:"contingency,omitempty" metadata:",optional"`
+}
+
+type Innovation struct {
+	Description    string   `json:"description"`
+	Benefits       []string `json:"benefits"`
+	Implementation string   `json:"implementation"`
+}
+
+type FinancialProposal struct {
+	BreakdownByPhase    []PhaseCosting    `json:"breakdownByPhase"`
+	BreakdownByCategory []CategoryCosting `json:"breakdownByCategory"`
+	PaymentSchedule     []PaymentRequest  `json:"paymentSchedule"`
+	CostAssumptions     []string          `json:"costAssumptions,omitempty" metadata:",optional"`
+	PriceValidity       int               `json:"priceValidity"` // days
+}
+
+type PhaseCosting struct {
+	Phase string  `json:"phase"`
+	Cost  float64 `json:"cost"`
+	Hours int     `json:"hours,omitempty" metadata:",optional"`
+}
+
+type CategoryCosting struct {
+	Category   string  `json:"category"` // LABOR, MATERIAL, EQUIPMENT, etc.
+	Cost       float64 `json:"cost"`
+	Percentage float64 `json:"percentage"`
+}
+
+type PaymentRequest struct {
+	Milestone    string   `json:"milestone"`
+	Percentage   float64  `json:"percentage"`
+	Amount       float64  `json:"amount"`
+	Deliverables []string `json:"deliverables"`
+}
+
+// Legacy types for backward compatibility
+type Tender struct {
+	ID           string `json:"id"`
+	Description  string `json:"description"`
+	OpenAt       string `json:"openAt"`
+	CloseAt      string `json:"closeAt"`
+	Criteria     string `json:"criteria"`
+	Status       string `json:"status"` // OPEN, CLOSED, AWARDED
+	AwardedBidID string `json:"awardedBidId,omitempty" metadata:",optional"`
+}
+
+type BidRef struct {
+	TenderID       string   `json:"tenderId"`
+	BidID          string   `json:"bidId"`
+	ContractorID   string   `json:"contractorId"`
+	BidHash        string   `json:"bidHash"`                                  // hash of the canonical private bid JSON
+	LotIDs         []string `json:"lotIds,omitempty" metadata:",optional"`    // lots covered by a multi-lot bid
+	Encrypted      bool     `json:"encrypted,omitempty" metadata:",optional"` // sealed bid; BidHash is the hash of the plaintext
+	CiphertextHash string   `json:"ciphertextHash,omitempty" metadata:",optional"`
+	OpenedAt       string   `json:"openedAt,omitempty" metadata:",optional"`
+	OpenError      string   `json:"openError,omitempty" metadata:",optional"`   // why a sealed bid could not be opened
+	SubmittedBy    string   `json:"submittedBy,omitempty" metadata:",optional"` // MSP ID of the submitting org; unset on reverse auction offers
+	DocType        string   `json:"docType,omitempty" metadata:",optional"`
+}
+
+type BidPrivate struct {
+	TenderID     string  `json:"tenderId"`
+	BidID        string  `json:"bidId"`
+	ContractorID string  `json:"contractorId"`
+	Amount       float64 `json:"amount"`
+	DocsHash     string  `json:"docsHash"`
+}
+
+// Evaluation holds an off-chain computed score recorded on-chain
+type Evaluation struct {
+	TenderID string  `json:"tenderId"`
+	BidID    string  `json:"bidId"`
+	Score    float64 `json:"score"`
+	Notes    string  `json:"notes,omitempty" metadata:",optional"`
+}
+
+// MilestoneRef is the public reference/metadata of a milestone submission
+type MilestoneRef struct {
+	TenderID        string `json:"tenderId"`
+	MilestoneID     string `json:"milestoneId"`
+	Title           string `json:"title"`
+	EvidenceHash    string `json:"evidenceHash"`
+	PayloadHash     string `json:"payloadHash,omitempty" metadata:",optional"` // hash of the canonical private milestone
+	Status          string `json:"status"`                                     // SUBMITTED, APPROVED, REJECTED
+	PaymentReleased bool   `json:"paymentReleased"`
+	SubmittedAt     string `json:"submittedAt,omitempty" metadata:",optional"`
+	SubmittedBy     string `json:"submittedBy,omitempty" metadata:",optional"` // MSP ID of the submitting org
+}
+
+// MilestonePrivate is the confidential payload
+type MilestonePrivate struct {
+	TenderID     string  `json:"tenderId"`
+	MilestoneID  string  `json:"milestoneId"`
+	Title        string  `json:"title"`
+	EvidenceHash string  `json:"evidenceHash"`
+	Amount       float64 `json:"amount"`
+	Details      string  `json:"details"`
+	PaidAmount   float64 `json:"paidAmount,omitempty" metadata:",optional"`
+	SubmittedAt  string  `json:"submittedAt,omitempty" metadata:",optional"`
+}
+
+// TenderValidationReport lists every problem found in an RFQ document by ValidateTender
+type TenderValidationReport struct {
+	TenderID string            `json:"tenderId,omitempty" metadata:",optional"`
+	Valid    bool              `json:"valid"`
+	Issues   []ValidationIssue `json:"issues,omitempty" metadata:",optional"`
+}
+
+// ValidationIssue is one problem in a document
+type ValidationIssue struct {
+	Field   string `json:"field"` // JSON path, e.g. deadlines.projectEndDate or evaluationCriteria.0.subCriteria
+	Message string `json:"message"`
+}
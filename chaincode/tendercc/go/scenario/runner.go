@@ -0,0 +1,369 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"tendercc/events"
+)
+
+// Call is one chaincode transaction of a scenario
+type Call struct {
+	Actor     string // key in Scenario.Actors
+	Identity  Actor
+	Contract  string
+	Function  string
+	Args      []string
+	Transient map[string][]byte
+	Submit    bool // order and commit; otherwise evaluate only
+}
+
+// Result is what a transaction returned and, when submitted, the events it committed
+type Result struct {
+	Payload []byte
+	Events  []events.Envelope
+}
+
+// Invoker executes calls against a ledger and controls its clock
+type Invoker interface {
+	Invoke(ctx context.Context, call *Call) (*Result, error)
+	// Now is the timestamp the next transaction will carry
+	Now() time.Time
+	// Advance moves the clock forward. Invokers that cannot control the clock may
+	// wait instead, or refuse.
+	Advance(ctx context.Context, d time.Duration) error
+}
+
+// Signer is implemented by invokers that hold the actors' enrolment keys. It signs
+// payload for the actor of call and returns the base64 signature, for $sign values.
+type Signer interface {
+	Sign(ctx context.Context, call *Call, payload []byte) (string, error)
+}
+
+// Step outcomes
+const (
+	StatusPassed  = "PASSED"
+	StatusFailed  = "FAILED"
+	StatusSkipped = "SKIPPED"
+)
+
+// StepReport is the outcome of one step
+type StepReport struct {
+	Index    int           `json:"index"`
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Events   []string      `json:"events,omitempty"`
+	Duration time.Duration `json:"durationNs"`
+}
+
+// Report is the outcome of a scenario. A run stops at the first failed step; the
+// remaining steps are reported as skipped.
+type Report struct {
+	Scenario string            `json:"scenario"`
+	Passed   bool              `json:"passed"`
+	Steps    []*StepReport     `json:"steps"`
+	Vars     map[string]string `json:"vars,omitempty"`
+}
+
+// Failed returns the failed step, or nil
+func (r *Report) Failed() *StepReport {
+	for _, s := range r.Steps {
+		if s.Status == StatusFailed {
+			return s
+		}
+	}
+	return nil
+}
+
+// Runner executes scenarios
+type Runner struct {
+	Invoker Invoker
+	// Log, when set, receives one line per step
+	Log io.Writer
+}
+
+// Run executes every step in order. The error is non-nil only if the scenario could
+// not be run at all; a failed expectation is reported in the Report.
+func (r *Runner) Run(ctx context.Context, sc *Scenario) (*Report, error) {
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+	e := &env{vars: make(map[string]string), now: r.Invoker.Now, dir: sc.Dir}
+	for k, v := range sc.Vars {
+		e.vars[k] = v
+	}
+	e.start = r.Invoker.Now()
+
+	report := &Report{Scenario: sc.Name, Passed: true}
+	for i := range sc.Steps {
+		st := &sc.Steps[i]
+		sr := &StepReport{Index: i + 1, Name: st.Label(i)}
+		report.Steps = append(report.Steps, sr)
+		if !report.Passed {
+			sr.Status = StatusSkipped
+			continue
+		}
+		begin := time.Now()
+		names, err := r.step(ctx, sc, st, e)
+		sr.Duration = time.Since(begin)
+		sr.Events = names
+		if err != nil {
+			sr.Status, sr.Error = StatusFailed, err.Error()
+			report.Passed = false
+		} else {
+			sr.Status = StatusPassed
+		}
+		if r.Log != nil {
+			line := fmt.Sprintf("%3d %-7s %s", sr.Index, sr.Status, sr.Name)
+			if sr.Error != "" {
+				line += ": " + sr.Error
+			}
+			fmt.Fprintln(r.Log, line)
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+	}
+	report.Vars = e.vars
+	return report, nil
+}
+
+// step runs one step and checks its expectations, returning the emitted event names
+func (r *Runner) step(ctx context.Context, sc *Scenario, st *Step, e *env) ([]string, error) {
+	switch {
+	case st.Advance != "":
+		d, err := ParseDuration(st.Advance)
+		if err != nil {
+			return nil, err
+		}
+		return nil, r.Invoker.Advance(ctx, d)
+	case st.At != "":
+		at, err := e.expandString(st.At)
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %s: %v", at, err)
+		}
+		d := t.Sub(r.Invoker.Now())
+		if d < 0 {
+			return nil, fmt.Errorf("clock is already past %s", at)
+		}
+		return nil, r.Invoker.Advance(ctx, d)
+	}
+
+	call := &Call{Actor: st.Actor, Identity: sc.Actors[st.Actor], Submit: st.Submit != ""}
+	if call.Identity.Name == "" {
+		call.Identity.Name = st.Actor
+	}
+	name := st.Submit
+	if name == "" {
+		name = st.Evaluate
+	}
+	call.Contract, call.Function = SplitFunction(name)
+	e.sign = nil
+	if signer, ok := r.Invoker.(Signer); ok {
+		e.sign = func(payload []byte) (string, error) { return signer.Sign(ctx, call, payload) }
+	}
+	for i := range st.Args {
+		v, err := e.value(&st.Args[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %v", i+1, err)
+		}
+		arg, err := encode(v)
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+	}
+	if len(st.Transient) > 0 {
+		call.Transient = make(map[string][]byte, len(st.Transient))
+		for key, node := range st.Transient {
+			node := node
+			v, err := e.value(&node)
+			if err != nil {
+				return nil, fmt.Errorf("transient %s: %v", key, err)
+			}
+			data, err := encode(v)
+			if err != nil {
+				return nil, err
+			}
+			call.Transient[key] = []byte(data)
+		}
+	}
+
+	result, err := r.Invoker.Invoke(ctx, call)
+	if st.Expect.Error != nil {
+		want, xerr := e.expandString(*st.Expect.Error)
+		if xerr != nil {
+			return nil, xerr
+		}
+		if err == nil {
+			return nil, fmt.Errorf("expected error containing %q, but the transaction succeeded", want)
+		}
+		if !strings.Contains(err.Error(), want) {
+			return nil, fmt.Errorf("expected error containing %q, got: %v", want, err)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, ev := range result.Events {
+		names = append(names, ev.Name)
+	}
+	var doc interface{}
+	if len(result.Payload) > 0 {
+		if err := json.Unmarshal(result.Payload, &doc); err != nil {
+			// Plain string results, e.g. a payload to sign
+			doc = string(result.Payload)
+		}
+	}
+	if st.Expect.Result.Kind != 0 {
+		want, err := e.value(&st.Expect.Result)
+		if err != nil {
+			return names, err
+		}
+		if err := match(normalize(want), doc, "result"); err != nil {
+			return names, err
+		}
+	}
+	if st.Expect.Events != nil {
+		if err := matchEvents(e, st.Expect.Events, result.Events); err != nil {
+			return names, err
+		}
+	}
+	for name, path := range st.Save {
+		v, ok := getPath(doc, path)
+		if !ok {
+			return names, fmt.Errorf("save %s: result has no %s", name, path)
+		}
+		s, err := encode(v)
+		if err != nil {
+			return names, err
+		}
+		e.vars[name] = s
+	}
+	return names, nil
+}
+
+func matchEvents(e *env, want []EventExpect, got []events.Envelope) error {
+	if len(want) != len(got) {
+		return fmt.Errorf("expected events %s, got %s", eventNames(want), envelopeNames(got))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Name != w.Name {
+			return fmt.Errorf("expected events %s, got %s", eventNames(want), envelopeNames(got))
+		}
+		if w.TenderID != "" {
+			id, err := e.expandString(w.TenderID)
+			if err != nil {
+				return err
+			}
+			if g.TenderID != id {
+				return fmt.Errorf("event %s: tender %s, want %s", g.Name, g.TenderID, id)
+			}
+		}
+		if w.Payload.Kind != 0 {
+			wantPayload, err := e.value(&w.Payload)
+			if err != nil {
+				return err
+			}
+			var payload interface{}
+			if err := json.Unmarshal(g.Payload, &payload); err != nil {
+				return fmt.Errorf("event %s: %v", g.Name, err)
+			}
+			if err := match(normalize(wantPayload), payload, g.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func eventNames(want []EventExpect) string {
+	names := make([]string, len(want))
+	for i, w := range want {
+		names[i] = w.Name
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+func envelopeNames(got []events.Envelope) string {
+	names := make([]string, len(got))
+	for i, g := range got {
+		names[i] = g.Name
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// normalize passes YAML-decoded data through JSON so numbers compare as float64
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if json.Unmarshal(data, &out) != nil {
+		return v
+	}
+	return out
+}
+
+// match checks that got contains want: objects match key by key, arrays element by
+// element with equal length, and scalars by value
+func match(want, got interface{}, path string) error {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %s", path, show(got))
+		}
+		for k, wv := range w {
+			gv, ok := g[k]
+			if !ok {
+				return fmt.Errorf("%s.%s: missing", path, k)
+			}
+			if err := match(wv, gv, path+"."+k); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %s", path, show(got))
+		}
+		if len(g) != len(w) {
+			return fmt.Errorf("%s: expected %d elements, got %d", path, len(w), len(g))
+		}
+		for i := range w {
+			if err := match(w[i], g[i], fmt.Sprintf("%s.%d", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !reflect.DeepEqual(want, got) {
+		return fmt.Errorf("%s: expected %s, got %s", path, show(want), show(got))
+	}
+	return nil
+}
+
+func show(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
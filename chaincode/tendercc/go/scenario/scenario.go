@@ -0,0 +1,247 @@
+// Package scenario describes whole procurement flows in YAML and runs them against
+// tendercc, either in process on the mock ledger or through a Fabric Gateway.
+//
+// A scenario names its actors, then lists steps. A step submits or evaluates one
+// chaincode transaction as an actor, or moves the clock:
+//
+//	name: civil-flow
+//	start: 2030-03-04T09:00:00Z
+//	actors:
+//	  buyer: {msp: Org1MSP}
+//	  techcorp: {msp: Org2MSP, name: TECHCORP-SOLUTIONS}
+//	vars:
+//	  tender: RFQ-CIVIL-001
+//	steps:
+//	  - name: create tender
+//	    actor: buyer
+//	    submit: EnhancedSmartContract:CreateEnhancedTender
+//	    args:
+//	      - $file: ../samples/rfq/construction-rfq-sample.json
+//	        $set: {id: "${tender}", deadlines.bidSubmissionDeadline: "${now+14d}"}
+//	    expect:
+//	      events: [EnhancedRFQCreated]
+//	  - advance: 15d
+//	  - actor: buyer
+//	    evaluate: EnhancedSmartContract:GetEnhancedTender
+//	    args: ["${tender}"]
+//	    expect:
+//	      result: {status: CLOSED}
+//
+// Arguments and transient values that are not strings are sent as JSON. A mapping
+// with a $file key loads a JSON file relative to the scenario and applies the dotted
+// paths in $set. Strings may reference ${var}, ${start} and ${now}, optionally with an
+// offset such as ${now+14d} or ${start-1h}. A mapping with a $sign key becomes the
+// detached signature ({"signature": ...}) of the step's actor over the canonical JSON
+// of its value, for invokers that implement Signer; a YAML anchor lets a transient
+// document and its signature share one definition.
+//
+// expect.error makes a step pass only if it fails with that text. expect.result is
+// matched as a subset of the JSON result, and expect.events lists the events the
+// transaction must emit, in order. save copies dotted paths of the result into
+// variables for later steps.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultContract is used when a step names a function without a contract
+const DefaultContract = "SmartContract"
+
+// Scenario is one procurement flow
+type Scenario struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Start       string            `yaml:"start,omitempty"` // RFC3339 clock at the first step on the mock ledger
+	Actors      map[string]Actor  `yaml:"actors"`
+	Vars        map[string]string `yaml:"vars,omitempty"`
+	Steps       []Step            `yaml:"steps"`
+
+	// Dir resolves $file paths; Load sets it to the scenario's directory
+	Dir string `yaml:"-"`
+}
+
+// Actor is an identity that submits transactions
+type Actor struct {
+	MSP   string            `yaml:"msp"`
+	Name  string            `yaml:"name,omitempty"` // certificate common name; defaults to the actor key
+	Attrs map[string]string `yaml:"attrs,omitempty"`
+}
+
+// Step is a transaction or a clock change
+type Step struct {
+	Name      string               `yaml:"name,omitempty"`
+	Actor     string               `yaml:"actor,omitempty"`
+	Submit    string               `yaml:"submit,omitempty"`   // Contract:Function ordered and committed
+	Evaluate  string               `yaml:"evaluate,omitempty"` // Contract:Function run as a query
+	Args      []yaml.Node          `yaml:"args,omitempty"`
+	Transient map[string]yaml.Node `yaml:"transient,omitempty"`
+	Advance   string               `yaml:"advance,omitempty"` // duration such as 8d, 36h or 1d12h
+	At        string               `yaml:"at,omitempty"`      // move the clock forward to a time
+	Expect    Expect               `yaml:"expect,omitempty"`
+	Save      map[string]string    `yaml:"save,omitempty"` // variable -> dotted path into the result
+}
+
+// Expect is the outcome a step must have
+type Expect struct {
+	Error  *string       `yaml:"error,omitempty"`
+	Result yaml.Node     `yaml:"result,omitempty"`
+	Events []EventExpect `yaml:"events,omitempty"`
+}
+
+// EventExpect is an event the transaction must emit. In YAML it is either the event
+// name or a mapping that also matches the tender ID and a subset of the payload.
+type EventExpect struct {
+	Name     string    `yaml:"name"`
+	TenderID string    `yaml:"tenderId,omitempty"`
+	Payload  yaml.Node `yaml:"payload,omitempty"`
+}
+
+func (e *EventExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		e.Name = node.Value
+		return nil
+	}
+	type plain EventExpect
+	return node.Decode((*plain)(e))
+}
+
+// Load reads a scenario file and checks its structure
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	sc.Dir = filepath.Dir(path)
+	return sc, nil
+}
+
+// Parse decodes and checks a scenario; unknown keys are rejected
+func Parse(data []byte) (*Scenario, error) {
+	var sc Scenario
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&sc); err != nil {
+		return nil, err
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// Validate checks that every step does one thing and names a known actor
+func (s *Scenario) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("scenario name is required")
+	}
+	if _, err := s.StartTime(); err != nil {
+		return err
+	}
+	for name, a := range s.Actors {
+		if a.MSP == "" {
+			return fmt.Errorf("actor %s has no msp", name)
+		}
+	}
+	for i, st := range s.Steps {
+		kinds := 0
+		for _, set := range []bool{st.Submit != "", st.Evaluate != "", st.Advance != "", st.At != ""} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return fmt.Errorf("step %d (%s) must have exactly one of submit, evaluate, advance or at", i+1, st.Name)
+		}
+		if st.Advance != "" {
+			if _, err := ParseDuration(st.Advance); err != nil {
+				return fmt.Errorf("step %d: %v", i+1, err)
+			}
+		}
+		if st.Submit == "" && st.Evaluate == "" {
+			continue
+		}
+		if _, ok := s.Actors[st.Actor]; !ok {
+			return fmt.Errorf("step %d (%s) uses unknown actor %q", i+1, st.Name, st.Actor)
+		}
+		if st.Evaluate != "" && len(st.Expect.Events) > 0 {
+			return fmt.Errorf("step %d (%s) expects events from a query", i+1, st.Name)
+		}
+	}
+	return nil
+}
+
+// StartTime is the clock at the first step, or the zero time when the scenario
+// runs on the invoker's own clock
+func (s *Scenario) StartTime() (time.Time, error) {
+	if s.Start == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s.Start)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start %s: %v", s.Start, err)
+	}
+	return t, nil
+}
+
+// Label names a step in reports
+func (st *Step) Label(index int) string {
+	if st.Name != "" {
+		return st.Name
+	}
+	switch {
+	case st.Submit != "":
+		return st.Submit
+	case st.Evaluate != "":
+		return st.Evaluate
+	case st.Advance != "":
+		return "advance " + st.Advance
+	case st.At != "":
+		return "at " + st.At
+	}
+	return fmt.Sprintf("step %d", index+1)
+}
+
+// SplitFunction splits Contract:Function, defaulting to DefaultContract
+func SplitFunction(name string) (contract, function string) {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return DefaultContract, name
+}
+
+var dayPrefix = regexp.MustCompile(`^(\d+)d`)
+
+// ParseDuration accepts time.ParseDuration syntax plus a leading day count, e.g. 1d12h
+func ParseDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	rest := strings.TrimSpace(s)
+	if m := dayPrefix.FindStringSubmatch(rest); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		d = time.Duration(days) * 24 * time.Hour
+		rest = rest[len(m[0]):]
+	}
+	if rest == "" {
+		if d == 0 && strings.TrimSpace(s) == "" {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return d, nil
+	}
+	more, err := time.ParseDuration(rest)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d + more, nil
+}
@@ -0,0 +1,323 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tendercc/events"
+)
+
+var t0 = time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+
+// fakeInvoker answers calls from a table keyed by function name
+type fakeInvoker struct {
+	now   time.Time
+	calls []*Call
+	reply func(call *Call) (*Result, error)
+}
+
+func (f *fakeInvoker) Now() time.Time { return f.now }
+
+func (f *fakeInvoker) Advance(_ context.Context, d time.Duration) error {
+	f.now = f.now.Add(d)
+	return nil
+}
+
+func (f *fakeInvoker) Invoke(_ context.Context, call *Call) (*Result, error) {
+	f.calls = append(f.calls, call)
+	return f.reply(call)
+}
+
+func envelope(name, tenderID string, payload interface{}) events.Envelope {
+	data, _ := json.Marshal(payload)
+	return events.Envelope{Name: name, TenderID: tenderID, Payload: data}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"14d", 14 * 24 * time.Hour, false},
+		{"1d12h", 36 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"1s", time.Second, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"two days", 0, true},
+	}
+	for _, tc := range tests {
+		got, err := ParseDuration(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ParseDuration(%q) = %v, %v", tc.in, got, err)
+		}
+	}
+}
+
+func TestParseRejectsMalformedScenarios(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"unknown key", "name: x\nsteps:\n  - advance: 1d\n    wait: 2d\n", "field wait not found"},
+		{"no name", "steps: []\n", "scenario name is required"},
+		{"two actions", "name: x\nsteps:\n  - advance: 1d\n    at: ${start}\n", "exactly one of submit, evaluate, advance or at"},
+		{"no action", "name: x\nsteps:\n  - name: idle\n", "exactly one of"},
+		{"unknown actor", "name: x\nsteps:\n  - submit: CreateTender\n    actor: ghost\n", `unknown actor "ghost"`},
+		{"actor without msp", "name: x\nactors:\n  buyer: {name: b}\n", "actor buyer has no msp"},
+		{"bad duration", "name: x\nsteps:\n  - advance: soon\n", `invalid duration "soon"`},
+		{"bad start", "name: x\nstart: monday\n", "invalid start monday"},
+		{"query events", "name: x\nactors: {a: {msp: M}}\nsteps:\n  - evaluate: GetTender\n    actor: a\n    expect: {events: [X]}\n", "expects events from a query"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.yaml))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestArgumentsAndTransient(t *testing.T) {
+	dir := t.TempDir()
+	sample := `{"id":"RFQ-1","deadlines":{"milestoneDeadlines":[{"name":"M1","deadline":"x"}]},"budget":{"estimatedMax":1}}`
+	if err := os.WriteFile(filepath.Join(dir, "rfq.json"), []byte(sample), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := Parse([]byte(`
+name: args
+actors: {buyer: {msp: Org1MSP}}
+vars: {tender: T1}
+steps:
+  - actor: buyer
+    submit: EnhancedSmartContract:CreateEnhancedTender
+    args:
+      - $file: rfq.json
+        $set:
+          id: ${tender}
+          deadlines.bidSubmissionDeadline: ${start+14d}
+          deadlines.milestoneDeadlines.0.deadline: ${now-1h}
+          budget.estimatedMax: 900000
+          ownerDetails.address.country: KE
+      - 42
+      - plain ${tender}
+    transient:
+      bid: {tenderId: "${tender}", amounts: [1, 2.5]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Dir = dir
+	inv := &fakeInvoker{now: t0, reply: func(*Call) (*Result, error) { return &Result{}, nil }}
+	report, err := (&Runner{Invoker: inv}).Run(context.Background(), sc)
+	if err != nil || !report.Passed {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+
+	call := inv.calls[0]
+	if call.Contract != "EnhancedSmartContract" || call.Function != "CreateEnhancedTender" || !call.Submit || call.Identity.Name != "buyer" {
+		t.Fatalf("call = %+v", call)
+	}
+	var rfq map[string]interface{}
+	if err := json.Unmarshal([]byte(call.Args[0]), &rfq); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"budget":{"estimatedMax":900000},"deadlines":{"bidSubmissionDeadline":"2030-03-18T09:00:00Z","milestoneDeadlines":[{"deadline":"2030-03-04T08:00:00Z","name":"M1"}]},"id":"T1","ownerDetails":{"address":{"country":"KE"}}}`
+	if got, _ := json.Marshal(rfq); string(got) != want {
+		t.Fatalf("rfq = %s", got)
+	}
+	if call.Args[1] != "42" || call.Args[2] != "plain T1" {
+		t.Fatalf("args = %q", call.Args[1:])
+	}
+	if got := string(call.Transient["bid"]); got != `{"amounts":[1,2.5],"tenderId":"T1"}` {
+		t.Fatalf("transient = %s", got)
+	}
+}
+
+// signingInvoker also signs, tagging each signature with the actor and the signed bytes
+type signingInvoker struct{ fakeInvoker }
+
+func (s *signingInvoker) Sign(_ context.Context, call *Call, payload []byte) (string, error) {
+	return call.Identity.Name + ":" + string(payload), nil
+}
+
+func TestSignedValues(t *testing.T) {
+	sc, err := Parse([]byte(`
+name: signed
+actors: {techcorp: {msp: Org2MSP, name: TECHCORP}}
+vars: {tender: T1}
+steps:
+  - actor: techcorp
+    submit: SubmitEnhancedBid
+    transient:
+      bid: &bid {tenderId: "${tender}", amount: 1.50, note: "a&b"}
+      signature: {$sign: *bid}
+  - actor: techcorp
+    submit: ApproveMilestone
+    transient:
+      signature: {$sign: '{"b": 1, "a": "${tender}"}'}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := &signingInvoker{fakeInvoker{now: t0, reply: func(*Call) (*Result, error) { return &Result{}, nil }}}
+	report, err := (&Runner{Invoker: inv}).Run(context.Background(), sc)
+	if err != nil || !report.Passed {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	// Signatures cover the canonical form, not the bytes sent
+	wants := []string{
+		`TECHCORP:{"amount":1.5,"note":"a&b","tenderId":"T1"}`,
+		`TECHCORP:{"a":"T1","b":1}`,
+	}
+	for i, want := range wants {
+		var sig struct{ Signature string }
+		if err := json.Unmarshal(inv.calls[i].Transient["signature"], &sig); err != nil {
+			t.Fatal(err)
+		}
+		if sig.Signature != want {
+			t.Fatalf("signature %d = %s, want %s", i, sig.Signature, want)
+		}
+	}
+
+	// Invokers without keys cannot sign
+	plain := &fakeInvoker{now: t0, reply: func(*Call) (*Result, error) { return &Result{}, nil }}
+	report, err = (&Runner{Invoker: plain}).Run(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed := report.Failed(); failed == nil || !strings.Contains(failed.Error, "$sign is not supported by this invoker") {
+		t.Fatalf("failure = %+v", failed)
+	}
+}
+
+func TestExpectations(t *testing.T) {
+	tender := map[string]interface{}{"id": "T1", "status": "AWARDED", "awardedBidId": "B2", "bids": []interface{}{"B1", "B2"}, "budget": map[string]interface{}{"max": 900000}}
+	reply := func(call *Call) (*Result, error) {
+		switch call.Function {
+		case "Fail":
+			return nil, fmt.Errorf("tender T1 not found")
+		case "Award":
+			return &Result{Events: []events.Envelope{envelope("TenderAwarded", "T1", map[string]string{"bidId": "B2"})}}, nil
+		case "Payload":
+			return &Result{Payload: []byte(`{"action":"AWARD"}` + "\n")}, nil
+		}
+		data, _ := json.Marshal(tender)
+		return &Result{Payload: data}, nil
+	}
+
+	tests := []struct {
+		name    string
+		step    string
+		wantErr string
+	}{
+		{"error matches", "submit: Fail\n  expect: {error: not found}", ""},
+		{"error differs", "submit: Fail\n  expect: {error: closed}", `expected error containing "closed", got: tender T1 not found`},
+		{"unexpected success", "submit: Award\n  expect: {error: closed}", "but the transaction succeeded"},
+		{"unexpected failure", "submit: Fail", "tender T1 not found"},
+		{"result subset", "evaluate: Get\n  expect: {result: {status: AWARDED, budget: {max: 900000}}}", ""},
+		{"result variable", "evaluate: Get\n  expect: {result: {id: '${tender}'}}", ""},
+		{"result mismatch", "evaluate: Get\n  expect: {result: {status: OPEN}}", `result.status: expected "OPEN", got "AWARDED"`},
+		{"result missing key", "evaluate: Get\n  expect: {result: {closedAt: x}}", "result.closedAt: missing"},
+		{"array length", "evaluate: Get\n  expect: {result: {bids: [B1]}}", "result.bids: expected 1 elements, got 2"},
+		{"plain result", "evaluate: Payload\n  expect: {result: {action: AWARD}}", ""},
+		{"event names", "submit: Award\n  expect: {events: [TenderAwarded]}", ""},
+		{"event detail", "submit: Award\n  expect: {events: [{name: TenderAwarded, tenderId: T1, payload: {bidId: B2}}]}", ""},
+		{"event payload mismatch", "submit: Award\n  expect: {events: [{name: TenderAwarded, payload: {bidId: B1}}]}", `TenderAwarded.bidId: expected "B1", got "B2"`},
+		{"event tender mismatch", "submit: Award\n  expect: {events: [{name: TenderAwarded, tenderId: T2}]}", "event TenderAwarded: tender T1, want T2"},
+		{"missing event", "submit: Award\n  expect: {events: [TenderAwarded, PaymentReleased]}", "expected events [TenderAwarded, PaymentReleased], got [TenderAwarded]"},
+		{"no events expected", "submit: Award\n  expect: {events: []}", "expected events [], got [TenderAwarded]"},
+		{"undefined variable", "evaluate: Get\n  args: ['${nope}']", "undefined variable nope"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sc, err := Parse([]byte("name: x\nactors: {a: {msp: M}}\nvars: {tender: T1}\nsteps:\n- actor: a\n  " + tc.step + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := (&Runner{Invoker: &fakeInvoker{now: t0, reply: reply}}).Run(context.Background(), sc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			failed := report.Failed()
+			switch {
+			case tc.wantErr == "" && failed != nil:
+				t.Fatalf("step failed: %s", failed.Error)
+			case tc.wantErr != "" && (failed == nil || !strings.Contains(failed.Error, tc.wantErr)):
+				t.Fatalf("failure = %+v, want %q", failed, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunClockSaveAndSkip(t *testing.T) {
+	sc, err := Parse([]byte(`
+name: flow
+actors: {buyer: {msp: Org1MSP, name: officer}}
+steps:
+  - advance: 1d12h
+  - at: ${start+2d}
+  - actor: buyer
+    evaluate: GetEnhancedTender
+    args: [T1]
+    save: {winner: awardedBidId, deadline: deadlines.bidSubmissionDeadline, amount: budget.max}
+  - actor: buyer
+    submit: EnhancedSmartContract:AwardTender
+    args: [T1, "${winner}"]
+  - at: ${start+1d}
+  - advance: 1d
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := &fakeInvoker{now: t0, reply: func(call *Call) (*Result, error) {
+		if call.Function == "GetEnhancedTender" {
+			return &Result{Payload: []byte(`{"awardedBidId":"B2","deadlines":{"bidSubmissionDeadline":"2030-03-18T09:00:00Z"},"budget":{"max":900000}}`)}, nil
+		}
+		return &Result{}, nil
+	}}
+	var log strings.Builder
+	report, err := (&Runner{Invoker: inv, Log: &log}).Run(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inv.now.Equal(t0.Add(48 * time.Hour)) {
+		t.Fatalf("clock = %s", inv.now)
+	}
+	if call := inv.calls[1]; call.Contract != "EnhancedSmartContract" || call.Args[1] != "B2" {
+		t.Fatalf("award call = %+v", call)
+	}
+	if inv.calls[0].Contract != DefaultContract || inv.calls[0].Submit || inv.calls[0].Identity.Name != "officer" {
+		t.Fatalf("query call = %+v", inv.calls[0])
+	}
+	if report.Vars["deadline"] != "2030-03-18T09:00:00Z" || report.Vars["amount"] != "900000" {
+		t.Fatalf("vars = %v", report.Vars)
+	}
+
+	// Moving the clock back fails the step and skips the rest
+	if report.Passed {
+		t.Fatal("scenario passed although the clock cannot go back")
+	}
+	statuses := make([]string, len(report.Steps))
+	for i, s := range report.Steps {
+		statuses[i] = s.Status
+	}
+	if got := strings.Join(statuses, ","); got != "PASSED,PASSED,PASSED,PASSED,FAILED,SKIPPED" {
+		t.Fatalf("statuses = %s", got)
+	}
+	if failed := report.Failed(); failed.Name != "at ${start+1d}" || failed.Error != "clock is already past 2030-03-05T09:00:00Z" {
+		t.Fatalf("failed = %+v", failed)
+	}
+	if !strings.Contains(log.String(), "  5 FAILED  at ${start+1d}: clock is already past") {
+		t.Fatalf("log = %s", log.String())
+	}
+}
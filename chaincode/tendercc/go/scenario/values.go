@@ -0,0 +1,242 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"tendercc/canonical"
+)
+
+// env resolves ${...} references while a scenario runs
+type env struct {
+	vars  map[string]string
+	start time.Time
+	now   func() time.Time
+	dir   string
+	sign  func(payload []byte) (string, error) // the current step's actor; nil if the invoker cannot sign
+}
+
+var reference = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// expandString replaces every ${...} reference in s
+func (e *env) expandString(s string) (string, error) {
+	var firstErr error
+	out := reference.ReplaceAllStringFunc(s, func(m string) string {
+		v, err := e.lookup(m[2 : len(m)-1])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return v
+	})
+	return out, firstErr
+}
+
+func (e *env) lookup(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	for _, base := range []string{"now", "start"} {
+		if !strings.HasPrefix(ref, base) {
+			continue
+		}
+		offset := strings.TrimSpace(ref[len(base):])
+		if offset != "" && offset[0] != '+' && offset[0] != '-' {
+			continue
+		}
+		t := e.start
+		if base == "now" {
+			t = e.now()
+		}
+		if offset != "" {
+			d, err := ParseDuration(offset[1:])
+			if err != nil {
+				return "", err
+			}
+			if offset[0] == '-' {
+				d = -d
+			}
+			t = t.Add(d)
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	v, ok := e.vars[ref]
+	if !ok {
+		return "", fmt.Errorf("undefined variable %s", ref)
+	}
+	return v, nil
+}
+
+// value decodes a YAML node into plain JSON-compatible data with references expanded
+func (e *env) value(node *yaml.Node) (interface{}, error) {
+	var raw interface{}
+	if err := node.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return e.expand(raw)
+}
+
+func (e *env) expand(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case string:
+		return e.expandString(x)
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i, item := range x {
+			var err error
+			if out[i], err = e.expand(item); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case map[string]interface{}:
+		if file, ok := x["$file"]; ok {
+			return e.loadFile(file, x["$set"])
+		}
+		if payload, ok := x["$sign"]; ok {
+			return e.signature(payload)
+		}
+		out := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			var err error
+			if out[k], err = e.expand(item); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// loadFile reads a JSON document and applies the $set overrides to it
+func (e *env) loadFile(file, set interface{}) (interface{}, error) {
+	name, ok := file.(string)
+	if !ok {
+		return nil, fmt.Errorf("$file must be a path")
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(e.dir, name)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if set == nil {
+		return doc, nil
+	}
+	overrides, ok := set.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("$set must be a mapping of paths to values")
+	}
+	for path, raw := range overrides {
+		v, err := e.expand(raw)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = setPath(doc, strings.Split(path, "."), v); err != nil {
+			return nil, fmt.Errorf("$set %s: %v", path, err)
+		}
+	}
+	return doc, nil
+}
+
+// signature returns a detached signature by the step's actor over the canonical
+// JSON of payload, the form the chaincode verifies
+func (e *env) signature(payload interface{}) (interface{}, error) {
+	if e.sign == nil {
+		return nil, fmt.Errorf("$sign is not supported by this invoker")
+	}
+	v, err := e.expand(payload)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encode(v)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := canonical.JSON([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("$sign: %v", err)
+	}
+	sig, err := e.sign(doc)
+	if err != nil {
+		return nil, fmt.Errorf("$sign: %v", err)
+	}
+	return map[string]interface{}{"signature": sig}, nil
+}
+
+// setPath sets a dotted path, creating objects on the way. Numeric segments index arrays.
+func setPath(doc interface{}, path []string, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	switch x := doc.(type) {
+	case []interface{}:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(x) {
+			return nil, fmt.Errorf("index %s out of range", path[0])
+		}
+		if x[i], err = setPath(x[i], path[1:], v); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case map[string]interface{}:
+		child, err := setPath(x[path[0]], path[1:], v)
+		if err != nil {
+			return nil, err
+		}
+		x[path[0]] = child
+		return x, nil
+	case nil:
+		child, err := setPath(nil, path[1:], v)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{path[0]: child}, nil
+	}
+	return nil, fmt.Errorf("cannot set %s inside a %T", path[0], doc)
+}
+
+// getPath reads a dotted path from decoded JSON
+func getPath(doc interface{}, path string) (interface{}, bool) {
+	if path == "" || path == "." {
+		return doc, true
+	}
+	for _, seg := range strings.Split(path, ".") {
+		switch x := doc.(type) {
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, false
+			}
+			doc = x[i]
+		case map[string]interface{}:
+			v, ok := x[seg]
+			if !ok {
+				return nil, false
+			}
+			doc = v
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// encode renders an argument or transient value: strings as they are, anything else as JSON
+func encode(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
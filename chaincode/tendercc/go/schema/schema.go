@@ -0,0 +1,243 @@
+// Package schema derives JSON Schemas (draft-07) from the model types that tendercc
+// accepts as JSON documents, and checks documents against them.
+//
+// The schemas follow encoding/json: properties are named by their json tags, and a
+// property may be omitted, leaving the Go zero value for the chaincode's own checks.
+// Unlike encoding/json, they reject properties the type does not have, so a
+// misspelled field fails instead of being dropped. A string field tagged
+// enum:"A,B" only takes one of the listed values, or the empty string for "not set".
+//
+// The chaincode enforces the schemas and serves them from GetSchemas, so clients can
+// validate a document before submitting it.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"tendercc/model"
+)
+
+// Names of the documents with a schema
+const (
+	EnhancedTender     = "EnhancedTender"
+	EnhancedBidPrivate = "EnhancedBidPrivate"
+	MilestonePrivate   = "MilestonePrivate"
+)
+
+var documents = map[string]reflect.Type{
+	EnhancedTender:     reflect.TypeOf(model.EnhancedTender{}),
+	EnhancedBidPrivate: reflect.TypeOf(model.EnhancedBidPrivate{}),
+	MilestonePrivate:   reflect.TypeOf(model.MilestonePrivate{}),
+}
+
+// Names returns the documents with a schema, sorted
+func Names() []string {
+	names := make([]string, 0, len(documents))
+	for name := range documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the JSON Schema of a document
+func Get(name string) (map[string]interface{}, error) {
+	t, ok := documents[name]
+	if !ok {
+		return nil, fmt.Errorf("no schema for %s", name)
+	}
+	s, err := generate(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = name
+	return s, nil
+}
+
+// All returns the schema of every document, keyed by name
+func All() (map[string]interface{}, error) {
+	all := make(map[string]interface{}, len(documents))
+	for name := range documents {
+		s, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		all[name] = s
+	}
+	return all, nil
+}
+
+// Error lists every way a document breaks its schema
+type Error struct {
+	Document string
+	Issues   []string // sorted, so every peer reports the same text
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s schema validation failed: %s", e.Document, strings.Join(e.Issues, "; "))
+}
+
+var (
+	compileOnce sync.Once
+	compiled    map[string]*gojsonschema.Schema
+	compileErr  error
+)
+
+func compile() {
+	compiled = make(map[string]*gojsonschema.Schema, len(documents))
+	for name := range documents {
+		s, err := Get(name)
+		if err != nil {
+			compileErr = err
+			return
+		}
+		if compiled[name], err = gojsonschema.NewSchema(gojsonschema.NewGoLoader(s)); err != nil {
+			compileErr = fmt.Errorf("schema %s: %v", name, err)
+			return
+		}
+	}
+}
+
+// Validate checks a JSON document against the named schema. It returns an *Error
+// listing every violation, or an error if data is not JSON.
+func Validate(name string, data []byte) error {
+	compileOnce.Do(compile)
+	if compileErr != nil {
+		return compileErr
+	}
+	s, ok := compiled[name]
+	if !ok {
+		return fmt.Errorf("no schema for %s", name)
+	}
+	result, err := s.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return fmt.Errorf("invalid %s JSON: %v", name, err)
+	}
+	if result.Valid() {
+		return nil
+	}
+	issues := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		issues = append(issues, describe(e))
+	}
+	sort.Strings(issues)
+	return &Error{Document: name, Issues: issues}
+}
+
+// describe words a violation as "path: problem"
+func describe(e gojsonschema.ResultError) string {
+	field := e.Field()
+	d := e.Details()
+	switch e.Type() {
+	case "additional_property_not_allowed":
+		if field == "(root)" {
+			return fmt.Sprintf("%v: unknown field", d["property"])
+		}
+		return fmt.Sprintf("%s.%v: unknown field", field, d["property"])
+	case "enum":
+		allowed := strings.TrimPrefix(fmt.Sprint(d["allowed"]), `"", `)
+		return fmt.Sprintf("%s: %q is not one of %s", field, fmt.Sprint(e.Value()), allowed)
+	case "invalid_type":
+		return fmt.Sprintf("%s: expected %v, got %v", field, d["expected"], d["given"])
+	}
+	return fmt.Sprintf("%s: %s", field, e.Description())
+}
+
+// generate builds the schema of t. Structs are inlined rather than referenced so
+// that optional (pointer) structs can also be null; seen guards against recursion.
+func generate(t reflect.Type, seen []reflect.Type) (map[string]interface{}, error) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s, err := generate(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := generate(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map key of %s is not a string", t)
+		}
+		values, err := generate(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": values}, nil
+	case reflect.Struct:
+		return generateStruct(t, seen)
+	}
+	return nil, fmt.Errorf("type %s has no JSON schema", t)
+}
+
+func generateStruct(t reflect.Type, seen []reflect.Type) (map[string]interface{}, error) {
+	for _, s := range seen {
+		if s == t {
+			return nil, fmt.Errorf("type %s is recursive", t)
+		}
+	}
+	seen = append(seen, t)
+	props := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous {
+			return nil, fmt.Errorf("embedded field %s.%s is not supported", t, f.Name)
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s, err := generate(f.Type, seen)
+		if err != nil {
+			return nil, err
+		}
+		if values := f.Tag.Get("enum"); values != "" {
+			if f.Type.Kind() != reflect.String {
+				return nil, fmt.Errorf("enum on %s.%s, which is not a string", t, f.Name)
+			}
+			s["enum"] = append([]string{""}, strings.Split(values, ",")...)
+		}
+		props[name] = s
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}, nil
+}
+
+// nullable also admits null, which encoding/json writes for a nil pointer. Slices
+// and maps already admit it.
+func nullable(s map[string]interface{}) map[string]interface{} {
+	if typ, ok := s["type"].(string); ok {
+		s["type"] = []string{typ, "null"}
+	}
+	return s
+}
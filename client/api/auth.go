@@ -0,0 +1,49 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"tenderclient"
+)
+
+// Authenticator maps a request to the wallet identity it acts as
+type Authenticator interface {
+	Authenticate(r *http.Request) (identity string, err error)
+}
+
+// TokenAuth authenticates bearer tokens. Each token maps to a wallet identity label.
+type TokenAuth map[string]string
+
+// Authenticate reads the Authorization: Bearer header
+func (a TokenAuth) Authenticate(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return "", errUnauthenticated("missing bearer token")
+	}
+	identity := ""
+	for candidate, label := range a {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			identity = label
+		}
+	}
+	if identity == "" {
+		return "", errUnauthenticated("unknown bearer token")
+	}
+	return identity, nil
+}
+
+// Clients returns the tendercc client that acts as a wallet identity
+type Clients interface {
+	Client(identity string) (*tenderclient.Client, error)
+}
+
+// ClientsFunc adapts a function to Clients
+type ClientsFunc func(identity string) (*tenderclient.Client, error)
+
+// Client calls f
+func (f ClientsFunc) Client(identity string) (*tenderclient.Client, error) {
+	return f(identity)
+}
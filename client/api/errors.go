@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"tenderclient"
+)
+
+// ErrorBody is the JSON body of every error response
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes a failed request. Code is stable; Message is the chaincode's
+// or the server's own text.
+type ErrorDetail struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Function string `json:"function,omitempty"`
+	TxID     string `json:"txId,omitempty"`
+}
+
+// requestError is a failure detected by the server before calling the chaincode
+type requestError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func errUnauthenticated(message string) error {
+	return &requestError{http.StatusUnauthorized, "UNAUTHENTICATED", message}
+}
+
+func errBadRequest(message string) error {
+	return &requestError{http.StatusBadRequest, "INVALID_ARGUMENT", message}
+}
+
+func errUnavailable(message string) error {
+	return &requestError{http.StatusServiceUnavailable, "UNAVAILABLE", message}
+}
+
+// errorCodes maps the client's error kinds to HTTP statuses and error codes
+var errorCodes = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{tenderclient.ErrNotFound, http.StatusNotFound, "NOT_FOUND"},
+	{tenderclient.ErrAlreadyExists, http.StatusConflict, "ALREADY_EXISTS"},
+	{tenderclient.ErrMVCCConflict, http.StatusConflict, "MVCC_CONFLICT"},
+	{tenderclient.ErrInvalidState, http.StatusConflict, "INVALID_STATE"},
+	{tenderclient.ErrDeadlinePassed, http.StatusUnprocessableEntity, "DEADLINE_PASSED"},
+	{tenderclient.ErrNotEligible, http.StatusForbidden, "NOT_ELIGIBLE"},
+	{tenderclient.ErrPermissionDenied, http.StatusForbidden, "PERMISSION_DENIED"},
+	{tenderclient.ErrInvalidArgument, http.StatusBadRequest, "INVALID_ARGUMENT"},
+	{tenderclient.ErrUnavailable, http.StatusServiceUnavailable, "UNAVAILABLE"},
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, detail := http.StatusInternalServerError, ErrorDetail{Code: "CHAINCODE_ERROR", Message: err.Error()}
+
+	var reqErr *requestError
+	var ccErr *tenderclient.Error
+	switch {
+	case errors.As(err, &reqErr):
+		status, detail.Code = reqErr.status, reqErr.code
+	case errors.As(err, &ccErr):
+		detail.Message, detail.Function, detail.TxID = ccErr.Message, ccErr.Function, ccErr.TxID
+		for _, c := range errorCodes {
+			if errors.Is(err, c.kind) {
+				status, detail.Code = c.status, c.code
+				break
+			}
+		}
+	}
+	writeJSON(w, status, ErrorBody{Error: detail})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
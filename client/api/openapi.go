@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// contractMetadata is the part of the contract API's GetMetadata result used here
+type contractMetadata struct {
+	Info      map[string]interface{} `json:"info"`
+	Contracts map[string]struct {
+		Transactions []struct {
+			Name    string                 `json:"name"`
+			Returns map[string]interface{} `json:"returns"`
+		} `json:"transactions"`
+	} `json:"contracts"`
+	Components struct {
+		Schemas map[string]map[string]interface{} `json:"schemas"`
+	} `json:"components"`
+}
+
+// schemas defined by the API rather than the chaincode
+var apiSchemas = map[string]interface{}{
+	"AwardRequest": object(map[string]interface{}{
+		"bidId": str("Bid to award; omit to award the best evaluated bid"),
+	}),
+	"RejectRequest": object(map[string]interface{}{
+		"reason": str("Why the milestone is rejected"),
+	}, "reason"),
+	"ErrorBody": object(map[string]interface{}{
+		"error": object(map[string]interface{}{
+			"code":     str("NOT_FOUND, ALREADY_EXISTS, MVCC_CONFLICT, INVALID_STATE, DEADLINE_PASSED, NOT_ELIGIBLE, PERMISSION_DENIED, INVALID_ARGUMENT, UNAUTHENTICATED, UNAVAILABLE or CHAINCODE_ERROR"),
+			"message":  str("Chaincode or server error message"),
+			"function": str("Chaincode function that failed"),
+			"txId":     str("Transaction ID, when the transaction was submitted"),
+		}, "code", "message"),
+	}, "error"),
+}
+
+func object(properties map[string]interface{}, required ...string) map[string]interface{} {
+	o := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		o["required"] = required
+	}
+	return o
+}
+
+func str(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPIDocument builds an OpenAPI 3 document for routes, taking the request and
+// response schemas from the chaincode metadata
+func openAPIDocument(raw []byte) ([]byte, error) {
+	var md contractMetadata
+	if err := json.Unmarshal(raw, &md); err != nil {
+		return nil, fmt.Errorf("invalid chaincode metadata: %v", err)
+	}
+	returns := make(map[string]map[string]interface{})
+	for contract, cm := range md.Contracts {
+		for _, tx := range cm.Transactions {
+			returns[contract+":"+tx.Name] = tx.Returns
+		}
+	}
+
+	schemas := make(map[string]interface{})
+	for name, schema := range md.Components.Schemas {
+		// $id is JSON Schema, not OpenAPI
+		delete(schema, "$id")
+		schemas[name] = schema
+	}
+	for name, schema := range apiSchemas {
+		schemas[name] = schema
+	}
+
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": ref("ErrorBody")}},
+	}
+	paths := make(map[string]map[string]interface{})
+	for _, rt := range routes {
+		var params []interface{}
+		for _, m := range pathParam.FindAllStringSubmatch(rt.path, -1) {
+			params = append(params, map[string]interface{}{
+				"name": m[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, q := range rt.query {
+			params = append(params, map[string]interface{}{
+				"name": q.name, "in": "query", "description": q.description, "schema": map[string]interface{}{"type": q.typ},
+			})
+		}
+		params = append(params,
+			map[string]interface{}{"name": HeaderEndorsingOrgs, "in": "header", "description": "Comma separated MSP IDs to target for endorsement", "schema": map[string]interface{}{"type": "string"}},
+			map[string]interface{}{"name": HeaderSignature, "in": "header", "description": "Detached signature as JSON: {\"signature\": base64, \"keyId\": optional}", "schema": map[string]interface{}{"type": "string"}},
+		)
+
+		success := map[string]interface{}{"description": "Success"}
+		if schema := returns[rt.returns]; schema != nil {
+			success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+		} else if rt.schema != "" {
+			success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": ref(rt.schema)}}
+		}
+		op := map[string]interface{}{
+			"operationId":             rt.operation,
+			"summary":                 rt.summary,
+			"tags":                    []string{rt.tag},
+			"parameters":              params,
+			"responses":               map[string]interface{}{fmt.Sprint(rt.status): success, "default": errorResponse},
+			"x-chaincode-transaction": rt.transaction,
+		}
+		if rt.body != "" {
+			op["requestBody"] = map[string]interface{}{
+				"required": rt.body != "AwardRequest",
+				"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": ref(rt.body)}},
+			}
+		}
+		if paths[rt.path] == nil {
+			paths[rt.path] = make(map[string]interface{})
+		}
+		paths[rt.path][strings.ToLower(rt.method)] = op
+	}
+
+	info := map[string]interface{}{"title": "tendercc REST API", "version": "1"}
+	if v, ok := md.Info["version"]; ok {
+		info["x-chaincode-version"] = v
+	}
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    info,
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer", "description": "Token mapped to a wallet identity"},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"bearer": []string{}}},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,414 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"tendercc/model"
+	"tenderclient"
+)
+
+// AwardRequest is the body of POST /v1/tenders/{tenderId}/award.
+// Without a BidID the tender is awarded to its highest scoring bid.
+type AwardRequest struct {
+	BidID string `json:"bidId,omitempty"`
+}
+
+// RejectRequest is the body of POST /v1/tenders/{tenderId}/milestones/{milestoneId}/reject
+type RejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// route is one REST operation. transaction is the chaincode function it calls and
+// returns the function whose result schema describes the response, both as
+// "Contract:Function"; schema names the response schema when no function returns it,
+// and body names the request schema.
+type route struct {
+	method      string
+	path        string
+	operation   string
+	summary     string
+	tag         string
+	transaction string
+	returns     string
+	schema      string
+	body        string
+	query       []queryParam
+	status      int
+	handler     func(c *call) (interface{}, error)
+}
+
+type queryParam struct {
+	name        string
+	typ         string
+	description string
+}
+
+const (
+	enhanced = tenderclient.ContractEnhanced + ":"
+	basic    = tenderclient.ContractBasic + ":"
+)
+
+var pageParams = []queryParam{
+	{"pageSize", "integer", "Maximum number of results"},
+	{"bookmark", "string", "Bookmark returned by the previous page"},
+}
+
+var routes = []route{
+	{
+		method: "GET", path: "/v1/tenders", operation: "listTenders", tag: "Tenders",
+		summary:     "List tenders matching a filter, one page at a time",
+		transaction: enhanced + "QueryTenders", returns: enhanced + "QueryTenders",
+		query: append([]queryParam{
+			{"status", "string", "DRAFT, OPEN, CLOSED, AWARDED, ..."},
+			{"procurementMethod", "string", "OPEN, RESTRICTED, INVITED or SINGLE_SOURCE"},
+			{"currency", "string", "Bid currency"},
+			{"country", "string", "Owner country"},
+			{"sector", "string", "Relevant experience sector"},
+			{"complianceStandard", "string", "Required compliance standard"},
+			{"budgetMin", "number", "Estimated budget maximum is at least this"},
+			{"budgetMax", "number", "Estimated budget minimum is at most this"},
+			{"deadlineFrom", "string", "Bid submission deadline on or after (RFC3339)"},
+			{"deadlineTo", "string", "Bid submission deadline on or before (RFC3339)"},
+		}, pageParams...),
+		status: http.StatusOK, handler: listTenders,
+	},
+	{
+		method: "POST", path: "/v1/tenders", operation: "createTender", tag: "Tenders",
+		summary:     "Create a draft tender from an RFQ document",
+		transaction: enhanced + "CreateEnhancedTender", returns: enhanced + "GetEnhancedTender", body: "EnhancedTender",
+		status: http.StatusCreated, handler: createTender,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}", operation: "getTender", tag: "Tenders",
+		summary:     "Read a tender",
+		transaction: enhanced + "GetEnhancedTender", returns: enhanced + "GetEnhancedTender",
+		status: http.StatusOK, handler: getTender,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/publish", operation: "publishTender", tag: "Tenders",
+		summary:     "Open a draft tender for bids",
+		transaction: enhanced + "PublishTender", returns: enhanced + "GetEnhancedTender",
+		status: http.StatusOK, handler: publishTender,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/close", operation: "closeTender", tag: "Tenders",
+		summary:     "Close an open tender to further bids",
+		transaction: enhanced + "CloseTenderEnhanced", returns: enhanced + "GetEnhancedTender",
+		status: http.StatusOK, handler: closeTender,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/award", operation: "awardTender", tag: "Tenders",
+		summary:     "Award a closed tender to a bid, or to the best evaluated bid when no bidId is given",
+		transaction: enhanced + "AwardTender", returns: enhanced + "GetEnhancedTender", body: "AwardRequest",
+		status: http.StatusOK, handler: awardTender,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/bids", operation: "listBids", tag: "Bids",
+		summary:     "List the public bid references of a tender",
+		transaction: enhanced + "ListBidsPublic", returns: enhanced + "ListBidsPublic",
+		status: http.StatusOK, handler: listBids,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/bids", operation: "submitBid", tag: "Bids",
+		summary:     "Submit a bid; the body is passed to the chaincode in the transient map unchanged",
+		transaction: enhanced + "SubmitEnhancedBid", returns: enhanced + "GetBidRef", body: "EnhancedBidPrivate",
+		status: http.StatusCreated, handler: submitBid,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/bids/{bidId}", operation: "getBid", tag: "Bids",
+		summary:     "Read the public reference of a bid",
+		transaction: enhanced + "GetBidRef", returns: enhanced + "GetBidRef",
+		status: http.StatusOK, handler: getBid,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/bids/{bidId}/private", operation: "getBidPrivate", tag: "Bids",
+		summary:     "Read a bid from the bids collection",
+		transaction: enhanced + "GetEnhancedBidPrivate", returns: enhanced + "GetEnhancedBidPrivate",
+		status: http.StatusOK, handler: getBidPrivate,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/evaluations", operation: "listEvaluations", tag: "Evaluations",
+		summary:     "List the evaluations of a tender's bids",
+		transaction: enhanced + "ListEvaluations", returns: enhanced + "ListEvaluations",
+		status: http.StatusOK, handler: listEvaluations,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/evaluations", operation: "evaluateBids", tag: "Evaluations",
+		summary:     "Score every bid of a closed tender against its evaluation criteria",
+		transaction: enhanced + "EvaluateBids", returns: enhanced + "ListEvaluations",
+		status: http.StatusOK, handler: evaluateBids,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/milestones", operation: "listMilestones", tag: "Milestones",
+		summary:     "List the public milestone references of a tender",
+		transaction: basic + "ListMilestonesPublic", returns: basic + "ListMilestonesPublic",
+		status: http.StatusOK, handler: listMilestones,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/milestones", operation: "submitMilestone", tag: "Milestones",
+		summary:     "Submit milestone evidence; the body is passed to the chaincode in the transient map unchanged",
+		transaction: basic + "SubmitMilestone", body: "MilestonePrivate", schema: "MilestoneRef",
+		status: http.StatusCreated, handler: submitMilestone,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/milestones/{milestoneId}/private", operation: "getMilestonePrivate", tag: "Milestones",
+		summary:     "Read milestone details from the milestones collection",
+		transaction: basic + "ReadMilestonePrivate", returns: basic + "ReadMilestonePrivate",
+		status: http.StatusOK, handler: getMilestonePrivate,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/milestones/{milestoneId}/approve", operation: "approveMilestone", tag: "Milestones",
+		summary:     "Approve a milestone and release its payment",
+		transaction: basic + "ApproveMilestone", schema: "MilestoneRef",
+		status: http.StatusOK, handler: approveMilestone,
+	},
+	{
+		method: "POST", path: "/v1/tenders/{tenderId}/milestones/{milestoneId}/reject", operation: "rejectMilestone", tag: "Milestones",
+		summary:     "Reject a milestone",
+		transaction: basic + "RejectMilestone", body: "RejectRequest", schema: "MilestoneRef",
+		status: http.StatusOK, handler: rejectMilestone,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/history", operation: "getTenderHistory", tag: "History",
+		summary:     "Read the history of a tender record",
+		transaction: enhanced + "GetTenderHistoryEntries", returns: enhanced + "GetTenderHistoryEntries",
+		query: pageParams, status: http.StatusOK, handler: tenderHistory,
+	},
+	{
+		method: "GET", path: "/v1/tenders/{tenderId}/audit-trail", operation: "getAuditTrail", tag: "History",
+		summary:     "Read the merged history of a tender and its bids, evaluations and milestones",
+		transaction: enhanced + "GetFullAuditTrail", returns: enhanced + "GetFullAuditTrail",
+		query: pageParams, status: http.StatusOK, handler: auditTrail,
+	},
+}
+
+// list returns an empty JSON array instead of null
+func list[T any](items []T, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return items, nil
+}
+
+func (c *call) queryFloat(name string) (float64, error) {
+	v := c.r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, errBadRequest(fmt.Sprintf("invalid %s: %s", name, v))
+	}
+	return f, nil
+}
+
+func (c *call) pageSize() (int32, error) {
+	v := c.r.URL.Query().Get("pageSize")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || n < 0 {
+		return 0, errBadRequest(fmt.Sprintf("invalid pageSize: %s", v))
+	}
+	return int32(n), nil
+}
+
+func listTenders(c *call) (interface{}, error) {
+	q := c.r.URL.Query()
+	filter := model.TenderFilter{
+		Status:             q.Get("status"),
+		ProcurementMethod:  q.Get("procurementMethod"),
+		Currency:           q.Get("currency"),
+		Country:            q.Get("country"),
+		Sector:             q.Get("sector"),
+		ComplianceStandard: q.Get("complianceStandard"),
+		DeadlineFrom:       q.Get("deadlineFrom"),
+		DeadlineTo:         q.Get("deadlineTo"),
+		Bookmark:           q.Get("bookmark"),
+	}
+	var err error
+	if filter.BudgetMin, err = c.queryFloat("budgetMin"); err != nil {
+		return nil, err
+	}
+	if filter.BudgetMax, err = c.queryFloat("budgetMax"); err != nil {
+		return nil, err
+	}
+	if filter.PageSize, err = c.pageSize(); err != nil {
+		return nil, err
+	}
+	return c.client.QueryTenders(c.ctx(), filter)
+}
+
+func createTender(c *call) (interface{}, error) {
+	var tender model.EnhancedTender
+	data, err := c.body(&tender)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.client.CreateEnhancedTenderJSON(c.ctx(), data, c.opts...); err != nil {
+		return nil, err
+	}
+	return c.client.GetEnhancedTender(c.ctx(), tender.ID)
+}
+
+func getTender(c *call) (interface{}, error) {
+	return c.client.GetEnhancedTender(c.ctx(), c.param("tenderId"))
+}
+
+func publishTender(c *call) (interface{}, error) {
+	if err := c.client.PublishTender(c.ctx(), c.param("tenderId"), c.opts...); err != nil {
+		return nil, err
+	}
+	return getTender(c)
+}
+
+func closeTender(c *call) (interface{}, error) {
+	if err := c.client.CloseTender(c.ctx(), c.param("tenderId"), c.opts...); err != nil {
+		return nil, err
+	}
+	return getTender(c)
+}
+
+func awardTender(c *call) (interface{}, error) {
+	data, err := c.rawBody()
+	if err != nil {
+		return nil, err
+	}
+	var req AwardRequest
+	if len(data) > 0 {
+		if err := decodeBody(data, &req); err != nil {
+			return nil, err
+		}
+	}
+	if req.BidID == "" {
+		err = c.client.AwardBestBid(c.ctx(), c.param("tenderId"), c.opts...)
+	} else {
+		err = c.client.AwardTender(c.ctx(), c.param("tenderId"), req.BidID, c.opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return getTender(c)
+}
+
+func listBids(c *call) (interface{}, error) {
+	return list(c.client.ListBidsPublic(c.ctx(), c.param("tenderId")))
+}
+
+func submitBid(c *call) (interface{}, error) {
+	tenderID := c.param("tenderId")
+	var bid model.EnhancedBidPrivate
+	data, err := c.body(&bid)
+	if err != nil {
+		return nil, err
+	}
+	if bid.BidID == "" {
+		return nil, errBadRequest("bidId is required")
+	}
+	if bid.TenderID != tenderID {
+		return nil, errBadRequest(fmt.Sprintf("bid tenderId %q does not match tender %s", bid.TenderID, tenderID))
+	}
+	if err := c.client.SubmitEnhancedBidJSON(c.ctx(), tenderID, bid.BidID, data, c.opts...); err != nil {
+		return nil, err
+	}
+	return c.client.GetBidRef(c.ctx(), tenderID, bid.BidID)
+}
+
+func getBid(c *call) (interface{}, error) {
+	return c.client.GetBidRef(c.ctx(), c.param("tenderId"), c.param("bidId"))
+}
+
+func getBidPrivate(c *call) (interface{}, error) {
+	return c.client.GetEnhancedBidPrivate(c.ctx(), c.param("tenderId"), c.param("bidId"))
+}
+
+func listEvaluations(c *call) (interface{}, error) {
+	return list(c.client.ListEvaluations(c.ctx(), c.param("tenderId")))
+}
+
+func evaluateBids(c *call) (interface{}, error) {
+	if err := c.client.EvaluateBids(c.ctx(), c.param("tenderId"), c.opts...); err != nil {
+		return nil, err
+	}
+	return listEvaluations(c)
+}
+
+func listMilestones(c *call) (interface{}, error) {
+	return list(c.client.ListMilestonesPublic(c.ctx(), c.param("tenderId")))
+}
+
+// milestoneRef finds a milestone's public reference; the chaincode only lists them
+func milestoneRef(c *call, tenderID, milestoneID string) (interface{}, error) {
+	refs, err := c.client.ListMilestonesPublic(c.ctx(), tenderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if ref.MilestoneID == milestoneID {
+			return ref, nil
+		}
+	}
+	return nil, &tenderclient.Error{Function: "ListMilestonesPublic", Message: "milestone not found", Kind: tenderclient.ErrNotFound}
+}
+
+func submitMilestone(c *call) (interface{}, error) {
+	tenderID := c.param("tenderId")
+	var ms model.MilestonePrivate
+	data, err := c.body(&ms)
+	if err != nil {
+		return nil, err
+	}
+	if ms.MilestoneID == "" {
+		return nil, errBadRequest("milestoneId is required")
+	}
+	if err := c.client.SubmitMilestoneJSON(c.ctx(), tenderID, ms.MilestoneID, data, c.opts...); err != nil {
+		return nil, err
+	}
+	return milestoneRef(c, tenderID, ms.MilestoneID)
+}
+
+func getMilestonePrivate(c *call) (interface{}, error) {
+	return c.client.ReadMilestonePrivate(c.ctx(), c.param("tenderId"), c.param("milestoneId"))
+}
+
+func approveMilestone(c *call) (interface{}, error) {
+	tenderID, milestoneID := c.param("tenderId"), c.param("milestoneId")
+	if err := c.client.ApproveMilestone(c.ctx(), tenderID, milestoneID, c.opts...); err != nil {
+		return nil, err
+	}
+	return milestoneRef(c, tenderID, milestoneID)
+}
+
+func rejectMilestone(c *call) (interface{}, error) {
+	tenderID, milestoneID := c.param("tenderId"), c.param("milestoneId")
+	var req RejectRequest
+	if _, err := c.body(&req); err != nil {
+		return nil, err
+	}
+	if err := c.client.RejectMilestone(c.ctx(), tenderID, milestoneID, req.Reason, c.opts...); err != nil {
+		return nil, err
+	}
+	return milestoneRef(c, tenderID, milestoneID)
+}
+
+func tenderHistory(c *call) (interface{}, error) {
+	size, err := c.pageSize()
+	if err != nil {
+		return nil, err
+	}
+	return c.client.GetTenderHistoryEntries(c.ctx(), c.param("tenderId"), size, c.r.URL.Query().Get("bookmark"))
+}
+
+func auditTrail(c *call) (interface{}, error) {
+	size, err := c.pageSize()
+	if err != nil {
+		return nil, err
+	}
+	return c.client.GetFullAuditTrail(c.ctx(), c.param("tenderId"), size, c.r.URL.Query().Get("bookmark"))
+}
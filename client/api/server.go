@@ -0,0 +1,176 @@
+// Package api serves tendercc tenders, bids, evaluations, milestones and history
+// as REST resources over tenderclient.
+//
+// Callers authenticate with a bearer token that maps to a wallet identity, and every
+// chaincode call is made as that identity. Bids and milestones are passed to the
+// chaincode in the transient map exactly as received. Failures are returned as an
+// ErrorBody with a stable code derived from the tenderclient error kind. The OpenAPI
+// document at /openapi.json is generated from the chaincode's contract metadata.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"tendercc/model"
+	"tenderclient"
+)
+
+// Headers read on every request
+const (
+	// HeaderEndorsingOrgs is a comma separated list of MSP IDs to target for endorsement
+	HeaderEndorsingOrgs = "X-Endorsing-Orgs"
+	// HeaderSignature carries a model.DetachedSignature as JSON for transactions that verify one
+	HeaderSignature = "X-Tender-Signature"
+)
+
+const maxBodyBytes = 4 << 20
+
+// Options configures a Server
+type Options struct {
+	Auth    Authenticator
+	Clients Clients
+	// MetadataIdentity is the wallet identity used to read the chaincode metadata for /openapi.json
+	MetadataIdentity string
+}
+
+// Server is an http.Handler for the REST API
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu      sync.Mutex
+	openapi []byte
+}
+
+// NewServer registers every route
+func NewServer(opts Options) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	for _, rt := range routes {
+		s.mux.HandleFunc(rt.method+" "+rt.path, s.handle(rt))
+	}
+	s.mux.HandleFunc("GET /openapi.json", s.serveOpenAPI)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// call is one authenticated request
+type call struct {
+	r      *http.Request
+	client *tenderclient.Client
+	opts   []tenderclient.CallOption
+}
+
+func (c *call) ctx() context.Context {
+	return c.r.Context()
+}
+
+func (c *call) param(name string) string {
+	return c.r.PathValue(name)
+}
+
+// body reads the raw request body and checks that it decodes into v
+func (c *call) body(v interface{}) ([]byte, error) {
+	data, err := c.rawBody()
+	if err != nil {
+		return nil, err
+	}
+	return data, decodeBody(data, v)
+}
+
+func (c *call) rawBody() ([]byte, error) {
+	data, err := io.ReadAll(c.r.Body)
+	if err != nil {
+		return nil, errBadRequest(fmt.Sprintf("failed to read request body: %v", err))
+	}
+	return data, nil
+}
+
+func decodeBody(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return errBadRequest(fmt.Sprintf("invalid request body: %v", err))
+	}
+	return nil
+}
+
+func (s *Server) handle(rt route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity, err := s.opts.Auth.Authenticate(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		client, err := s.opts.Clients.Client(identity)
+		if err != nil {
+			writeError(w, errUnavailable(fmt.Sprintf("failed to connect as %s: %v", identity, err)))
+			return
+		}
+		opts, err := callOptions(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		result, err := rt.handler(&call{r: r, client: client, opts: opts})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, rt.status, result)
+	}
+}
+
+// callOptions reads the endorsement and signature headers
+func callOptions(r *http.Request) ([]tenderclient.CallOption, error) {
+	var opts []tenderclient.CallOption
+	if orgs := r.Header.Get(HeaderEndorsingOrgs); orgs != "" {
+		var mspIDs []string
+		for _, org := range strings.Split(orgs, ",") {
+			if org = strings.TrimSpace(org); org != "" {
+				mspIDs = append(mspIDs, org)
+			}
+		}
+		opts = append(opts, tenderclient.WithEndorsingOrgs(mspIDs...))
+	}
+	if header := r.Header.Get(HeaderSignature); header != "" {
+		var sig model.DetachedSignature
+		if err := json.Unmarshal([]byte(header), &sig); err != nil {
+			return nil, errBadRequest(fmt.Sprintf("invalid %s header: %v", HeaderSignature, err))
+		}
+		opts = append(opts, tenderclient.WithSignature(sig))
+	}
+	return opts, nil
+}
+
+func (s *Server) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.openapi == nil {
+		client, err := s.opts.Clients.Client(s.opts.MetadataIdentity)
+		if err != nil {
+			writeError(w, errUnavailable(fmt.Sprintf("failed to connect as %s: %v", s.opts.MetadataIdentity, err)))
+			return
+		}
+		metadata, err := client.Metadata(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		doc, err := openAPIDocument(metadata)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		s.openapi = doc
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.openapi)
+}
@@ -0,0 +1,390 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tendercc/model"
+	"tenderclient"
+	"tenderclient/fakeledger"
+)
+
+var t0 = time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+
+// identities maps the wallet identities of the test tokens to their organizations
+var identities = map[string]string{
+	"buyer":      "BuyerMSP",
+	"contractor": "ContractorMSP",
+}
+
+// recorder keeps the requests submitted by every client of a test server
+type recorder struct {
+	mu       sync.Mutex
+	requests []*tenderclient.Request
+}
+
+// last returns the last submitted request
+func (r *recorder) last() *tenderclient.Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.requests) == 0 {
+		return nil
+	}
+	return r.requests[len(r.requests)-1]
+}
+
+// recording passes submits to the fake ledger after recording them
+type recording struct {
+	tenderclient.Transport
+	rec *recorder
+}
+
+func (r *recording) Submit(ctx context.Context, req *tenderclient.Request) ([]byte, error) {
+	r.rec.mu.Lock()
+	r.rec.requests = append(r.rec.requests, req)
+	r.rec.mu.Unlock()
+	return r.Transport.Submit(ctx, req)
+}
+
+// testServer serves the API over a fake ledger running the real chaincode
+type testServer struct {
+	t   *testing.T
+	url string
+	rec *recorder
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestServer(t *testing.T) *testServer {
+	ts := &testServer{t: t, rec: &recorder{}, now: t0}
+	ledger := fakeledger.New()
+	ledger.SetClock(ts.clock)
+	srv := httptest.NewServer(NewServer(Options{
+		Auth: TokenAuth{"buyer-token": "buyer", "contractor-token": "contractor"},
+		Clients: ClientsFunc(func(identity string) (*tenderclient.Client, error) {
+			return tenderclient.New(&recording{Transport: ledger.Transport(identities[identity], identity), rec: ts.rec}), nil
+		}),
+		MetadataIdentity: "buyer",
+	}))
+	t.Cleanup(srv.Close)
+	ts.url = srv.URL
+	return ts
+}
+
+func (ts *testServer) clock() time.Time {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.now
+}
+
+func (ts *testServer) advance(d time.Duration) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.now = ts.now.Add(d)
+}
+
+// do sends a request as the identity of token and returns the status and body
+func (ts *testServer) do(method, path, token, body string, header ...string) (int, []byte) {
+	ts.t.Helper()
+	req, err := http.NewRequest(method, ts.url+path, strings.NewReader(body))
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+// mustDo sends a request, checks its status and decodes the response into v
+func (ts *testServer) mustDo(method, path, token, body string, status int, v interface{}) {
+	ts.t.Helper()
+	got, data := ts.do(method, path, token, body)
+	if got != status {
+		ts.t.Fatalf("%s %s: status %d, want %d: %s", method, path, got, status, data)
+	}
+	if v != nil {
+		if err := json.Unmarshal(data, v); err != nil {
+			ts.t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func rfc(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// tenderFixture returns a valid single-envelope tender with bids due at deadline
+func tenderFixture(id string, deadline time.Time) *model.EnhancedTender {
+	return &model.EnhancedTender{
+		ID: id,
+		ProjectScope: model.ProjectScope{
+			Description:      "Resurface district road " + id,
+			Objectives:       []string{"Restore ride quality"},
+			Deliverables:     []string{"Resurfaced carriageway"},
+			TechnicalSpecs:   []string{"50mm wearing course"},
+			QualityStandards: []string{"AASHTO M 323"},
+			Budget: model.Budget{
+				Currency:     "USD",
+				EstimatedMin: 400000,
+				EstimatedMax: 900000,
+				PaymentTerms: "MILESTONE_BASED",
+				PaymentSchedule: []model.PaymentMilestone{
+					{Name: "Base course", Percentage: 40},
+					{Name: "Wearing course", Percentage: 60},
+				},
+			},
+		},
+		Deadlines: model.TenderDeadlines{
+			QuestionsDeadline:     rfc(deadline.Add(-72 * time.Hour)),
+			BidSubmissionDeadline: rfc(deadline),
+			ProjectStartDate:      rfc(deadline.Add(30 * 24 * time.Hour)),
+			ProjectEndDate:        rfc(deadline.Add(180 * 24 * time.Hour)),
+		},
+		EvaluationCriteria: []model.EvalCriterion{
+			{ID: "C1", Name: "Price", Weight: 60, Type: "QUANTITATIVE", ScoringMethod: "LOWEST_PRICE"},
+			{ID: "C2", Name: "Safety", Weight: 40, Type: "QUALITATIVE", ScoringMethod: "HIGHEST_SCORE"},
+		},
+		BidRequirements: model.BidRequirements{
+			RequiredDocuments:         []model.DocumentReq{{Name: "Method statement", Mandatory: true, Format: "PDF"}},
+			TechnicalRequirements:     []model.TechnicalReq{},
+			CertificationRequirements: []model.CertificationReq{},
+			SubmissionFormat:          model.SubmissionFormat{Method: "ONLINE", FileFormats: []string{"PDF"}, MaxFileSize: 10},
+		},
+		ComplianceReqs: []model.ComplianceReq{},
+		OwnerDetails: model.OwnerInfo{
+			OrganizationName: "District Roads Authority",
+			Address:          model.Address{City: "Springfield", Country: "US"},
+			AuthorizedBy:     model.AuthorizedPerson{Name: "Dana Reyes", SignerID: "buyer"},
+		},
+	}
+}
+
+func bidFixture(tenderID, bidID string, amount float64) *model.EnhancedBidPrivate {
+	return &model.EnhancedBidPrivate{
+		TenderID:     tenderID,
+		BidID:        bidID,
+		ContractorID: "contractorA",
+		TotalAmount:  amount,
+		Currency:     "USD",
+		TechnicalProposal: model.TechnicalProposal{
+			Methodology:      "Cold in-place recycling",
+			Timeline:         model.ProjectTimeline{Phases: []model.Phase{}, CriticalPath: []string{}},
+			TeamComposition:  []model.TeamMember{},
+			Resources:        []model.Resource{},
+			QualityAssurance: model.QAProcess{Procedures: []string{}, Standards: []string{}},
+			RiskMitigation:   []model.RiskMitigation{},
+		},
+		FinancialProposal: model.FinancialProposal{
+			BreakdownByPhase:    []model.PhaseCosting{{Phase: "Works", Cost: amount}},
+			BreakdownByCategory: []model.CategoryCosting{},
+			PaymentSchedule:     []model.PaymentRequest{},
+		},
+		ComplianceChecklist: map[string]bool{"Safety": true},
+		DocumentHashes:      map[string]string{},
+	}
+}
+
+// TestTenderLifecycle walks a tender through every route, from draft to paid milestone
+func TestTenderLifecycle(t *testing.T) {
+	ts := newTestServer(t)
+	deadline := t0.Add(14 * 24 * time.Hour)
+
+	var tender model.EnhancedTender
+	ts.mustDo("POST", "/v1/tenders", "buyer-token", mustJSON(t, tenderFixture("T1", deadline)), http.StatusCreated, &tender)
+	if tender.Status != "DRAFT" || tender.OwnerMSP != "BuyerMSP" {
+		t.Fatalf("created tender = %s owned by %s", tender.Status, tender.OwnerMSP)
+	}
+	ts.mustDo("GET", "/v1/tenders/T1", "contractor-token", "", http.StatusOK, &tender)
+	ts.mustDo("POST", "/v1/tenders/T1/publish", "buyer-token", "", http.StatusOK, &tender)
+	if tender.Status != "OPEN" {
+		t.Fatalf("published tender is %s", tender.Status)
+	}
+
+	var ref model.BidRef
+	ts.mustDo("POST", "/v1/tenders/T1/bids", "contractor-token", mustJSON(t, bidFixture("T1", "B1", 650000)), http.StatusCreated, &ref)
+	if ref.BidID != "B1" || ref.SubmittedBy != "ContractorMSP" {
+		t.Fatalf("bid ref = %+v", ref)
+	}
+	var refs []*model.BidRef
+	ts.mustDo("GET", "/v1/tenders/T1/bids", "buyer-token", "", http.StatusOK, &refs)
+	if len(refs) != 1 {
+		t.Fatalf("listed %d bids", len(refs))
+	}
+	ts.mustDo("GET", "/v1/tenders/T1/bids/B1", "buyer-token", "", http.StatusOK, &ref)
+	var bid model.EnhancedBidPrivate
+	ts.mustDo("GET", "/v1/tenders/T1/bids/B1/private", "contractor-token", "", http.StatusOK, &bid)
+	if bid.TotalAmount != 650000 || bid.SubmittedAt != rfc(t0) {
+		t.Fatalf("private bid = %+v", bid)
+	}
+
+	ts.advance(15 * 24 * time.Hour)
+	ts.mustDo("POST", "/v1/tenders/T1/close", "buyer-token", "", http.StatusOK, &tender)
+	var evals []*model.Evaluation
+	ts.mustDo("POST", "/v1/tenders/T1/evaluations", "buyer-token", "", http.StatusOK, &evals)
+	if len(evals) != 1 || evals[0].BidID != "B1" {
+		t.Fatalf("evaluations = %+v", evals)
+	}
+	ts.mustDo("GET", "/v1/tenders/T1/evaluations", "buyer-token", "", http.StatusOK, &evals)
+	ts.mustDo("POST", "/v1/tenders/T1/award", "buyer-token", "", http.StatusOK, &tender)
+	if tender.Status != "AWARDED" {
+		t.Fatalf("awarded tender is %s", tender.Status)
+	}
+
+	ms := model.MilestonePrivate{TenderID: "T1", MilestoneID: "M1", Title: "Base course", EvidenceHash: "abc", Amount: 260000}
+	var msRef model.MilestoneRef
+	ts.mustDo("POST", "/v1/tenders/T1/milestones", "contractor-token", mustJSON(t, ms), http.StatusCreated, &msRef)
+	if msRef.Status != "SUBMITTED" {
+		t.Fatalf("milestone ref = %+v", msRef)
+	}
+	var msRefs []*model.MilestoneRef
+	ts.mustDo("GET", "/v1/tenders/T1/milestones", "buyer-token", "", http.StatusOK, &msRefs)
+	ts.mustDo("GET", "/v1/tenders/T1/milestones/M1/private", "buyer-token", "", http.StatusOK, &ms)
+	ts.mustDo("POST", "/v1/tenders/T1/milestones/M1/approve", "buyer-token", "", http.StatusOK, &msRef)
+	if msRef.Status != "APPROVED" || !msRef.PaymentReleased {
+		t.Fatalf("approved milestone ref = %+v", msRef)
+	}
+
+	var page model.HistoryPage
+	ts.mustDo("GET", "/v1/tenders/T1/history?pageSize=2", "buyer-token", "", http.StatusOK, &page)
+	if len(page.Entries) != 2 || page.Bookmark == "" {
+		t.Fatalf("history page has %d entries, bookmark %q", len(page.Entries), page.Bookmark)
+	}
+	ts.mustDo("GET", "/v1/tenders/T1/audit-trail", "buyer-token", "", http.StatusOK, &page)
+	var result model.TenderQueryResult
+	ts.mustDo("GET", "/v1/tenders?status=AWARDED", "contractor-token", "", http.StatusOK, &result)
+	if len(result.Tenders) != 1 || result.Tenders[0].ID != "T1" {
+		t.Fatalf("query returned %d tenders", len(result.Tenders))
+	}
+
+	var doc struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	ts.mustDo("GET", "/openapi.json", "", "", http.StatusOK, &doc)
+	if doc.Paths["/v1/tenders/{tenderId}/bids"] == nil {
+		t.Fatalf("openapi.json has no bids path")
+	}
+}
+
+// TestTransientPacking checks that bodies and signatures reach the chaincode in the
+// transient map exactly as received
+func TestTransientPacking(t *testing.T) {
+	ts := newTestServer(t)
+	ts.mustDo("POST", "/v1/tenders", "buyer-token", mustJSON(t, tenderFixture("T1", t0.Add(time.Hour))), http.StatusCreated, nil)
+	ts.mustDo("POST", "/v1/tenders/T1/publish", "buyer-token", "", http.StatusOK, nil)
+
+	// Field order and spacing are kept: the chaincode hashes and verifies these bytes
+	body := `{ "bidId": "B1",
+  "tenderId": "T1", "contractorId": "contractorA", "totalAmount": 650000, "currency": "USD",
+  "technicalProposal": {"methodology": "Cold in-place recycling"},
+  "financialProposal": {"breakdownByPhase": [{"phase": "Works", "cost": 650000}]},
+  "complianceChecklist": {"Safety": true} }`
+	sig := `{"signature":"c2ln","keyId":"K1"}`
+	status, data := ts.do("POST", "/v1/tenders/T1/bids", "contractor-token", body, HeaderSignature, sig)
+	req := ts.rec.last()
+	if req == nil || req.Function != "SubmitEnhancedBid" {
+		t.Fatalf("no bid submitted (status %d: %s)", status, data)
+	}
+	if strings.Join(req.Args, ",") != "T1,B1" {
+		t.Errorf("args = %q", req.Args)
+	}
+	if string(req.Transient[tenderclient.TransientBid]) != body {
+		t.Errorf("transient bid = %s, want the request body", req.Transient[tenderclient.TransientBid])
+	}
+	var got model.DetachedSignature
+	if err := json.Unmarshal(req.Transient[tenderclient.TransientSignature], &got); err != nil || got.KeyID != "K1" || got.Signature != "c2ln" {
+		t.Errorf("transient signature = %s", req.Transient[tenderclient.TransientSignature])
+	}
+
+	ms := `{"tenderId":"T1", "milestoneId":"M1", "title":"Base course", "evidenceHash":"abc", "amount":1, "details":""}`
+	ts.mustDo("POST", "/v1/tenders/T1/milestones", "contractor-token", ms, http.StatusCreated, nil)
+	req = ts.rec.last()
+	if req.Contract != tenderclient.ContractBasic || string(req.Transient[tenderclient.TransientMilestone]) != ms {
+		t.Errorf("milestone request = %s:%s with %s", req.Contract, req.Function, req.Transient[tenderclient.TransientMilestone])
+	}
+	if len(req.Transient) != 1 {
+		t.Errorf("milestone transient has %d keys", len(req.Transient))
+	}
+}
+
+// TestErrorCodes runs failing requests in order and checks the status and code of each
+func TestErrorCodes(t *testing.T) {
+	ts := newTestServer(t)
+	deadline := t0.Add(time.Hour)
+	bad := tenderFixture("T2", deadline)
+	bad.EvaluationCriteria[0].Weight = 50
+
+	ts.mustDo("POST", "/v1/tenders", "buyer-token", mustJSON(t, tenderFixture("T1", deadline)), http.StatusCreated, nil)
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		before func()
+		status int
+		code   string
+		text   string
+	}{
+		{name: "no token", method: "GET", path: "/v1/tenders/T1", status: http.StatusUnauthorized, code: "UNAUTHENTICATED"},
+		{name: "unknown token", method: "GET", path: "/v1/tenders/T1", token: "guess", status: http.StatusUnauthorized, code: "UNAUTHENTICATED"},
+		{name: "malformed body", method: "POST", path: "/v1/tenders", token: "buyer-token", body: "{", status: http.StatusBadRequest, code: "INVALID_ARGUMENT", text: "invalid request body"},
+		{name: "chaincode validation", method: "POST", path: "/v1/tenders", token: "buyer-token", body: mustJSON(t, bad), status: http.StatusBadRequest, code: "INVALID_ARGUMENT", text: "weight must equal 100"},
+		{name: "unknown tender", method: "GET", path: "/v1/tenders/T9", token: "buyer-token", status: http.StatusNotFound, code: "NOT_FOUND", text: "tender T9 not found"},
+		{name: "duplicate tender", method: "POST", path: "/v1/tenders", token: "buyer-token", body: mustJSON(t, tenderFixture("T1", deadline)), status: http.StatusConflict, code: "ALREADY_EXISTS"},
+		{name: "bid on draft", method: "POST", path: "/v1/tenders/T1/bids", token: "contractor-token", body: mustJSON(t, bidFixture("T1", "B1", 650000)), status: http.StatusConflict, code: "INVALID_STATE", text: "not open for bids"},
+		{name: "bid for another tender", method: "POST", path: "/v1/tenders/T1/bids", token: "contractor-token", body: mustJSON(t, bidFixture("T2", "B1", 650000)), status: http.StatusBadRequest, code: "INVALID_ARGUMENT"},
+		{name: "bad page size", method: "GET", path: "/v1/tenders/T1/history?pageSize=-1", token: "buyer-token", status: http.StatusBadRequest, code: "INVALID_ARGUMENT"},
+		{
+			name: "late bid", method: "POST", path: "/v1/tenders/T1/bids", token: "contractor-token", body: mustJSON(t, bidFixture("T1", "B1", 650000)),
+			before: func() {
+				ts.mustDo("POST", "/v1/tenders/T1/publish", "buyer-token", "", http.StatusOK, nil)
+				ts.advance(2 * time.Hour)
+			},
+			status: http.StatusUnprocessableEntity, code: "DEADLINE_PASSED",
+		},
+		{name: "publish twice", method: "POST", path: "/v1/tenders/T1/publish", token: "buyer-token", status: http.StatusConflict, code: "INVALID_STATE", text: "only draft tenders can be published"},
+	}
+	for _, step := range steps {
+		if step.before != nil {
+			step.before()
+		}
+		status, data := ts.do(step.method, step.path, step.token, step.body)
+		var body ErrorBody
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Fatalf("%s: %v: %s", step.name, err, data)
+		}
+		if status != step.status || body.Error.Code != step.code || !strings.Contains(body.Error.Message, step.text) {
+			t.Errorf("%s: %d %+v, want %d %s containing %q", step.name, status, body.Error, step.status, step.code, step.text)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package tenderclient
+
+import (
+	"context"
+	"strconv"
+
+	"tendercc/model"
+)
+
+// GetTenderHistoryEntries returns one page of a tender's history
+func (c *Client) GetTenderHistoryEntries(ctx context.Context, tenderID string, pageSize int32, bookmark string) (*model.HistoryPage, error) {
+	return evaluateAs[*model.HistoryPage](c, ctx, ContractEnhanced, "GetTenderHistoryEntries", tenderID, formatPageSize(pageSize), bookmark)
+}
+
+// GetBidRefHistory returns one page of a bid reference's history
+func (c *Client) GetBidRefHistory(ctx context.Context, tenderID, bidID string, pageSize int32, bookmark string) (*model.HistoryPage, error) {
+	return evaluateAs[*model.HistoryPage](c, ctx, ContractEnhanced, "GetBidRefHistory", tenderID, bidID, formatPageSize(pageSize), bookmark)
+}
+
+// GetEvaluationHistory returns one page of an evaluation's history
+func (c *Client) GetEvaluationHistory(ctx context.Context, tenderID, bidID string, pageSize int32, bookmark string) (*model.HistoryPage, error) {
+	return evaluateAs[*model.HistoryPage](c, ctx, ContractEnhanced, "GetEvaluationHistory", tenderID, bidID, formatPageSize(pageSize), bookmark)
+}
+
+// GetMilestoneHistory returns one page of a milestone reference's history
+func (c *Client) GetMilestoneHistory(ctx context.Context, tenderID, milestoneID string, pageSize int32, bookmark string) (*model.HistoryPage, error) {
+	return evaluateAs[*model.HistoryPage](c, ctx, ContractEnhanced, "GetMilestoneHistory", tenderID, milestoneID, formatPageSize(pageSize), bookmark)
+}
+
+// GetFullAuditTrail returns one page of the merged history of a tender and its bids, evaluations and milestones
+func (c *Client) GetFullAuditTrail(ctx context.Context, tenderID string, pageSize int32, bookmark string) (*model.HistoryPage, error) {
+	return evaluateAs[*model.HistoryPage](c, ctx, ContractEnhanced, "GetFullAuditTrail", tenderID, formatPageSize(pageSize), bookmark)
+}
+
+// AuditPrivateData compares the private data hashes of a tender's bids and milestones with their public references
+func (c *Client) AuditPrivateData(ctx context.Context, tenderID string) (*model.PrivateDataAuditReport, error) {
+	return evaluateAs[*model.PrivateDataAuditReport](c, ctx, ContractEnhanced, "AuditPrivateData", tenderID)
+}
+
+func formatPageSize(pageSize int32) string {
+	return strconv.FormatInt(int64(pageSize), 10)
+}
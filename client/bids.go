@@ -0,0 +1,138 @@
+package tenderclient
+
+import (
+	"context"
+	"strconv"
+
+	"tendercc/model"
+)
+
+// SubmitEnhancedBid submits a bid. The bid travels in the transient map and is
+// stored only in the bids collection; a detached signature can be added with WithSignature.
+func (c *Client) SubmitEnhancedBid(ctx context.Context, tenderID, bidID string, bid *model.EnhancedBidPrivate, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransientJSON(TransientBid, bid)}, opts...)
+	_, err := c.submit(ctx, ContractEnhanced, "SubmitEnhancedBid", opts, tenderID, bidID)
+	return err
+}
+
+// SubmitEnhancedBidJSON submits a bid that is already encoded. Use it when a detached
+// signature covers the exact bytes, since SubmitEnhancedBid re-encodes the bid.
+func (c *Client) SubmitEnhancedBidJSON(ctx context.Context, tenderID, bidID string, bid []byte, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransient(TransientBid, bid)}, opts...)
+	_, err := c.submit(ctx, ContractEnhanced, "SubmitEnhancedBid", opts, tenderID, bidID)
+	return err
+}
+
+// SubmitEncryptedBid submits a bid sealed to the tender's bid encryption key
+func (c *Client) SubmitEncryptedBid(ctx context.Context, tenderID, bidID string, sealed *model.EncryptedBid, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransientJSON(TransientEncryptedBid, sealed)}, opts...)
+	_, err := c.submit(ctx, ContractEnhanced, "SubmitEncryptedBid", opts, tenderID, bidID)
+	return err
+}
+
+// ReleaseKeyShare releases one Shamir share of a tender's bid decryption key
+func (c *Client) ReleaseKeyShare(ctx context.Context, tenderID string, index int, value string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "ReleaseKeyShare", opts, tenderID, strconv.Itoa(index), value)
+	return err
+}
+
+// OpenEncryptedBids decrypts the sealed bids of a tender once its key is released
+func (c *Client) OpenEncryptedBids(ctx context.Context, tenderID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "OpenEncryptedBids", opts, tenderID)
+	return err
+}
+
+// GetEnhancedBidPrivate reads a bid from the bids collection; the peer's organisation must be a collection member
+func (c *Client) GetEnhancedBidPrivate(ctx context.Context, tenderID, bidID string) (*model.EnhancedBidPrivate, error) {
+	return evaluateAs[*model.EnhancedBidPrivate](c, ctx, ContractEnhanced, "GetEnhancedBidPrivate", tenderID, bidID)
+}
+
+// ListBidsPublic returns the public references of a tender's bids
+func (c *Client) ListBidsPublic(ctx context.Context, tenderID string) ([]*model.BidRef, error) {
+	return evaluateAs[[]*model.BidRef](c, ctx, ContractEnhanced, "ListBidsPublic", tenderID)
+}
+
+// GetBidRef returns the public reference of one bid
+func (c *Client) GetBidRef(ctx context.Context, tenderID, bidID string) (*model.BidRef, error) {
+	return evaluateAs[*model.BidRef](c, ctx, ContractEnhanced, "GetBidRef", tenderID, bidID)
+}
+
+// QueryBids returns one page of bid references matching filter
+func (c *Client) QueryBids(ctx context.Context, filter model.BidFilter) (*model.BidQueryResult, error) {
+	arg, err := marshalArg("QueryBids", filter)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateAs[*model.BidQueryResult](c, ctx, ContractEnhanced, "QueryBids", arg)
+}
+
+// CheckPrequalification checks a vendor against a tender's bid requirements
+func (c *Client) CheckPrequalification(ctx context.Context, tenderID, vendorID string) (*model.PrequalificationReport, error) {
+	return evaluateAs[*model.PrequalificationReport](c, ctx, ContractEnhanced, "CheckPrequalification", tenderID, vendorID)
+}
+
+// EvaluateBids scores every bid of a closed tender against its evaluation criteria
+func (c *Client) EvaluateBids(ctx context.Context, tenderID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "EvaluateBids", opts, tenderID)
+	return err
+}
+
+// ListEvaluations returns the evaluation of every bid of a tender
+func (c *Client) ListEvaluations(ctx context.Context, tenderID string) ([]*model.Evaluation, error) {
+	return evaluateAs[[]*model.Evaluation](c, ctx, ContractEnhanced, "ListEvaluations", tenderID)
+}
+
+// AwardTender awards a tender to a bid; pass WithSignature when awards must be signed
+func (c *Client) AwardTender(ctx context.Context, tenderID, bidID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "AwardTender", opts, tenderID, bidID)
+	return err
+}
+
+// AwardBestBid awards a tender to its highest scoring bid
+func (c *Client) AwardBestBid(ctx context.Context, tenderID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "AwardBestBid", opts, tenderID)
+	return err
+}
+
+// VerifyBidIntegrity recomputes a stored bid's hash and compares it with its public reference
+func (c *Client) VerifyBidIntegrity(ctx context.Context, tenderID, bidID string) (*model.BidIntegrityReport, error) {
+	return evaluateAs[*model.BidIntegrityReport](c, ctx, ContractEnhanced, "VerifyBidIntegrity", tenderID, bidID)
+}
+
+// PlaceAuctionBid places a reverse auction offer
+func (c *Client) PlaceAuctionBid(ctx context.Context, tenderID, bidID string, offer *model.AuctionOffer, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransientJSON(TransientAuctionBid, offer)}, opts...)
+	_, err := c.submit(ctx, ContractEnhanced, "PlaceAuctionBid", opts, tenderID, bidID)
+	return err
+}
+
+// GetAuctionState returns the public state of a reverse auction
+func (c *Client) GetAuctionState(ctx context.Context, tenderID string) (*model.AuctionState, error) {
+	return evaluateAs[*model.AuctionState](c, ctx, ContractEnhanced, "GetAuctionState", tenderID)
+}
+
+// GetMyAuctionRank returns the caller's rank in a reverse auction
+func (c *Client) GetMyAuctionRank(ctx context.Context, tenderID string) (*model.AuctionRank, error) {
+	return evaluateAs[*model.AuctionRank](c, ctx, ContractEnhanced, "GetMyAuctionRank", tenderID)
+}
+
+// GetAuctionResult returns the outcome of a finished reverse auction
+func (c *Client) GetAuctionResult(ctx context.Context, tenderID string) (*model.AuctionResult, error) {
+	return evaluateAs[*model.AuctionResult](c, ctx, ContractEnhanced, "GetAuctionResult", tenderID)
+}
+
+// ListLotEvaluations returns per-lot evaluations, optionally for one lot
+func (c *Client) ListLotEvaluations(ctx context.Context, tenderID, lotID string) ([]*model.LotEvaluation, error) {
+	return evaluateAs[[]*model.LotEvaluation](c, ctx, ContractEnhanced, "ListLotEvaluations", tenderID, lotID)
+}
+
+// AwardLot awards a single lot to a bid
+func (c *Client) AwardLot(ctx context.Context, tenderID, lotID, bidID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "AwardLot", opts, tenderID, lotID, bidID)
+	return err
+}
+
+// AwardLots awards every lot using the tender's lot award mode
+func (c *Client) AwardLots(ctx context.Context, tenderID string, opts ...CallOption) (*model.LotAwardResult, error) {
+	return submitAs[*model.LotAwardResult](c, ctx, ContractEnhanced, "AwardLots", opts, tenderID)
+}
@@ -0,0 +1,158 @@
+// Package tenderclient is a typed Go client for the tendercc chaincode.
+//
+// Each chaincode transaction has a method that takes and returns the chaincode's
+// own types from package tendercc/model, packs confidential payloads into the
+// transient map, and maps chaincode error messages to the Err* kinds in this package.
+package tenderclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"tendercc/model"
+)
+
+// Transient map keys read by the chaincode
+const (
+	TransientBid          = "bid"
+	TransientMilestone    = "milestone"
+	TransientAuctionBid   = "auctionBid"
+	TransientEncryptedBid = "encryptedBid"
+	TransientCallOffBid   = "callOffBid"
+	TransientSignature    = "signature"
+)
+
+// Client calls tendercc transactions
+type Client struct {
+	transport Transport
+}
+
+// New creates a client over an existing transport
+func New(transport Transport) *Client {
+	return &Client{transport: transport}
+}
+
+// Close releases the underlying connection
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
+
+// CallOption adjusts a single transaction
+type CallOption func(*Request) error
+
+// WithEndorsingOrgs targets endorsement at the given MSP IDs. Transactions that
+// write private data should name organisations that are members of the collection.
+func WithEndorsingOrgs(mspIDs ...string) CallOption {
+	return func(r *Request) error {
+		r.EndorsingOrgs = append(r.EndorsingOrgs, mspIDs...)
+		return nil
+	}
+}
+
+// WithSignature attaches a detached signature for transactions that verify one
+func WithSignature(sig model.DetachedSignature) CallOption {
+	return WithTransientJSON(TransientSignature, sig)
+}
+
+// WithTransient adds raw bytes to the transient map
+func WithTransient(key string, data []byte) CallOption {
+	return func(r *Request) error {
+		if r.Transient == nil {
+			r.Transient = make(map[string][]byte)
+		}
+		r.Transient[key] = data
+		return nil
+	}
+}
+
+// WithTransientJSON adds a JSON encoded value to the transient map
+func WithTransientJSON(key string, value interface{}) CallOption {
+	return func(r *Request) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode transient %s: %w", key, err)
+		}
+		if r.Transient == nil {
+			r.Transient = make(map[string][]byte)
+		}
+		r.Transient[key] = data
+		return nil
+	}
+}
+
+// Metadata returns the chaincode's contract metadata: its contracts, transactions
+// and the JSON schemas of the types they take and return
+func (c *Client) Metadata(ctx context.Context) ([]byte, error) {
+	return c.evaluate(ctx, ContractSystem, "GetMetadata")
+}
+
+func (c *Client) request(contract, function string, args []string, opts []CallOption) (*Request, error) {
+	req := &Request{Contract: contract, Function: function, Args: args}
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return nil, &Error{Function: function, Message: err.Error(), Kind: ErrInvalidArgument, Err: err}
+		}
+	}
+	return req, nil
+}
+
+func (c *Client) submit(ctx context.Context, contract, function string, opts []CallOption, args ...string) ([]byte, error) {
+	req, err := c.request(contract, function, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.transport.Submit(ctx, req)
+	return result, wrapError(function, err)
+}
+
+func (c *Client) evaluate(ctx context.Context, contract, function string, args ...string) ([]byte, error) {
+	req, err := c.request(contract, function, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.transport.Evaluate(ctx, req)
+	return result, wrapError(function, err)
+}
+
+// decode unmarshals a transaction result into out
+func decode(function string, data []byte, out interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return &Error{Function: function, Message: fmt.Sprintf("invalid result: %v", err), Kind: ErrChaincode, Err: err}
+	}
+	return nil
+}
+
+func evaluateAs[T any](c *Client, ctx context.Context, contract, function string, args ...string) (T, error) {
+	var out T
+	data, err := c.evaluate(ctx, contract, function, args...)
+	if err != nil {
+		return out, err
+	}
+	return out, decode(function, data, &out)
+}
+
+func submitAs[T any](c *Client, ctx context.Context, contract, function string, opts []CallOption, args ...string) (T, error) {
+	var out T
+	data, err := c.submit(ctx, contract, function, opts, args...)
+	if err != nil {
+		return out, err
+	}
+	return out, decode(function, data, &out)
+}
+
+func marshalArg(function string, v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", &Error{Function: function, Message: err.Error(), Kind: ErrInvalidArgument, Err: err}
+	}
+	return string(data), nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,23 @@
+//go:build fake
+
+package main
+
+import (
+	"errors"
+
+	"tenderclient"
+	"tenderclient/api"
+	"tenderclient/fakeledger"
+)
+
+func gatewayClients(tenderclient.Config) (api.Clients, func() error, error) {
+	return nil, nil, errors.New("built with -tags fake; run with -fake")
+}
+
+// fakeClients serves every identity from one in-memory ledger
+func fakeClients(mspID string) (api.Clients, error) {
+	ledger := fakeledger.New()
+	return api.ClientsFunc(func(identity string) (*tenderclient.Client, error) {
+		return ledger.Client(mspID, identity), nil
+	}), nil
+}
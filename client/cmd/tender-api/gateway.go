@@ -0,0 +1,21 @@
+//go:build !fake
+
+package main
+
+import (
+	"errors"
+
+	"tenderclient"
+	"tenderclient/api"
+	"tenderclient/gateway"
+)
+
+// gatewayClients connects each wallet identity through the gateway on first use
+func gatewayClients(cfg tenderclient.Config) (api.Clients, func() error, error) {
+	clients := gateway.NewClients(cfg)
+	return clients, clients.Close, nil
+}
+
+func fakeClients(mspID string) (api.Clients, error) {
+	return nil, errors.New("-fake needs a build with -tags fake")
+}
@@ -0,0 +1,124 @@
+// Command tender-api serves the tendercc REST API.
+//
+//	tender-api -config tender-api.json
+//
+// The configuration names the gateway peer and wallet and maps bearer tokens to
+// wallet identities:
+//
+//	{
+//	  "listen": ":8080",
+//	  "gateway": {"peerEndpoint": "localhost:7051", "walletPath": "wallets/org0.example.com", ...},
+//	  "tokens": {"<token>": "Admin"},
+//	  "metadataIdentity": "Admin"
+//	}
+//
+// With -fake the API runs against an in-memory ledger running the chaincode instead
+// of the gateway, with every identity in the MSP given by -fake-msp. The chaincode and
+// the gateway cannot share a binary, so -fake needs a build with -tags fake, which in
+// turn cannot reach a gateway.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"tenderclient"
+	"tenderclient/api"
+)
+
+type config struct {
+	Listen           string              `json:"listen"`
+	Gateway          tenderclient.Config `json:"gateway"`
+	Tokens           map[string]string   `json:"tokens"` // bearer token -> wallet identity
+	MetadataIdentity string              `json:"metadataIdentity"`
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = ":8080"
+	}
+	if len(cfg.Tokens) == 0 {
+		return nil, fmt.Errorf("config %s defines no tokens", path)
+	}
+	if cfg.MetadataIdentity == "" {
+		for _, identity := range cfg.Tokens {
+			cfg.MetadataIdentity = identity
+			break
+		}
+	}
+	return &cfg, nil
+}
+
+func main() {
+	configPath := flag.String("config", "tender-api.json", "configuration file")
+	fake := flag.Bool("fake", false, "serve an in-memory ledger instead of connecting to the gateway")
+	fakeMSP := flag.String("fake-msp", "org0-example-com", "MSP ID of every identity on the in-memory ledger")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var clients api.Clients
+	if *fake {
+		if clients, err = fakeClients(*fakeMSP); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		// Fail at startup rather than on the first request of a misconfigured identity
+		if cfg.Gateway.WalletPath != "" {
+			for _, identity := range cfg.Tokens {
+				if _, err := tenderclient.LoadWalletIdentity(cfg.Gateway.WalletPath, identity); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+		var closeClients func() error
+		if clients, closeClients, err = gatewayClients(cfg.Gateway); err != nil {
+			log.Fatal(err)
+		}
+		defer closeClients()
+	}
+
+	srv := &http.Server{
+		Addr: cfg.Listen,
+		Handler: api.NewServer(api.Options{
+			Auth:             api.TokenAuth(cfg.Tokens),
+			Clients:          clients,
+			MetadataIdentity: cfg.MetadataIdentity,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("tender-api listening on %s", cfg.Listen)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build fake
+
+package main
+
+import (
+	"errors"
+
+	"tenderclient"
+	"tenderclient/fakeledger"
+)
+
+func dialGateway(tenderclient.Config) (tenderclient.Transport, error) {
+	return nil, errors.New("built with -tags fake; only -fake runs are supported")
+}
+
+// newFakeLedger returns a function that opens connections to one in-memory ledger
+func newFakeLedger() (func() tenderclient.Transport, error) {
+	ledger := fakeledger.New()
+	return func() tenderclient.Transport {
+		return ledger.Transport("org0-example-com", "bench")
+	}, nil
+}
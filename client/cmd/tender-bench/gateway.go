@@ -0,0 +1,16 @@
+//go:build !fake
+
+package main
+
+import (
+	"errors"
+
+	"tenderclient"
+	"tenderclient/gateway"
+)
+
+var dialGateway = gateway.Dial
+
+func newFakeLedger() (func() tenderclient.Transport, error) {
+	return nil, errors.New("-fake needs a build with -tags fake")
+}
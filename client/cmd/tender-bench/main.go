@@ -0,0 +1,270 @@
+// Command tender-bench measures how fast a tendercc network takes tenders and bids.
+//
+//	tender-bench -config tenderctl.json -tenders 20 -bids 50 -rate 40 -concurrency 32
+//	tender-bench -config tenderctl.json -connections 4 -endorse org0-example-com,org1-example-com -o json > bench.json
+//	tender-bench -fake -tenders 5 -bids 200
+//
+// It creates and publishes -tenders synthetic tenders, then submits -bids bids to
+// each of them, at most -rate transactions per second from -concurrency workers.
+// Every submit is timed in its endorsement, ordering and commit phases. For each
+// operation the results give valid commits, transactions invalidated at commit
+// (MVCC_READ_CONFLICT, PHANTOM_READ_CONFLICT), transactions rejected before ordering,
+// throughput and latency percentiles, as a table or as JSON with -o json.
+//
+// The gateway connection is read from -config (tenderclient.Config as JSON, the
+// tenderctl format). With -fake the run uses an in-memory ledger running the
+// chaincode, which validates read sets like a peer; it checks the workload, not the
+// network. The chaincode and the gateway cannot share a binary, so -fake needs a
+// build with -tags fake, which in turn cannot reach a gateway. Document IDs
+// start with -prefix, which defaults to a fresh BENCH-<unix time> on every run.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"tenderclient"
+)
+
+// Parameters are the workload settings of a run
+type Parameters struct {
+	Tenders       int     `json:"tenders"`
+	BidsPerTender int     `json:"bidsPerTender"`
+	Rate          float64 `json:"rate"` // transactions per second; 0 is unlimited
+	Concurrency   int     `json:"concurrency"`
+	Connections   int     `json:"connections"`
+	BidWindow     string  `json:"bidWindow"`
+	Prefix        string  `json:"prefix"`
+}
+
+// Results is the machine-readable outcome of a run
+type Results struct {
+	StartedAt  string        `json:"startedAt"`
+	Target     string        `json:"target"` // gateway peer, or "fake"
+	Parameters Parameters    `json:"parameters"`
+	Phases     []PhaseResult `json:"phases"`
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr, dialGateway); err != nil {
+		fmt.Fprintln(os.Stderr, "tender-bench:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, dial func(tenderclient.Config) (tenderclient.Transport, error)) error {
+	fs := flag.NewFlagSet("tender-bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", os.Getenv("TENDERCTL_CONFIG"), "gateway configuration file (tenderclient.Config as JSON)")
+	fake := fs.Bool("fake", false, "run against an in-memory ledger instead of the gateway")
+	var p Parameters
+	fs.IntVar(&p.Tenders, "tenders", 10, "synthetic tenders to create and publish")
+	fs.IntVar(&p.BidsPerTender, "bids", 20, "bids to submit to each tender")
+	fs.Float64Var(&p.Rate, "rate", 0, "most transactions per second to send (0 is unlimited)")
+	fs.IntVar(&p.Concurrency, "concurrency", 16, "transactions in flight at once")
+	fs.IntVar(&p.Connections, "connections", 1, "gateway connections to spread the load over")
+	window := fs.Duration("bid-window", 24*time.Hour, "time from now to the synthetic tenders' bid deadline")
+	fs.StringVar(&p.Prefix, "prefix", fmt.Sprintf("BENCH-%d", time.Now().Unix()), "prefix of tender, bid and contractor IDs")
+	endorse := fs.String("endorse", "", "comma separated MSP IDs to target for endorsement")
+	output := fs.String("o", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output != "text" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	if p.Tenders < 1 || p.BidsPerTender < 0 || p.Concurrency < 1 || p.Connections < 1 {
+		return fmt.Errorf("-tenders, -concurrency and -connections must be at least 1 and -bids at least 0")
+	}
+	p.BidWindow = window.String()
+
+	res := &Results{StartedAt: time.Now().UTC().Format(time.RFC3339), Parameters: p}
+	var clients []*tenderclient.Client
+	if *fake {
+		res.Target = "fake"
+		connect, err := newFakeLedger()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < p.Connections; i++ {
+			t, err := timed(connect())
+			if err != nil {
+				return err
+			}
+			clients = append(clients, tenderclient.New(t))
+		}
+	} else {
+		cfg, err := loadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+		res.Target = cfg.PeerEndpoint
+		for i := 0; i < p.Connections; i++ {
+			t, err := dial(cfg)
+			if err == nil {
+				t, err = timed(t)
+			}
+			if err != nil {
+				return err
+			}
+			clients = append(clients, tenderclient.New(t))
+		}
+	}
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+	var opts []tenderclient.CallOption
+	if *endorse != "" {
+		opts = append(opts, tenderclient.WithEndorsingOrgs(strings.Split(*endorse, ",")...))
+	}
+
+	b := &bench{params: p, clients: clients, progress: stderr}
+	start := time.Now()
+	created := b.phase(ctx, res, "CreateEnhancedTender", p.Tenders, func(ctx context.Context, c *tenderclient.Client, i int) error {
+		return c.CreateEnhancedTender(ctx, syntheticTender(tenderID(p.Prefix, i), start, *window), opts...)
+	})
+	published := b.phase(ctx, res, "PublishTender", len(created), func(ctx context.Context, c *tenderclient.Client, i int) error {
+		return c.PublishTender(ctx, tenderID(p.Prefix, created[i]), opts...)
+	})
+	// Bids go out tender by tender, so a tender's bids are in flight together
+	b.phase(ctx, res, "SubmitEnhancedBid", len(published)*p.BidsPerTender, func(ctx context.Context, c *tenderclient.Client, i int) error {
+		tender := tenderID(p.Prefix, created[published[i/p.BidsPerTender]])
+		n := i % p.BidsPerTender
+		bid := syntheticBid(tender, bidID(p.Prefix, n), contractorID(p.Prefix, n), n, start)
+		return c.SubmitEnhancedBid(ctx, tender, bid.BidID, bid, opts...)
+	})
+	if err := ctx.Err(); err != nil {
+		fmt.Fprintln(stderr, "interrupted; results cover the transactions sent")
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	return printText(stdout, res)
+}
+
+// loadConfig reads the gateway configuration
+func loadConfig(path string) (tenderclient.Config, error) {
+	var cfg tenderclient.Config
+	if path == "" {
+		return cfg, fmt.Errorf("no gateway configured; use -config or -fake")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// bench sends the phases of a run
+type bench struct {
+	params   Parameters
+	clients  []*tenderclient.Client
+	progress io.Writer
+}
+
+// phase sends n transactions, the i-th built by call, and appends their summary to res.
+// It returns the indexes of the transactions that committed successfully, in order.
+func (b *bench) phase(ctx context.Context, res *Results, operation string, n int, call func(context.Context, *tenderclient.Client, int) error) []int {
+	samples := make([]sample, n)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < b.params.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				s := &samples[i]
+				s.err = call(withTiming(ctx, &s.timing), b.clients[i%len(b.clients)], i)
+			}
+		}()
+	}
+
+	var tick <-chan time.Time
+	if b.params.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / b.params.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	start := time.Now()
+	sent := 0
+send:
+	for ; sent < n; sent++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				break send
+			}
+		}
+		select {
+		case jobs <- sent:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result := summarise(operation, samples[:sent], elapsed)
+	res.Phases = append(res.Phases, result)
+	fmt.Fprintf(b.progress, "%s: %d sent, %d valid, %d conflicts, %d errors in %s\n",
+		operation, result.Submitted, result.Succeeded, count(result.Conflicts), count(result.Errors), elapsed.Round(time.Millisecond))
+
+	var ok []int
+	for i, s := range samples[:sent] {
+		if s.err == nil {
+			ok = append(ok, i)
+		}
+	}
+	return ok
+}
+
+type timingKey struct{}
+
+// withTiming asks the timed transport to record the submit's phases in t
+func withTiming(ctx context.Context, t *tenderclient.SubmitTiming) context.Context {
+	return context.WithValue(ctx, timingKey{}, t)
+}
+
+// timedTransport submits through TimedSubmitter and hands the timing to the caller
+// through the context, so the typed Client methods can be used unchanged
+type timedTransport struct {
+	tenderclient.Transport
+	timed tenderclient.TimedSubmitter
+}
+
+func timed(t tenderclient.Transport) (tenderclient.Transport, error) {
+	ts, ok := t.(tenderclient.TimedSubmitter)
+	if !ok {
+		t.Close()
+		return nil, fmt.Errorf("transport %T does not time submits", t)
+	}
+	return &timedTransport{Transport: t, timed: ts}, nil
+}
+
+func (t *timedTransport) Submit(ctx context.Context, req *tenderclient.Request) ([]byte, error) {
+	result, _, timing, err := t.timed.SubmitTimed(ctx, req)
+	if slot, ok := ctx.Value(timingKey{}).(*tenderclient.SubmitTiming); ok {
+		*slot = timing
+	}
+	return result, err
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"tenderclient"
+)
+
+// sample is the outcome of one submitted transaction
+type sample struct {
+	timing tenderclient.SubmitTiming
+	err    error
+}
+
+// Latency summarises one latency distribution in milliseconds
+type Latency struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// PhaseResult is the summary of one operation over the whole run. Latencies cover the
+// transactions that reached a commit status, whether valid or invalidated by a conflict.
+type PhaseResult struct {
+	Operation  string             `json:"operation"`
+	Submitted  int                `json:"submitted"`
+	Succeeded  int                `json:"succeeded"`
+	Conflicts  map[string]int     `json:"conflicts"` // validation code -> transactions
+	Errors     map[string]int     `json:"errors"`    // endorsement or ordering failures by message
+	DurationMS float64            `json:"durationMs"`
+	Throughput float64            `json:"throughputTps"` // valid commits per second
+	Latency    map[string]Latency `json:"latencyMs"`     // endorse, order, commit, total
+}
+
+// summarise folds the samples of one phase into its result
+func summarise(operation string, samples []sample, elapsed time.Duration) PhaseResult {
+	res := PhaseResult{
+		Operation:  operation,
+		Submitted:  len(samples),
+		Conflicts:  map[string]int{},
+		Errors:     map[string]int{},
+		DurationMS: ms(elapsed),
+	}
+	var endorse, order, commit, total []time.Duration
+	for _, s := range samples {
+		var commitErr *tenderclient.CommitError
+		switch {
+		case s.err == nil:
+			res.Succeeded++
+		case errors.As(s.err, &commitErr):
+			res.Conflicts[commitErr.Code]++
+		default:
+			res.Errors[errorKey(s.err)]++
+			continue
+		}
+		endorse = append(endorse, s.timing.Endorse)
+		order = append(order, s.timing.Order)
+		commit = append(commit, s.timing.Commit)
+		total = append(total, s.timing.Total())
+	}
+	if elapsed > 0 {
+		res.Throughput = float64(res.Succeeded) / elapsed.Seconds()
+	}
+	res.Latency = map[string]Latency{
+		"endorse": distribution(endorse),
+		"order":   distribution(order),
+		"commit":  distribution(commit),
+		"total":   distribution(total),
+	}
+	return res
+}
+
+// errorKey groups failures by kind and chaincode message
+func errorKey(err error) string {
+	var e *tenderclient.Error
+	if errors.As(err, &e) {
+		return e.Kind.Error() + ": " + e.Message
+	}
+	return err.Error()
+}
+
+// distribution computes nearest-rank percentiles
+func distribution(values []time.Duration) Latency {
+	if len(values) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		return ms(sorted[max(i, 0)])
+	}
+	var sum time.Duration
+	for _, v := range sorted {
+		sum += v
+	}
+	return Latency{
+		Count: len(sorted),
+		Mean:  ms(sum / time.Duration(len(sorted))),
+		P50:   rank(50),
+		P90:   rank(90),
+		P95:   rank(95),
+		P99:   rank(99),
+		Max:   ms(sorted[len(sorted)-1]),
+	}
+}
+
+func ms(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
+
+// printText writes the results as tables: throughput, latency percentiles, then failures
+func printText(w io.Writer, res *Results) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "tenders %d, bids per tender %d, rate %s, concurrency %d, prefix %s\n\n",
+		res.Parameters.Tenders, res.Parameters.BidsPerTender, rateLabel(res.Parameters.Rate), res.Parameters.Concurrency, res.Parameters.Prefix)
+	fmt.Fprintln(tw, "OPERATION\tSUBMITTED\tVALID\tCONFLICTS\tERRORS\tTPS\tENDORSE p50/p95/p99 ms\tCOMMIT p50/p95/p99 ms\tTOTAL p50/p95/p99 ms")
+	for _, p := range res.Phases {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f\t%s\t%s\t%s\n", p.Operation, p.Submitted, p.Succeeded,
+			count(p.Conflicts), count(p.Errors), p.Throughput,
+			percentiles(p.Latency["endorse"]), percentiles(p.Latency["commit"]), percentiles(p.Latency["total"]))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, p := range res.Phases {
+		for _, code := range sortedKeys(p.Conflicts) {
+			fmt.Fprintf(w, "%s: %d x %s\n", p.Operation, p.Conflicts[code], code)
+		}
+		for _, msg := range sortedKeys(p.Errors) {
+			fmt.Fprintf(w, "%s: %d x %s\n", p.Operation, p.Errors[msg], msg)
+		}
+	}
+	return nil
+}
+
+func percentiles(l Latency) string {
+	if l.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f/%.1f/%.1f", l.P50, l.P95, l.P99)
+}
+
+func rateLabel(rate float64) string {
+	if rate <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%g tx/s", rate)
+}
+
+func count(m map[string]int) int {
+	n := 0
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"tendercc/model"
+)
+
+// tenderID and bidID name the synthetic documents of one run
+func tenderID(prefix string, i int) string { return fmt.Sprintf("%s-T%04d", prefix, i) }
+
+func bidID(prefix string, bid int) string { return fmt.Sprintf("%s-B%04d", prefix, bid) }
+
+func contractorID(prefix string, bid int) string { return fmt.Sprintf("%s-C%04d", prefix, bid) }
+
+// syntheticTender is a small tender that passes the chaincode's validation, with its
+// bid deadline window after now
+func syntheticTender(id string, now time.Time, window time.Duration) *model.EnhancedTender {
+	at := func(d time.Duration) string { return now.Add(d).UTC().Format(time.RFC3339) }
+	return &model.EnhancedTender{
+		ID: id,
+		ProjectScope: model.ProjectScope{
+			Description:      "Benchmark tender " + id,
+			Objectives:       []string{"Measure tendercc throughput"},
+			Deliverables:     []string{"Load report"},
+			TechnicalSpecs:   []string{},
+			QualityStandards: []string{},
+			Budget: model.Budget{
+				Currency:     "USD",
+				EstimatedMin: 100000,
+				EstimatedMax: 500000,
+				PaymentTerms: "Net 30",
+				PaymentSchedule: []model.PaymentMilestone{
+					{Name: "Mobilisation", Percentage: 20, Description: "On signing"},
+					{Name: "Completion", Percentage: 80, Description: "On handover"},
+				},
+			},
+		},
+		Deadlines: model.TenderDeadlines{
+			QuestionsDeadline:     at(window / 2),
+			BidSubmissionDeadline: at(window),
+			ProjectStartDate:      at(window + 30*24*time.Hour),
+			ProjectEndDate:        at(window + 210*24*time.Hour),
+		},
+		EvaluationCriteria: []model.EvalCriterion{
+			{ID: "PRICE", Name: "Price", Weight: 60, Type: "QUANTITATIVE", Description: "Total amount", ScoringMethod: "LOWEST_PRICE"},
+			{ID: "TECH", Name: "Technical", Weight: 40, Type: "QUALITATIVE", Description: "Methodology", ScoringMethod: "HIGHEST_SCORE"},
+		},
+		OwnerDetails: model.OwnerInfo{OrganizationName: "Benchmark Authority"},
+		Status:       "DRAFT",
+	}
+}
+
+// syntheticBid is bid number n of a tender; amounts vary so evaluations have a spread
+func syntheticBid(tenderID, bidID, contractorID string, n int, now time.Time) *model.EnhancedBidPrivate {
+	amount := 150000 + float64(n%97)*3000
+	return &model.EnhancedBidPrivate{
+		TenderID:     tenderID,
+		BidID:        bidID,
+		ContractorID: contractorID,
+		TotalAmount:  amount,
+		Currency:     "USD",
+		TechnicalProposal: model.TechnicalProposal{
+			Methodology: "Synthetic benchmark methodology",
+		},
+		FinancialProposal: model.FinancialProposal{
+			BreakdownByPhase: []model.PhaseCosting{{Phase: "Execution", Cost: amount}},
+			PriceValidity:    90,
+		},
+		ComplianceChecklist: map[string]bool{"TAX_CLEARANCE": true},
+		DocumentHashes:      map[string]string{},
+		ValidUntil:          now.Add(90 * 24 * time.Hour).UTC().Format(time.RFC3339),
+	}
+}
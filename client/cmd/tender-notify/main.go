@@ -0,0 +1,75 @@
+// Command tender-notify sends email, webhook and file notifications for tendercc
+// events and deadline reminders.
+//
+//	tender-notify -config notify.json
+//
+// Example configuration:
+//
+//	{
+//	  "gateway": {"peerEndpoint": "localhost:7051", ...},
+//	  "stateFile": "notify-state.json",
+//	  "channels": {
+//	    "mail": {"type": "smtp", "addr": "smtp.example.com:587", "from": "tenders@example.com", "username": "...", "password": "..."},
+//	    "erp":  {"type": "webhook", "url": "https://erp.example.com/hooks/tenders", "secret": "...", "maxAttempts": 5},
+//	    "log":  {"type": "file", "path": "notifications.jsonl"}
+//	  },
+//	  "subscriptions": [
+//	    {"id": "techcorp", "contractorId": "TECHCORP-SOLUTIONS", "channel": "mail", "recipients": ["bids@techcorp.example"]},
+//	    {"id": "erp-rfq-001", "tenderId": "RFQ-2025-INFRASTRUCTURE-001", "channel": "erp"}
+//	  ],
+//	  "reminderHours": [48, 24]
+//	}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tenderclient"
+	"tenderclient/gateway"
+	"tenderclient/notify"
+)
+
+type config struct {
+	Gateway tenderclient.Config `json:"gateway"`
+	notify.Config
+}
+
+func main() {
+	configPath := flag.String("config", "notify.json", "configuration file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		log.Fatalf("invalid config %s: %v", *configPath, err)
+	}
+
+	channels, err := notify.OpenChannels(cfg.Channels)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := gateway.Connect(cfg.Gateway)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+	n, err := notify.New(client, cfg.Config, channels)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := n.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,90 @@
+// Command tender-projector follows the tendercc chaincode and block events and
+// projects them into a SQLite database for dashboards, search and reporting.
+//
+//	tender-projector -config projector.json            # follow from the stored checkpoint
+//	tender-projector -config projector.json -rebuild   # empty the database and replay from genesis
+//	tender-projector -db tender.db -search 'infrastructure AND awarded'
+//
+// The configuration holds the gateway settings and the database path:
+//
+//	{"gateway": {"peerEndpoint": "localhost:7051", ...}, "database": "tender.db"}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tenderclient"
+	"tenderclient/gateway"
+	"tenderclient/projector"
+)
+
+type config struct {
+	Gateway  tenderclient.Config `json:"gateway"`
+	Database string              `json:"database"`
+}
+
+func main() {
+	configPath := flag.String("config", "projector.json", "configuration file")
+	dbPath := flag.String("db", "", "database path, overriding the configuration")
+	rebuild := flag.Bool("rebuild", false, "empty the database and replay every event from the genesis block")
+	search := flag.String("search", "", "print the events matching a full-text query and exit")
+	flag.Parse()
+
+	var cfg config
+	if data, err := os.ReadFile(*configPath); err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			log.Fatalf("invalid config %s: %v", *configPath, err)
+		}
+	} else if *search == "" || *dbPath == "" {
+		log.Fatal(err)
+	}
+	if *dbPath != "" {
+		cfg.Database = *dbPath
+	}
+	if cfg.Database == "" {
+		cfg.Database = "tender.db"
+	}
+
+	store, err := projector.Open(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *search != "" {
+		results, err := store.Search(ctx, *search, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, r := range results {
+			fmt.Printf("%d\t%s\t%s\t%s\t%s\n", r.BlockNumber, r.TxTime, r.Name, r.TenderID, r.Snippet)
+		}
+		return
+	}
+
+	client, err := gateway.Connect(cfg.Gateway)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	p := projector.New(client, store)
+	if *rebuild {
+		err = p.Rebuild(ctx)
+	} else {
+		err = p.Run(ctx)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
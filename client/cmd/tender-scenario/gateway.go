@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tendercc/events"
+	"tendercc/scenario"
+	"tenderclient"
+)
+
+// gatewayInvoker runs scenario calls on a live network, one gateway connection per actor
+type gatewayInvoker struct {
+	base      tenderclient.Config
+	actors    map[string]json.RawMessage // per-actor overrides of base
+	dial      func(tenderclient.Config) (tenderclient.Transport, error)
+	maxWait   time.Duration
+	eventWait time.Duration
+
+	transports map[string]tenderclient.Transport
+}
+
+// Now is the wall clock: the peers' transaction timestamps cannot be set
+func (g *gatewayInvoker) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Advance waits for short clock steps and refuses long ones
+func (g *gatewayInvoker) Advance(ctx context.Context, d time.Duration) error {
+	if d > g.maxWait {
+		return fmt.Errorf("cannot move a live network's clock by %s (waiting is limited to %s); run the scenario in process or shorten its deadlines", d, g.maxWait)
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *gatewayInvoker) transport(call *scenario.Call) (tenderclient.Transport, error) {
+	if t, ok := g.transports[call.Actor]; ok {
+		return t, nil
+	}
+	cfg := g.base
+	raw, ok := g.actors[call.Actor]
+	if !ok {
+		return nil, fmt.Errorf("no gateway identity configured for actor %s", call.Actor)
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid identity for actor %s: %v", call.Actor, err)
+	}
+	if cfg.MSPID != "" && cfg.MSPID != call.Identity.MSP {
+		return nil, fmt.Errorf("actor %s is %s in the scenario but signs as %s", call.Actor, call.Identity.MSP, cfg.MSPID)
+	}
+	t, err := g.dial(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("actor %s: %v", call.Actor, err)
+	}
+	g.transports[call.Actor] = t
+	return t, nil
+}
+
+func (g *gatewayInvoker) Invoke(ctx context.Context, call *scenario.Call) (*scenario.Result, error) {
+	t, err := g.transport(call)
+	if err != nil {
+		return nil, err
+	}
+	req := &tenderclient.Request{Contract: call.Contract, Function: call.Function, Args: call.Args, Transient: call.Transient}
+	if !call.Submit {
+		payload, err := t.Evaluate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return &scenario.Result{Payload: payload}, nil
+	}
+
+	reporter, ok := t.(tenderclient.CommitReporter)
+	if !ok {
+		return nil, fmt.Errorf("transport %T does not report commits", t)
+	}
+	payload, commit, err := reporter.SubmitCommit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := g.events(ctx, t, commit)
+	if err != nil {
+		return nil, err
+	}
+	return &scenario.Result{Payload: payload, Events: envs}, nil
+}
+
+// events reads the chaincode event of a committed transaction. Only blocks with
+// chaincode events are delivered, so a transaction that emitted nothing is detected
+// by a later block or by eventWait passing.
+func (g *gatewayInvoker) events(ctx context.Context, t tenderclient.Transport, commit *tenderclient.Commit) ([]events.Envelope, error) {
+	source, ok := t.(tenderclient.EventSource)
+	if !ok {
+		return nil, fmt.Errorf("transport %T does not deliver events", t)
+	}
+	ctx, cancel := context.WithTimeout(ctx, g.eventWait)
+	defer cancel()
+	stream, err := source.ChaincodeEvents(ctx, tenderclient.Checkpoint{BlockNumber: commit.BlockNumber})
+	if err != nil {
+		return nil, err
+	}
+	for ev := range stream {
+		if ev.BlockNumber > commit.BlockNumber {
+			break
+		}
+		if ev.TransactionID == commit.TransactionID {
+			return ev.Envelopes()
+		}
+	}
+	return nil, nil
+}
+
+func (g *gatewayInvoker) Close() {
+	for _, t := range g.transports {
+		t.Close()
+	}
+}
@@ -0,0 +1,151 @@
+// Command tender-scenario runs YAML procurement scenarios (package tendercc/scenario)
+// against a live network through the Fabric Gateway.
+//
+//	tender-scenario -config scenario-gateway.json scenarios/civil-flow.yaml
+//	tender-scenario -config scenario-gateway.json -var tender=RFQ-$(date +%s) -o json scenarios/*.yaml
+//
+// The configuration holds the gateway settings shared by every actor and, per
+// scenario actor, the identity fields that differ (tenderclient.Config as JSON):
+//
+//	{"gateway": {"peerEndpoint": "localhost:7051", "tlsCaCertPath": "...", "channel": "tenderchannel"},
+//	 "actors": {"buyer": {"mspId": "Org1MSP", "certPath": "...", "keyPath": "..."},
+//	            "techcorp": {"walletPath": "wallet", "identity": "techcorp"}}}
+//
+// A live network's clock cannot be moved, so ${start} is the time of the run,
+// `start:` in the scenario is ignored, and advance steps wait when they are no longer
+// than -max-wait and fail otherwise. Scenarios with deadlines days apart run in
+// process only (go test -run TestScenarios in chaincode/tendercc/go). Ledger IDs
+// must be fresh on every run; override the scenario's vars with -var.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"tendercc/scenario"
+	"tenderclient"
+	"tenderclient/gateway"
+)
+
+type config struct {
+	Gateway tenderclient.Config        `json:"gateway"`
+	Actors  map[string]json.RawMessage `json:"actors"`
+}
+
+// varFlags collects repeated -var name=value flags
+type varFlags map[string]string
+
+func (v varFlags) String() string { return "" }
+
+func (v varFlags) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value")
+	}
+	v[name] = value
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	passed, err := run(ctx, os.Args[1:], os.Stdout, gateway.Dial)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tender-scenario:", err)
+		os.Exit(2)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, dial func(tenderclient.Config) (tenderclient.Transport, error)) (bool, error) {
+	fs := flag.NewFlagSet("tender-scenario", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	configPath := fs.String("config", "scenario-gateway.json", "gateway and actor identity configuration")
+	output := fs.String("o", "text", "output format: text or json")
+	maxWait := fs.Duration("max-wait", 2*time.Minute, "longest clock advance to wait out")
+	eventWait := fs.Duration("event-wait", 5*time.Second, "how long to wait for a committed transaction's event")
+	vars := varFlags{}
+	fs.Var(vars, "var", "override a scenario variable, name=value (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return false, err
+	}
+	if fs.NArg() == 0 {
+		return false, fmt.Errorf("no scenario files given")
+	}
+	if *output != "text" && *output != "json" {
+		return false, fmt.Errorf("unknown output format %s", *output)
+	}
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		return false, err
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return false, fmt.Errorf("invalid config %s: %v", *configPath, err)
+	}
+
+	var scenarios []*scenario.Scenario
+	for _, path := range fs.Args() {
+		sc, err := scenario.Load(path)
+		if err != nil {
+			return false, err
+		}
+		if sc.Vars == nil {
+			sc.Vars = make(map[string]string)
+		}
+		for k, v := range vars {
+			sc.Vars[k] = v
+		}
+		scenarios = append(scenarios, sc)
+	}
+
+	passed := true
+	var reports []*scenario.Report
+	for _, sc := range scenarios {
+		inv := &gatewayInvoker{
+			base:       cfg.Gateway,
+			actors:     cfg.Actors,
+			dial:       dial,
+			maxWait:    *maxWait,
+			eventWait:  *eventWait,
+			transports: make(map[string]tenderclient.Transport),
+		}
+		runner := &scenario.Runner{Invoker: inv}
+		if *output == "text" {
+			fmt.Fprintf(stdout, "=== %s\n", sc.Name)
+			runner.Log = stdout
+		}
+		report, err := runner.Run(ctx, sc)
+		inv.Close()
+		if err != nil {
+			return false, fmt.Errorf("%s: %v", sc.Name, err)
+		}
+		reports = append(reports, report)
+		passed = passed && report.Passed
+		if *output == "text" {
+			result := "PASS"
+			if !report.Passed {
+				result = "FAIL"
+			}
+			fmt.Fprintf(stdout, "--- %s %s\n", result, sc.Name)
+		}
+	}
+	if *output == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return false, err
+		}
+	}
+	return passed, nil
+}
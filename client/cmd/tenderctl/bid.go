@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+
+	"tendercc/model"
+)
+
+func init() {
+	register(
+		command{"bid", "submit", "-f bid.json [-tender T] [-bid B] [-signature sig.json]", "submit a private bid through the transient map", bidSubmit},
+		command{"bid", "list", "TENDER [-contractor C]", "list public bid references", bidList},
+		command{"bid", "get", "TENDER BID", "show a private bid (collection members only)", bidGet},
+		command{"bid", "verify", "TENDER BID", "check a private bid against its on-chain hash", bidVerify},
+	)
+}
+
+func bidSubmit(e *env, args []string) error {
+	fs := flag.NewFlagSet("bid submit", flag.ContinueOnError)
+	file := fs.String("f", "", "bid document (EnhancedBidPrivate JSON), - for stdin")
+	tender := fs.String("tender", "", "tender ID, overriding the document's tenderId")
+	bid := fs.String("bid", "", "bid ID, overriding the document's bidId")
+	sigFile := fs.String("signature", "", "detached BID signature over the submitted document (DetachedSignature JSON)")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	opts, err := signatureOpts(e, *sigFile)
+	if err != nil {
+		return err
+	}
+	p, err := readPayload(*file)
+	if err != nil {
+		return err
+	}
+	if err := p.set("tenderId", *tender); err != nil {
+		return err
+	}
+	if err := p.set("bidId", *bid); err != nil {
+		return err
+	}
+	tenderID, err := p.require("tenderId")
+	if err != nil {
+		return err
+	}
+	bidID, err := p.require("bidId")
+	if err != nil {
+		return err
+	}
+	if err := e.client.SubmitEnhancedBidJSON(e.ctx, tenderID, bidID, p.data, opts...); err != nil {
+		return err
+	}
+	ref, err := e.client.GetBidRef(e.ctx, tenderID, bidID)
+	if err != nil {
+		return err
+	}
+	return e.out.fields(ref, "Tender", ref.TenderID, "Bid", ref.BidID, "Contractor", ref.ContractorID, "Hash", ref.BidHash)
+}
+
+func bidList(e *env, args []string) error {
+	fs := flag.NewFlagSet("bid list", flag.ContinueOnError)
+	contractor := fs.String("contractor", "", "only bids by this contractor")
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	refs, err := e.client.ListBidsPublic(e.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	shown := make([]*model.BidRef, 0, len(refs))
+	var rows [][]string
+	for _, r := range refs {
+		if *contractor != "" && r.ContractorID != *contractor {
+			continue
+		}
+		shown = append(shown, r)
+		rows = append(rows, []string{r.BidID, r.ContractorID, strconv.FormatBool(r.Encrypted), strings.Join(r.LotIDs, ","), r.BidHash})
+	}
+	return e.out.table(shown, []string{"BID", "CONTRACTOR", "SEALED", "LOTS", "HASH"}, rows)
+}
+
+func bidGet(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("bid get", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	b, err := e.client.GetEnhancedBidPrivate(e.ctx, pos[0], pos[1])
+	if err != nil {
+		return err
+	}
+	return e.out.fields(b,
+		"Tender", b.TenderID,
+		"Bid", b.BidID,
+		"Contractor", b.ContractorID,
+		"Amount", money(b.TotalAmount, b.Currency),
+		"Submitted", b.SubmittedAt,
+	)
+}
+
+func bidVerify(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("bid verify", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	r, err := e.client.VerifyBidIntegrity(e.ctx, pos[0], pos[1])
+	if err != nil {
+		return err
+	}
+	return e.out.fields(r,
+		"Tender", r.TenderID,
+		"Bid", r.BidID,
+		"Match", strconv.FormatBool(r.Match),
+		"Recorded hash", r.RecordedHash,
+		"Computed hash", r.ComputedHash,
+		"Error", r.Error,
+	)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"tendercc/model"
+)
+
+func init() {
+	register(
+		command{"history", "", "TENDER [-bid B | -milestone M | -all]", "show the committed history of a tender, bid or milestone", history},
+	)
+}
+
+func history(e *env, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	bid := fs.String("bid", "", "history of one bid reference")
+	evaluation := fs.String("evaluation", "", "history of one bid's evaluation")
+	milestone := fs.String("milestone", "", "history of one milestone reference")
+	all := fs.Bool("all", false, "every record of the tender (full audit trail)")
+	pageSize := fs.Int("page-size", 0, "entries per page")
+	bookmark := fs.String("bookmark", "", "bookmark from the previous page")
+	pos, err := parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	tenderID, size := pos[0], int32(*pageSize)
+
+	var page *model.HistoryPage
+	switch {
+	case *bid != "":
+		page, err = e.client.GetBidRefHistory(e.ctx, tenderID, *bid, size, *bookmark)
+	case *evaluation != "":
+		page, err = e.client.GetEvaluationHistory(e.ctx, tenderID, *evaluation, size, *bookmark)
+	case *milestone != "":
+		page, err = e.client.GetMilestoneHistory(e.ctx, tenderID, *milestone, size, *bookmark)
+	case *all:
+		page, err = e.client.GetFullAuditTrail(e.ctx, tenderID, size, *bookmark)
+	default:
+		page, err = e.client.GetTenderHistoryEntries(e.ctx, tenderID, size, *bookmark)
+	}
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, h := range page.Entries {
+		rows = append(rows, []string{h.Timestamp, h.RecordType, h.RefID, summarize(h), h.TxID})
+	}
+	if err := e.out.table(page, []string{"TIME", "RECORD", "REF", "CHANGE", "TX"}, rows); err != nil {
+		return err
+	}
+	e.out.next(page.Bookmark)
+	return nil
+}
+
+// summarize describes the state a history entry recorded
+func summarize(h *model.HistoryEntry) string {
+	switch {
+	case h.IsDelete:
+		return "deleted"
+	case h.DecodeError != "":
+		return "undecodable: " + h.DecodeError
+	case h.Tender != nil:
+		s := "status " + h.Tender.Status
+		if h.Tender.AwardedBidID != "" {
+			s += ", awarded " + h.Tender.AwardedBidID
+		}
+		return s
+	case h.LegacyTender != nil:
+		return "status " + h.LegacyTender.Status + " (legacy)"
+	case h.BidRef != nil:
+		if h.BidRef.OpenedAt != "" {
+			return "opened"
+		}
+		return "submitted by " + h.BidRef.ContractorID
+	case h.Evaluation != nil:
+		return fmt.Sprintf("score %.2f", h.Evaluation.Score)
+	case h.LotEvaluation != nil:
+		return fmt.Sprintf("lot %s score %.2f", h.LotEvaluation.LotID, h.LotEvaluation.Score)
+	case h.Milestone != nil:
+		return strings.ToLower(h.Milestone.Status)
+	}
+	return ""
+}
@@ -0,0 +1,229 @@
+// Command tenderctl calls tendercc transactions from the shell.
+//
+//	tenderctl [global flags] <group> <command> [flags] [args]
+//
+// It replaces hand-escaped `peer chaincode invoke -c '{"Args":[...]}'` calls:
+// payloads are read from files, private bids and milestones are passed in the
+// transient map, and results are printed as a table or as JSON.
+//
+//	tenderctl tender create -f samples/rfq/construction-rfq-sample.json
+//	tenderctl tender publish RFQ-2025-INFRASTRUCTURE-001
+//	tenderctl -endorse org0-example-com,org1-example-com bid submit -f samples/bid/construction-bid-sample.json
+//	tenderctl milestone approve RFQ-2025-INFRASTRUCTURE-001 MS-001
+//	tenderctl -o json history RFQ-2025-INFRASTRUCTURE-001
+//
+// The gateway connection is read from the JSON file named by -config or
+// TENDERCTL_CONFIG (the tenderclient.Config fields), and any connection flag
+// given on the command line overrides the file.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"tenderclient"
+	"tenderclient/gateway"
+)
+
+// env is what every command runs with
+type env struct {
+	ctx    context.Context
+	client *tenderclient.Client
+	out    *printer
+	// opts apply to every submitted transaction
+	opts []tenderclient.CallOption
+}
+
+type command struct {
+	group, name string
+	usage       string // arguments and flags after the command name
+	summary     string
+	run         func(e *env, args []string) error
+}
+
+// commands is populated by the per-group files
+var commands []command
+
+func register(cmds ...command) {
+	commands = append(commands, cmds...)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout, gateway.Connect); err != nil {
+		fmt.Fprintln(os.Stderr, "tenderctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, connects and dispatches to the command
+func run(ctx context.Context, args []string, stdout io.Writer, connect func(tenderclient.Config) (*tenderclient.Client, error)) error {
+	fs := flag.NewFlagSet("tenderctl", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	configPath := fs.String("config", os.Getenv("TENDERCTL_CONFIG"), "gateway configuration file (tenderclient.Config as JSON)")
+	var cfg tenderclient.Config
+	fs.StringVar(&cfg.PeerEndpoint, "peer", "", "gateway peer host:port")
+	fs.StringVar(&cfg.PeerHostOverride, "peer-host", "", "TLS server name of the gateway peer")
+	fs.StringVar(&cfg.TLSCACertPath, "tls-ca", "", "peer TLS CA certificate")
+	fs.StringVar(&cfg.WalletPath, "wallet", "", "wallet directory")
+	fs.StringVar(&cfg.Identity, "identity", "", "wallet identity label")
+	fs.StringVar(&cfg.MSPID, "msp", "", "MSP ID, with -cert and -key")
+	fs.StringVar(&cfg.CertPath, "cert", "", "signing certificate or signcerts directory")
+	fs.StringVar(&cfg.KeyPath, "key", "", "private key or keystore directory")
+	fs.StringVar(&cfg.Channel, "channel", "", "channel (default "+tenderclient.DefaultChannel+")")
+	fs.StringVar(&cfg.Chaincode, "chaincode", "", "chaincode (default "+tenderclient.DefaultChaincode+")")
+	endorse := fs.String("endorse", "", "comma separated MSP IDs to target for endorsement")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		return fmt.Errorf("a command is required")
+	}
+	cmd, cmdArgs := lookup(rest)
+	if cmd == nil {
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", strings.Join(rest[:min(len(rest), 2)], " "))
+	}
+
+	merged, err := loadConfig(*configPath, cfg)
+	if err != nil {
+		return err
+	}
+	client, err := connect(merged)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	e := &env{ctx: ctx, client: client, out: &printer{w: stdout, json: *output == "json"}}
+	if *endorse != "" {
+		e.opts = append(e.opts, tenderclient.WithEndorsingOrgs(splitList(*endorse)...))
+	}
+	return cmd.run(e, cmdArgs)
+}
+
+// lookup finds the command named by the leading arguments and returns the rest.
+// A command with an empty name is invoked by its group alone.
+func lookup(args []string) (*command, []string) {
+	for i := range commands {
+		c := &commands[i]
+		switch {
+		case c.group != args[0]:
+		case c.name == "":
+			return c, args[1:]
+		case len(args) > 1 && c.name == args[1]:
+			return c, args[2:]
+		}
+	}
+	return nil, nil
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "usage: tenderctl [global flags] <group> <command> [flags] [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	sorted := append([]command(nil), commands...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].group < sorted[j].group })
+	for _, c := range sorted {
+		name := strings.TrimSpace(c.group + " " + c.name)
+		fmt.Fprintf(w, "  %-62s %s\n", name+" "+c.usage, c.summary)
+	}
+	fmt.Fprintln(w, "\nglobal flags:")
+	fs.PrintDefaults()
+}
+
+// loadConfig reads the configuration file, if any, and applies the flag overrides
+func loadConfig(path string, flags tenderclient.Config) (tenderclient.Config, error) {
+	var cfg tenderclient.Config
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("invalid config %s: %v", path, err)
+		}
+	}
+	override := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	override(&cfg.PeerEndpoint, flags.PeerEndpoint)
+	override(&cfg.PeerHostOverride, flags.PeerHostOverride)
+	override(&cfg.TLSCACertPath, flags.TLSCACertPath)
+	override(&cfg.WalletPath, flags.WalletPath)
+	override(&cfg.Identity, flags.Identity)
+	override(&cfg.MSPID, flags.MSPID)
+	override(&cfg.CertPath, flags.CertPath)
+	override(&cfg.KeyPath, flags.KeyPath)
+	override(&cfg.Channel, flags.Channel)
+	override(&cfg.Chaincode, flags.Chaincode)
+	if cfg.PeerEndpoint == "" {
+		return cfg, fmt.Errorf("no gateway peer configured; use -config or -peer")
+	}
+	return cfg, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parse parses a command's flags, allowing them before or after the positional
+// arguments, and checks the number of positional arguments
+func parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) < minArgs || (maxArgs >= 0 && len(positional) > maxArgs) {
+		return nil, fmt.Errorf("%s: expected %s", fs.Name(), argCount(minArgs, maxArgs))
+	}
+	return positional, nil
+}
+
+func argCount(min, max int) string {
+	switch {
+	case min == max:
+		return fmt.Sprintf("%d argument(s)", min)
+	case max < 0:
+		return fmt.Sprintf("at least %d argument(s)", min)
+	default:
+		return fmt.Sprintf("%d to %d arguments", min, max)
+	}
+}
+
+// withOpts appends per-command options to the global ones
+func (e *env) withOpts(opts ...tenderclient.CallOption) []tenderclient.CallOption {
+	return append(append([]tenderclient.CallOption(nil), e.opts...), opts...)
+}
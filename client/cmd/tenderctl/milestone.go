@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+func init() {
+	register(
+		command{"milestone", "submit", "-f milestone.json [-tender T] [-milestone M]", "submit milestone evidence through the transient map", milestoneSubmit},
+		command{"milestone", "approve", "TENDER MILESTONE [-signature sig.json]", "approve a milestone and release its payment", milestoneApprove},
+		command{"milestone", "reject", "TENDER MILESTONE -reason R", "reject a submitted milestone", milestoneReject},
+		command{"milestone", "list", "TENDER", "list milestone references", milestoneList},
+		command{"milestone", "get", "TENDER MILESTONE", "show milestone details (collection members only)", milestoneGet},
+	)
+}
+
+func milestoneSubmit(e *env, args []string) error {
+	fs := flag.NewFlagSet("milestone submit", flag.ContinueOnError)
+	file := fs.String("f", "", "milestone document (MilestonePrivate JSON), - for stdin")
+	tender := fs.String("tender", "", "tender ID, overriding the document's tenderId")
+	milestone := fs.String("milestone", "", "milestone ID, overriding the document's milestoneId")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	p, err := readPayload(*file)
+	if err != nil {
+		return err
+	}
+	if err := p.set("tenderId", *tender); err != nil {
+		return err
+	}
+	if err := p.set("milestoneId", *milestone); err != nil {
+		return err
+	}
+	tenderID, err := p.require("tenderId")
+	if err != nil {
+		return err
+	}
+	milestoneID, err := p.require("milestoneId")
+	if err != nil {
+		return err
+	}
+	if err := e.client.SubmitMilestoneJSON(e.ctx, tenderID, milestoneID, p.data, e.opts...); err != nil {
+		return err
+	}
+	return showMilestone(e, tenderID, milestoneID)
+}
+
+func milestoneApprove(e *env, args []string) error {
+	fs := flag.NewFlagSet("milestone approve", flag.ContinueOnError)
+	sigFile := fs.String("signature", "", "detached MILESTONE_APPROVAL signature (DetachedSignature JSON)")
+	pos, err := parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	opts, err := signatureOpts(e, *sigFile)
+	if err != nil {
+		return err
+	}
+	if err := e.client.ApproveMilestone(e.ctx, pos[0], pos[1], opts...); err != nil {
+		return err
+	}
+	return showMilestone(e, pos[0], pos[1])
+}
+
+func milestoneReject(e *env, args []string) error {
+	fs := flag.NewFlagSet("milestone reject", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why the milestone is rejected")
+	pos, err := parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	if *reason == "" {
+		return fmt.Errorf("-reason is required")
+	}
+	if err := e.client.RejectMilestone(e.ctx, pos[0], pos[1], *reason, e.opts...); err != nil {
+		return err
+	}
+	return showMilestone(e, pos[0], pos[1])
+}
+
+func milestoneList(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("milestone list", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	refs, err := e.client.ListMilestonesPublic(e.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, r := range refs {
+		rows = append(rows, []string{r.MilestoneID, r.Status, strconv.FormatBool(r.PaymentReleased), r.SubmittedAt, r.Title})
+	}
+	return e.out.table(refs, []string{"MILESTONE", "STATUS", "PAID", "SUBMITTED", "TITLE"}, rows)
+}
+
+// showMilestone prints the public reference of one milestone
+func showMilestone(e *env, tenderID, milestoneID string) error {
+	refs, err := e.client.ListMilestonesPublic(e.ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	for _, r := range refs {
+		if r.MilestoneID == milestoneID {
+			return e.out.fields(r,
+				"Tender", r.TenderID,
+				"Milestone", r.MilestoneID,
+				"Title", r.Title,
+				"Status", r.Status,
+				"Paid", strconv.FormatBool(r.PaymentReleased),
+				"Evidence", r.EvidenceHash,
+			)
+		}
+	}
+	return fmt.Errorf("milestone %s not found on tender %s", milestoneID, tenderID)
+}
+
+func milestoneGet(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("milestone get", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	m, err := e.client.ReadMilestonePrivate(e.ctx, pos[0], pos[1])
+	if err != nil {
+		return err
+	}
+	return e.out.fields(m,
+		"Tender", m.TenderID,
+		"Milestone", m.MilestoneID,
+		"Title", m.Title,
+		"Amount", money(m.Amount, ""),
+		"Paid", money(m.PaidAmount, ""),
+		"Evidence", m.EvidenceHash,
+		"Details", m.Details,
+	)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer writes results as an aligned table or as indented JSON
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// table prints v as JSON, or headers and rows as a table
+func (p *printer) table(v interface{}, headers []string, rows [][]string) error {
+	if p.json {
+		return p.writeJSON(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// fields prints v as JSON, or label/value pairs one per line
+func (p *printer) fields(v interface{}, pairs ...string) error {
+	if p.json {
+		return p.writeJSON(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", pairs[i], pairs[i+1])
+		}
+	}
+	return tw.Flush()
+}
+
+func (p *printer) writeJSON(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// next prints the bookmark of the next page in table mode
+func (p *printer) next(bookmark string) {
+	if !p.json && bookmark != "" {
+		fmt.Fprintf(p.w, "\nmore results: -bookmark %s\n", bookmark)
+	}
+}
+
+func money(amount float64, currency string) string {
+	if amount == 0 {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", amount, currency))
+}
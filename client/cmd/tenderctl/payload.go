@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// payload is a JSON object read from a file
+type payload struct {
+	path   string
+	data   []byte
+	fields map[string]json.RawMessage
+}
+
+// readPayload reads a JSON object from path, or from stdin when path is "-"
+func readPayload(path string) (*payload, error) {
+	if path == "" {
+		return nil, fmt.Errorf("-f is required")
+	}
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &payload{path: path, data: data}
+	if err := json.Unmarshal(data, &p.fields); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON object: %v", path, err)
+	}
+	return p, nil
+}
+
+// str returns a string field, or "" when it is missing or not a string
+func (p *payload) str(field string) string {
+	var s string
+	json.Unmarshal(p.fields[field], &s)
+	return s
+}
+
+// set replaces a string field when value is not empty. The object is re-encoded,
+// so a signature over the original file no longer matches.
+func (p *payload) set(field, value string) error {
+	if value == "" || value == p.str(field) {
+		return nil
+	}
+	raw, _ := json.Marshal(value)
+	p.fields[field] = raw
+	data, err := json.Marshal(p.fields)
+	if err != nil {
+		return err
+	}
+	p.data = data
+	return nil
+}
+
+// require returns a string field that must be present
+func (p *payload) require(field string) (string, error) {
+	v := p.str(field)
+	if v == "" {
+		return "", fmt.Errorf("%s has no %q; set it in the file or with a flag", p.path, field)
+	}
+	return v, nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"tendercc/model"
+	"tenderclient"
+)
+
+func init() {
+	register(
+		command{"tender", "create", "-f rfq.json [-id ID]", "create a DRAFT tender from an RFQ document", tenderCreate},
+		command{"tender", "validate", "-f rfq.json [-id ID]", "check an RFQ document without creating it", tenderValidate},
+		command{"tender", "publish", "TENDER", "open a DRAFT tender for bids", tenderPublish},
+		command{"tender", "close", "TENDER", "close an OPEN tender to further bids", tenderClose},
+		command{"tender", "get", "TENDER", "show a tender", tenderGet},
+		command{"tender", "list", "[-status S] [-method M] [-currency C]", "list tenders", tenderList},
+		command{"tender", "stats", "TENDER", "show bid counts and amounts", tenderStats},
+		command{"tender", "evaluate", "TENDER", "score the bids of a CLOSED tender", tenderEvaluate},
+		command{"tender", "evaluations", "TENDER", "list bid scores", tenderEvaluations},
+		command{"tender", "award", "TENDER [BID] [-signature sig.json]", "award a bid, or the best scored bid", tenderAward},
+	)
+}
+
+func tenderCreate(e *env, args []string) error {
+	fs := flag.NewFlagSet("tender create", flag.ContinueOnError)
+	file := fs.String("f", "", "RFQ document (EnhancedTender JSON), - for stdin")
+	id := fs.String("id", "", "tender ID, overriding the document's id")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	p, err := readPayload(*file)
+	if err != nil {
+		return err
+	}
+	if err := p.set("id", *id); err != nil {
+		return err
+	}
+	tenderID, err := p.require("id")
+	if err != nil {
+		return err
+	}
+	if err := e.client.CreateEnhancedTenderJSON(e.ctx, p.data, e.opts...); err != nil {
+		return err
+	}
+	return showTender(e, tenderID)
+}
+
+func tenderValidate(e *env, args []string) error {
+	fs := flag.NewFlagSet("tender validate", flag.ContinueOnError)
+	file := fs.String("f", "", "RFQ document (EnhancedTender JSON), - for stdin")
+	id := fs.String("id", "", "tender ID, overriding the document's id")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	p, err := readPayload(*file)
+	if err != nil {
+		return err
+	}
+	if err := p.set("id", *id); err != nil {
+		return err
+	}
+	report, err := e.client.ValidateTenderJSON(e.ctx, p.data)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		rows = append(rows, []string{issue.Field, issue.Message})
+	}
+	if report.Valid && !e.out.json {
+		fmt.Fprintf(e.out.w, "%s is valid\n", report.TenderID)
+	} else if err := e.out.table(report, []string{"FIELD", "ISSUE"}, rows); err != nil {
+		return err
+	}
+	if !report.Valid {
+		return fmt.Errorf("%d validation issues", len(report.Issues))
+	}
+	return nil
+}
+
+func tenderPublish(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("tender publish", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := e.client.PublishTender(e.ctx, pos[0], e.opts...); err != nil {
+		return err
+	}
+	return showTender(e, pos[0])
+}
+
+func tenderClose(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("tender close", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := e.client.CloseTender(e.ctx, pos[0], e.opts...); err != nil {
+		return err
+	}
+	return showTender(e, pos[0])
+}
+
+func tenderGet(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("tender get", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	return showTender(e, pos[0])
+}
+
+func showTender(e *env, tenderID string) error {
+	t, err := e.client.GetEnhancedTender(e.ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	budget := t.ProjectScope.Budget
+	budgetRange := ""
+	if budget.EstimatedMin != 0 || budget.EstimatedMax != 0 {
+		budgetRange = fmt.Sprintf("%.2f - %.2f %s", budget.EstimatedMin, budget.EstimatedMax, budget.Currency)
+	}
+	return e.out.fields(t,
+		"ID", t.ID,
+		"Status", t.Status,
+		"Description", t.ProjectScope.Description,
+		"Method", t.ProcurementMethod,
+		"Budget", budgetRange,
+		"Questions deadline", t.Deadlines.QuestionsDeadline,
+		"Bid deadline", t.Deadlines.BidSubmissionDeadline,
+		"Awarded bid", t.AwardedBidID,
+		"Version", strconv.Itoa(t.Version),
+		"Updated", t.UpdatedAt,
+	)
+}
+
+func tenderList(e *env, args []string) error {
+	fs := flag.NewFlagSet("tender list", flag.ContinueOnError)
+	var filter model.TenderFilter
+	fs.StringVar(&filter.Status, "status", "", "DRAFT, OPEN, CLOSED, AWARDED, ...")
+	fs.StringVar(&filter.ProcurementMethod, "method", "", "OPEN, RESTRICTED, INVITED or SINGLE_SOURCE")
+	fs.StringVar(&filter.Currency, "currency", "", "budget currency")
+	fs.StringVar(&filter.Country, "country", "", "owner country")
+	fs.StringVar(&filter.Sector, "sector", "", "relevant sector")
+	pageSize := fs.Int("page-size", 0, "results per page")
+	fs.StringVar(&filter.Bookmark, "bookmark", "", "bookmark from the previous page")
+	if _, err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	filter.PageSize = int32(*pageSize)
+	result, err := e.client.QueryTenders(e.ctx, filter)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, t := range result.Tenders {
+		rows = append(rows, []string{t.ID, t.Status, t.ProcurementMethod, t.Deadlines.BidSubmissionDeadline, t.ProjectScope.Description})
+	}
+	if err := e.out.table(result, []string{"ID", "STATUS", "METHOD", "BID DEADLINE", "DESCRIPTION"}, rows); err != nil {
+		return err
+	}
+	e.out.next(result.Bookmark)
+	return nil
+}
+
+func tenderStats(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("tender stats", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	s, err := e.client.GetTenderStatistics(e.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	pairs := []string{"ID", s.TenderID, "Status", s.Status, "Bids", strconv.Itoa(s.TotalBids), "Owner", s.Owner}
+	if b := s.BidStatistics; b != nil {
+		pairs = append(pairs,
+			"Lowest bid", money(b.LowestBid, b.Currency),
+			"Average bid", money(b.AverageBid, b.Currency),
+			"Highest bid", money(b.HighestBid, b.Currency),
+		)
+	}
+	return e.out.fields(s, pairs...)
+}
+
+func tenderEvaluate(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("tender evaluate", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := e.client.EvaluateBids(e.ctx, pos[0], e.opts...); err != nil {
+		return err
+	}
+	return showEvaluations(e, pos[0])
+}
+
+func tenderEvaluations(e *env, args []string) error {
+	pos, err := parse(flag.NewFlagSet("tender evaluations", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	return showEvaluations(e, pos[0])
+}
+
+func showEvaluations(e *env, tenderID string) error {
+	evals, err := e.client.ListEvaluations(e.ctx, tenderID)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, ev := range evals {
+		rows = append(rows, []string{ev.BidID, strconv.FormatFloat(ev.Score, 'f', 2, 64), ev.Notes})
+	}
+	return e.out.table(evals, []string{"BID", "SCORE", "NOTES"}, rows)
+}
+
+func tenderAward(e *env, args []string) error {
+	fs := flag.NewFlagSet("tender award", flag.ContinueOnError)
+	sigFile := fs.String("signature", "", "detached AWARD signature (DetachedSignature JSON)")
+	pos, err := parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	opts, err := signatureOpts(e, *sigFile)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 2 {
+		err = e.client.AwardTender(e.ctx, pos[0], pos[1], opts...)
+	} else {
+		err = e.client.AwardBestBid(e.ctx, pos[0], opts...)
+	}
+	if err != nil {
+		return err
+	}
+	return showTender(e, pos[0])
+}
+
+// signatureOpts adds a detached signature read from path to the global options
+func signatureOpts(e *env, path string) ([]tenderclient.CallOption, error) {
+	if path == "" {
+		return e.opts, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sig model.DetachedSignature
+	if err := json.Unmarshal(data, &sig); err != nil {
+		return nil, fmt.Errorf("invalid signature file %s: %v", path, err)
+	}
+	return e.withOpts(tenderclient.WithSignature(sig)), nil
+}
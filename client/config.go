@@ -0,0 +1,66 @@
+package tenderclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Defaults used when a Config field is left empty
+const (
+	DefaultChannel   = "tenderchannel"
+	DefaultChaincode = "tendercc"
+)
+
+// Config describes how to reach the Fabric Gateway and who to sign as.
+// The signing identity comes either from a file system wallet (WalletPath and
+// Identity) or from an MSP certificate and key (MSPID, CertPath and KeyPath).
+type Config struct {
+	PeerEndpoint     string `json:"peerEndpoint"`               // gateway peer, host:port
+	PeerHostOverride string `json:"peerHostOverride,omitempty"` // TLS server name when it differs from the endpoint host
+	TLSCACertPath    string `json:"tlsCaCertPath,omitempty"`    // peer TLS CA certificate; empty connects without TLS
+
+	WalletPath string `json:"walletPath,omitempty"` // directory of <label>.id wallet files
+	Identity   string `json:"identity,omitempty"`   // wallet label, e.g. Admin
+
+	MSPID    string `json:"mspId,omitempty"`
+	CertPath string `json:"certPath,omitempty"` // PEM certificate or a signcerts directory
+	KeyPath  string `json:"keyPath,omitempty"`  // PEM private key or a keystore directory
+
+	Channel   string `json:"channel,omitempty"`
+	Chaincode string `json:"chaincode,omitempty"`
+
+	EvaluateTimeout     time.Duration `json:"evaluateTimeout,omitempty"`
+	EndorseTimeout      time.Duration `json:"endorseTimeout,omitempty"`
+	SubmitTimeout       time.Duration `json:"submitTimeout,omitempty"`
+	CommitStatusTimeout time.Duration `json:"commitStatusTimeout,omitempty"`
+}
+
+// WalletIdentity is an X.509 identity stored in a Fabric file system wallet
+type WalletIdentity struct {
+	Credentials struct {
+		Certificate string `json:"certificate"`
+		PrivateKey  string `json:"privateKey"`
+	} `json:"credentials"`
+	MSPID   string `json:"mspId"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+// LoadWalletIdentity reads <walletPath>/<label>.id
+func LoadWalletIdentity(walletPath, label string) (*WalletIdentity, error) {
+	data, err := os.ReadFile(filepath.Join(walletPath, label+".id"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet identity %s: %w", label, err)
+	}
+	var id WalletIdentity
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil, fmt.Errorf("invalid wallet identity %s: %w", label, err)
+	}
+	if id.Type != "" && id.Type != "X.509" {
+		return nil, fmt.Errorf("wallet identity %s has unsupported type %s", label, id.Type)
+	}
+	return &id, nil
+}
@@ -0,0 +1,182 @@
+package tenderclient
+
+import (
+	"context"
+	"strconv"
+
+	"tendercc/model"
+)
+
+// Signing subjects accepted by GetSigningPayload and GetSignatureRecord
+const (
+	SubjectBid       = "BID"
+	SubjectAward     = "AWARD"
+	SubjectContract  = "CONTRACT"
+	SubjectMilestone = "MILESTONE_APPROVAL"
+)
+
+// GetContractorPerformance returns a contractor's performance record
+func (c *Client) GetContractorPerformance(ctx context.Context, contractorID string) (*model.ContractorPerformance, error) {
+	return evaluateAs[*model.ContractorPerformance](c, ctx, ContractEnhanced, "GetContractorPerformance", contractorID)
+}
+
+// RecordPenalty records a penalty against the contractor of an awarded tender
+func (c *Client) RecordPenalty(ctx context.Context, tenderID, penaltyID, penaltyType string, amount float64, reason string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "RecordPenalty", opts, tenderID, penaltyID, penaltyType, formatFloat(amount), reason)
+	return err
+}
+
+// ListPenalties returns the penalties recorded on a tender
+func (c *Client) ListPenalties(ctx context.Context, tenderID string) ([]*model.PenaltyRecord, error) {
+	return evaluateAs[[]*model.PenaltyRecord](c, ctx, ContractEnhanced, "ListPenalties", tenderID)
+}
+
+// RecordContractCompletion marks an awarded contract as completed
+func (c *Client) RecordContractCompletion(ctx context.Context, tenderID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "RecordContractCompletion", opts, tenderID)
+	return err
+}
+
+// TerminateContract terminates an awarded contract
+func (c *Client) TerminateContract(ctx context.Context, tenderID, reason string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "TerminateContract", opts, tenderID, reason)
+	return err
+}
+
+// RateContractor records the buyer's 1-5 rating of a finished contract
+func (c *Client) RateContractor(ctx context.Context, tenderID string, rating int, comment string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "RateContractor", opts, tenderID, strconv.Itoa(rating), comment)
+	return err
+}
+
+// DebarContractor debars a contractor; an empty endDate debars indefinitely
+func (c *Client) DebarContractor(ctx context.Context, contractorID, reason, startDate, endDate string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "DebarContractor", opts, contractorID, reason, startDate, endDate)
+	return err
+}
+
+// LiftDebarment lifts a contractor's debarment
+func (c *Client) LiftDebarment(ctx context.Context, contractorID, reason string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "LiftDebarment", opts, contractorID, reason)
+	return err
+}
+
+// GetDebarment returns a contractor's debarment entry
+func (c *Client) GetDebarment(ctx context.Context, contractorID string) (*model.DebarmentEntry, error) {
+	return evaluateAs[*model.DebarmentEntry](c, ctx, ContractEnhanced, "GetDebarment", contractorID)
+}
+
+// ListDebarments returns every debarment entry
+func (c *Client) ListDebarments(ctx context.Context) ([]*model.DebarmentEntry, error) {
+	return evaluateAs[[]*model.DebarmentEntry](c, ctx, ContractEnhanced, "ListDebarments")
+}
+
+// IsContractorDebarred reports whether a contractor is debarred now
+func (c *Client) IsContractorDebarred(ctx context.Context, contractorID string) (bool, error) {
+	return evaluateAs[bool](c, ctx, ContractEnhanced, "IsContractorDebarred", contractorID)
+}
+
+// CreateFrameworkAgreement creates a framework agreement with its member list
+func (c *Client) CreateFrameworkAgreement(ctx context.Context, framework *model.FrameworkAgreement, opts ...CallOption) error {
+	arg, err := marshalArg("CreateFrameworkAgreement", framework)
+	if err != nil {
+		return err
+	}
+	_, err = c.submit(ctx, ContractEnhanced, "CreateFrameworkAgreement", opts, arg)
+	return err
+}
+
+// GetFrameworkAgreement reads a framework agreement
+func (c *Client) GetFrameworkAgreement(ctx context.Context, frameworkID string) (*model.FrameworkAgreement, error) {
+	return evaluateAs[*model.FrameworkAgreement](c, ctx, ContractEnhanced, "GetFrameworkAgreement", frameworkID)
+}
+
+// CreateDirectCallOff awards a call-off order directly to a framework member
+func (c *Client) CreateDirectCallOff(ctx context.Context, frameworkID, orderID, contractorID, description string, value float64, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "CreateDirectCallOff", opts, frameworkID, orderID, contractorID, description, formatFloat(value))
+	return err
+}
+
+// StartMiniCompetition opens a call-off order to the framework members until deadline
+func (c *Client) StartMiniCompetition(ctx context.Context, frameworkID, orderID, description string, maxValue float64, deadline string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "StartMiniCompetition", opts, frameworkID, orderID, description, formatFloat(maxValue), deadline)
+	return err
+}
+
+// GetCallOffOrder reads a call-off order
+func (c *Client) GetCallOffOrder(ctx context.Context, frameworkID, orderID string) (*model.CallOffOrder, error) {
+	return evaluateAs[*model.CallOffOrder](c, ctx, ContractEnhanced, "GetCallOffOrder", frameworkID, orderID)
+}
+
+// SubmitCallOffResponse submits a member's confidential offer in a mini-competition
+func (c *Client) SubmitCallOffResponse(ctx context.Context, frameworkID, orderID string, response *model.CallOffResponse, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransientJSON(TransientCallOffBid, response)}, opts...)
+	_, err := c.submit(ctx, ContractEnhanced, "SubmitCallOffResponse", opts, frameworkID, orderID)
+	return err
+}
+
+// AwardCallOff awards a mini-competition to one of its respondents
+func (c *Client) AwardCallOff(ctx context.Context, frameworkID, orderID, contractorID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "AwardCallOff", opts, frameworkID, orderID, contractorID)
+	return err
+}
+
+// ListCallOffOrders returns the call-off orders under a framework
+func (c *Client) ListCallOffOrders(ctx context.Context, frameworkID string) ([]*model.CallOffOrder, error) {
+	return evaluateAs[[]*model.CallOffOrder](c, ctx, ContractEnhanced, "ListCallOffOrders", frameworkID)
+}
+
+// RegisterSigningKey registers a public key used to verify detached signatures. The caller
+// must have registered the vendor ownerID or hold the registrar role.
+func (c *Client) RegisterSigningKey(ctx context.Context, keyID, ownerID, publicKeyPEM string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "RegisterSigningKey", opts, keyID, ownerID, publicKeyPEM)
+	return err
+}
+
+// RevokeSigningKey revokes a signing key
+func (c *Client) RevokeSigningKey(ctx context.Context, keyID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "RevokeSigningKey", opts, keyID)
+	return err
+}
+
+// GetSigningKey reads a registered signing key
+func (c *Client) GetSigningKey(ctx context.Context, keyID string) (*model.SigningKey, error) {
+	return evaluateAs[*model.SigningKey](c, ctx, ContractEnhanced, "GetSigningKey", keyID)
+}
+
+// GetSigningPayload returns the canonical payload to sign for subject
+func (c *Client) GetSigningPayload(ctx context.Context, tenderID, subject, refID string) ([]byte, error) {
+	return c.evaluate(ctx, ContractEnhanced, "GetSigningPayload", tenderID, subject, refID)
+}
+
+// SignContract records the caller's signature on an awarded contract
+func (c *Client) SignContract(ctx context.Context, tenderID string, sig model.DetachedSignature, opts ...CallOption) error {
+	opts = append([]CallOption{WithSignature(sig)}, opts...)
+	_, err := c.submit(ctx, ContractEnhanced, "SignContract", opts, tenderID)
+	return err
+}
+
+// GetSignatureRecord returns a recorded signature
+func (c *Client) GetSignatureRecord(ctx context.Context, tenderID, subject, refID string) (*model.SignatureRecord, error) {
+	return evaluateAs[*model.SignatureRecord](c, ctx, ContractEnhanced, "GetSignatureRecord", tenderID, subject, refID)
+}
+
+// ListSignatures returns every signature recorded on a tender
+func (c *Client) ListSignatures(ctx context.Context, tenderID string) ([]*model.SignatureRecord, error) {
+	return evaluateAs[[]*model.SignatureRecord](c, ctx, ContractEnhanced, "ListSignatures", tenderID)
+}
+
+// PurgeLosingBids purges the private details of a tender's losing bids and returns how many were purged
+func (c *Client) PurgeLosingBids(ctx context.Context, tenderID, reason string, opts ...CallOption) (int, error) {
+	return submitAs[int](c, ctx, ContractEnhanced, "PurgeLosingBids", opts, tenderID, reason)
+}
+
+// PurgeMilestoneDetails purges the private milestone details of a finished contract
+func (c *Client) PurgeMilestoneDetails(ctx context.Context, tenderID, reason string, opts ...CallOption) (int, error) {
+	return submitAs[int](c, ctx, ContractEnhanced, "PurgeMilestoneDetails", opts, tenderID, reason)
+}
+
+// ListPurgeRecords returns the purge tombstones of a tender
+func (c *Client) ListPurgeRecords(ctx context.Context, tenderID string) ([]*model.PurgeRecord, error) {
+	return evaluateAs[[]*model.PurgeRecord](c, ctx, ContractEnhanced, "ListPurgeRecords", tenderID)
+}
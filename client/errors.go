@@ -0,0 +1,137 @@
+package tenderclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Error kinds. Every error returned by Client wraps one of them, so callers can
+// use errors.Is(err, tenderclient.ErrNotFound).
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrAlreadyExists    = errors.New("already exists")
+	ErrDeadlinePassed   = errors.New("deadline passed")
+	ErrNotEligible      = errors.New("bidder not eligible")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrInvalidState     = errors.New("invalid state")
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrMVCCConflict     = errors.New("MVCC read conflict")
+	ErrUnavailable      = errors.New("gateway unavailable")
+	ErrChaincode        = errors.New("chaincode error")
+)
+
+// Error is a failed chaincode call. Message is the chaincode's own error text.
+type Error struct {
+	Function string
+	TxID     string
+	Message  string
+	Kind     error
+	Err      error // underlying gateway or transport error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Function, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CommitError reports a transaction that was ordered but marked invalid by the peers
+type CommitError struct {
+	TxID string
+	Code string // peer.TxValidationCode name, e.g. MVCC_READ_CONFLICT
+}
+
+func (e *CommitError) Error() string {
+	return fmt.Sprintf("transaction %s failed to commit: %s", e.TxID, e.Code)
+}
+
+// TransportError is a failed request as reported by a transport. Message is the
+// chaincode's own error text when the transport could extract it, and Unavailable
+// marks failures to reach the network rather than chaincode errors.
+type TransportError struct {
+	TxID        string
+	Message     string
+	Unavailable bool
+	Err         error
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// errorRules map chaincode messages to error kinds; the first match wins.
+// Validation wrappers come first so "deadline validation failed: ..." stays an argument error.
+// Access and eligibility checks come next: they quote the failed lookup ("caller must have
+// the regulator role: attribute 'role' was not found", "... is not prequalified: vendor X
+// not found") and read like state errors ("only the tender owner may ...").
+var errorRules = []struct {
+	kind    error
+	phrases []string
+}{
+	{ErrInvalidArgument, []string{"validation failed"}},
+	{ErrPermissionDenied, []string{"must have the", " role may ", "only the tender owner", "only the submitter of", "belongs to another", "does not hold share", "only the registering", " may change ", "not authorized"}},
+	{ErrNotEligible, []string{"debarred", "not invited", "not prequalified", "not a member of framework", "is registered by"}},
+	{ErrNotFound, []string{"not found", "does not exist", "no signature recorded"}},
+	{ErrAlreadyExists, []string{"already"}},
+	{ErrDeadlinePassed, []string{"deadline has passed", "window closed", "has ended", "expired"}},
+	{ErrInvalidState, []string{"not open", "can only", "only ", "cannot", "has not", "still running", "open until", "retained until", "no bids", "no eligible", "no valid", "received no"}},
+	{ErrInvalidArgument, []string{"invalid", "required", "must", "duplicate", "mismatch", "exceeds", "does not match", "do not match", "more than once", "not covered", "not valid", "transient"}},
+}
+
+var notFoundPattern = regexp.MustCompile(`^no .* found`)
+
+// classify picks the error kind for a chaincode message
+func classify(message string) error {
+	msg := strings.ToLower(message)
+	if notFoundPattern.MatchString(msg) {
+		return ErrNotFound
+	}
+	for _, rule := range errorRules {
+		for _, phrase := range rule.phrases {
+			if strings.Contains(msg, phrase) {
+				return rule.kind
+			}
+		}
+	}
+	return ErrChaincode
+}
+
+// wrapError turns a transport error into an *Error
+func wrapError(function string, err error) error {
+	if err == nil {
+		return nil
+	}
+	out := &Error{Function: function, Err: err, Message: err.Error(), Kind: ErrChaincode}
+
+	var commitErr *CommitError
+	if errors.As(err, &commitErr) {
+		out.TxID = commitErr.TxID
+		if commitErr.Code == "MVCC_READ_CONFLICT" || commitErr.Code == "PHANTOM_READ_CONFLICT" {
+			out.Kind = ErrMVCCConflict
+		}
+		return out
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		out.TxID, out.Message = transportErr.TxID, transportErr.Message
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || (transportErr != nil && transportErr.Unavailable) {
+		out.Kind = ErrUnavailable
+		return out
+	}
+	out.Kind = classify(out.Message)
+	return out
+}
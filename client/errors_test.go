@@ -0,0 +1,87 @@
+package tenderclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestClassify runs chaincode error messages, as tendercc formats them, through classify
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		message string
+		want    error
+	}{
+		// Access checks
+		{"caller must have the regulator role: attribute 'role' was not found", ErrPermissionDenied},
+		{"caller must have the approver role: attribute 'role' has value 'viewer'", ErrPermissionDenied},
+		{"caller must have the registrar role to bind a key to contractorV", ErrPermissionDenied},
+		{"only the tender owner may add invitees to tender T1", ErrPermissionDenied},
+		{"only the tender owner may attach documents to tender T1", ErrPermissionDenied},
+		{"only the tender owner may close out tender T1", ErrPermissionDenied},
+		{"only the tender owner or the regulator role may record penalties on tender T1", ErrPermissionDenied},
+		{"only the tender owner or the regulator role may purge private data of tender T1", ErrPermissionDenied},
+		{"only the submitter of bid B1 may attach documents to it", ErrPermissionDenied},
+		{"only the submitter of milestone M1 may attach documents to it", ErrPermissionDenied},
+		{"only BuyerMSP or the registrar role may bind a key to vendor contractorV", ErrPermissionDenied},
+		{"only BuyerMSP may change vendor contractorA", ErrPermissionDenied},
+		{"only the registering identity can revoke signing key K1", ErrPermissionDenied},
+		{"caller does not hold share 2", ErrPermissionDenied},
+
+		// Validation wrappers stay argument errors whatever they quote
+		{"bid validation failed: bid for tender T1 not found", ErrInvalidArgument},
+		{"tender validation failed: deadlines.bidSubmissionDeadline: must be in the future", ErrInvalidArgument},
+
+		{"tender T9 not found", ErrNotFound},
+		{"no auction offer found for caller on tender A1", ErrNotFound},
+		{"no signature recorded for BID B1 on tender T1", ErrNotFound},
+		{"bid B1 already exists for tender T1", ErrAlreadyExists},
+		{"BuyerMSP has already rated tender T1", ErrAlreadyExists},
+		{"bid submission deadline has passed", ErrDeadlinePassed},
+		{"auction for tender A1 has ended", ErrDeadlinePassed},
+		{"contractor contractorA is debarred until 2031-03-04T09:00:00Z", ErrNotEligible},
+		{"contractor contractorC is not invited to RESTRICTED tender T1", ErrNotEligible},
+		{"contractor contractorA is not prequalified: vendor contractorA not found", ErrNotEligible},
+		{"vendor contractorA is registered by ContractorAMSP, not ContractorBMSP", ErrNotEligible},
+		{"tender is not open for bids", ErrInvalidState},
+		{"only draft tenders can be published", ErrInvalidState},
+		{"losing bids can only be purged after award", ErrInvalidState},
+		{"offer amount must be positive", ErrInvalidArgument},
+		{"transient map must contain 'bid'", ErrInvalidArgument},
+		{"something unexpected", ErrChaincode},
+	}
+	for _, tc := range tests {
+		if got := classify(tc.message); got != tc.want {
+			t.Errorf("classify(%q) = %v, want %v", tc.message, got, tc.want)
+		}
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		kind    error
+		message string
+		txID    string
+	}{
+		{"chaincode message", &TransportError{TxID: "tx1", Message: "only the tender owner may award tender T1", Err: errors.New("endorse failed")}, ErrPermissionDenied, "only the tender owner may award tender T1", "tx1"},
+		{"plain error", errors.New("tender T9 not found"), ErrNotFound, "tender T9 not found", ""},
+		{"unreachable", &TransportError{Message: "connection refused", Unavailable: true, Err: errors.New("connection refused")}, ErrUnavailable, "connection refused", ""},
+		{"timeout", fmt.Errorf("submit: %w", context.DeadlineExceeded), ErrUnavailable, "submit: context deadline exceeded", ""},
+		{"commit conflict", &CommitError{TxID: "tx1", Code: "MVCC_READ_CONFLICT"}, ErrMVCCConflict, "transaction tx1 failed to commit: MVCC_READ_CONFLICT", "tx1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := wrapError("AwardTender", tc.err)
+			var e *Error
+			if !errors.As(err, &e) || !errors.Is(err, tc.kind) || e.Message != tc.message || e.Function != "AwardTender" || e.TxID != tc.txID {
+				t.Fatalf("wrapError = %#v", err)
+			}
+		})
+	}
+	if wrapError("AwardTender", nil) != nil {
+		t.Fatal("nil error wrapped")
+	}
+}
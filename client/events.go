@@ -0,0 +1,83 @@
+package tenderclient
+
+import (
+	"context"
+	"fmt"
+
+	"tendercc/events"
+)
+
+// Checkpoint is a position in the committed event stream: every event up to and
+// including TransactionID in block BlockNumber has been processed. An empty
+// TransactionID means nothing in BlockNumber has been processed yet, so the zero
+// Checkpoint reads from the genesis block.
+type Checkpoint struct {
+	BlockNumber   uint64 `json:"blockNumber"`
+	TransactionID string `json:"transactionId,omitempty"`
+}
+
+// ChaincodeEvent is the event of one committed tendercc transaction
+type ChaincodeEvent struct {
+	BlockNumber   uint64
+	TransactionID string
+	EventName     string
+	Payload       []byte
+}
+
+// Checkpoint returns the position just after this event
+func (e *ChaincodeEvent) Checkpoint() Checkpoint {
+	return Checkpoint{BlockNumber: e.BlockNumber, TransactionID: e.TransactionID}
+}
+
+// Envelopes decodes the event into the envelopes it carries, one per event the
+// transaction raised
+func (e *ChaincodeEvent) Envelopes() ([]events.Envelope, error) {
+	return events.Parse(e.EventName, e.Payload)
+}
+
+// Block summarises a committed block: its transactions and whether each was valid
+type Block struct {
+	Number       uint64
+	Transactions []BlockTransaction
+}
+
+// BlockTransaction is one transaction in a Block. Code is the Fabric validation code,
+// e.g. VALID or MVCC_READ_CONFLICT.
+type BlockTransaction struct {
+	TransactionID string
+	Code          string
+}
+
+// Valid reports whether the transaction's writes were committed
+func (t BlockTransaction) Valid() bool {
+	return t.Code == "VALID"
+}
+
+// EventSource is implemented by transports that can stream committed events.
+// Both channels are closed when ctx is done or the stream fails; callers resume
+// from their own checkpoint.
+type EventSource interface {
+	ChaincodeEvents(ctx context.Context, from Checkpoint) (<-chan *ChaincodeEvent, error)
+	BlockEvents(ctx context.Context, startBlock uint64) (<-chan *Block, error)
+}
+
+// ChaincodeEvents streams the events of committed tendercc transactions, starting
+// after from
+func (c *Client) ChaincodeEvents(ctx context.Context, from Checkpoint) (<-chan *ChaincodeEvent, error) {
+	source, ok := c.transport.(EventSource)
+	if !ok {
+		return nil, fmt.Errorf("transport %T does not deliver events", c.transport)
+	}
+	out, err := source.ChaincodeEvents(ctx, from)
+	return out, wrapError("ChaincodeEvents", err)
+}
+
+// BlockEvents streams a summary of every committed block from startBlock
+func (c *Client) BlockEvents(ctx context.Context, startBlock uint64) (<-chan *Block, error) {
+	source, ok := c.transport.(EventSource)
+	if !ok {
+		return nil, fmt.Errorf("transport %T does not deliver events", c.transport)
+	}
+	out, err := source.BlockEvents(ctx, startBlock)
+	return out, wrapError("BlockEvents", err)
+}
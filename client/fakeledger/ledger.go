@@ -0,0 +1,276 @@
+// Package fakeledger is an in-memory stand-in for a peer running tendercc.
+//
+// It implements tenderclient.Transport by hosting the real tendercc contracts on a
+// mockstub ledger, so services built on tenderclient can be tested without a Fabric
+// network and see exactly the chaincode's validation, errors and events. Like a peer,
+// it runs evaluations without committing them, commits a submitted transaction's writes
+// atomically, and does not let a transaction read its own writes. A transaction whose
+// reads were changed by another commit after it executed is marked MVCC_READ_CONFLICT
+// (or PHANTOM_READ_CONFLICT for range reads) and its writes are dropped, so concurrent
+// submitters see the same failures as on a network. Each commit is a new block, and its
+// chaincode event is delivered to event streams as the peer would. Rich queries are
+// answered as on a CouchDB peer.
+package fakeledger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"tendercc/contract"
+	"tendercc/events"
+	"tendercc/mockstub"
+	"tenderclient"
+)
+
+// block is a committed block. Each submitted transaction is committed in its own
+// block; block 0 is the genesis block and holds no transaction.
+type block struct {
+	txID  string
+	code  string                       // validation code of the transaction
+	event *tenderclient.ChaincodeEvent // nil when the transaction emitted nothing or is invalid
+}
+
+// Ledger runs tendercc on a mock ledger and records the blocks of its commits
+type Ledger struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	ledger *mockstub.Ledger
+	cc     *contractapi.ContractChaincode
+	ids    map[events.Actor]*mockstub.Identity
+	blocks []block
+	// committed is closed and replaced on every commit to wake event streams
+	committed chan struct{}
+}
+
+// New returns an empty ledger using the wall clock for transaction timestamps
+func New() *Ledger {
+	cc, err := contract.New()
+	if err != nil {
+		// The contracts are fixed at build time; chaincode tests catch a bad one
+		panic(err)
+	}
+	ledger := mockstub.NewLedger(time.Now())
+	ledger.RichQueries = true
+	return &Ledger{
+		now:       time.Now,
+		ledger:    ledger,
+		cc:        cc,
+		ids:       make(map[events.Actor]*mockstub.Identity),
+		blocks:    []block{{}},
+		committed: make(chan struct{}),
+	}
+}
+
+// SetClock replaces the source of transaction timestamps
+func (l *Ledger) SetClock(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = now
+}
+
+// Enroll creates the identity of a client with the given certificate attributes,
+// such as role. Identities that submit without enrolling have no attributes.
+func (l *Ledger) Enroll(mspID, clientID string, attrs map[string]string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.enroll(events.Actor{MSPID: mspID, ClientID: clientID}, attrs)
+	return err
+}
+
+func (l *Ledger) enroll(actor events.Actor, attrs map[string]string) (*mockstub.Identity, error) {
+	id, err := mockstub.NewIdentity(actor.MSPID, actor.ClientID, attrs, l.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to enroll %s: %v", actor.ClientID, err)
+	}
+	l.ids[actor] = id
+	return id, nil
+}
+
+// Events returns the envelopes of every committed transaction in commit order
+func (l *Ledger) Events() []events.Envelope {
+	var out []events.Envelope
+	for _, ev := range l.ledger.Events() {
+		envs, err := events.Parse(ev.Name, ev.Payload)
+		if err != nil {
+			continue
+		}
+		out = append(out, envs...)
+	}
+	return out
+}
+
+// Transport returns a transport that submits as the given client identity
+func (l *Ledger) Transport(mspID, clientID string) tenderclient.Transport {
+	return &transport{ledger: l, actor: events.Actor{MSPID: mspID, ClientID: clientID}}
+}
+
+// Client returns a tenderclient.Client that submits as the given client identity
+func (l *Ledger) Client(mspID, clientID string) *tenderclient.Client {
+	return tenderclient.New(l.Transport(mspID, clientID))
+}
+
+type transport struct {
+	ledger *Ledger
+	actor  events.Actor
+}
+
+func (t *transport) Evaluate(ctx context.Context, req *tenderclient.Request) ([]byte, error) {
+	result, _, err := t.ledger.execute(ctx, t.actor, req)
+	return result, err
+}
+
+func (t *transport) Submit(ctx context.Context, req *tenderclient.Request) ([]byte, error) {
+	result, _, err := t.SubmitCommit(ctx, req)
+	return result, err
+}
+
+func (t *transport) SubmitCommit(ctx context.Context, req *tenderclient.Request) ([]byte, *tenderclient.Commit, error) {
+	result, commit, _, err := t.SubmitTimed(ctx, req)
+	return result, commit, err
+}
+
+// SubmitTimed reports execution as endorsement and validation as commit; the fake has
+// no ordering phase
+func (t *transport) SubmitTimed(ctx context.Context, req *tenderclient.Request) ([]byte, *tenderclient.Commit, tenderclient.SubmitTiming, error) {
+	var timing tenderclient.SubmitTiming
+	start := time.Now()
+	result, stub, err := t.ledger.execute(ctx, t.actor, req)
+	timing.Endorse = time.Since(start)
+	if err != nil {
+		return nil, nil, timing, err
+	}
+	start = time.Now()
+	number, err := t.ledger.commit(stub)
+	timing.Commit = time.Since(start)
+	if err != nil {
+		return nil, nil, timing, err
+	}
+	return result, &tenderclient.Commit{TransactionID: stub.GetTxID(), BlockNumber: number}, timing, nil
+}
+
+func (t *transport) Close() error {
+	return nil
+}
+
+func (t *transport) ChaincodeEvents(ctx context.Context, from tenderclient.Checkpoint) (<-chan *tenderclient.ChaincodeEvent, error) {
+	out := make(chan *tenderclient.ChaincodeEvent)
+	go t.ledger.stream(ctx, from.BlockNumber, func(number uint64, b block) bool {
+		if b.event == nil || (number == from.BlockNumber && b.txID == from.TransactionID) {
+			return true
+		}
+		select {
+		case out <- b.event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+	return out, nil
+}
+
+func (t *transport) BlockEvents(ctx context.Context, startBlock uint64) (<-chan *tenderclient.Block, error) {
+	out := make(chan *tenderclient.Block)
+	go t.ledger.stream(ctx, startBlock, func(number uint64, b block) bool {
+		summary := &tenderclient.Block{Number: number}
+		if b.txID != "" {
+			summary.Transactions = []tenderclient.BlockTransaction{{TransactionID: b.txID, Code: b.code}}
+		}
+		select {
+		case out <- summary:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+	return out, nil
+}
+
+// stream calls deliver for every block from start, waiting for new blocks until ctx
+// is done or deliver returns false
+func (l *Ledger) stream(ctx context.Context, start uint64, deliver func(uint64, block) bool, done func()) {
+	defer done()
+	next := start
+	for {
+		l.mu.Lock()
+		var pending []block
+		if next < uint64(len(l.blocks)) {
+			pending = append(pending, l.blocks[next:]...)
+		}
+		committed := l.committed
+		l.mu.Unlock()
+
+		for _, b := range pending {
+			if !deliver(next, b) {
+				return
+			}
+			next++
+		}
+		select {
+		case <-committed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// execute runs a transaction through the contract API dispatcher, as the peer does,
+// without applying its writes
+func (l *Ledger) execute(ctx context.Context, actor events.Actor, req *tenderclient.Request) ([]byte, *mockstub.Stub, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id, ok := l.ids[actor]
+	if !ok {
+		var err error
+		if id, err = l.enroll(actor, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+	l.ledger.SetTime(l.now())
+	stub := l.ledger.NewStub(id, req.Transient, append([]string{req.Contract + ":" + req.Function}, req.Args...)...)
+	resp := l.cc.Invoke(stub)
+	if resp.Status >= errorThreshold {
+		return nil, nil, fmt.Errorf("%s", resp.Message)
+	}
+	return resp.Payload, stub, nil
+}
+
+// errorThreshold is shim.ERRORTHRESHOLD, the lowest status of a failed invocation
+const errorThreshold = 400
+
+// commit validates a transaction's reads and applies its writes and event. It returns
+// the number of the new block; an invalid transaction is still recorded in a block, as
+// on a peer, and returned as a *tenderclient.CommitError.
+func (l *Ledger) commit(stub *mockstub.Stub) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := block{txID: stub.GetTxID(), code: stub.Validate()}
+	if b.code == mockstub.Valid {
+		if err := stub.Commit(); err != nil {
+			return 0, err
+		}
+		if ev := stub.Event(); ev != nil {
+			b.event = &tenderclient.ChaincodeEvent{
+				BlockNumber:   uint64(len(l.blocks)),
+				TransactionID: b.txID,
+				EventName:     ev.Name,
+				Payload:       ev.Payload,
+			}
+		}
+	}
+	l.blocks = append(l.blocks, b)
+	close(l.committed)
+	l.committed = make(chan struct{})
+	number := uint64(len(l.blocks) - 1)
+	if b.code != mockstub.Valid {
+		return number, &tenderclient.CommitError{TxID: b.txID, Code: b.code}
+	}
+	return number, nil
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"sync"
+
+	"tenderclient"
+)
+
+// Clients opens one gateway connection per wallet identity, on first use. It
+// implements api.Clients.
+type Clients struct {
+	config  tenderclient.Config
+	mu      sync.Mutex
+	clients map[string]*tenderclient.Client
+}
+
+// NewClients connects with cfg, replacing cfg.Identity with the caller's identity
+func NewClients(cfg tenderclient.Config) *Clients {
+	return &Clients{config: cfg, clients: make(map[string]*tenderclient.Client)}
+}
+
+// Client returns the connection for identity
+func (g *Clients) Client(identity string) (*tenderclient.Client, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.clients[identity]; ok {
+		return c, nil
+	}
+	cfg := g.config
+	cfg.Identity = identity
+	c, err := Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	g.clients[identity] = c
+	return c, nil
+}
+
+// Close closes every connection
+func (g *Clients) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var first error
+	for identity, c := range g.clients {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+		delete(g.clients, identity)
+	}
+	return first
+}
@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+
+	"tenderclient"
+)
+
+func withDefaults(c tenderclient.Config) tenderclient.Config {
+	if c.Channel == "" {
+		c.Channel = tenderclient.DefaultChannel
+	}
+	if c.Chaincode == "" {
+		c.Chaincode = tenderclient.DefaultChaincode
+	}
+	if c.EvaluateTimeout == 0 {
+		c.EvaluateTimeout = 5 * time.Second
+	}
+	if c.EndorseTimeout == 0 {
+		c.EndorseTimeout = 15 * time.Second
+	}
+	if c.SubmitTimeout == 0 {
+		c.SubmitTimeout = 5 * time.Second
+	}
+	if c.CommitStatusTimeout == 0 {
+		c.CommitStatusTimeout = time.Minute
+	}
+	return c
+}
+
+// signer loads the configured identity and its signing function
+func signer(c tenderclient.Config) (*identity.X509Identity, identity.Sign, error) {
+	var mspID string
+	var certPEM, keyPEM []byte
+	switch {
+	case c.WalletPath != "":
+		if c.Identity == "" {
+			return nil, nil, fmt.Errorf("wallet identity label is required")
+		}
+		id, err := tenderclient.LoadWalletIdentity(c.WalletPath, c.Identity)
+		if err != nil {
+			return nil, nil, err
+		}
+		mspID = id.MSPID
+		certPEM = []byte(id.Credentials.Certificate)
+		keyPEM = []byte(id.Credentials.PrivateKey)
+	case c.CertPath != "" && c.KeyPath != "":
+		if c.MSPID == "" {
+			return nil, nil, fmt.Errorf("MSP ID is required with a certificate and key")
+		}
+		var err error
+		mspID = c.MSPID
+		if certPEM, err = readPEM(c.CertPath); err != nil {
+			return nil, nil, err
+		}
+		if keyPEM, err = readPEM(c.KeyPath); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("either a wallet or a certificate and key must be configured")
+	}
+
+	cert, err := identity.CertificateFromPEM(certPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	id, err := identity.NewX509Identity(mspID, cert)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := identity.PrivateKeyFromPEM(keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	sign, err := identity.NewPrivateKeySign(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, sign, nil
+}
+
+// readPEM reads a PEM file, or the first file of a directory such as an MSP keystore
+func readPEM(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				return os.ReadFile(filepath.Join(path, e.Name()))
+			}
+		}
+		return nil, fmt.Errorf("no files in %s", path)
+	}
+	return os.ReadFile(path)
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pemBytes, err := readPEM(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS CA certificate: %w", err)
+	}
+	cert, err := identity.CertificateFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return pool, nil
+}
@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	gatewaypb "github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tenderclient"
+)
+
+var chaincodeResponsePrefix = regexp.MustCompile(`^chaincode response \d+, `)
+
+// translate turns a gateway error into a *tenderclient.TransportError carrying the
+// chaincode's own message, taken from the endorsement error details when present
+func translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	out := &tenderclient.TransportError{Message: err.Error(), Err: err}
+	var txErr *client.TransactionError
+	if errors.As(err, &txErr) {
+		out.TxID = txErr.TransactionID
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return out
+	}
+	out.Message = st.Message()
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*gatewaypb.ErrorDetail); ok && d.Message != "" {
+			out.Message = chaincodeResponsePrefix.ReplaceAllString(d.Message, "")
+			return out
+		}
+	}
+	out.Unavailable = st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded
+	return out
+}
@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"errors"
+	"testing"
+
+	gatewaypb "github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tenderclient"
+)
+
+func TestTranslate(t *testing.T) {
+	st, err := status.New(codes.Aborted, "failed to endorse transaction").WithDetails(&gatewaypb.ErrorDetail{
+		Address: "peer0:7051",
+		MspId:   "Org1MSP",
+		Message: "chaincode response 500, only the tender owner may award tender T1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		err         error
+		message     string
+		unavailable bool
+	}{
+		{"endorsement detail", st.Err(), "only the tender owner may award tender T1", false},
+		{"plain status", status.Error(codes.Unknown, "tender T9 not found"), "tender T9 not found", false},
+		{"unreachable", status.Error(codes.Unavailable, "connection refused"), "connection refused", true},
+		{"not a status", errors.New("no peers available"), "no peers available", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var e *tenderclient.TransportError
+			err := translate(tc.err)
+			if !errors.As(err, &e) || e.Message != tc.message || e.Unavailable != tc.unavailable || !errors.Is(err, tc.err) {
+				t.Fatalf("translate = %#v", err)
+			}
+		})
+	}
+	if translate(nil) != nil {
+		t.Fatal("nil error translated")
+	}
+}
@@ -0,0 +1,206 @@
+// Package gateway implements tenderclient.Transport over the Fabric Gateway.
+//
+// It lives apart from tenderclient because the gateway protos (fabric-protos-go-apiv2)
+// and the chaincode shim's protos (fabric-protos-go) register the same message names,
+// and a binary linking both panics at start up. Programs that host the chaincode in
+// process, such as fakeledger, must not import this package.
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"tenderclient"
+)
+
+// Dial opens a Fabric Gateway transport described by cfg. Most callers want
+// Connect; Dial is for tools that send raw requests.
+func Dial(cfg tenderclient.Config) (tenderclient.Transport, error) {
+	return dial(withDefaults(cfg))
+}
+
+// Connect opens a Fabric Gateway connection described by cfg
+func Connect(cfg tenderclient.Config) (*tenderclient.Client, error) {
+	t, err := dial(withDefaults(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return tenderclient.New(t), nil
+}
+
+// transport sends requests through the Fabric Gateway
+type transport struct {
+	conn      *grpc.ClientConn
+	gateway   *client.Gateway
+	network   *client.Network
+	chaincode string
+}
+
+func dial(cfg tenderclient.Config) (*transport, error) {
+	if cfg.PeerEndpoint == "" {
+		return nil, fmt.Errorf("peer endpoint is required")
+	}
+	id, sign, err := signer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.TLSCACertPath != "" {
+		pool, err := loadCertPool(cfg.TLSCACertPath)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewClientTLSFromCert(pool, cfg.PeerHostOverride)
+	}
+	conn, err := grpc.NewClient(cfg.PeerEndpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", cfg.PeerEndpoint, err)
+	}
+
+	gw, err := client.Connect(id,
+		client.WithSign(sign),
+		client.WithClientConnection(conn),
+		client.WithEvaluateTimeout(cfg.EvaluateTimeout),
+		client.WithEndorseTimeout(cfg.EndorseTimeout),
+		client.WithSubmitTimeout(cfg.SubmitTimeout),
+		client.WithCommitStatusTimeout(cfg.CommitStatusTimeout),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &transport{
+		conn:      conn,
+		gateway:   gw,
+		network:   gw.GetNetwork(cfg.Channel),
+		chaincode: cfg.Chaincode,
+	}, nil
+}
+
+func (t *transport) proposal(req *tenderclient.Request) (*client.Proposal, error) {
+	contract := t.network.GetContractWithName(t.chaincode, req.Contract)
+	opts := []client.ProposalOption{client.WithArguments(req.Args...)}
+	if len(req.Transient) > 0 {
+		opts = append(opts, client.WithTransient(req.Transient))
+	}
+	if len(req.EndorsingOrgs) > 0 {
+		opts = append(opts, client.WithEndorsingOrganizations(req.EndorsingOrgs...))
+	}
+	return contract.NewProposal(req.Function, opts...)
+}
+
+func (t *transport) Evaluate(ctx context.Context, req *tenderclient.Request) ([]byte, error) {
+	proposal, err := t.proposal(req)
+	if err != nil {
+		return nil, err
+	}
+	result, err := proposal.EvaluateWithContext(ctx)
+	return result, translate(err)
+}
+
+func (t *transport) Submit(ctx context.Context, req *tenderclient.Request) ([]byte, error) {
+	result, _, err := t.SubmitCommit(ctx, req)
+	return result, err
+}
+
+func (t *transport) SubmitCommit(ctx context.Context, req *tenderclient.Request) ([]byte, *tenderclient.Commit, error) {
+	result, commit, _, err := t.SubmitTimed(ctx, req)
+	return result, commit, err
+}
+
+func (t *transport) SubmitTimed(ctx context.Context, req *tenderclient.Request) ([]byte, *tenderclient.Commit, tenderclient.SubmitTiming, error) {
+	var timing tenderclient.SubmitTiming
+	proposal, err := t.proposal(req)
+	if err != nil {
+		return nil, nil, timing, err
+	}
+	start := time.Now()
+	tx, err := proposal.EndorseWithContext(ctx)
+	timing.Endorse = time.Since(start)
+	if err != nil {
+		return nil, nil, timing, translate(err)
+	}
+	start = time.Now()
+	commit, err := tx.SubmitWithContext(ctx)
+	timing.Order = time.Since(start)
+	if err != nil {
+		return nil, nil, timing, translate(err)
+	}
+	start = time.Now()
+	status, err := commit.StatusWithContext(ctx)
+	timing.Commit = time.Since(start)
+	if err != nil {
+		return nil, nil, timing, translate(err)
+	}
+	if !status.Successful {
+		return nil, nil, timing, &tenderclient.CommitError{TxID: status.TransactionID, Code: status.Code.String()}
+	}
+	return tx.Result(), &tenderclient.Commit{TransactionID: status.TransactionID, BlockNumber: status.BlockNumber}, timing, nil
+}
+
+func (t *transport) Close() error {
+	t.gateway.Close()
+	return t.conn.Close()
+}
+
+// checkpoint adapts tenderclient.Checkpoint to client.Checkpoint
+type checkpoint struct{ cp tenderclient.Checkpoint }
+
+func (c checkpoint) BlockNumber() uint64   { return c.cp.BlockNumber }
+func (c checkpoint) TransactionID() string { return c.cp.TransactionID }
+
+func (t *transport) ChaincodeEvents(ctx context.Context, from tenderclient.Checkpoint) (<-chan *tenderclient.ChaincodeEvent, error) {
+	opts := []client.ChaincodeEventsOption{client.WithStartBlock(from.BlockNumber)}
+	if from.TransactionID != "" {
+		opts = append(opts, client.WithCheckpoint(checkpoint{from}))
+	}
+	in, err := t.network.ChaincodeEvents(ctx, t.chaincode, opts...)
+	if err != nil {
+		return nil, translate(err)
+	}
+	out := make(chan *tenderclient.ChaincodeEvent)
+	go func() {
+		defer close(out)
+		for ev := range in {
+			select {
+			case out <- &tenderclient.ChaincodeEvent{BlockNumber: ev.BlockNumber, TransactionID: ev.TransactionID, EventName: ev.EventName, Payload: ev.Payload}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (t *transport) BlockEvents(ctx context.Context, startBlock uint64) (<-chan *tenderclient.Block, error) {
+	in, err := t.network.FilteredBlockEvents(ctx, client.WithStartBlock(startBlock))
+	if err != nil {
+		return nil, translate(err)
+	}
+	out := make(chan *tenderclient.Block)
+	go func() {
+		defer close(out)
+		for fb := range in {
+			block := &tenderclient.Block{Number: fb.GetNumber()}
+			for _, ftx := range fb.GetFilteredTransactions() {
+				block.Transactions = append(block.Transactions, tenderclient.BlockTransaction{
+					TransactionID: ftx.GetTxid(),
+					Code:          ftx.GetTxValidationCode().String(),
+				})
+			}
+			select {
+			case out <- block:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package tenderclient
+
+import (
+	"context"
+
+	"tendercc/model"
+)
+
+// LegacyClient calls the original SmartContract tender transactions
+type LegacyClient struct {
+	c *Client
+}
+
+// Legacy returns a client for the original SmartContract transactions
+func (c *Client) Legacy() *LegacyClient {
+	return &LegacyClient{c: c}
+}
+
+// CreateTender creates a basic tender open between openAt and closeAt
+func (l *LegacyClient) CreateTender(ctx context.Context, tenderID, description, openAt, closeAt, criteria string, opts ...CallOption) error {
+	_, err := l.c.submit(ctx, ContractBasic, "CreateTender", opts, tenderID, description, openAt, closeAt, criteria)
+	return err
+}
+
+// GetTender reads a basic tender
+func (l *LegacyClient) GetTender(ctx context.Context, tenderID string) (*model.Tender, error) {
+	return evaluateAs[*model.Tender](l.c, ctx, ContractBasic, "GetTender", tenderID)
+}
+
+// ListTenders returns every basic tender
+func (l *LegacyClient) ListTenders(ctx context.Context) ([]*model.Tender, error) {
+	return evaluateAs[[]*model.Tender](l.c, ctx, ContractBasic, "ListTenders")
+}
+
+// SubmitBid submits a bid through the transient map
+func (l *LegacyClient) SubmitBid(ctx context.Context, tenderID, bidID string, bid *model.BidPrivate, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransientJSON(TransientBid, bid)}, opts...)
+	_, err := l.c.submit(ctx, ContractBasic, "SubmitBid", opts, tenderID, bidID)
+	return err
+}
+
+// ReadBidPrivate reads a bid from the bids collection
+func (l *LegacyClient) ReadBidPrivate(ctx context.Context, tenderID, bidID string) (*model.BidPrivate, error) {
+	return evaluateAs[*model.BidPrivate](l.c, ctx, ContractBasic, "ReadBidPrivate", tenderID, bidID)
+}
+
+// RecordEvaluation records a manual score for a bid
+func (l *LegacyClient) RecordEvaluation(ctx context.Context, tenderID, bidID string, score float64, notes string, opts ...CallOption) error {
+	_, err := l.c.submit(ctx, ContractBasic, "RecordEvaluation", opts, tenderID, bidID, formatFloat(score), notes)
+	return err
+}
+
+// CloseTender closes a basic tender
+func (l *LegacyClient) CloseTender(ctx context.Context, tenderID string, opts ...CallOption) error {
+	_, err := l.c.submit(ctx, ContractBasic, "CloseTender", opts, tenderID)
+	return err
+}
+
+// AwardTender awards a basic tender to a bid
+func (l *LegacyClient) AwardTender(ctx context.Context, tenderID, bidID string, opts ...CallOption) error {
+	_, err := l.c.submit(ctx, ContractBasic, "AwardTender", opts, tenderID, bidID)
+	return err
+}
+
+// GetTenderHistory returns the raw history values of a basic tender
+func (l *LegacyClient) GetTenderHistory(ctx context.Context, tenderID string) ([]string, error) {
+	return evaluateAs[[]string](l.c, ctx, ContractBasic, "GetTenderHistory", tenderID)
+}
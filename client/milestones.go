@@ -0,0 +1,45 @@
+package tenderclient
+
+import (
+	"context"
+
+	"tendercc/model"
+)
+
+// SubmitMilestone submits milestone evidence. The details travel in the transient
+// map and are stored only in the milestones collection.
+func (c *Client) SubmitMilestone(ctx context.Context, tenderID, milestoneID string, milestone *model.MilestonePrivate, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransientJSON(TransientMilestone, milestone)}, opts...)
+	_, err := c.submit(ctx, ContractBasic, "SubmitMilestone", opts, tenderID, milestoneID)
+	return err
+}
+
+// SubmitMilestoneJSON submits milestone evidence that is already encoded
+func (c *Client) SubmitMilestoneJSON(ctx context.Context, tenderID, milestoneID string, milestone []byte, opts ...CallOption) error {
+	opts = append([]CallOption{WithTransient(TransientMilestone, milestone)}, opts...)
+	_, err := c.submit(ctx, ContractBasic, "SubmitMilestone", opts, tenderID, milestoneID)
+	return err
+}
+
+// ReadMilestonePrivate reads milestone details from the milestones collection
+func (c *Client) ReadMilestonePrivate(ctx context.Context, tenderID, milestoneID string) (*model.MilestonePrivate, error) {
+	return evaluateAs[*model.MilestonePrivate](c, ctx, ContractBasic, "ReadMilestonePrivate", tenderID, milestoneID)
+}
+
+// ListMilestonesPublic returns the public references of a tender's milestones
+func (c *Client) ListMilestonesPublic(ctx context.Context, tenderID string) ([]*model.MilestoneRef, error) {
+	return evaluateAs[[]*model.MilestoneRef](c, ctx, ContractBasic, "ListMilestonesPublic", tenderID)
+}
+
+// ApproveMilestone approves a submitted milestone and releases its payment;
+// pass WithSignature when approvals must be signed
+func (c *Client) ApproveMilestone(ctx context.Context, tenderID, milestoneID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractBasic, "ApproveMilestone", opts, tenderID, milestoneID)
+	return err
+}
+
+// RejectMilestone rejects a submitted milestone
+func (c *Client) RejectMilestone(ctx context.Context, tenderID, milestoneID, reason string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractBasic, "RejectMilestone", opts, tenderID, milestoneID, reason)
+	return err
+}
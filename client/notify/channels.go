@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Channel delivers messages to one destination
+type Channel interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SMTPChannel sends each message as a plain text email to the subscription's recipients
+type SMTPChannel struct {
+	Addr string // host:port
+	From string
+	Auth smtp.Auth // nil for an unauthenticated relay
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("message %s has no email recipients", msg.ID)
+	}
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", c.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&body, "Message-ID: <%s@tendercc>\r\n", msg.ID)
+	fmt.Fprintf(&body, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return smtp.SendMail(c.Addr, c.Auth, c.From, msg.To, body.Bytes())
+}
+
+// Webhook request headers
+const (
+	HeaderDelivery  = "X-Tender-Delivery"  // message ID; the same on every retry
+	HeaderTimestamp = "X-Tender-Timestamp" // Unix seconds when the request was signed
+	HeaderSignature = "X-Tender-Signature" // sha256=<hex HMAC of timestamp "." body>
+)
+
+// WebhookChannel POSTs each message as JSON, signed with an HMAC of the shared secret.
+// Network errors, 429 and 5xx responses are retried with exponential backoff.
+type WebhookChannel struct {
+	URL         string
+	Secret      string
+	MaxAttempts int           // default 5
+	Backoff     time.Duration // first retry delay, doubled per attempt; default 1s
+	Client      *http.Client
+}
+
+// Sign returns the signature header value for a webhook body sent at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a received webhook request's signature header
+func VerifySignature(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	attempts, delay := c.MaxAttempts, c.Backoff
+	if attempts <= 0 {
+		attempts = 5
+	}
+	if delay <= 0 {
+		delay = time.Second
+	}
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := c.post(ctx, client, msg.ID, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == attempts {
+			return fmt.Errorf("webhook %s: attempt %d: %w", c.URL, attempt, err)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// post sends one attempt and reports whether a failure may be retried
+func (c *WebhookChannel) post(ctx context.Context, client *http.Client, id string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, id)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(c.Secret, timestamp, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	default:
+		return false, fmt.Errorf("status %s", resp.Status)
+	}
+}
+
+// FileChannel appends each message to a file as one line of JSON. It is meant for
+// local testing and auditing of what would have been sent.
+type FileChannel struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (c *FileChannel) Send(ctx context.Context, msg *Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := os.OpenFile(c.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
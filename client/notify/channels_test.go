@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"m1"}`)
+	sig := Sign("s3cret", "1700000000", body)
+	if !strings.HasPrefix(sig, "sha256=") || len(sig) != len("sha256=")+64 {
+		t.Fatalf("Sign = %q, want sha256=<64 hex digits>", sig)
+	}
+	if !VerifySignature("s3cret", "1700000000", body, sig) {
+		t.Error("signature does not verify")
+	}
+	for name, ok := range map[string]bool{
+		"other secret":    VerifySignature("other", "1700000000", body, sig),
+		"other timestamp": VerifySignature("s3cret", "1700000001", body, sig),
+		"other body":      VerifySignature("s3cret", "1700000000", []byte(`{"id":"m2"}`), sig),
+		"bare hex":        VerifySignature("s3cret", "1700000000", body, strings.TrimPrefix(sig, "sha256=")),
+	} {
+		if ok {
+			t.Errorf("%s: signature verified", name)
+		}
+	}
+}
+
+// webhookServer answers each request with the next status and records what it received
+type webhookServer struct {
+	mu         sync.Mutex
+	statuses   []int
+	deliveries []string
+	bad        []string // why requests failed verification
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !VerifySignature("s3cret", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) {
+		s.bad = append(s.bad, "signature")
+	}
+	var msg Message
+	if err := json.Unmarshal(body, &msg); err != nil || msg.ID != r.Header.Get(HeaderDelivery) {
+		s.bad = append(s.bad, "delivery "+r.Header.Get(HeaderDelivery))
+	}
+	s.deliveries = append(s.deliveries, r.Header.Get(HeaderDelivery))
+	status := http.StatusOK
+	if n := len(s.deliveries); n <= len(s.statuses) {
+		status = s.statuses[n-1]
+	}
+	w.WriteHeader(status)
+}
+
+// received returns the delivery header of every request and the verification failures
+func (s *webhookServer) received() (deliveries, bad []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.deliveries...), append([]string(nil), s.bad...)
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		wantErr  bool
+	}{
+		{"delivered", []int{200}, 1, false},
+		{"too many requests then delivered", []int{429, 429, 204}, 3, false},
+		{"server errors then delivered", []int{500, 502, 503, 200}, 4, false},
+		{"server errors until attempts run out", []int{503, 503, 503, 503, 503, 503}, 5, true},
+		{"client error is not retried", []int{400}, 1, true},
+		{"not found is not retried", []int{500, 404}, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &webhookServer{statuses: tt.statuses}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+			ch := &WebhookChannel{URL: srv.URL, Secret: "s3cret", Backoff: time.Millisecond}
+
+			err := ch.Send(context.Background(), &Message{ID: "reminder-T1-48h-sub1", Subject: "Bids due"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send error = %v, want error %v", err, tt.wantErr)
+			}
+			deliveries, bad := s.received()
+			if len(deliveries) != tt.attempts {
+				t.Errorf("attempts = %d, want %d", len(deliveries), tt.attempts)
+			}
+			for _, id := range deliveries {
+				if id != "reminder-T1-48h-sub1" {
+					t.Errorf("delivery header = %q, want the message ID on every attempt", id)
+				}
+			}
+			if len(bad) > 0 {
+				t.Errorf("requests failed verification: %v", bad)
+			}
+		})
+	}
+}
+
+func TestWebhookMaxAttempts(t *testing.T) {
+	s := &webhookServer{statuses: []int{503, 503, 503}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	ch := &WebhookChannel{URL: srv.URL, Secret: "s3cret", MaxAttempts: 2, Backoff: time.Millisecond}
+	if err := ch.Send(context.Background(), &Message{ID: "m1"}); err == nil || !strings.Contains(err.Error(), "attempt 2") {
+		t.Fatalf("Send error = %v, want a failure on attempt 2", err)
+	}
+	if deliveries, _ := s.received(); len(deliveries) != 2 {
+		t.Errorf("attempts = %d, want 2", len(deliveries))
+	}
+}
+
+// TestWebhookBackoff checks that retries wait the doubling backoff and stop when ctx ends
+func TestWebhookBackoff(t *testing.T) {
+	s := &webhookServer{statuses: []int{503, 503, 503, 503, 503}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	ch := &WebhookChannel{URL: srv.URL, Secret: "s3cret", Backoff: 40 * time.Millisecond}
+
+	// Retries wait 40ms, 80ms and 160ms, so the fourth attempt starts after 280ms
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := ch.Send(ctx, &Message{ID: "m1"}); err != context.DeadlineExceeded {
+		t.Fatalf("Send error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if deliveries, _ := s.received(); len(deliveries) != 3 {
+		t.Errorf("attempts within 200ms = %d, want 3", len(deliveries))
+	}
+}
@@ -0,0 +1,361 @@
+// Package notify tells bidders and buyers about tendercc activity as it happens.
+//
+// A Notifier follows the chaincode event stream and sends a templated message to
+// every matching subscription through its channel: SMTP email, a signed webhook or
+// a local file. It also sends reminders a configurable number of hours before an
+// open tender's questions and bid submission deadlines.
+//
+// Delivery is at least once. The event checkpoint and the reminders already sent
+// are saved in a state file after every event, so a restart resumes where the
+// notifier stopped; a crash between sending and saving repeats that one event's
+// messages, which carry the same Message.ID each time.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"time"
+
+	"tendercc/events"
+	"tendercc/model"
+	"tenderclient"
+)
+
+// Message is one notification for one subscription
+type Message struct {
+	ID             string          `json:"id"` // stable across redeliveries
+	Notice         string          `json:"notice"`
+	TenderID       string          `json:"tenderId,omitempty"`
+	SubscriptionID string          `json:"subscriptionId"`
+	To             []string        `json:"to,omitempty"`
+	Subject        string          `json:"subject"`
+	Body           string          `json:"body"`
+	TxID           string          `json:"txId,omitempty"`
+	Time           string          `json:"time"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscription selects the notices sent to one destination. A contractor
+// subscription receives publications and reminders for tenders open to that
+// contractor, events naming the contractor, and closures, awards and milestone
+// decisions on tenders it has bid on. A tender subscription receives every notice
+// about that tender. When both keys are set, both must match.
+type Subscription struct {
+	ID           string   `json:"id"`
+	ContractorID string   `json:"contractorId,omitempty"`
+	TenderID     string   `json:"tenderId,omitempty"`
+	Notices      []string `json:"notices,omitempty"` // event names or reminder kinds; empty for every notice with a template
+	Channel      string   `json:"channel"`
+	Recipients   []string `json:"recipients,omitempty"` // email addresses, for SMTP channels
+}
+
+func (s *Subscription) wants(notice string) bool {
+	if len(s.Notices) == 0 {
+		return true
+	}
+	for _, n := range s.Notices {
+		if n == notice {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures a Notifier
+type Config struct {
+	StateFile        string                   `json:"stateFile"`
+	Channels         map[string]ChannelConfig `json:"channels"`
+	Subscriptions    []Subscription           `json:"subscriptions"`
+	ReminderHours    []int                    `json:"reminderHours,omitempty"`    // e.g. [48, 24]
+	ReminderInterval time.Duration            `json:"reminderInterval,omitempty"` // how often deadlines are checked; default one minute
+	Templates        map[string]Template      `json:"templates,omitempty"`        // overrides DefaultTemplates by notice
+}
+
+// ChannelConfig describes a channel. Type is smtp, webhook or file.
+type ChannelConfig struct {
+	Type     string `json:"type"`
+	Addr     string `json:"addr,omitempty"` // smtp host:port
+	From     string `json:"from,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	URL         string `json:"url,omitempty"`
+	Secret      string `json:"secret,omitempty"`
+	MaxAttempts int    `json:"maxAttempts,omitempty"`
+
+	Path string `json:"path,omitempty"`
+}
+
+// OpenChannels creates the configured channels
+func OpenChannels(configs map[string]ChannelConfig) (map[string]Channel, error) {
+	channels := make(map[string]Channel, len(configs))
+	for name, c := range configs {
+		switch c.Type {
+		case "smtp":
+			ch := &SMTPChannel{Addr: c.Addr, From: c.From}
+			if c.Username != "" {
+				host, _, err := net.SplitHostPort(c.Addr)
+				if err != nil {
+					return nil, fmt.Errorf("channel %s: invalid address %s: %v", name, c.Addr, err)
+				}
+				ch.Auth = smtp.PlainAuth("", c.Username, c.Password, host)
+			}
+			channels[name] = ch
+		case "webhook":
+			if c.Secret == "" {
+				return nil, fmt.Errorf("channel %s: webhooks must have a secret", name)
+			}
+			channels[name] = &WebhookChannel{URL: c.URL, Secret: c.Secret, MaxAttempts: c.MaxAttempts}
+		case "file":
+			channels[name] = &FileChannel{Path: c.Path}
+		default:
+			return nil, fmt.Errorf("channel %s: unknown type %q", name, c.Type)
+		}
+	}
+	return channels, nil
+}
+
+// Notifier sends notices for the events read through a tenderclient.Client
+type Notifier struct {
+	client           *tenderclient.Client
+	channels         map[string]Channel
+	subs             []Subscription
+	templates        templates
+	reminderHours    []int
+	reminderInterval time.Duration
+	state            *state
+
+	// Now is the clock used for reminders; RetryDelay is the wait before resubscribing
+	Now        func() time.Time
+	RetryDelay time.Duration
+	Logger     *log.Logger
+}
+
+// New checks the subscriptions against channels and loads the state file. When the
+// state file does not exist yet, events committed before the first Run are not notified.
+func New(client *tenderclient.Client, cfg Config, channels map[string]Channel) (*Notifier, error) {
+	for _, sub := range cfg.Subscriptions {
+		if sub.ID == "" {
+			return nil, fmt.Errorf("subscription without an id")
+		}
+		if sub.ContractorID == "" && sub.TenderID == "" {
+			return nil, fmt.Errorf("subscription %s must name a contractorId or a tenderId", sub.ID)
+		}
+		if channels[sub.Channel] == nil {
+			return nil, fmt.Errorf("subscription %s uses unknown channel %q", sub.ID, sub.Channel)
+		}
+	}
+	ts, err := parseTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+	n := &Notifier{
+		client:           client,
+		channels:         channels,
+		subs:             cfg.Subscriptions,
+		templates:        ts,
+		reminderHours:    cfg.ReminderHours,
+		reminderInterval: cfg.ReminderInterval,
+		Now:              time.Now,
+		RetryDelay:       5 * time.Second,
+		Logger:           log.Default(),
+	}
+	if n.reminderInterval <= 0 {
+		n.reminderInterval = time.Minute
+	}
+	if n.state, err = loadState(cfg.StateFile); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Run follows the event stream and checks reminders until ctx is done
+func (n *Notifier) Run(ctx context.Context) error {
+	if err := n.state.start(n.Now()); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 2)
+	go func() { errs <- n.followEvents(ctx) }()
+	go func() { errs <- n.runReminders(ctx) }()
+
+	var first error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+			cancel()
+		}
+	}
+	return first
+}
+
+func (n *Notifier) followEvents(ctx context.Context) error {
+	for {
+		ch, err := n.client.ChaincodeEvents(ctx, n.state.checkpoint())
+		if err != nil {
+			n.Logger.Printf("chaincode events: %v; retrying in %s", err, n.RetryDelay)
+		} else {
+			for ev := range ch {
+				n.handleEvent(ctx, ev)
+				if err := n.state.advance(ev.Checkpoint()); err != nil {
+					return err
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		select {
+		case <-time.After(n.RetryDelay):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (n *Notifier) handleEvent(ctx context.Context, ev *tenderclient.ChaincodeEvent) {
+	envs, err := ev.Envelopes()
+	if err != nil {
+		n.Logger.Printf("skipping event %s in %s: %v", ev.EventName, ev.TransactionID, err)
+		return
+	}
+	for i, env := range envs {
+		if n.state.before(env.TxTime) {
+			continue
+		}
+		if n.templates[env.Name] == nil {
+			continue
+		}
+		payload := events.NewPayload(env.Name)
+		if payload == nil || env.Decode(payload) != nil {
+			n.Logger.Printf("skipping %s in %s: undecodable payload", env.Name, env.TxID)
+			continue
+		}
+		data := &TemplateData{Notice: env.Name, TenderID: env.TenderID, Payload: payload, TxTime: env.TxTime}
+		n.notify(ctx, data, fmt.Sprintf("%s-%d", env.TxID, i), contractorOf(payload), func(msg *Message) {
+			msg.TxID = env.TxID
+			msg.Time = env.TxTime
+			msg.Payload = env.Payload
+		})
+	}
+}
+
+// notify renders and sends a notice to every matching subscription
+func (n *Notifier) notify(ctx context.Context, data *TemplateData, id, contractorID string, fill func(*Message)) {
+	if data.TenderID != "" && data.Tender.ID == "" {
+		if t, err := n.client.GetEnhancedTender(ctx, data.TenderID); err == nil {
+			data.Tender = *t
+		}
+	}
+	data.Contact = data.Tender.OwnerDetails.ContactPerson
+
+	var bidders map[string]bool
+	for _, sub := range n.subs {
+		if !sub.wants(data.Notice) {
+			continue
+		}
+		if bidders == nil && sub.ContractorID != "" && contractorID != sub.ContractorID && !openNotice(data.Notice) {
+			bidders = n.bidders(ctx, data.TenderID)
+		}
+		if !matches(&sub, data, contractorID, bidders) {
+			continue
+		}
+		data.Subscription = sub
+		subject, body, ok, err := n.templates.render(data)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			n.Logger.Printf("subscription %s: %v", sub.ID, err)
+			continue
+		}
+		msg := &Message{
+			ID:             id + "-" + sub.ID,
+			Notice:         data.Notice,
+			TenderID:       data.TenderID,
+			SubscriptionID: sub.ID,
+			To:             sub.Recipients,
+			Subject:        subject,
+			Body:           body,
+		}
+		fill(msg)
+		if err := n.channels[sub.Channel].Send(ctx, msg); err != nil {
+			n.Logger.Printf("failed to send %s to subscription %s: %v", msg.ID, sub.ID, err)
+		}
+	}
+}
+
+// openNotice reports whether a notice concerns every contractor the tender is open to
+func openNotice(notice string) bool {
+	switch notice {
+	case events.TenderPublished, events.InviteesAdded, QuestionsDeadlineReminder, BidDeadlineReminder:
+		return true
+	}
+	return false
+}
+
+// matches applies the subscription rules described on Subscription
+func matches(sub *Subscription, data *TemplateData, contractorID string, bidders map[string]bool) bool {
+	if sub.TenderID != "" && sub.TenderID != data.TenderID {
+		return false
+	}
+	switch {
+	case sub.ContractorID == "":
+		return true
+	case contractorID == sub.ContractorID:
+		return true
+	case openNotice(data.Notice):
+		return openTo(&data.Tender, sub.ContractorID)
+	default:
+		return bidders[sub.ContractorID]
+	}
+}
+
+// openTo reports whether a contractor may bid on a tender
+func openTo(t *model.EnhancedTender, contractorID string) bool {
+	if t.ProcurementMethod == "" || t.ProcurementMethod == "OPEN" {
+		return true
+	}
+	for _, invitee := range t.Invitees {
+		if invitee == contractorID {
+			return true
+		}
+	}
+	return false
+}
+
+// bidders returns the contractors with a bid on a tender
+func (n *Notifier) bidders(ctx context.Context, tenderID string) map[string]bool {
+	set := make(map[string]bool)
+	refs, err := n.client.ListBidsPublic(ctx, tenderID)
+	if err != nil {
+		n.Logger.Printf("failed to list bids of %s: %v", tenderID, err)
+		return set
+	}
+	for _, r := range refs {
+		set[r.ContractorID] = true
+	}
+	return set
+}
+
+// contractorOf returns the contractor named by an event payload
+func contractorOf(payload interface{}) string {
+	switch p := payload.(type) {
+	case *events.BidSubmittedPayload:
+		return p.ContractorID
+	case *events.PenaltyPayload:
+		return p.ContractorID
+	case *events.RatingPayload:
+		return p.ContractorID
+	case *events.DebarmentPayload:
+		return p.ContractorID
+	case *events.CallOffPayload:
+		return p.ContractorID
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"tendercc/model"
+)
+
+func (n *Notifier) runReminders(ctx context.Context) error {
+	if len(n.reminderHours) == 0 {
+		return nil
+	}
+	ticker := time.NewTicker(n.reminderInterval)
+	defer ticker.Stop()
+	for {
+		if err := n.CheckReminders(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			n.Logger.Printf("reminders: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// CheckReminders sends the reminders that have come due for open tenders. When
+// several reminder offsets are due at once, for example after downtime, only the
+// closest to the deadline is sent.
+func (n *Notifier) CheckReminders(ctx context.Context) error {
+	now := n.Now()
+	filter := model.TenderFilter{Status: "OPEN", PageSize: 100}
+	for {
+		page, err := n.client.QueryTenders(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, t := range page.Tenders {
+			if err := n.remindTender(ctx, t, now); err != nil {
+				return err
+			}
+		}
+		if page.Bookmark == "" {
+			break
+		}
+		filter.Bookmark = page.Bookmark
+	}
+	return n.state.prune(now)
+}
+
+func (n *Notifier) remindTender(ctx context.Context, t *model.EnhancedTender, now time.Time) error {
+	for _, d := range []struct{ notice, deadline string }{
+		{QuestionsDeadlineReminder, t.Deadlines.QuestionsDeadline},
+		{BidDeadlineReminder, t.Deadlines.BidSubmissionDeadline},
+	} {
+		deadline, err := time.Parse(time.RFC3339, d.deadline)
+		if err != nil || !now.Before(deadline) {
+			continue
+		}
+		due := make(map[string]string)
+		closest := -1
+		for _, h := range n.reminderHours {
+			if now.Before(deadline.Add(-time.Duration(h) * time.Hour)) {
+				continue
+			}
+			due[fmt.Sprintf("%s|%s|%s|%d", t.ID, d.notice, d.deadline, h)] = d.deadline
+			if closest < 0 || h < closest {
+				closest = h
+			}
+		}
+		if closest < 0 {
+			continue
+		}
+		fresh, err := n.state.remind(due)
+		if err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s|%s|%s|%d", t.ID, d.notice, d.deadline, closest)
+		if !fresh[key] {
+			continue
+		}
+		data := &TemplateData{
+			Notice:    d.notice,
+			TenderID:  t.ID,
+			Tender:    *t,
+			Deadline:  d.deadline,
+			HoursLeft: int(math.Ceil(deadline.Sub(now).Hours())),
+		}
+		id := fmt.Sprintf("reminder-%s-%s-%dh", t.ID, d.notice, closest)
+		n.notify(ctx, data, id, "", func(msg *Message) {
+			msg.Time = now.UTC().Format(time.RFC3339)
+		})
+	}
+	return nil
+}
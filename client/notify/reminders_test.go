@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"tendercc/model"
+	"tenderclient"
+	"tenderclient/fakeledger"
+)
+
+var t0 = time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+
+// recordChannel keeps the IDs of the messages sent to it
+type recordChannel struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (c *recordChannel) Send(ctx context.Context, msg *Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids = append(c.ids, msg.ID)
+	return nil
+}
+
+func (c *recordChannel) sent() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.ids...)
+}
+
+// publishedTender opens tender T1 on a fake ledger, with bids due 100 hours after t0
+func publishedTender(t *testing.T) *tenderclient.Client {
+	t.Helper()
+	ledger := fakeledger.New()
+	ledger.SetClock(func() time.Time { return t0 })
+	client := ledger.Client("BuyerMSP", "buyer")
+	ctx := context.Background()
+	if err := client.CreateEnhancedTender(ctx, tenderFixture("T1", t0.Add(100*time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PublishTender(ctx, "T1"); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// newReminderNotifier sends bid deadline reminders for T1, 48 and 24 hours ahead, to ch
+func newReminderNotifier(t *testing.T, client *tenderclient.Client, ch Channel, now *time.Time) *Notifier {
+	t.Helper()
+	n, err := New(client, Config{
+		Subscriptions: []Subscription{{ID: "sub1", TenderID: "T1", Notices: []string{BidDeadlineReminder}, Channel: "rec"}},
+		ReminderHours: []int{48, 24},
+	}, map[string]Channel{"rec": ch})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Now = func() time.Time { return *now }
+	n.Logger = log.New(io.Discard, "", 0)
+	return n
+}
+
+func TestReminderPerOffset(t *testing.T) {
+	client := publishedTender(t)
+	ch := &recordChannel{}
+	now := t0
+	n := newReminderNotifier(t, client, ch, &now)
+	const (
+		reminder48 = "reminder-T1-BidDeadlineReminder-48h-sub1"
+		reminder24 = "reminder-T1-BidDeadlineReminder-24h-sub1"
+	)
+
+	// Each offset is sent once, however often the deadlines are checked
+	steps := []struct {
+		at   time.Duration // since t0; bids are due at 100h
+		want []string
+	}{
+		{1 * time.Hour, nil},
+		{51 * time.Hour, nil},
+		{53 * time.Hour, []string{reminder48}},
+		{54 * time.Hour, []string{reminder48}},
+		{77 * time.Hour, []string{reminder48, reminder24}},
+		{99 * time.Hour, []string{reminder48, reminder24}},
+		{101 * time.Hour, []string{reminder48, reminder24}},
+	}
+	for _, step := range steps {
+		now = t0.Add(step.at)
+		for i := 0; i < 2; i++ {
+			if err := n.CheckReminders(context.Background()); err != nil {
+				t.Fatalf("at +%s: %v", step.at, err)
+			}
+		}
+		if got := ch.sent(); !equalStrings(got, step.want) {
+			t.Fatalf("at +%s sent %v, want %v", step.at, got, step.want)
+		}
+	}
+}
+
+// TestReminderAfterDowntime starts checking once both offsets are due: only the
+// closest is sent, and the other is not sent later
+func TestReminderAfterDowntime(t *testing.T) {
+	client := publishedTender(t)
+	ch := &recordChannel{}
+	now := t0.Add(80 * time.Hour)
+	n := newReminderNotifier(t, client, ch, &now)
+
+	for _, at := range []time.Duration{80 * time.Hour, 90 * time.Hour} {
+		now = t0.Add(at)
+		if err := n.CheckReminders(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"reminder-T1-BidDeadlineReminder-24h-sub1"}
+	if got := ch.sent(); !equalStrings(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func rfc(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// tenderFixture returns a valid single-envelope tender with bids due at deadline
+func tenderFixture(id string, deadline time.Time) *model.EnhancedTender {
+	return &model.EnhancedTender{
+		ID: id,
+		ProjectScope: model.ProjectScope{
+			Description:      "Resurface district road " + id,
+			Objectives:       []string{"Restore ride quality"},
+			Deliverables:     []string{"Resurfaced carriageway"},
+			TechnicalSpecs:   []string{"50mm wearing course"},
+			QualityStandards: []string{"AASHTO M 323"},
+			Budget: model.Budget{
+				Currency:     "USD",
+				EstimatedMin: 400000,
+				EstimatedMax: 900000,
+				PaymentTerms: "MILESTONE_BASED",
+				PaymentSchedule: []model.PaymentMilestone{
+					{Name: "Base course", Percentage: 40},
+					{Name: "Wearing course", Percentage: 60},
+				},
+			},
+		},
+		Deadlines: model.TenderDeadlines{
+			QuestionsDeadline:     rfc(deadline.Add(-72 * time.Hour)),
+			BidSubmissionDeadline: rfc(deadline),
+			ProjectStartDate:      rfc(deadline.Add(30 * 24 * time.Hour)),
+			ProjectEndDate:        rfc(deadline.Add(180 * 24 * time.Hour)),
+		},
+		EvaluationCriteria: []model.EvalCriterion{
+			{ID: "C1", Name: "Price", Weight: 60, Type: "QUANTITATIVE", ScoringMethod: "LOWEST_PRICE"},
+			{ID: "C2", Name: "Safety", Weight: 40, Type: "QUALITATIVE", ScoringMethod: "HIGHEST_SCORE"},
+		},
+		BidRequirements: model.BidRequirements{
+			RequiredDocuments:         []model.DocumentReq{{Name: "Method statement", Mandatory: true, Format: "PDF"}},
+			TechnicalRequirements:     []model.TechnicalReq{},
+			CertificationRequirements: []model.CertificationReq{},
+			SubmissionFormat:          model.SubmissionFormat{Method: "ONLINE", FileFormats: []string{"PDF"}, MaxFileSize: 10},
+		},
+		ComplianceReqs: []model.ComplianceReq{},
+		OwnerDetails: model.OwnerInfo{
+			OrganizationName: "District Roads Authority",
+			Address:          model.Address{City: "Springfield", Country: "US"},
+			AuthorizedBy:     model.AuthorizedPerson{Name: "Dana Reyes", SignerID: "buyer"},
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tenderclient"
+)
+
+// state is what the notifier remembers across restarts
+type state struct {
+	path string
+	mu   sync.Mutex
+	data struct {
+		Checkpoint tenderclient.Checkpoint `json:"checkpoint"`
+		Since      string                  `json:"since"`     // events committed before this are not notified
+		Reminders  map[string]string       `json:"reminders"` // sent reminder key -> deadline
+	}
+}
+
+// loadState reads the state file, or starts an empty state when it does not exist.
+// An empty path keeps the state in memory only.
+func loadState(path string) (*state, error) {
+	s := &state{path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case path == "" || errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &s.data); err != nil {
+			return nil, fmt.Errorf("invalid state file %s: %v", path, err)
+		}
+	}
+	if s.data.Reminders == nil {
+		s.data.Reminders = make(map[string]string)
+	}
+	return s, nil
+}
+
+// start records the first start time of a new state
+func (s *state) start(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Since != "" {
+		return nil
+	}
+	s.data.Since = now.UTC().Format(time.RFC3339)
+	return s.save()
+}
+
+func (s *state) checkpoint() tenderclient.Checkpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Checkpoint
+}
+
+// before reports whether a transaction time precedes the notifier's first start
+func (s *state) before(txTime string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := time.Parse(time.RFC3339, txTime)
+	if err != nil {
+		return false
+	}
+	since, err := time.Parse(time.RFC3339, s.data.Since)
+	return err == nil && t.Before(since)
+}
+
+func (s *state) advance(cp tenderclient.Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Checkpoint = cp
+	return s.save()
+}
+
+// remind marks reminder keys as sent and reports which of them were not sent before
+func (s *state) remind(keys map[string]string) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fresh := make(map[string]bool)
+	for key, deadline := range keys {
+		if _, sent := s.data.Reminders[key]; !sent {
+			fresh[key] = true
+			s.data.Reminders[key] = deadline
+		}
+	}
+	if len(fresh) == 0 {
+		return fresh, nil
+	}
+	return fresh, s.save()
+}
+
+// prune forgets reminders whose deadline is more than a day before now
+func (s *state) prune(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := false
+	for key, deadline := range s.data.Reminders {
+		if d, err := time.Parse(time.RFC3339, deadline); err != nil || d.Before(now.Add(-24*time.Hour)) {
+			delete(s.data.Reminders, key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// save writes the state file atomically; the caller holds mu
+func (s *state) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(&s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".notify-state-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
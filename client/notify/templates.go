@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"tendercc/events"
+	"tendercc/model"
+)
+
+// Reminder notices, used in place of an event name
+const (
+	QuestionsDeadlineReminder = "QuestionsDeadlineReminder"
+	BidDeadlineReminder       = "BidDeadlineReminder"
+)
+
+// Template is the text/template source of a message subject and body
+type Template struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// TemplateData is what templates are executed with
+type TemplateData struct {
+	Notice       string // event name or reminder kind
+	TenderID     string
+	Tender       model.EnhancedTender // zero when the tender cannot be read
+	Contact      model.ContactPerson  // Tender.OwnerDetails.ContactPerson
+	Payload      interface{}          // decoded event payload; nil for reminders
+	TxTime       string
+	Subscription Subscription
+	Deadline     string // reminders only
+	HoursLeft    int    // reminders only
+}
+
+const contactFooter = `
+{{with .Contact}}{{if .Name}}Contact: {{.Name}}{{with .Title}}, {{.}}{{end}}{{with .Email}} <{{.}}>{{end}}{{with .Phone}}, {{.}}{{end}}
+{{end}}{{end}}`
+
+// DefaultTemplates cover publications, closures, awards, milestone decisions and reminders
+var DefaultTemplates = map[string]Template{
+	events.TenderPublished: {
+		Subject: "Tender {{.TenderID}} is open for bids",
+		Body: `{{.Tender.OwnerDetails.OrganizationName}} has published tender {{.TenderID}}: {{.Tender.ProjectScope.Description}}
+Questions are due by {{.Tender.Deadlines.QuestionsDeadline}} and bids by {{.Tender.Deadlines.BidSubmissionDeadline}}.` + contactFooter,
+	},
+	events.TenderClosed: {
+		Subject: "Tender {{.TenderID}} is closed",
+		Body:    `Tender {{.TenderID}} ({{.Tender.ProjectScope.Description}}) closed to bids at {{.Payload.At}}.` + contactFooter,
+	},
+	events.BidWindowClosed: {
+		Subject: "Tender {{.TenderID}} is closed",
+		Body:    `Tender {{.TenderID}} closed to bids at {{.TxTime}}.` + contactFooter,
+	},
+	events.TenderAwarded: {
+		Subject: "Tender {{.TenderID}} has been awarded",
+		Body:    `Tender {{.TenderID}} ({{.Tender.ProjectScope.Description}}) was awarded to bid {{.Payload.BidID}} at {{.Payload.AwardedAt}}.` + contactFooter,
+	},
+	events.EnhancedBidSubmitted: {
+		Subject: "Bid {{.Payload.BidID}} received for tender {{.TenderID}}",
+		Body:    `Bid {{.Payload.BidID}} from {{.Payload.ContractorID}} was recorded on tender {{.TenderID}} at {{.TxTime}}.` + contactFooter,
+	},
+	events.InviteesAdded: {
+		Subject: "Invitations issued for tender {{.TenderID}}",
+		Body:    `{{.Payload.Added}} contractor(s) were invited to tender {{.TenderID}}: {{.Tender.ProjectScope.Description}}.` + contactFooter,
+	},
+	events.MilestoneApproved: {
+		Subject: "Milestone {{.Payload.MilestoneID}} of tender {{.TenderID}} approved",
+		Body:    `Milestone {{.Payload.MilestoneID}} ({{.Payload.Title}}) was approved{{if .Payload.PaymentReleased}} and its payment released{{end}}.` + contactFooter,
+	},
+	events.MilestoneRejected: {
+		Subject: "Milestone {{.Payload.MilestoneID}} of tender {{.TenderID}} rejected",
+		Body:    `Milestone {{.Payload.MilestoneID}} ({{.Payload.Title}}) was rejected.` + contactFooter,
+	},
+	events.ContractTerminated: {
+		Subject: "Contract for tender {{.TenderID}} terminated",
+		Body:    `The contract for tender {{.TenderID}} was terminated: {{.Payload.Reason}}` + contactFooter,
+	},
+	QuestionsDeadlineReminder: {
+		Subject: "Questions on tender {{.TenderID}} close in {{.HoursLeft}} hours",
+		Body:    `Clarification questions on tender {{.TenderID}} ({{.Tender.ProjectScope.Description}}) must be submitted by {{.Deadline}}.` + contactFooter,
+	},
+	BidDeadlineReminder: {
+		Subject: "Bids for tender {{.TenderID}} are due in {{.HoursLeft}} hours",
+		Body:    `Bids for tender {{.TenderID}} ({{.Tender.ProjectScope.Description}}) must be submitted by {{.Deadline}}.` + contactFooter,
+	},
+}
+
+// templates holds the parsed templates for each notice
+type templates map[string]*template.Template
+
+func parseTemplates(overrides map[string]Template) (templates, error) {
+	sources := make(map[string]Template, len(DefaultTemplates))
+	for notice, t := range DefaultTemplates {
+		sources[notice] = t
+	}
+	for notice, t := range overrides {
+		sources[notice] = t
+	}
+	parsed := make(templates, len(sources))
+	for notice, src := range sources {
+		t, err := template.New(notice).Option("missingkey=zero").Parse(`{{define "subject"}}` + src.Subject + `{{end}}{{define "body"}}` + src.Body + `{{end}}`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %v", notice, err)
+		}
+		parsed[notice] = t
+	}
+	return parsed, nil
+}
+
+// render returns the subject and body for a notice, or ok=false when it has no template
+func (ts templates) render(data *TemplateData) (subject, body string, ok bool, err error) {
+	t := ts[data.Notice]
+	if t == nil {
+		return "", "", false, nil
+	}
+	var s, b bytes.Buffer
+	if err := t.ExecuteTemplate(&s, "subject", data); err != nil {
+		return "", "", true, fmt.Errorf("template %s: %v", data.Notice, err)
+	}
+	if err := t.ExecuteTemplate(&b, "body", data); err != nil {
+		return "", "", true, fmt.Errorf("template %s: %v", data.Notice, err)
+	}
+	return s.String(), b.String(), true, nil
+}
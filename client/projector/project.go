@@ -0,0 +1,123 @@
+package projector
+
+import (
+	"context"
+	"database/sql"
+
+	"tendercc/events"
+)
+
+// project records one envelope and applies it to the projection tables
+func project(ctx context.Context, tx *sql.Tx, block uint64, seq int, env events.Envelope) error {
+	if _, err := tx.ExecContext(ctx, `INSERT INTO events (tx_id, seq, block_number, name, schema_version, tender_id, msp_id, client_id, tx_time, payload)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		env.TxID, seq, block, env.Name, env.SchemaVersion, env.TenderID, env.Actor.MSPID, env.Actor.ClientID, env.TxTime, string(env.Payload)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO search (name, tender_id, text, tx_id, seq) VALUES (?, ?, ?, ?, ?)`,
+		env.Name, env.TenderID, searchText(env.Payload), env.TxID, seq); err != nil {
+		return err
+	}
+
+	// Events from a newer chaincode, or payloads that do not decode, are kept in
+	// events and search only
+	payload := events.NewPayload(env.Name)
+	if payload == nil || env.Decode(payload) != nil {
+		return nil
+	}
+	exec := func(query string, args ...interface{}) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	switch p := payload.(type) {
+	case *events.TenderCreatedPayload:
+		return exec(`INSERT INTO tenders (tender_id, description, owner, status, created_at, updated_at, last_tx_id) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (tender_id) DO UPDATE SET description = excluded.description, owner = excluded.owner, status = excluded.status,
+				created_at = excluded.created_at, updated_at = excluded.updated_at, last_tx_id = excluded.last_tx_id`,
+			p.TenderID, p.Description, p.Owner, p.Status, p.CreatedAt, env.TxTime, env.TxID)
+
+	case *events.RFQCreatedPayload:
+		return exec(`INSERT INTO tenders (tender_id, description, status, legacy, created_at, updated_at, last_tx_id) VALUES (?, ?, ?, 1, ?, ?, ?)
+			ON CONFLICT (tender_id) DO UPDATE SET description = excluded.description, status = excluded.status, legacy = 1,
+				updated_at = excluded.updated_at, last_tx_id = excluded.last_tx_id`,
+			p.TenderID, p.Description, p.Status, env.TxTime, env.TxTime, env.TxID)
+
+	case *events.TenderStatusPayload:
+		at := p.At
+		if at == "" {
+			at = env.TxTime
+		}
+		if err := upsertTender(exec, p.TenderID, p.Status, env); err != nil {
+			return err
+		}
+		switch env.Name {
+		case events.TenderPublished:
+			return exec(`UPDATE tenders SET published_at = ? WHERE tender_id = ?`, at, p.TenderID)
+		case events.TenderClosed, events.BidWindowClosed:
+			return exec(`UPDATE tenders SET closed_at = ? WHERE tender_id = ?`, at, p.TenderID)
+		}
+		return nil
+
+	case *events.BidSubmittedPayload:
+		submittedAt := p.SubmittedAt
+		if submittedAt == "" {
+			submittedAt = env.TxTime
+		}
+		res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO bids (tender_id, bid_id, contractor_id, bid_hash, encrypted, submitted_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			p.TenderID, p.BidID, p.ContractorID, p.BidHash, p.Encrypted, submittedAt)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+		if err := upsertTender(exec, p.TenderID, "", env); err != nil {
+			return err
+		}
+		return exec(`UPDATE tenders SET bid_count = bid_count + 1 WHERE tender_id = ?`, p.TenderID)
+
+	case *events.BidEvaluatedPayload:
+		return exec(`INSERT INTO bids (tender_id, bid_id, score) VALUES (?, ?, ?)
+			ON CONFLICT (tender_id, bid_id) DO UPDATE SET score = excluded.score`, p.TenderID, p.BidID, p.Score)
+
+	case *events.TenderAwardedPayload:
+		awardedAt := p.AwardedAt
+		if awardedAt == "" {
+			awardedAt = env.TxTime
+		}
+		if err := upsertTender(exec, p.TenderID, p.Status, env); err != nil {
+			return err
+		}
+		if err := exec(`UPDATE tenders SET awarded_bid_id = ?, awarded_at = ? WHERE tender_id = ?`, p.BidID, awardedAt, p.TenderID); err != nil {
+			return err
+		}
+		return exec(`UPDATE bids SET awarded = (bid_id = ?) WHERE tender_id = ?`, p.BidID, p.TenderID)
+
+	case *events.MilestonePayload:
+		return exec(`INSERT INTO milestones (tender_id, milestone_id, title, status, payment_released, submitted_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (tender_id, milestone_id) DO UPDATE SET title = excluded.title, status = excluded.status,
+				payment_released = excluded.payment_released, updated_at = excluded.updated_at`,
+			p.TenderID, p.MilestoneID, p.Title, p.Status, p.PaymentReleased, p.SubmittedAt, env.TxTime)
+
+	case *events.PaymentReleasedPayload:
+		return exec(`UPDATE milestones SET payment_released = 1, updated_at = ? WHERE tender_id = ? AND milestone_id = ?`,
+			env.TxTime, p.TenderID, p.MilestoneID)
+
+	case *events.DebarmentPayload:
+		return exec(`INSERT INTO debarments (contractor_id, reason, start_date, end_date, debarred_by, lifted_at, lift_reason) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (contractor_id) DO UPDATE SET reason = excluded.reason, start_date = excluded.start_date, end_date = excluded.end_date,
+				debarred_by = excluded.debarred_by, lifted_at = excluded.lifted_at, lift_reason = excluded.lift_reason`,
+			p.ContractorID, p.Reason, p.StartDate, p.EndDate, p.DebarredBy, p.LiftedAt, p.LiftReason)
+	}
+	return nil
+}
+
+// upsertTender records the tender's latest status, creating the row for a tender
+// whose creation event predates the projection's first event
+func upsertTender(exec func(string, ...interface{}) error, tenderID, status string, env events.Envelope) error {
+	return exec(`INSERT INTO tenders (tender_id, status, updated_at, last_tx_id) VALUES (?, ?, ?, ?)
+		ON CONFLICT (tender_id) DO UPDATE SET status = CASE WHEN excluded.status = '' THEN tenders.status ELSE excluded.status END,
+			updated_at = excluded.updated_at, last_tx_id = excluded.last_tx_id`,
+		tenderID, status, env.TxTime, env.TxID)
+}
@@ -0,0 +1,120 @@
+// Package projector maintains a SQLite projection of the tendercc event stream for
+// dashboards, full-text search and reporting.
+//
+// A Projector follows the chaincode events and block events of the channel. Each
+// chaincode event is applied in the same database transaction that advances the
+// stored checkpoint, so after a restart the projector resumes exactly where it
+// stopped, without missing or duplicating events. Reset empties the store so that
+// the next Run rebuilds it from the genesis block.
+package projector
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tenderclient"
+)
+
+// Projector applies the events delivered by a tenderclient.Client to a Store
+type Projector struct {
+	client *tenderclient.Client
+	store  *Store
+	// RetryDelay is how long to wait before resubscribing after a stream ends
+	RetryDelay time.Duration
+	Logger     *log.Logger
+}
+
+// New creates a projector with a five second retry delay, logging to the standard logger
+func New(client *tenderclient.Client, store *Store) *Projector {
+	return &Projector{client: client, store: store, RetryDelay: 5 * time.Second, Logger: log.Default()}
+}
+
+// Run follows both streams until ctx is done or the store fails. It returns nil when ctx ends.
+func (p *Projector) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 2)
+	go func() { errs <- p.follow(ctx, "chaincode events", p.chaincodeEvents) }()
+	go func() { errs <- p.follow(ctx, "block events", p.blockEvents) }()
+
+	var first error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+			cancel()
+		}
+	}
+	return first
+}
+
+// Rebuild empties the store and then runs from the genesis block
+func (p *Projector) Rebuild(ctx context.Context) error {
+	if err := p.store.Reset(ctx); err != nil {
+		return err
+	}
+	p.Logger.Printf("projection reset; rebuilding from genesis")
+	return p.Run(ctx)
+}
+
+// follow resubscribes to a stream each time it ends, until ctx is done. A store
+// error stops it; a stream that fails to open or closes is retried.
+func (p *Projector) follow(ctx context.Context, name string, stream func(context.Context) (bool, error)) error {
+	for {
+		subscribed, err := stream(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && subscribed {
+			return err
+		}
+		if err != nil {
+			p.Logger.Printf("%s: %v; retrying in %s", name, err, p.RetryDelay)
+		} else {
+			p.Logger.Printf("%s: stream ended; resubscribing in %s", name, p.RetryDelay)
+		}
+		select {
+		case <-time.After(p.RetryDelay):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// chaincodeEvents applies chaincode events from the stored checkpoint until the
+// stream ends. It reports whether the subscription succeeded.
+func (p *Projector) chaincodeEvents(ctx context.Context) (bool, error) {
+	from, err := p.store.Checkpoint(ctx)
+	if err != nil {
+		return true, err
+	}
+	ch, err := p.client.ChaincodeEvents(ctx, from)
+	if err != nil {
+		return false, err
+	}
+	p.Logger.Printf("chaincode events: following from block %d", from.BlockNumber)
+	for ev := range ch {
+		if err := p.store.ApplyEvent(ctx, ev); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// blockEvents records block summaries from the next unrecorded block until the stream ends
+func (p *Projector) blockEvents(ctx context.Context) (bool, error) {
+	next, err := p.store.NextBlock(ctx)
+	if err != nil {
+		return true, err
+	}
+	ch, err := p.client.BlockEvents(ctx, next)
+	if err != nil {
+		return false, err
+	}
+	for b := range ch {
+		if err := p.store.ApplyBlock(ctx, b); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
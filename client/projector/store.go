@@ -0,0 +1,313 @@
+package projector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	"tenderclient"
+)
+
+// Checkpoint streams
+const (
+	streamChaincode = "chaincode"
+	streamBlocks    = "blocks"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS checkpoint (
+	stream         TEXT PRIMARY KEY,
+	block_number   INTEGER NOT NULL,
+	transaction_id TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS events (
+	tx_id          TEXT NOT NULL,
+	seq            INTEGER NOT NULL,
+	block_number   INTEGER NOT NULL,
+	name           TEXT NOT NULL,
+	schema_version INTEGER NOT NULL,
+	tender_id      TEXT NOT NULL DEFAULT '',
+	msp_id         TEXT NOT NULL DEFAULT '',
+	client_id      TEXT NOT NULL DEFAULT '',
+	tx_time        TEXT NOT NULL DEFAULT '',
+	payload        TEXT NOT NULL,
+	PRIMARY KEY (tx_id, seq)
+);
+CREATE INDEX IF NOT EXISTS events_tender ON events (tender_id, block_number);
+CREATE INDEX IF NOT EXISTS events_name ON events (name, block_number);
+CREATE TABLE IF NOT EXISTS tenders (
+	tender_id      TEXT PRIMARY KEY,
+	description    TEXT NOT NULL DEFAULT '',
+	owner          TEXT NOT NULL DEFAULT '',
+	status         TEXT NOT NULL DEFAULT '',
+	legacy         INTEGER NOT NULL DEFAULT 0,
+	created_at     TEXT NOT NULL DEFAULT '',
+	published_at   TEXT NOT NULL DEFAULT '',
+	closed_at      TEXT NOT NULL DEFAULT '',
+	awarded_bid_id TEXT NOT NULL DEFAULT '',
+	awarded_at     TEXT NOT NULL DEFAULT '',
+	bid_count      INTEGER NOT NULL DEFAULT 0,
+	updated_at     TEXT NOT NULL DEFAULT '',
+	last_tx_id     TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS tenders_status ON tenders (status);
+CREATE TABLE IF NOT EXISTS bids (
+	tender_id     TEXT NOT NULL,
+	bid_id        TEXT NOT NULL,
+	contractor_id TEXT NOT NULL DEFAULT '',
+	bid_hash      TEXT NOT NULL DEFAULT '',
+	encrypted     INTEGER NOT NULL DEFAULT 0,
+	submitted_at  TEXT NOT NULL DEFAULT '',
+	score         REAL,
+	awarded       INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (tender_id, bid_id)
+);
+CREATE INDEX IF NOT EXISTS bids_contractor ON bids (contractor_id);
+CREATE TABLE IF NOT EXISTS milestones (
+	tender_id        TEXT NOT NULL,
+	milestone_id     TEXT NOT NULL,
+	title            TEXT NOT NULL DEFAULT '',
+	status           TEXT NOT NULL DEFAULT '',
+	payment_released INTEGER NOT NULL DEFAULT 0,
+	submitted_at     TEXT NOT NULL DEFAULT '',
+	updated_at       TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (tender_id, milestone_id)
+);
+CREATE TABLE IF NOT EXISTS debarments (
+	contractor_id TEXT PRIMARY KEY,
+	reason        TEXT NOT NULL DEFAULT '',
+	start_date    TEXT NOT NULL DEFAULT '',
+	end_date      TEXT NOT NULL DEFAULT '',
+	debarred_by   TEXT NOT NULL DEFAULT '',
+	lifted_at     TEXT NOT NULL DEFAULT '',
+	lift_reason   TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS blocks (
+	block_number   INTEGER PRIMARY KEY,
+	tx_count       INTEGER NOT NULL,
+	invalid_count  INTEGER NOT NULL,
+	mvcc_conflicts INTEGER NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS search USING fts5 (
+	name, tender_id UNINDEXED, text, tx_id UNINDEXED, seq UNINDEXED
+);
+CREATE VIEW IF NOT EXISTS tender_report AS
+	SELECT t.tender_id, t.status, t.description, t.owner, t.bid_count,
+		MAX(b.score) AS best_score, t.awarded_bid_id,
+		(SELECT COUNT(*) FROM milestones m WHERE m.tender_id = t.tender_id) AS milestones,
+		(SELECT COUNT(*) FROM milestones m WHERE m.tender_id = t.tender_id AND m.payment_released) AS milestones_paid
+	FROM tenders t LEFT JOIN bids b ON b.tender_id = t.tender_id
+	GROUP BY t.tender_id;
+`
+
+// projectionTables are emptied by Reset
+var projectionTables = []string{"checkpoint", "events", "tenders", "bids", "milestones", "debarments", "blocks", "search"}
+
+// Store is the SQLite projection of the tendercc event stream. Dashboards and
+// reports may query its tables directly through DB.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens or creates the database at path
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	// A single connection serialises the projector's writes
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema in %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// DB returns the underlying database for reporting queries
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Checkpoint returns the position after the last applied chaincode event
+func (s *Store) Checkpoint(ctx context.Context) (tenderclient.Checkpoint, error) {
+	return s.checkpoint(ctx, streamChaincode)
+}
+
+// NextBlock returns the number of the first block not yet recorded
+func (s *Store) NextBlock(ctx context.Context) (uint64, error) {
+	cp, err := s.checkpoint(ctx, streamBlocks)
+	return cp.BlockNumber, err
+}
+
+func (s *Store) checkpoint(ctx context.Context, stream string) (tenderclient.Checkpoint, error) {
+	var cp tenderclient.Checkpoint
+	err := s.db.QueryRowContext(ctx, `SELECT block_number, transaction_id FROM checkpoint WHERE stream = ?`, stream).
+		Scan(&cp.BlockNumber, &cp.TransactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return tenderclient.Checkpoint{}, nil
+	}
+	return cp, err
+}
+
+func setCheckpoint(ctx context.Context, tx *sql.Tx, stream string, cp tenderclient.Checkpoint) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO checkpoint (stream, block_number, transaction_id) VALUES (?, ?, ?)
+		ON CONFLICT (stream) DO UPDATE SET block_number = excluded.block_number, transaction_id = excluded.transaction_id`,
+		stream, cp.BlockNumber, cp.TransactionID)
+	return err
+}
+
+// ApplyEvent records a chaincode event, updates the projections and advances the
+// checkpoint in one database transaction, so an event is applied exactly once
+// however often it is delivered. A payload that cannot be decoded is kept in
+// events under its chaincode event name and otherwise skipped.
+func (s *Store) ApplyEvent(ctx context.Context, ev *tenderclient.ChaincodeEvent) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var seen int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM events WHERE tx_id = ?`, ev.TransactionID).Scan(&seen); err != nil {
+		return err
+	}
+	if seen == 0 {
+		envs, err := ev.Envelopes()
+		if err != nil {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO events (tx_id, seq, block_number, name, schema_version, payload) VALUES (?, 0, ?, ?, 0, ?)`,
+				ev.TransactionID, ev.BlockNumber, ev.EventName, string(ev.Payload)); err != nil {
+				return err
+			}
+		}
+		for i, env := range envs {
+			if err := project(ctx, tx, ev.BlockNumber, i, env); err != nil {
+				return fmt.Errorf("failed to project %s in %s: %w", env.Name, ev.TransactionID, err)
+			}
+		}
+	}
+	if err := setCheckpoint(ctx, tx, streamChaincode, ev.Checkpoint()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ApplyBlock records a block summary and advances the block checkpoint
+func (s *Store) ApplyBlock(ctx context.Context, b *tenderclient.Block) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var invalid, conflicts int
+	for _, t := range b.Transactions {
+		if !t.Valid() {
+			invalid++
+		}
+		if t.Code == "MVCC_READ_CONFLICT" || t.Code == "PHANTOM_READ_CONFLICT" {
+			conflicts++
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO blocks (block_number, tx_count, invalid_count, mvcc_conflicts) VALUES (?, ?, ?, ?)`,
+		b.Number, len(b.Transactions), invalid, conflicts); err != nil {
+		return err
+	}
+	if err := setCheckpoint(ctx, tx, streamBlocks, tenderclient.Checkpoint{BlockNumber: b.Number + 1}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Reset empties every table and checkpoint so that the next run rebuilds from genesis
+func (s *Store) Reset(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, table := range projectionTables {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchResult is an event matching a full-text query
+type SearchResult struct {
+	TxID        string `json:"txId"`
+	Seq         int    `json:"seq"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Name        string `json:"name"`
+	TenderID    string `json:"tenderId,omitempty"`
+	TxTime      string `json:"txTime"`
+	Snippet     string `json:"snippet"`
+}
+
+// Search runs an FTS5 query over event names and the text of their payloads,
+// best matches first
+func (s *Store) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.tx_id, e.seq, e.block_number, e.name, e.tender_id, e.tx_time, snippet(search, 2, '[', ']', '...', 12)
+		FROM search JOIN events e ON e.tx_id = search.tx_id AND e.seq = search.seq
+		WHERE search MATCH ? ORDER BY rank LIMIT ?`, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search %q failed: %w", query, err)
+	}
+	defer rows.Close()
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		if err := rows.Scan(&r.TxID, &r.Seq, &r.BlockNumber, &r.Name, &r.TenderID, &r.TxTime, &r.Snippet); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// searchText collects the string values of a JSON payload
+func searchText(payload json.RawMessage) string {
+	var v interface{}
+	if json.Unmarshal(payload, &v) != nil {
+		return ""
+	}
+	var parts []string
+	var walk func(interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			parts = append(parts, v)
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(v[k])
+			}
+		}
+	}
+	walk(v)
+	return strings.Join(parts, " ")
+}
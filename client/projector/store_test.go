@@ -0,0 +1,277 @@
+package projector
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tendercc/model"
+	"tenderclient"
+	"tenderclient/fakeledger"
+)
+
+var t0 = time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+
+// fixture is a fake ledger with one legacy tender, one bid and a closed bid window,
+// so three transactions in blocks 1 to 3
+type fixture struct {
+	t      *testing.T
+	ledger *fakeledger.Ledger
+	client *tenderclient.Client
+	path   string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ledger := fakeledger.New()
+	ledger.SetClock(func() time.Time { return t0 })
+	f := &fixture{t: t, ledger: ledger, client: ledger.Client("BuyerMSP", "buyer"), path: filepath.Join(t.TempDir(), "projection.db")}
+
+	ctx := context.Background()
+	legacy := f.client.Legacy()
+	if err := legacy.CreateTender(ctx, "T1", "Bridge deck repair", rfc(t0.Add(-time.Hour)), rfc(t0.Add(time.Hour)), "lowest price"); err != nil {
+		t.Fatal(err)
+	}
+	bid := &model.BidPrivate{TenderID: "T1", BidID: "B1", ContractorID: "contractorA", Amount: 120000, DocsHash: "abc"}
+	if err := ledger.Client("ContractorMSP", "contractor").Legacy().SubmitBid(ctx, "T1", "B1", bid); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.CloseTender(ctx, "T1"); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func rfc(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func (f *fixture) open() *Store {
+	f.t.Helper()
+	store, err := Open(f.path)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// chaincodeEvents reads n events from the stream starting after from
+func (f *fixture) chaincodeEvents(from tenderclient.Checkpoint, n int) []*tenderclient.ChaincodeEvent {
+	f.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ch, err := f.client.ChaincodeEvents(ctx, from)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	var out []*tenderclient.ChaincodeEvent
+	for len(out) < n {
+		select {
+		case ev := <-ch:
+			out = append(out, ev)
+		case <-ctx.Done():
+			f.t.Fatalf("got %d of %d events from %+v", len(out), n, from)
+		}
+	}
+	return out
+}
+
+// count returns the result of a COUNT query
+func count(t *testing.T, store *Store, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := store.DB().QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+// checkProjection asserts the tables hold the fixture's three transactions once each
+func checkProjection(t *testing.T, store *Store) {
+	t.Helper()
+	if n := count(t, store, `SELECT COUNT(*) FROM events`); n != 3 {
+		t.Errorf("events = %d, want 3", n)
+	}
+	if n := count(t, store, `SELECT COUNT(*) FROM search`); n != 3 {
+		t.Errorf("search rows = %d, want 3", n)
+	}
+	if n := count(t, store, `SELECT COUNT(*) FROM bids WHERE tender_id = 'T1'`); n != 1 {
+		t.Errorf("bids = %d, want 1", n)
+	}
+	var status string
+	var bidCount int
+	if err := store.DB().QueryRow(`SELECT status, bid_count FROM tenders WHERE tender_id = 'T1'`).Scan(&status, &bidCount); err != nil {
+		t.Fatal(err)
+	}
+	if status != "CLOSED" || bidCount != 1 {
+		t.Errorf("tender T1 = %s with %d bids, want CLOSED with 1", status, bidCount)
+	}
+}
+
+// TestApplyEventRedelivery applies every event twice, as a stream resubscribed
+// before its checkpoint was stored would deliver them
+func TestApplyEventRedelivery(t *testing.T) {
+	f := newFixture(t)
+	store := f.open()
+	ctx := context.Background()
+
+	evs := f.chaincodeEvents(tenderclient.Checkpoint{}, 3)
+	for _, ev := range evs {
+		for i := 0; i < 2; i++ {
+			if err := store.ApplyEvent(ctx, ev); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// A late redelivery of the bid must not count it again
+	if err := store.ApplyEvent(ctx, evs[1]); err != nil {
+		t.Fatal(err)
+	}
+	checkProjection(t, store)
+
+	cp, err := store.Checkpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp != evs[1].Checkpoint() {
+		t.Errorf("checkpoint = %+v, want the last applied event %+v", cp, evs[1].Checkpoint())
+	}
+}
+
+// TestApplyEventResume stops after the first event and resumes from the stored
+// checkpoint in a reopened store
+func TestApplyEventResume(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	store := f.open()
+	first := f.chaincodeEvents(tenderclient.Checkpoint{}, 1)[0]
+	if err := store.ApplyEvent(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store = f.open()
+	cp, err := store.Checkpoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp != first.Checkpoint() {
+		t.Fatalf("checkpoint after reopening = %+v, want %+v", cp, first.Checkpoint())
+	}
+	rest := f.chaincodeEvents(cp, 2)
+	if rest[0].TransactionID == first.TransactionID {
+		t.Fatalf("stream resumed at the applied event %s", first.TransactionID)
+	}
+	for _, ev := range rest {
+		if err := store.ApplyEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkProjection(t, store)
+}
+
+// TestApplyBlock records the ledger's blocks, redelivers one and checks that
+// invalid transactions are counted by kind
+func TestApplyBlock(t *testing.T) {
+	f := newFixture(t)
+	store := f.open()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ch, err := f.client.BlockEvents(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		b := <-ch
+		if b == nil {
+			t.Fatalf("block stream closed after %d blocks", i)
+		}
+		if err := store.ApplyBlock(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 {
+			if err := store.ApplyBlock(ctx, b); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	conflict := &tenderclient.Block{Number: 4, Transactions: []tenderclient.BlockTransaction{
+		{TransactionID: "tx-a", Code: "VALID"},
+		{TransactionID: "tx-b", Code: "MVCC_READ_CONFLICT"},
+		{TransactionID: "tx-c", Code: "ENDORSEMENT_POLICY_FAILURE"},
+	}}
+	if err := store.ApplyBlock(ctx, conflict); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := store.NextBlock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 5 {
+		t.Errorf("next block = %d, want 5", next)
+	}
+	if n := count(t, store, `SELECT COUNT(*) FROM blocks`); n != 5 {
+		t.Errorf("blocks = %d, want 5", n)
+	}
+	if n := count(t, store, `SELECT SUM(tx_count) FROM blocks WHERE block_number < 4`); n != 3 {
+		t.Errorf("transactions in blocks 0-3 = %d, want 3", n)
+	}
+	var txs, invalid, conflicts int
+	if err := store.DB().QueryRow(`SELECT tx_count, invalid_count, mvcc_conflicts FROM blocks WHERE block_number = 4`).Scan(&txs, &invalid, &conflicts); err != nil {
+		t.Fatal(err)
+	}
+	if txs != 3 || invalid != 2 || conflicts != 1 {
+		t.Errorf("block 4 = %d txs, %d invalid, %d conflicts; want 3, 2, 1", txs, invalid, conflicts)
+	}
+}
+
+// TestRebuild replaces a stale projection with one rebuilt from genesis
+func TestRebuild(t *testing.T) {
+	f := newFixture(t)
+	store := f.open()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := store.DB().Exec(`INSERT INTO tenders (tender_id, status) VALUES ('STALE', 'OPEN')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyEvent(ctx, f.chaincodeEvents(tenderclient.Checkpoint{}, 3)[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(f.client, store)
+	p.RetryDelay = 10 * time.Millisecond
+	p.Logger = log.New(io.Discard, "", 0)
+	done := make(chan error, 1)
+	runCtx, stop := context.WithCancel(ctx)
+	go func() { done <- p.Rebuild(runCtx) }()
+
+	// Wait until both streams have caught up with the three blocks
+	for count(t, store, `SELECT COUNT(*) FROM events`) < 3 || count(t, store, `SELECT COUNT(*) FROM blocks`) < 4 {
+		select {
+		case err := <-done:
+			t.Fatalf("Rebuild returned early: %v", err)
+		case <-ctx.Done():
+			t.Fatal("projection did not catch up")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	stop()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if n := count(t, store, `SELECT COUNT(*) FROM tenders WHERE tender_id = 'STALE'`); n != 0 {
+		t.Errorf("stale tender survived the rebuild")
+	}
+	checkProjection(t, store)
+}
@@ -0,0 +1,68 @@
+package tenderclient
+
+import (
+	"context"
+
+	"tendercc/model"
+)
+
+// RegisterVendor registers the caller's vendor profile
+func (c *Client) RegisterVendor(ctx context.Context, vendor *model.VendorProfile, opts ...CallOption) error {
+	arg, err := marshalArg("RegisterVendor", vendor)
+	if err != nil {
+		return err
+	}
+	_, err = c.submit(ctx, ContractEnhanced, "RegisterVendor", opts, arg)
+	return err
+}
+
+// UpdateVendor replaces a vendor profile
+func (c *Client) UpdateVendor(ctx context.Context, vendor *model.VendorProfile, opts ...CallOption) error {
+	arg, err := marshalArg("UpdateVendor", vendor)
+	if err != nil {
+		return err
+	}
+	_, err = c.submit(ctx, ContractEnhanced, "UpdateVendor", opts, arg)
+	return err
+}
+
+// VerifyVendorDocument marks one of a vendor's documents as verified
+func (c *Client) VerifyVendorDocument(ctx context.Context, vendorID, documentHash string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "VerifyVendorDocument", opts, vendorID, documentHash)
+	return err
+}
+
+// SetVendorStatus changes a vendor's registration status
+func (c *Client) SetVendorStatus(ctx context.Context, vendorID, status string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "SetVendorStatus", opts, vendorID, status)
+	return err
+}
+
+// GetVendor reads a vendor profile
+func (c *Client) GetVendor(ctx context.Context, vendorID string) (*model.VendorProfile, error) {
+	return evaluateAs[*model.VendorProfile](c, ctx, ContractEnhanced, "GetVendor", vendorID)
+}
+
+// ListVendors returns every vendor profile
+func (c *Client) ListVendors(ctx context.Context) ([]*model.VendorProfile, error) {
+	return evaluateAs[[]*model.VendorProfile](c, ctx, ContractEnhanced, "ListVendors")
+}
+
+// AttachDocument links an off-chain document to a tender, bid, milestone or vendor
+func (c *Client) AttachDocument(ctx context.Context, doc *model.DocumentAttachment, opts ...CallOption) (*model.DocumentLink, error) {
+	arg, err := marshalArg("AttachDocument", doc)
+	if err != nil {
+		return nil, err
+	}
+	return submitAs[*model.DocumentLink](c, ctx, ContractEnhanced, "AttachDocument", opts, arg)
+}
+
+// VerifyDocument reports where a document hash is anchored on the ledger
+func (c *Client) VerifyDocument(ctx context.Context, hash string) (*model.DocumentVerification, error) {
+	return evaluateAs[*model.DocumentVerification](c, ctx, ContractEnhanced, "VerifyDocument", hash)
+}
+
+// ListDocuments returns a tender's documents, optionally narrowed to one link type and reference
+func (c *Client) ListDocuments(ctx context.Context, tenderID, linkType, refID string) ([]*model.DocumentLink, error) {
+	return evaluateAs[[]*model.DocumentLink](c, ctx, ContractEnhanced, "ListDocuments", tenderID, linkType, refID)
+}
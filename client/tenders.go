@@ -0,0 +1,126 @@
+package tenderclient
+
+import (
+	"context"
+	"encoding/json"
+
+	"tendercc/model"
+)
+
+// TenderStatistics is the result of GetTenderStatistics
+type TenderStatistics struct {
+	TenderID           string         `json:"tenderId"`
+	Status             string         `json:"status"`
+	TotalBids          int            `json:"totalBids"`
+	ProjectDescription string         `json:"projectDescription"`
+	Owner              string         `json:"owner"`
+	CreatedAt          string         `json:"createdAt"`
+	UpdatedAt          string         `json:"updatedAt"`
+	BidStatistics      *BidStatistics `json:"bidStatistics,omitempty"`
+}
+
+// BidStatistics summarises the bid amounts readable by the caller's organisation
+type BidStatistics struct {
+	TotalAmount float64 `json:"totalAmount"`
+	AverageBid  float64 `json:"averageBid"`
+	LowestBid   float64 `json:"lowestBid"`
+	HighestBid  float64 `json:"highestBid"`
+	Currency    string  `json:"currency"`
+}
+
+// CreateEnhancedTender creates a tender in DRAFT status
+func (c *Client) CreateEnhancedTender(ctx context.Context, tender *model.EnhancedTender, opts ...CallOption) error {
+	arg, err := marshalArg("CreateEnhancedTender", tender)
+	if err != nil {
+		return err
+	}
+	_, err = c.submit(ctx, ContractEnhanced, "CreateEnhancedTender", opts, arg)
+	return err
+}
+
+// CreateEnhancedTenderJSON creates a tender from an encoded RFQ document, passing it to
+// the chaincode unchanged so that its own validation sees every field
+func (c *Client) CreateEnhancedTenderJSON(ctx context.Context, tender []byte, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "CreateEnhancedTender", opts, string(tender))
+	return err
+}
+
+// ValidateTender runs the chaincode's CreateEnhancedTender checks on tender without
+// writing it, returning every issue found
+func (c *Client) ValidateTender(ctx context.Context, tender *model.EnhancedTender) (*model.TenderValidationReport, error) {
+	arg, err := marshalArg("ValidateTender", tender)
+	if err != nil {
+		return nil, err
+	}
+	return c.ValidateTenderJSON(ctx, []byte(arg))
+}
+
+// ValidateTenderJSON validates an encoded RFQ document as CreateEnhancedTenderJSON would
+// submit it, including fields the model does not know
+func (c *Client) ValidateTenderJSON(ctx context.Context, tender []byte) (*model.TenderValidationReport, error) {
+	return evaluateAs[*model.TenderValidationReport](c, ctx, ContractEnhanced, "ValidateTender", string(tender))
+}
+
+// PublishTender moves a DRAFT tender to OPEN
+func (c *Client) PublishTender(ctx context.Context, tenderID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "PublishTender", opts, tenderID)
+	return err
+}
+
+// CloseTender closes an OPEN tender to further bids
+func (c *Client) CloseTender(ctx context.Context, tenderID string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "CloseTenderEnhanced", opts, tenderID)
+	return err
+}
+
+// GetEnhancedTender reads a tender
+func (c *Client) GetEnhancedTender(ctx context.Context, tenderID string) (*model.EnhancedTender, error) {
+	return evaluateAs[*model.EnhancedTender](c, ctx, ContractEnhanced, "GetEnhancedTender", tenderID)
+}
+
+// GetTenderStatistics summarises a tender and its bids
+func (c *Client) GetTenderStatistics(ctx context.Context, tenderID string) (*TenderStatistics, error) {
+	return evaluateAs[*TenderStatistics](c, ctx, ContractEnhanced, "GetTenderStatistics", tenderID)
+}
+
+// GetTendersByStatus returns the first page of tenders in a status
+func (c *Client) GetTendersByStatus(ctx context.Context, status string) ([]*model.EnhancedTender, error) {
+	return evaluateAs[[]*model.EnhancedTender](c, ctx, ContractEnhanced, "GetTendersByStatus", status)
+}
+
+// QueryTenders returns one page of tenders matching filter
+func (c *Client) QueryTenders(ctx context.Context, filter model.TenderFilter) (*model.TenderQueryResult, error) {
+	arg, err := marshalArg("QueryTenders", filter)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateAs[*model.TenderQueryResult](c, ctx, ContractEnhanced, "QueryTenders", arg)
+}
+
+// AddInvitees adds contractors to a restricted or invited tender
+func (c *Client) AddInvitees(ctx context.Context, tenderID string, invitees []string, opts ...CallOption) error {
+	arg, err := marshalArg("AddInvitees", invitees)
+	if err != nil {
+		return err
+	}
+	_, err = c.submit(ctx, ContractEnhanced, "AddInvitees", opts, tenderID, arg)
+	return err
+}
+
+// RecordSingleSourceJustification records why a tender is single-sourced
+func (c *Client) RecordSingleSourceJustification(ctx context.Context, tenderID, reason, details, documentHash string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "RecordSingleSourceJustification", opts, tenderID, reason, details, documentHash)
+	return err
+}
+
+// ApproveSingleSourceAward adds the caller's organisation's sign-off to a single-source award
+func (c *Client) ApproveSingleSourceAward(ctx context.Context, tenderID, comment string, opts ...CallOption) error {
+	_, err := c.submit(ctx, ContractEnhanced, "ApproveSingleSourceAward", opts, tenderID, comment)
+	return err
+}
+
+// GetSchemas returns the JSON Schemas the chaincode enforces on RFQ, bid and milestone
+// documents, keyed by type name (EnhancedTender, EnhancedBidPrivate, MilestonePrivate)
+func (c *Client) GetSchemas(ctx context.Context) (map[string]json.RawMessage, error) {
+	return evaluateAs[map[string]json.RawMessage](c, ctx, ContractEnhanced, "GetSchemas")
+}
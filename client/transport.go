@@ -0,0 +1,67 @@
+package tenderclient
+
+import (
+	"context"
+	"time"
+)
+
+// Contract names registered by the tendercc chaincode. ContractSystem is the
+// contract API's built-in contract that serves the chaincode metadata.
+const (
+	ContractBasic    = "SmartContract"
+	ContractEnhanced = "EnhancedSmartContract"
+	ContractSystem   = "org.hyperledger.fabric"
+)
+
+// Request is one chaincode transaction
+type Request struct {
+	Contract      string
+	Function      string
+	Args          []string
+	Transient     map[string][]byte
+	EndorsingOrgs []string
+}
+
+// Transport sends requests to the chaincode. Package gateway implements it over the
+// Fabric Gateway and package fakeledger in memory; tools and tests can pass their
+// own implementation to New. Transports report chaincode failures as *TransportError
+// or *CommitError.
+type Transport interface {
+	// Evaluate runs a query on a single peer without ordering it
+	Evaluate(ctx context.Context, req *Request) ([]byte, error)
+	// Submit endorses, orders and waits for the commit of a transaction
+	Submit(ctx context.Context, req *Request) ([]byte, error)
+	Close() error
+}
+
+// Commit locates a committed transaction
+type Commit struct {
+	TransactionID string
+	BlockNumber   uint64
+}
+
+// CommitReporter is implemented by transports that can report where a submitted
+// transaction was committed, for example to collect its chaincode event
+type CommitReporter interface {
+	SubmitCommit(ctx context.Context, req *Request) ([]byte, *Commit, error)
+}
+
+// SubmitTiming splits the latency of a submitted transaction into its phases.
+// A phase that was not reached is zero.
+type SubmitTiming struct {
+	Endorse time.Duration // proposal sent until the endorsed transaction was assembled
+	Order   time.Duration // endorsed transaction sent until the orderer accepted it
+	Commit  time.Duration // accepted until the peer reported the commit status
+}
+
+// Total is the time from proposal to commit status
+func (t SubmitTiming) Total() time.Duration {
+	return t.Endorse + t.Order + t.Commit
+}
+
+// TimedSubmitter is implemented by transports that time the phases of a submit.
+// The timing is returned even when the transaction fails, for example with a
+// *CommitError after an MVCC read conflict.
+type TimedSubmitter interface {
+	SubmitTimed(ctx context.Context, req *Request) ([]byte, *Commit, SubmitTiming, error)
+}
@@ -50,6 +50,9 @@ with open(rfq_path, 'r', encoding='utf-8') as f:
 
 # Force the RFQ id to the desired tender id
 obj['id'] = os.environ['TID']
+# peer chaincode invoke cannot attach detached signatures; scenarios/civil-flow.yaml
+# runs the signed flow of the sample
+obj['bidRequirements']['submissionFormat']['digitalSignature'] = False
 
 now = datetime.now(timezone.utc)
 obj.setdefault('deadlines', {})
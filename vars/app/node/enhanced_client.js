@@ -51,8 +51,11 @@ async function run() {
   try {
     switch (cmd) {
       case 'createSampleRFQ': {
-        const rfq = fs.readFileSync(path.resolve('../../../samples/rfq/construction-rfq-sample.json'), 'utf8');
-        await contract.submitTransaction('EnhancedSmartContract:CreateEnhancedTender', rfq);
+        const rfq = JSON.parse(fs.readFileSync(path.resolve('../../../samples/rfq/construction-rfq-sample.json'), 'utf8'));
+        // This demo does not sign bids or awards; tenderctl -signature and
+        // scenarios/civil-flow.yaml cover the signed flow of the sample
+        rfq.bidRequirements.submissionFormat.digitalSignature = false;
+        await contract.submitTransaction('EnhancedSmartContract:CreateEnhancedTender', JSON.stringify(rfq));
         console.log('RFQ created');
         break;
       }
@@ -26,13 +26,18 @@ const path = require('path');
     const contract = network.getContract(chaincodeName);
 
     console.log(`Listening for events on ${channelName}/${chaincodeName}... (Ctrl+C to exit)`);
+    // Events are versioned envelopes; transactions raising several events send them as one TenderEvents batch
     await contract.addContractListener(async (event) => {
+      let envelopes = [];
       try {
-        const payload = event.payload ? event.payload.toString() : '';
-        console.log(`[${new Date().toISOString()}] Event: ${event.eventName}`);
-        if (payload) console.log(payload);
+        const data = JSON.parse(event.payload ? event.payload.toString() : '{}');
+        envelopes = event.eventName === 'TenderEvents' ? data.events : [data];
       } catch (e) {
-        console.error('Listener error:', e.message);
+        console.error(`Unreadable event ${event.eventName}: ${e.message}`);
+        return;
+      }
+      for (const env of envelopes) {
+        console.log(`[${env.txTime}] Event ${env.name} v${env.schemaVersion} tender=${env.tenderId || '-'} by ${env.actor.mspId}: ${JSON.stringify(env.payload)}`);
       }
     });
   } catch (e) {
@@ -25,14 +25,22 @@ async function main() {
   const network = await gateway.getNetwork(channel);
   const contract = network.getContract(chaincode);
 
+  // Events are versioned envelopes; transactions raising several events send them as one TenderEvents batch
   const listener = async (event) => {
-    const name = event.eventName;
-    let payload = '';
-    try { payload = event.payload ? event.payload.toString() : ''; } catch (_) {}
-    console.log(`[${new Date().toISOString()}] Event ${name}: ${payload}`);
+    let envelopes = [];
+    try {
+      const data = JSON.parse(event.payload ? event.payload.toString() : '{}');
+      envelopes = event.eventName === 'TenderEvents' ? data.events : [data];
+    } catch (e) {
+      console.error(`Unreadable event ${event.eventName}: ${e.message}`);
+      return;
+    }
+    for (const env of envelopes) {
+      console.log(`[${env.txTime}] Event ${env.name} v${env.schemaVersion} tender=${env.tenderId || '-'} by ${env.actor.mspId}: ${JSON.stringify(env.payload)}`);
+    }
   };
 
-  await contract.addContractListener(listener, 'RFQCreated', 'BidSubmitted', 'BidWindowClosed', 'BidEvaluated', 'TenderAwarded', 'MilestoneSubmitted', 'MilestoneApproved', 'MilestoneRejected', 'PaymentReleased');
+  await contract.addContractListener(listener, 'TenderEvents', 'RFQCreated', 'BidSubmitted', 'BidWindowClosed', 'BidEvaluated', 'TenderAwarded', 'MilestoneSubmitted', 'MilestoneApproved', 'MilestoneRejected', 'PaymentReleased');
   console.log('Listening for contract events... Press Ctrl+C to exit');
 }
 